@@ -4,9 +4,10 @@ ZN=zinc
 ZN_DEV=zinc-devtool
 ZN_SERVER=zinc-server
 ZN_PLAYGROUND=zinc-playground
+ZN_LSP=zinc-lsp
 
 # build Zn
-rm -f ./$ZN ./$ZN_DEV ./$ZN_SERVER ./$ZN_PLAYGROUND
+rm -f ./$ZN ./$ZN_DEV ./$ZN_SERVER ./$ZN_PLAYGROUND ./$ZN_LSP
 
 echo '=== build [Zn] ==='
 go build -o zinc ./cmd/$ZN
@@ -19,3 +20,6 @@ go build -o zinc-server ./cmd/$ZN_SERVER
 
 echo '=== build [Zn-playground] ==='
 go build -o zinc-playground ./cmd/$ZN_PLAYGROUND
+
+echo '=== build [Zn-lsp] ==='
+go build -o zinc-lsp ./cmd/$ZN_LSP
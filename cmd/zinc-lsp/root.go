@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	zinc "github.com/DemoHn/Zn"
+	"github.com/DemoHn/Zn/pkg/lsp"
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "zinc-lsp",
+	Short: "Zn 语言服务器",
+	Long:  "Zn 语言服务器 - 通过标准输入/输出提供语言服务器协议（Language Server Protocol）服务，供编辑器显示语法错误、文档符号、跳转定义、悬停提示及自动补全",
+	Args:  cobra.NoArgs,
+	Run: func(c *cobra.Command, args []string) {
+		server := lsp.NewServer(os.Stdin, os.Stdout, zinc.ZINC_VERSION)
+		if err := server.Serve(); err != nil {
+			fmt.Fprintf(os.Stderr, "语言服务器异常退出：%s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func main() {
+	rootCmd.Execute()
+}
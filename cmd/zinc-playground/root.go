@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	zinc "github.com/DemoHn/Zn"
+	"github.com/DemoHn/Zn/pkg/runtime"
 	"github.com/DemoHn/Zn/pkg/server"
 	"github.com/spf13/cobra"
 )
@@ -16,13 +18,40 @@ var (
 	maxProcs        int
 	initProcs       int
 	timeout         int
+	execLimits      runtime.ExecLimits
+	allowedLibs     []string
+	fileRoots       []string
+	httpHosts       []string
+	frozenTime      string
+	randomSeed      int64
 
 	rootCmd = &cobra.Command{
 		Use:   "zinc-playground",
 		Short: "zinc playground",
 		Long:  "zinc playground - 在启动服务器之后，用户发送HTTP请求并提交代码后即可执行，并返回对应的结果；这样用户可以线上编写并运行代码",
 		Run: func(c *cobra.Command, args []string) {
-			interpreter := zinc.NewInterpreter()
+			// scripts from playground are untrusted, so files & hosts are denied by default
+			policy := &runtime.PermissionPolicy{
+				AllowedLibs: allowedLibs,
+				FileRoots:   fileRoots,
+				HTTPHosts:   httpHosts,
+			}
+			interpreter := zinc.NewInterpreter().
+				SetExecLimits(execLimits).
+				SetPermissionPolicy(policy)
+			// freeze the time of @时间, so that the results are deterministic
+			if frozenTime != "" {
+				t, err := time.Parse(time.RFC3339, frozenTime)
+				if err != nil {
+					fmt.Printf("冻结时间的格式不符合要求：%s\n", err.Error())
+					return
+				}
+				interpreter.SetClock(runtime.NewFrozenClock(t))
+			}
+			// fix the seed of random numbers, so that the results are reproducible
+			if c.Flags().Changed("random-seed") {
+				interpreter.SetRandomSeed(randomSeed)
+			}
 			// set Playground MODE
 			playgroundHandler := zinc.NewPlaygroundHandler(interpreter)
 			// set FPM server (instead of goroutine server)
@@ -49,5 +78,20 @@ func main() {
 	rootCmd.Flags().IntVar(&initProcs, "init-procs", 20, "初始创建子进程数量")
 
 	rootCmd.Flags().IntVar(&timeout, "timeout", 60, "执行超时时间，单位为秒")
+
+	// resource limits of executing untrusted code (0 = no limit)
+	rootCmd.Flags().IntVar(&execLimits.MaxSteps, "max-steps", 1000000, "限制最大执行语句数")
+	rootCmd.Flags().IntVar(&execLimits.MaxCallDepth, "max-call-depth", 1000, "限制最大调用深度")
+	rootCmd.Flags().IntVar(&execLimits.MaxArraySize, "max-array-size", 100000, "限制元组的最大长度")
+	rootCmd.Flags().IntVar(&execLimits.MaxHashMapSize, "max-hashmap-size", 100000, "限制列表的最大长度")
+	rootCmd.Flags().IntVar(&execLimits.MaxStringSize, "max-string-size", 1048576, "限制文本的最大长度（字节）")
+
+	// permission policy
+	rootCmd.Flags().StringSliceVar(&allowedLibs, "allow-lib", nil, "允许导入的库（如 @JSON），不设置则允许导入所有库")
+	rootCmd.Flags().StringSliceVar(&fileRoots, "allow-file-root", nil, "允许@文件读写的目录，不设置则禁止读写任何文件")
+	rootCmd.Flags().StringSliceVar(&httpHosts, "allow-http-host", nil, "允许@HTTP访问的主机（如 api.example.com 或 *.example.com），不设置则禁止访问任何主机")
+
+	rootCmd.Flags().StringVar(&frozenTime, "frozen-time", "", "冻结@时间返回的当前时间（RFC 3339格式，如 2024-03-15T08:30:00+08:00），不设置则使用系统时间")
+	rootCmd.Flags().Int64Var(&randomSeed, "random-seed", 0, "固定随机数的种子，使取随机数及@数学的随机结果可复现，不设置则以当前时间为种子")
 	rootCmd.Execute()
 }
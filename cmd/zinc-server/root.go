@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"time"
 
 	zinc "github.com/DemoHn/Zn"
 	"github.com/spf13/cobra"
@@ -13,6 +15,10 @@ const defaultConnUrl = "tcp://127.0.0.1:3862"
 var (
 	connUrl   string
 	entryFile string
+	timeout   int
+	// noCache - disable the program cache (parse files on every request)
+	noCache            bool
+	cacheStatsInterval int
 
 	rootCmd = &cobra.Command{
 		Use:   "zinc-server",
@@ -20,9 +26,16 @@ var (
 		Long:  "Zn HTTP服务器 - 处理上游传过来的HTTP请求，并返回相应的结果 - 请注意和 zinc-playground 不同，这里每接受一次请求时都会创建一个新的 goroutine，所以请小心别把服务器搞挂了！",
 		Run: func(c *cobra.Command, args []string) {
 			interpreter := zinc.NewInterpreter()
+			if !noCache {
+				cache := zinc.NewProgramCache()
+				interpreter.SetProgramCache(cache)
+				if cacheStatsInterval > 0 {
+					go reportCacheStats(cache, time.Duration(cacheStatsInterval)*time.Second)
+				}
+			}
 			// set HTTP MODE
 			httpHandler := zinc.NewHttpHandler(interpreter, entryFile)
-			threadServer := zinc.NewThreadServer()
+			threadServer := zinc.NewThreadServer().SetTimeout(timeout)
 
 			// check if entryFile exists
 			if entryFile == "" {
@@ -43,8 +56,20 @@ var (
 	}
 )
 
+// reportCacheStats - print metrics of the program cache periodically
+func reportCacheStats(cache *zinc.ProgramCache, interval time.Duration) {
+	for range time.Tick(interval) {
+		stats := cache.Stats()
+		log.Printf("程序缓存：命中率 %.2f%%，命中 %d 次，未命中 %d 次，失效 %d 次，缓存程序 %d 个，平均解析耗时 %v",
+			stats.HitRate()*100, stats.Hits, stats.Misses, stats.Invalidations, stats.Entries, stats.AvgParseTime())
+	}
+}
+
 func main() {
 	rootCmd.Flags().StringVarP(&connUrl, "listen", "l", defaultConnUrl, "设置服务器监听的URL 如 tcp://127.0.0.1:3862 或 unix:///tmp/zinc.sock")
 	rootCmd.Flags().StringVarP(&entryFile, "file", "f", "", "执行入口文件")
+	rootCmd.Flags().IntVar(&timeout, "timeout", 60, "执行超时时间，单位为秒；设为0则不限制")
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "禁用程序缓存，每次请求时都重新解析入口文件及其导入的模块")
+	rootCmd.Flags().IntVar(&cacheStatsInterval, "cache-stats-interval", 60, "输出程序缓存统计信息（命中率等）的间隔时间，单位为秒；设为0则不输出")
 	rootCmd.Execute()
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	zinc "github.com/DemoHn/Zn"
+	"github.com/DemoHn/Zn/pkg/dap"
+	"github.com/spf13/cobra"
+)
+
+var dapCmd = &cobra.Command{
+	Use:   "dap",
+	Short: "启动调试适配器（DAP）服务",
+	Long:  "通过标准输入/输出启动调试适配器协议（Debug Adapter Protocol）服务，供编辑器插件调试Zn程序",
+	Args:  cobra.NoArgs,
+	Run: func(c *cobra.Command, args []string) {
+		ServeDAP(vendorFlag, encodingFlag)
+	},
+}
+
+// ServeDAP - serve DAP requests from stdin and send responses to stdout
+func ServeDAP(vendorPaths []string, encoding string) {
+	znInterpreter := zinc.NewInterpreter().SetSourceEncoding(encoding)
+	if len(vendorPaths) > 0 {
+		znInterpreter.SetVendorPaths(vendorPaths)
+	}
+
+	// stdout is used by the protocol - redirect the program output (e.g. 显示)
+	// to output events
+	dapOut := os.Stdout
+	outReader, outWriter, err := os.Pipe()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "启动调试适配器失败：%s\n", err.Error())
+		return
+	}
+	os.Stdout = outWriter
+
+	server := dap.NewServer(znInterpreter, os.Stdin, dapOut)
+	go server.CaptureOutput(outReader)
+
+	if err := server.Serve(); err != nil {
+		fmt.Fprintf(os.Stderr, "调试适配器异常退出：%s\n", err.Error())
+	}
+}
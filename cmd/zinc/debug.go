@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	zinc "github.com/DemoHn/Zn"
+	"github.com/DemoHn/Zn/pkg/exec"
+	"github.com/DemoHn/Zn/pkg/value"
+	"github.com/peterh/liner"
+	"github.com/spf13/cobra"
+)
+
+const debugHelpText = `调试命令：
+  断点 <行号>              在主模块的指定行设置断点（简写：b）
+  断点 <模块名>:<行号>      在指定模块的指定行设置断点
+  删除断点 <行号>           删除断点（简写：d）
+  继续                     继续执行，直到下一个断点（简写：c）
+  单步                     执行下一条语句，会进入函数内部（简写：s）
+  下一步                   执行下一条语句，不进入函数内部（简写：n）
+  跳出                     执行到当前函数返回（简写：o）
+  调用栈                   显示调用栈（简写：bt）
+  变量 [帧号]              显示指定调用帧（默认为当前帧）的局部变量（简写：v）
+  求值 <表达式>             在当前调用帧中求值表达式（简写：p）
+  帮助                     显示本帮助（简写：h）
+  退出                     终止程序并退出调试（简写：q）`
+
+var debugCmd = &cobra.Command{
+	Use:   "debug <文件>",
+	Short: "调试Zn程序",
+	Long:  "以交互方式调试Zn程序：程序在第一条语句前暂停，可设置断点、单步执行、查看调用栈及变量、求值表达式",
+	Args:  cobra.ExactArgs(1),
+	Run: func(c *cobra.Command, args []string) {
+		varInputBlock := strings.Join(varInputFlag, "\n")
+		DebugProgram(args[0], varInputBlock, vendorFlag, encodingFlag)
+	},
+}
+
+// DebugProgram - exec program from file with the interactive debugger
+func DebugProgram(file string, varInputBlock string, vendorPaths []string, encoding string) {
+	znInterpreter := zinc.NewInterpreter().SetSourceEncoding(encoding)
+	if len(vendorPaths) > 0 {
+		znInterpreter.SetVendorPaths(vendorPaths)
+	}
+	inputMap, err := znInterpreter.ExecuteVarInputText(varInputBlock)
+	if err != nil {
+		prettyPrintError(os.Stdout, err)
+		return
+	}
+
+	linerR := liner.NewLiner()
+	linerR.SetCtrlCAborts(true)
+	defer linerR.Close()
+
+	debugger := exec.NewDebugger(true)
+	debugger.Run(znInterpreter.LoadFile(file), inputMap)
+
+	terminated := false
+	for ev := range debugger.Events() {
+		if ev.Type == exec.DebugEventExited {
+			if terminated {
+				fmt.Println("已终止调试")
+				return
+			}
+			if ev.Error != nil {
+				prettyPrintError(os.Stdout, ev.Error)
+				fmt.Println()
+			} else if _, ok := ev.Result.(*value.Null); !ok && ev.Result != nil {
+				fmt.Println(ev.Result.String())
+			}
+			fmt.Println("程序已结束")
+			return
+		}
+
+		displayDebugLocation(debugger, ev)
+		terminated = debugPromptLoop(linerR, debugger)
+	}
+}
+
+// debugPromptLoop - handle debug commands until the program is resumed;
+// returns true if the program is terminated by user
+func debugPromptLoop(linerR *liner.State, debugger *exec.Debugger) bool {
+	// current frame - the top frame by default
+	frameID := -1
+	for {
+		text, err := linerR.Prompt("(调试) ")
+		if err != nil {
+			debugger.Terminate()
+			return true
+		}
+		linerR.AppendHistory(text)
+
+		cmd, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+		arg = strings.TrimSpace(arg)
+		switch cmd {
+		case "":
+			continue
+		case "断点", "b":
+			if module, line, ok := parseDebugLocation(arg); ok {
+				debugger.AddBreakpoint(module, line)
+				fmt.Printf("已设置断点：%s第 %d 行\n", displayModuleName(module), line)
+			}
+		case "删除断点", "d":
+			if module, line, ok := parseDebugLocation(arg); ok {
+				debugger.RemoveBreakpoint(module, line)
+				fmt.Printf("已删除断点：%s第 %d 行\n", displayModuleName(module), line)
+			}
+		case "继续", "c":
+			debugger.Continue()
+			return false
+		case "单步", "s":
+			debugger.StepIn()
+			return false
+		case "下一步", "n":
+			debugger.StepOver()
+			return false
+		case "跳出", "o":
+			debugger.StepOut()
+			return false
+		case "调用栈", "bt":
+			frames, _ := debugger.StackFrames()
+			for _, frame := range frames {
+				fmt.Printf("#%d %s %s第 %d 行：%s\n", frame.ID, frame.Kind, displayModuleName(frame.Module), frame.Line, strings.TrimSpace(frame.Source))
+			}
+		case "变量", "v":
+			if arg != "" {
+				id, err := strconv.Atoi(arg)
+				if err != nil {
+					fmt.Println("帧号必须为整数")
+					continue
+				}
+				frameID = id
+			}
+			id := frameID
+			if id < 0 {
+				frames, _ := debugger.StackFrames()
+				id = frames[0].ID
+			}
+			vars, err := debugger.Variables(id)
+			if err != nil {
+				fmt.Println(err.Error())
+				continue
+			}
+			for _, v := range vars {
+				fmt.Printf("%s = %s\n", v.Name, v.Value.String())
+			}
+		case "求值", "p":
+			result, err := debugger.Evaluate(arg)
+			if err != nil {
+				prettyPrintError(os.Stdout, err)
+				fmt.Println()
+			} else if result != nil {
+				// color codes are written only when the output is a terminal (not redirected to a file or pipe)
+				if isTerminal(os.Stdout) {
+					prettyDisplayValue(result, os.Stdout)
+				} else {
+					fmt.Println(result.String())
+				}
+			}
+		case "帮助", "h":
+			fmt.Println(debugHelpText)
+		case "退出", "q":
+			debugger.Terminate()
+			return true
+		default:
+			fmt.Printf("未知命令：%s（输入「帮助」查看所有命令）\n", cmd)
+		}
+	}
+}
+
+func displayDebugLocation(debugger *exec.Debugger, ev exec.DebugEvent) {
+	reasons := map[string]string{
+		exec.DebugReasonEntry:      "程序开始",
+		exec.DebugReasonBreakpoint: "断点",
+		exec.DebugReasonStep:       "单步",
+		exec.DebugReasonPause:      "暂停",
+	}
+	fmt.Printf("[%s] 暂停于%s第 %d 行：\n", reasons[ev.Reason], displayModuleName(ev.Module), ev.Line)
+	if frames, err := debugger.StackFrames(); err == nil && len(frames) > 0 {
+		fmt.Printf("    %s\n", strings.TrimSpace(frames[0].Source))
+	}
+}
+
+// parseDebugLocation - parse "<行号>" or "<模块名>:<行号>"
+func parseDebugLocation(arg string) (string, int, bool) {
+	module := exec.MODULE_NAME_MAIN
+	lineStr := arg
+	if idx := strings.LastIndexAny(arg, ":："); idx >= 0 {
+		module = arg[:idx]
+		lineStr = strings.TrimLeft(arg[idx:], ":：")
+	}
+	line, err := strconv.Atoi(lineStr)
+	if err != nil || line <= 0 {
+		fmt.Println("行号必须为正整数")
+		return "", 0, false
+	}
+	return module, line, true
+}
+
+func displayModuleName(module string) string {
+	if module == exec.MODULE_NAME_MAIN {
+		return "主模块"
+	}
+	return fmt.Sprintf("模块“%s”", module)
+}
+
+// isTerminal - if the file is a terminal (character device)
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
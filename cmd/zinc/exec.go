@@ -50,8 +50,14 @@ func EnterREPL() {
 }
 
 // ExecProgram - exec program from file directly
-func ExecProgram(file string, varInputBlock string) {
-	znInterpreter := zinc.NewInterpreter()
+func ExecProgram(file string, varInputBlock string, vendorPaths []string, encoding string, bytecode bool, randomSeed *int64) {
+	znInterpreter := zinc.NewInterpreter().SetBytecode(bytecode).SetSourceEncoding(encoding)
+	if randomSeed != nil {
+		znInterpreter.SetRandomSeed(*randomSeed)
+	}
+	if len(vendorPaths) > 0 {
+		znInterpreter.SetVendorPaths(vendorPaths)
+	}
 	inputMap, err := znInterpreter.ExecuteVarInputText(varInputBlock)
 	if err != nil {
 		prettyPrintError(os.Stdout, err)
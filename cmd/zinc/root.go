@@ -9,10 +9,15 @@ import (
 var (
 	versionFlag  bool
 	varInputFlag []string
+	vendorFlag   []string
+	encodingFlag string
+	bytecodeFlag bool
+	seedFlag     int64
 	rootCmd      = &cobra.Command{
 		Use:   "Zn",
 		Short: "Zn语言解释器",
 		Long:  "Zn语言解释器",
+		Args:  cobra.ArbitraryArgs,
 		Run: func(c *cobra.Command, args []string) {
 			// -v, --version
 			if versionFlag {
@@ -23,7 +28,12 @@ var (
 			if len(args) > 0 {
 				filename := args[0]
 				varInputBlock := strings.Join(varInputFlag, "\n")
-				ExecProgram(filename, varInputBlock)
+				// fix the seed of random numbers, so that the results are reproducible
+				var randomSeed *int64
+				if c.Flags().Changed("random-seed") {
+					randomSeed = &seedFlag
+				}
+				ExecProgram(filename, varInputBlock, vendorFlag, encodingFlag, bytecodeFlag, randomSeed)
 				return
 			}
 			// by default, enter REPL
@@ -34,6 +44,11 @@ var (
 
 func main() {
 	rootCmd.Flags().BoolVarP(&versionFlag, "version", "v", false, "显示Zn语言版本")
-	rootCmd.Flags().StringArrayVarP(&varInputFlag, "input", "i", []string{}, "定义输入变量(支持多个变量)，格式为 <变量名>=<表达式>，如：‘./zinc xx.zn -i 客单价=28.25 -i 销量=300’")
+	rootCmd.PersistentFlags().StringArrayVarP(&varInputFlag, "input", "i", []string{}, "定义输入变量(支持多个变量)，格式为 <变量名>=<表达式>，如：‘./zinc xx.zn -i 客单价=28.25 -i 销量=300’")
+	rootCmd.PersistentFlags().StringArrayVar(&vendorFlag, "vendor", []string{}, "设置依赖包的查找目录(支持多个目录，按顺序查找)；未设置时默认查找 <主模块目录>/zn_vendor 及 ~/.zinc/vendor")
+	rootCmd.PersistentFlags().StringVar(&encodingFlag, "encoding", "", "设置代码文件的编码(如 GB18030、GBK)；未设置时根据文件内容自动识别 UTF-8 或 GB18030")
+	rootCmd.Flags().BoolVar(&bytecodeFlag, "bytecode", false, "使用字节码引擎执行程序（实验性功能）")
+	rootCmd.Flags().Int64Var(&seedFlag, "random-seed", 0, "固定随机数的种子，使取随机数及@数学的随机结果可复现，不设置则以当前时间为种子")
+	rootCmd.AddCommand(debugCmd, dapCmd)
 	rootCmd.Execute()
 }
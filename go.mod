@@ -10,6 +10,7 @@ require (
 	github.com/spf13/cobra v1.1.1
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/image v0.0.0-20200618115811-c13761719519
+	golang.org/x/text v0.22.0
 )
 
 require (
@@ -3,6 +3,10 @@ package common
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
 
 	r "github.com/DemoHn/Zn/pkg/runtime"
 	"github.com/DemoHn/Zn/pkg/value"
@@ -16,14 +20,25 @@ func HashMapToJSONString(hm *value.HashMap) (*value.String, error) {
 	return value.NewString(string(data)), nil
 }
 
-func JSONStringToElement(jsonStr *value.String) (r.Element, error) {
+// JSONStringToElement - parse the JSON text. Strings are kept as texts unless parseDates is true,
+// in which case dates (e.g. "2024-03-15") and RFC 3339 datetimes are converted to datetimes.
+func JSONStringToElement(jsonStr *value.String, parseDates bool) (r.Element, error) {
 	plainMap := map[string]any{}
-	vdata := []byte(jsonStr.GetValue())
-	if err := json.Unmarshal(vdata, &plainMap); err != nil {
+	// decode numbers as json.Number to avoid precision loss of large amounts
+	decoder := json.NewDecoder(strings.NewReader(jsonStr.GetValue()))
+	decoder.UseNumber()
+	if err := decoder.Decode(&plainMap); err != nil {
+		return nil, value.ThrowException("解析JSON失败 - " + err.Error())
+	}
+	// only one JSON value is allowed, e.g. `{"a": 1} xyz` is invalid
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		if err == nil {
+			err = fmt.Errorf("invalid character after top-level value")
+		}
 		return nil, value.ThrowException("解析JSON失败 - " + err.Error())
 	}
 
-	return buildElementFromPlainValue(plainMap), nil
+	return buildElementFromPlainValue(plainMap, parseDates), nil
 }
 
 func ElementToJSONString(elem r.Element) (*value.String, error) {
@@ -44,7 +59,23 @@ func buildPlainValueFromElement(elem r.Element) any {
 	case *value.Bool:
 		return vv.GetValue()
 	case *value.Number:
+		// numbers with unit are kept as text, e.g. "80kg"
+		if vv.GetUnit() != nil {
+			return vv.String()
+		}
 		return vv.GetValue()
+	case *value.Currency:
+		// currencies are kept as text, e.g. "USD$20.30"
+		return vv.String()
+	case *value.DateTime:
+		// dates are kept as "2024-03-15", datetimes as RFC 3339 text (with offset)
+		if vv.IsDateOnly() {
+			return vv.String()
+		}
+		return vv.GetValue().Format(time.RFC3339Nano)
+	case *value.Decimal:
+		// keep all digits of decimals in JSON
+		return json.Number(vv.String())
 	case *value.Array:
 		var resultList []interface{}
 		for _, vi := range vv.GetValue() {
@@ -61,7 +92,7 @@ func buildPlainValueFromElement(elem r.Element) any {
 	return nil
 }
 
-func buildElementFromPlainValue(item any) r.Element {
+func buildElementFromPlainValue(item any, parseDates bool) r.Element {
 	if item == nil {
 		return value.NewNull()
 	}
@@ -81,10 +112,15 @@ func buildElementFromPlainValue(item any) r.Element {
 		return value.NewNumber(float64(vv))
 	case float64:
 		return value.NewNumber(vv)
+	case json.Number:
+		return buildElementFromJSONNumber(vv)
 	//// case#2: strings
 	case []rune:
 		return value.NewString(string(vv))
 	case string:
+		if parseDates {
+			return buildElementFromJSONString(vv)
+		}
 		return value.NewString(vv)
 	//// case#3: booleans
 	case bool:
@@ -92,7 +128,7 @@ func buildElementFromPlainValue(item any) r.Element {
 	case map[string]any:
 		target := value.NewEmptyHashMap()
 		for k, v := range vv {
-			finalValue := buildElementFromPlainValue(v)
+			finalValue := buildElementFromPlainValue(v, parseDates)
 			target.AppendKVPair(value.KVPair{
 				Key:   k,
 				Value: finalValue,
@@ -102,10 +138,51 @@ func buildElementFromPlainValue(item any) r.Element {
 	case []any:
 		varr := value.NewEmptyArray()
 		for _, vitem := range vv {
-			varr.AppendValue(buildElementFromPlainValue(vitem))
+			varr.AppendValue(buildElementFromPlainValue(vitem, parseDates))
 		}
 		return varr
 	}
 	// default fallback logic
 	return value.NewString(fmt.Sprintf("%v", item))
 }
+
+// buildElementFromJSONNumber - numbers are converted to float64 first; only those can't be represented
+// by float64 exactly (e.g. 12345678901234567.89) are converted to decimals
+func buildElementFromJSONNumber(num json.Number) r.Element {
+	f, err := num.Float64()
+	if err == nil {
+		// the shortest representation of f is the same as the input (e.g. 1.5, 1e-7)
+		if text := num.String(); text == strconv.FormatFloat(f, 'f', -1, 64) || text == strconv.FormatFloat(f, 'g', -1, 64) {
+			return value.NewNumber(f)
+		}
+	}
+
+	// numbers out of the decimal range (e.g. 1e99999) are kept as strings
+	dec, decErr := value.NewDecimalFromString(num.String())
+	if decErr != nil {
+		if err == nil {
+			return value.NewNumber(f)
+		}
+		return value.NewString(num.String())
+	}
+	if err == nil {
+		if fdec, err := value.NewDecimalFromNumber(value.NewNumber(f)); err == nil && fdec.Cmp(dec) == 0 {
+			return value.NewNumber(f)
+		}
+	}
+	return dec
+}
+
+// buildElementFromJSONString - strings of dates (e.g. "2024-03-15") or RFC 3339 datetimes
+// (e.g. "2024-03-15T08:30:00+08:00") are converted to datetimes, so that they could be round-tripped
+func buildElementFromJSONString(str string) r.Element {
+	if len(str) == len("2024-03-15") {
+		if dt, err := value.ParseDateTime(str, value.DateLayout, time.Local, true); err == nil {
+			return dt
+		}
+	}
+	if t, err := time.Parse(time.RFC3339Nano, str); err == nil {
+		return value.NewDateTime(t, false)
+	}
+	return value.NewString(str)
+}
@@ -0,0 +1,96 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+func TestJSONStringToElement_DateStrings(t *testing.T) {
+	// date-like strings are kept as texts, scripts could convert them by 新建日期 explicitly
+	elem, err := JSONStringToElement(value.NewString(`{"d": "2024-03-15", "t": "2024-03-15T08:30:00+08:00"}`), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hm := elem.(*value.HashMap).GetValue()
+	for _, key := range []string{"d", "t"} {
+		str, ok := hm[key].(*value.String)
+		if !ok {
+			t.Fatalf("%s: expect a string, got %T", key, hm[key])
+		}
+		length, err := str.GetProperty("长度")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", key, err)
+		}
+		if expected := len([]rune(str.String())); length.String() != value.NewNumber(float64(expected)).String() {
+			t.Errorf("%s: expect length = %d, got %s", key, expected, length.String())
+		}
+	}
+}
+
+func TestJSONStringToElement_ParseDates(t *testing.T) {
+	elem, err := JSONStringToElement(value.NewString(`{"d": "2024-03-15", "t": "2024-03-15T08:30:00+08:00", "s": "2024-13-45", "l": ["2024-01-31"]}`), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hm := elem.(*value.HashMap).GetValue()
+	for key, expected := range map[string]string{"d": "2024-03-15", "t": "2024-03-15T08:30:00+08:00"} {
+		dt, ok := hm[key].(*value.DateTime)
+		if !ok {
+			t.Fatalf("%s: expect a datetime, got %T", key, hm[key])
+		}
+		if key == "d" && (!dt.IsDateOnly() || dt.String() != expected) {
+			t.Errorf("%s: expect date %s, got %s", key, expected, dt.String())
+		}
+		if key == "t" && (dt.IsDateOnly() || dt.GetValue().Format(time.RFC3339) != expected) {
+			t.Errorf("%s: expect datetime %s, got %s", key, expected, dt.GetValue().Format(time.RFC3339))
+		}
+	}
+	if _, ok := hm["s"].(*value.String); !ok {
+		t.Errorf("s: expect invalid dates are kept as strings, got %T", hm["s"])
+	}
+	if items := hm["l"].(*value.Array).GetValue(); len(items) != 1 {
+		t.Errorf("l: expect 1 item, got %d", len(items))
+	} else if _, ok := items[0].(*value.DateTime); !ok {
+		t.Errorf("l: expect dates inside arrays are parsed, got %T", items[0])
+	}
+}
+
+func TestJSONStringToElement_TrailingData(t *testing.T) {
+	for _, str := range []string{`{"a": 1} xyz`, `{"a": 1}{"b": 2}`, `{"a": 1}]`} {
+		if _, err := JSONStringToElement(value.NewString(str), false); err == nil {
+			t.Errorf("%s: expect error, got nil", str)
+		}
+	}
+	if _, err := JSONStringToElement(value.NewString("{\"a\": 1}\n  "), false); err != nil {
+		t.Errorf("expect trailing spaces are allowed, got %v", err)
+	}
+}
+
+func TestJSONStringToElement_Numbers(t *testing.T) {
+	elem, err := JSONStringToElement(value.NewString(`{"a": 1.50, "b": 1e-7, "c": 12345678901234567.89, "d": 1e400, "e": 1e30000000}`), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hm := elem.(*value.HashMap).GetValue()
+	cases := []struct {
+		key      string
+		typeName string
+		expected string
+	}{
+		{"a", "*value.Number", "1.5"},
+		{"b", "*value.Number", "1e-07"},
+		{"c", "*value.Decimal", "12345678901234567.89"},
+		{"d", "*value.Decimal", "1" + strings.Repeat("0", 400)},
+		// out of the range of decimals - kept as text
+		{"e", "*value.String", "1e30000000"},
+	}
+	for _, tt := range cases {
+		if typeName := fmt.Sprintf("%T", hm[tt.key]); typeName != tt.typeName || hm[tt.key].String() != tt.expected {
+			t.Errorf("%s: expect %s (%s), got %s (%s)", tt.key, tt.expected, tt.typeName, hm[tt.key].String(), typeName)
+		}
+	}
+}
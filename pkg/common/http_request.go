@@ -29,6 +29,8 @@ import (
 		- 若是文本，本次请求的 Content-Type 为 application/x-www-form-urlencoded，其内容即是诸如 a=1&b=2&c=3 这样的文本
 		- 若是字典，本次请求的 Content-Type 为 application/json，实际传输内容会转换成JSON字符串，诸如 {"a": 1, "b": 2, "c": 3}
 	其内容 = “”
+
+	注：读取内容 - 得到请求的内容；对于收到的请求，若 Content-Type 为 application/json，内容是由JSON解析得到的值
 */
 var CLASS_HttpRequest = value.NewClassModel("HTTP请求").
 	DefineProperty("URL", value.NewString("")).
@@ -37,6 +39,7 @@ var CLASS_HttpRequest = value.NewClassModel("HTTP请求").
 	DefineProperty("头部", value.NewEmptyHashMap()).
 	DefineProperty("查询参数", value.NewEmptyHashMap()).
 	DefineProperty("内容", value.NewString("")).
+	DefineMethod("读取内容", value.NewFunction(httpRequestReadBody)).
 	SetConstructor(httpRequestConsturctor)
 
 func httpRequestConsturctor(self r.Element, values []r.Element) (r.Element, error) {
@@ -68,3 +71,10 @@ func httpRequestConsturctor(self r.Element, values []r.Element) (r.Element, erro
 	}
 	return self, nil
 }
+
+func httpRequestReadBody(self r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values); err != nil {
+		return nil, err
+	}
+	return self.GetProperty("内容")
+}
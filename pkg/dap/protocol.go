@@ -0,0 +1,102 @@
+package dap
+
+import (
+	"encoding/json"
+)
+
+// Message - the base of DAP requests, responses & events.
+// See https://microsoft.github.io/debug-adapter-protocol/specification
+type Message struct {
+	Seq  int    `json:"seq"`
+	Type string `json:"type"`
+}
+
+// Request - a request from the client (editor)
+type Request struct {
+	Message
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// Response - the response of a request
+type Response struct {
+	Message
+	RequestSeq int         `json:"request_seq"`
+	Success    bool        `json:"success"`
+	Command    string      `json:"command"`
+	ErrMessage string      `json:"message,omitempty"`
+	Body       interface{} `json:"body,omitempty"`
+}
+
+// Event - an event sent to the client
+type Event struct {
+	Message
+	Event string      `json:"event"`
+	Body  interface{} `json:"body,omitempty"`
+}
+
+///// arguments & bodies of requests /////
+
+type launchArguments struct {
+	// Program - path of the main module file
+	Program string `json:"program"`
+	// StopOnEntry - pause before the first statement
+	StopOnEntry bool `json:"stopOnEntry"`
+	// Inputs - variable inputs, format: <变量名>=<表达式> (same as `zinc -i`)
+	Inputs []string `json:"inputs"`
+	// Vendor - dirs to search vendor packages
+	Vendor []string `json:"vendor"`
+}
+
+type source struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+type sourceBreakpoint struct {
+	Line int `json:"line"`
+}
+
+type setBreakpointsArguments struct {
+	Source      source             `json:"source"`
+	Breakpoints []sourceBreakpoint `json:"breakpoints"`
+}
+
+type breakpoint struct {
+	Verified bool   `json:"verified"`
+	Line     int    `json:"line"`
+	Message  string `json:"message,omitempty"`
+}
+
+type stackFrame struct {
+	ID     int     `json:"id"`
+	Name   string  `json:"name"`
+	Source *source `json:"source,omitempty"`
+	Line   int     `json:"line"`
+	Column int     `json:"column"`
+}
+
+type scopesArguments struct {
+	FrameID int `json:"frameId"`
+}
+
+type scope struct {
+	Name               string `json:"name"`
+	VariablesReference int    `json:"variablesReference"`
+	Expensive          bool   `json:"expensive"`
+}
+
+type variablesArguments struct {
+	VariablesReference int `json:"variablesReference"`
+}
+
+type variable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+type evaluateArguments struct {
+	Expression string `json:"expression"`
+	FrameID    int    `json:"frameId"`
+}
@@ -0,0 +1,479 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/DemoHn/Zn/pkg/exec"
+	"github.com/DemoHn/Zn/pkg/framing"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+// the only thread exposed to the client - the main program
+const mainThreadID = 1
+
+// Server - a Debug Adapter Protocol (DAP) server, so that editors (e.g. VSCode) could
+// launch & debug Zn programs. It's built on exec.Debugger - i.e. the call stack and
+// scopes of the VM.
+//
+// Supported requests: initialize, launch, setBreakpoints, configurationDone, threads,
+// stackTrace, scopes, variables, evaluate, continue, next, stepIn, stepOut, pause,
+// terminate & disconnect.
+type Server struct {
+	interpreter *exec.Interpreter
+	reader      *bufio.Reader
+	writer      io.Writer
+
+	// writeMu - guards writer & seq, since events are sent from other goroutines
+	writeMu sync.Mutex
+	seq     int
+
+	mu         sync.Mutex
+	debugger   *exec.Debugger
+	launchArgs *launchArguments
+	varInputs  r.ElementMap
+	configured bool
+	// program - absolute path of the main module file
+	program string
+	// breakpoints - absolute path of module file -> lines
+	breakpoints map[string][]int
+	// handles - variablesReference (index + 1) -> frame scope or element to expand;
+	// it's reset whenever the program is paused
+	handles []varHandle
+}
+
+// varHandle - if elem is nil, it refers to local variables of the frame
+type varHandle struct {
+	frameID int
+	elem    r.Element
+}
+
+// NewServer - create a DAP server that reads requests from in and writes responses & events
+// to out. The program is loaded & executed by the interpreter.
+func NewServer(interpreter *exec.Interpreter, in io.Reader, out io.Writer) *Server {
+	return &Server{
+		interpreter: interpreter,
+		reader:      bufio.NewReader(in),
+		writer:      out,
+		breakpoints: map[string][]int{},
+	}
+}
+
+// Serve - handle requests until the client disconnects or the input is closed
+func (s *Server) Serve() error {
+	for {
+		data, err := framing.ReadMessage(s.reader)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var req Request
+		if err := json.Unmarshal(data, &req); err != nil {
+			return err
+		}
+		if req.Type != "request" {
+			continue
+		}
+
+		body, resume, err := s.handleRequest(&req)
+		s.sendResponse(&req, body, err)
+		// resume the program after responding, so that the next stopped event
+		// won't be sent before the response
+		if resume != nil {
+			resume()
+		}
+
+		switch req.Command {
+		case "initialize":
+			s.sendEvent("initialized", nil)
+		case "disconnect":
+			return nil
+		}
+	}
+}
+
+// CaptureOutput - send the program output (e.g. printed by 显示) read from reader to the
+// client as output events. It returns when reader is closed.
+func (s *Server) CaptureOutput(reader io.Reader) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			s.sendOutput("stdout", string(buf[:n]))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleRequest - returns the response body, and the action to resume the program (if any)
+func (s *Server) handleRequest(req *Request) (interface{}, func(), error) {
+	var body interface{}
+	var err error
+	switch req.Command {
+	case "initialize":
+		body = map[string]interface{}{
+			"supportsConfigurationDoneRequest": true,
+			"supportsEvaluateForHovers":        true,
+			"supportsTerminateRequest":         true,
+		}
+	case "launch":
+		err = s.handleLaunch(req)
+	case "setBreakpoints":
+		body, err = s.handleSetBreakpoints(req)
+	case "configurationDone":
+		s.mu.Lock()
+		s.configured = true
+		s.mu.Unlock()
+		err = s.startIfReady()
+	case "threads":
+		body = map[string]interface{}{
+			"threads": []map[string]interface{}{{"id": mainThreadID, "name": "主线程"}},
+		}
+	case "stackTrace":
+		body, err = s.handleStackTrace()
+	case "scopes":
+		body, err = s.handleScopes(req)
+	case "variables":
+		body, err = s.handleVariables(req)
+	case "evaluate":
+		body, err = s.handleEvaluate(req)
+	case "continue", "next", "stepIn", "stepOut":
+		return s.handleResume(req.Command)
+	case "pause":
+		var debugger *exec.Debugger
+		if debugger, err = s.getDebugger(); err == nil {
+			debugger.Pause()
+		}
+	case "terminate", "disconnect":
+		s.mu.Lock()
+		debugger := s.debugger
+		s.mu.Unlock()
+		if debugger != nil {
+			debugger.Terminate()
+		}
+	default:
+		err = fmt.Errorf("不支持的请求：%s", req.Command)
+	}
+	return body, nil, err
+}
+
+func (s *Server) handleResume(command string) (interface{}, func(), error) {
+	debugger, err := s.getDebugger()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !debugger.IsPaused() {
+		return nil, nil, exec.ErrNotPaused
+	}
+
+	var body interface{}
+	resume := debugger.Continue
+	switch command {
+	case "continue":
+		body = map[string]interface{}{"allThreadsContinued": true}
+	case "next":
+		resume = debugger.StepOver
+	case "stepIn":
+		resume = debugger.StepIn
+	case "stepOut":
+		resume = debugger.StepOut
+	}
+	return body, func() { _ = resume() }, nil
+}
+
+func (s *Server) handleLaunch(req *Request) error {
+	var args launchArguments
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return err
+	}
+	if args.Program == "" {
+		return fmt.Errorf("未指定调试程序（program）")
+	}
+	program, err := filepath.Abs(args.Program)
+	if err != nil {
+		return err
+	}
+
+	if len(args.Vendor) > 0 {
+		s.interpreter.SetVendorPaths(args.Vendor)
+	}
+	varInputs, err := s.interpreter.ExecuteVarInputText(strings.Join(args.Inputs, "\n"))
+	if err != nil {
+		return fmt.Errorf("%s", exec.DisplayError(err))
+	}
+	s.interpreter.LoadFile(program)
+
+	s.mu.Lock()
+	s.launchArgs = &args
+	s.varInputs = varInputs
+	s.program = program
+	s.mu.Unlock()
+	return s.startIfReady()
+}
+
+// startIfReady - start the program once it's launched & configured
+func (s *Server) startIfReady() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.launchArgs == nil || !s.configured || s.debugger != nil {
+		return nil
+	}
+
+	debugger := exec.NewDebugger(s.launchArgs.StopOnEntry)
+	for path, lines := range s.breakpoints {
+		for _, module := range s.interpreter.FindModuleNames(path) {
+			debugger.SetBreakpoints(module, lines)
+		}
+	}
+	s.debugger = debugger
+	debugger.Run(s.interpreter, s.varInputs)
+	go s.forwardEvents(debugger)
+	return nil
+}
+
+func (s *Server) forwardEvents(debugger *exec.Debugger) {
+	for ev := range debugger.Events() {
+		switch ev.Type {
+		case exec.DebugEventStopped:
+			s.mu.Lock()
+			s.handles = nil
+			s.mu.Unlock()
+			s.sendEvent("stopped", map[string]interface{}{
+				"reason":            ev.Reason,
+				"threadId":          mainThreadID,
+				"allThreadsStopped": true,
+			})
+		case exec.DebugEventExited:
+			exitCode := 0
+			if ev.Error != nil {
+				exitCode = 1
+				s.sendOutput("stderr", exec.DisplayError(ev.Error)+"\n")
+			} else if _, ok := ev.Result.(*value.Null); !ok && ev.Result != nil {
+				s.sendOutput("stdout", ev.Result.String()+"\n")
+			}
+			s.sendEvent("exited", map[string]interface{}{"exitCode": exitCode})
+			s.sendEvent("terminated", nil)
+			return
+		}
+	}
+}
+
+func (s *Server) handleSetBreakpoints(req *Request) (interface{}, error) {
+	var args setBreakpointsArguments
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return nil, err
+	}
+	path, err := filepath.Abs(args.Source.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []int{}
+	for _, bp := range args.Breakpoints {
+		lines = append(lines, bp.Line)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.breakpoints[path] = lines
+
+	// before launching, the module of the file is unknown - assume the breakpoints are valid
+	verified, message := true, ""
+	if s.program != "" {
+		modules := s.interpreter.FindModuleNames(path)
+		if len(modules) == 0 {
+			verified, message = false, "该文件不在主模块所在目录或依赖包中"
+		} else if s.debugger != nil {
+			for _, module := range modules {
+				s.debugger.SetBreakpoints(module, lines)
+			}
+		}
+	}
+
+	breakpoints := []breakpoint{}
+	for _, line := range lines {
+		breakpoints = append(breakpoints, breakpoint{Verified: verified, Line: line, Message: message})
+	}
+	return map[string]interface{}{"breakpoints": breakpoints}, nil
+}
+
+func (s *Server) handleStackTrace() (interface{}, error) {
+	debugger, err := s.getDebugger()
+	if err != nil {
+		return nil, err
+	}
+	frames, err := debugger.StackFrames()
+	if err != nil {
+		return nil, err
+	}
+
+	stackFrames := []stackFrame{}
+	for _, frame := range frames {
+		sf := stackFrame{
+			// frame IDs start from 1, since 0 is invalid for some clients
+			ID:     frame.ID + 1,
+			Name:   fmt.Sprintf("%s：%s", frame.Kind, strings.TrimSpace(frame.Source)),
+			Line:   frame.Line,
+			Column: 1,
+		}
+		if path, err := s.interpreter.FindModulePath(frame.Module); err == nil {
+			absPath, _ := filepath.Abs(path)
+			sf.Source = &source{Name: frame.Module, Path: absPath}
+		}
+		stackFrames = append(stackFrames, sf)
+	}
+	return map[string]interface{}{
+		"stackFrames": stackFrames,
+		"totalFrames": len(stackFrames),
+	}, nil
+}
+
+func (s *Server) handleScopes(req *Request) (interface{}, error) {
+	var args scopesArguments
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return nil, err
+	}
+	ref := s.newHandle(varHandle{frameID: args.FrameID - 1})
+	return map[string]interface{}{
+		"scopes": []scope{{Name: "局部变量", VariablesReference: ref}},
+	}, nil
+}
+
+func (s *Server) handleVariables(req *Request) (interface{}, error) {
+	var args variablesArguments
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return nil, err
+	}
+	debugger, err := s.getDebugger()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if args.VariablesReference <= 0 || args.VariablesReference > len(s.handles) {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("变量引用不存在：%d", args.VariablesReference)
+	}
+	handle := s.handles[args.VariablesReference-1]
+	s.mu.Unlock()
+
+	variables := []variable{}
+	switch v := handle.elem.(type) {
+	case nil:
+		frameVars, err := debugger.Variables(handle.frameID)
+		if err != nil {
+			return nil, err
+		}
+		for _, fv := range frameVars {
+			variables = append(variables, s.newVariable(fv.Name, fv.Value))
+		}
+	case *value.Array:
+		for i, item := range v.GetValue() {
+			variables = append(variables, s.newVariable(fmt.Sprintf("#%d", i+1), item))
+		}
+	case *value.HashMap:
+		items := v.GetValue()
+		for _, key := range v.GetKeyOrder() {
+			variables = append(variables, s.newVariable(key, items[key]))
+		}
+	}
+	return map[string]interface{}{"variables": variables}, nil
+}
+
+func (s *Server) handleEvaluate(req *Request) (interface{}, error) {
+	var args evaluateArguments
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return nil, err
+	}
+	debugger, err := s.getDebugger()
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: expressions are always evaluated in the paused (top) frame
+	result, err := debugger.Evaluate(args.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("%s", exec.DisplayError(err))
+	}
+	v := s.newVariable("", result)
+	return map[string]interface{}{
+		"result":             v.Value,
+		"variablesReference": v.VariablesReference,
+	}, nil
+}
+
+// newVariable - arrays & hashmaps could be expanded by the client
+func (s *Server) newVariable(name string, elem r.Element) variable {
+	v := variable{Name: name, Value: "空"}
+	if elem == nil {
+		return v
+	}
+	v.Value = elem.String()
+	switch elem.(type) {
+	case *value.Array, *value.HashMap:
+		v.VariablesReference = s.newHandle(varHandle{elem: elem})
+	}
+	return v
+}
+
+func (s *Server) newHandle(handle varHandle) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handles = append(s.handles, handle)
+	return len(s.handles)
+}
+
+func (s *Server) getDebugger() (*exec.Debugger, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.debugger == nil {
+		return nil, fmt.Errorf("程序尚未启动")
+	}
+	return s.debugger, nil
+}
+
+func (s *Server) sendResponse(req *Request, body interface{}, err error) {
+	resp := &Response{
+		Message:    Message{Type: "response"},
+		RequestSeq: req.Seq,
+		Success:    err == nil,
+		Command:    req.Command,
+		Body:       body,
+	}
+	if err != nil {
+		resp.ErrMessage = err.Error()
+	}
+	s.send(func(seq int) interface{} {
+		resp.Seq = seq
+		return resp
+	})
+}
+
+func (s *Server) sendEvent(event string, body interface{}) {
+	s.send(func(seq int) interface{} {
+		return &Event{Message: Message{Seq: seq, Type: "event"}, Event: event, Body: body}
+	})
+}
+
+func (s *Server) sendOutput(category string, output string) {
+	s.sendEvent("output", map[string]interface{}{"category": category, "output": output})
+}
+
+func (s *Server) send(build func(seq int) interface{}) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.seq++
+	// the client is gone if the message couldn't be written - nothing more to do
+	_ = framing.WriteMessage(s.writer, build(s.seq))
+}
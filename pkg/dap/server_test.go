@@ -0,0 +1,297 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DemoHn/Zn/pkg/exec"
+	"github.com/DemoHn/Zn/pkg/framing"
+)
+
+const serverTestCode = `令A = 1
+如何加倍？
+    输入X
+    令Y = 【X，X * 2】
+    输出Y#2
+
+令B = （加倍：A）
+B`
+
+type testMessage struct {
+	Type       string          `json:"type"`
+	Command    string          `json:"command"`
+	Event      string          `json:"event"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Body       json.RawMessage `json:"body"`
+}
+
+type testClient struct {
+	t        *testing.T
+	writer   io.Writer
+	messages chan testMessage
+	events   []testMessage
+	seq      int
+}
+
+func newTestClient(t *testing.T, server *Server, clientIn io.Reader, clientOut io.Writer) *testClient {
+	c := &testClient{t: t, writer: clientOut, messages: make(chan testMessage, 100)}
+	go func() {
+		reader := bufio.NewReader(clientIn)
+		for {
+			data, err := framing.ReadMessage(reader)
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			var msg testMessage
+			json.Unmarshal(data, &msg)
+			c.messages <- msg
+		}
+	}()
+	go server.Serve()
+	return c
+}
+
+func (c *testClient) next() testMessage {
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatalf("connection closed")
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatalf("wait message timeout")
+	}
+	return testMessage{}
+}
+
+// request - send the request and wait for its response; events received meanwhile are kept
+func (c *testClient) request(command string, args interface{}, body interface{}) testMessage {
+	c.seq++
+	argData, _ := json.Marshal(args)
+	framing.WriteMessage(c.writer, &Request{
+		Message:   Message{Seq: c.seq, Type: "request"},
+		Command:   command,
+		Arguments: argData,
+	})
+	for {
+		msg := c.next()
+		if msg.Type == "event" {
+			c.events = append(c.events, msg)
+			continue
+		}
+		if msg.RequestSeq == c.seq {
+			if body != nil {
+				json.Unmarshal(msg.Body, body)
+			}
+			return msg
+		}
+	}
+}
+
+func (c *testClient) waitEvent(event string, body interface{}) {
+	for {
+		var msg testMessage
+		if len(c.events) > 0 {
+			msg, c.events = c.events[0], c.events[1:]
+		} else {
+			msg = c.next()
+		}
+		if msg.Type == "event" && msg.Event == event {
+			if body != nil {
+				json.Unmarshal(msg.Body, body)
+			}
+			return
+		}
+	}
+}
+
+func TestServer_DebugSession(t *testing.T) {
+	root := t.TempDir()
+	program := filepath.Join(root, "主程序.zn")
+	if err := os.WriteFile(program, []byte(serverTestCode), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	server := NewServer(exec.NewInterpreter("test"), serverIn, serverOut)
+	c := newTestClient(t, server, clientIn, clientOut)
+
+	if resp := c.request("initialize", map[string]interface{}{"adapterID": "zinc"}, nil); !resp.Success {
+		t.Fatalf("initialize failed: %s", resp.Message)
+	}
+	c.waitEvent("initialized", nil)
+
+	c.request("launch", map[string]interface{}{"program": program}, nil)
+	var bps struct {
+		Breakpoints []breakpoint `json:"breakpoints"`
+	}
+	c.request("setBreakpoints", map[string]interface{}{
+		"source":      map[string]string{"path": program},
+		"breakpoints": []map[string]int{{"line": 5}},
+	}, &bps)
+	if len(bps.Breakpoints) != 1 || !bps.Breakpoints[0].Verified {
+		t.Fatalf("expect 1 verified breakpoint, got %+v", bps)
+	}
+	c.request("configurationDone", nil, nil)
+
+	var stopped struct {
+		Reason   string `json:"reason"`
+		ThreadID int    `json:"threadId"`
+	}
+	c.waitEvent("stopped", &stopped)
+	if stopped.Reason != "breakpoint" || stopped.ThreadID != mainThreadID {
+		t.Fatalf("expect stopped by breakpoint, got %+v", stopped)
+	}
+
+	// stack frames
+	var trace struct {
+		StackFrames []stackFrame `json:"stackFrames"`
+	}
+	c.request("stackTrace", map[string]int{"threadId": mainThreadID}, &trace)
+	if len(trace.StackFrames) != 2 {
+		t.Fatalf("expect 2 stack frames, got %+v", trace.StackFrames)
+	}
+	top := trace.StackFrames[0]
+	if top.Line != 5 || top.Source == nil || top.Source.Path != program || trace.StackFrames[1].Line != 7 {
+		t.Fatalf("unexpected stack frames: %+v", trace.StackFrames)
+	}
+
+	// scopes & variables
+	var scopes struct {
+		Scopes []scope `json:"scopes"`
+	}
+	c.request("scopes", map[string]int{"frameId": top.ID}, &scopes)
+	if len(scopes.Scopes) != 1 {
+		t.Fatalf("expect 1 scope, got %+v", scopes)
+	}
+	var vars struct {
+		Variables []variable `json:"variables"`
+	}
+	c.request("variables", map[string]int{"variablesReference": scopes.Scopes[0].VariablesReference}, &vars)
+	if len(vars.Variables) != 2 || vars.Variables[0].Name != "X" || vars.Variables[0].Value != "1" ||
+		vars.Variables[1].Name != "Y" || vars.Variables[1].VariablesReference == 0 {
+		t.Fatalf("unexpected variables: %+v", vars.Variables)
+	}
+	// expand the array
+	var items struct {
+		Variables []variable `json:"variables"`
+	}
+	c.request("variables", map[string]int{"variablesReference": vars.Variables[1].VariablesReference}, &items)
+	if len(items.Variables) != 2 || items.Variables[1].Name != "#2" || items.Variables[1].Value != "2" {
+		t.Fatalf("unexpected array items: %+v", items.Variables)
+	}
+
+	// evaluate
+	var eval struct {
+		Result string `json:"result"`
+	}
+	if resp := c.request("evaluate", map[string]interface{}{"expression": "X + 10", "frameId": top.ID}, &eval); !resp.Success || eval.Result != "11" {
+		t.Fatalf("expect evaluated result = 11, got %+v (%s)", eval, resp.Message)
+	}
+
+	// step over -> back to the caller
+	c.request("next", map[string]int{"threadId": mainThreadID}, nil)
+	c.waitEvent("stopped", &stopped)
+	if stopped.Reason != "step" {
+		t.Fatalf("expect stopped by step, got %+v", stopped)
+	}
+	c.request("stackTrace", map[string]int{"threadId": mainThreadID}, &trace)
+	if len(trace.StackFrames) != 1 || trace.StackFrames[0].Line != 8 {
+		t.Fatalf("expect paused at line 8, got %+v", trace.StackFrames)
+	}
+
+	// continue to the end
+	if resp := c.request("continue", map[string]int{"threadId": mainThreadID}, nil); !resp.Success {
+		t.Fatalf("continue failed: %s", resp.Message)
+	}
+	var output struct {
+		Output string `json:"output"`
+	}
+	c.waitEvent("output", &output)
+	if output.Output != "2\n" {
+		t.Errorf("expect output = 2, got %q", output.Output)
+	}
+	var exited struct {
+		ExitCode int `json:"exitCode"`
+	}
+	c.waitEvent("exited", &exited)
+	if exited.ExitCode != 0 {
+		t.Errorf("expect exit code = 0, got %d", exited.ExitCode)
+	}
+	c.waitEvent("terminated", nil)
+
+	// requests after the program exits
+	if resp := c.request("continue", map[string]int{"threadId": mainThreadID}, nil); resp.Success {
+		t.Errorf("expect continue to fail after the program exits")
+	}
+	c.request("disconnect", nil, nil)
+}
+
+func TestServer_VendorModuleBreakpoint(t *testing.T) {
+	root := t.TempDir()
+	program := filepath.Join(root, "主程序.zn")
+	vendorModule := filepath.Join(root, exec.VendorLocalDir, "工具", "计算.zn")
+	files := map[string]string{
+		program: "导入《#工具-计算》之加倍\n（加倍：21）",
+		filepath.Join(root, exec.VendorLocalDir, "工具", exec.VendorManifestFile): `{"name": "工具", "version": "1.0.0", "entry": "计算.zn"}`,
+		vendorModule: "如何加倍？\n    输入X\n    输出X * 2",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	server := NewServer(exec.NewInterpreter("test"), serverIn, serverOut)
+	c := newTestClient(t, server, clientIn, clientOut)
+
+	c.request("initialize", map[string]interface{}{"adapterID": "zinc"}, nil)
+	c.waitEvent("initialized", nil)
+	c.request("launch", map[string]interface{}{"program": program}, nil)
+
+	var bps struct {
+		Breakpoints []breakpoint `json:"breakpoints"`
+	}
+	c.request("setBreakpoints", map[string]interface{}{
+		"source":      map[string]string{"path": vendorModule},
+		"breakpoints": []map[string]int{{"line": 3}},
+	}, &bps)
+	if len(bps.Breakpoints) != 1 || !bps.Breakpoints[0].Verified {
+		t.Fatalf("expect 1 verified breakpoint, got %+v", bps)
+	}
+	c.request("configurationDone", nil, nil)
+
+	var stopped struct {
+		Reason string `json:"reason"`
+	}
+	c.waitEvent("stopped", &stopped)
+	if stopped.Reason != "breakpoint" {
+		t.Fatalf("expect stopped by breakpoint, got %+v", stopped)
+	}
+	var trace struct {
+		StackFrames []stackFrame `json:"stackFrames"`
+	}
+	c.request("stackTrace", map[string]int{"threadId": mainThreadID}, &trace)
+	if len(trace.StackFrames) != 2 || trace.StackFrames[0].Line != 3 ||
+		trace.StackFrames[0].Source == nil || trace.StackFrames[0].Source.Path != vendorModule {
+		t.Fatalf("unexpected stack frames: %+v", trace.StackFrames)
+	}
+
+	c.request("continue", map[string]int{"threadId": mainThreadID}, nil)
+	c.waitEvent("terminated", nil)
+	c.request("disconnect", nil, nil)
+}
@@ -16,6 +16,7 @@ const (
 	ErrFileNotFound = 10
 	ErrReadFile     = 11
 	ErrReadVarInput = 12
+	ErrEncoding     = 13
 )
 
 func (e *IOError) Error() string {
@@ -58,3 +59,12 @@ func ReadVarInputError(err error) *IOError {
 		Path:    "预定义变量",
 	}
 }
+
+// UnsupportedEncoding - the encoding of the file is not supported
+func UnsupportedEncoding(path string, encoding string) *IOError {
+	return &IOError{
+		Code:    ErrEncoding,
+		Message: fmt.Sprintf("不支持的编码「%s」", encoding),
+		Path:    fmt.Sprintf("文件「%s」", path),
+	}
+}
@@ -15,6 +15,12 @@ func (r *RuntimeError) Error() string {
 	return r.Message
 }
 
+// IsInterrupt - if the error stops the execution of the whole program
+// (so it won't be converted to an exception)
+func (r *RuntimeError) IsInterrupt() bool {
+	return r.Code >= errInterruptBegin && r.Code <= errInterruptEnd
+}
+
 const (
 	ErrIndexOutOfRange          = 40
 	ErrIndexKeyNotFound         = 41
@@ -36,11 +42,14 @@ const (
 	ErrDuplicateModule          = 62
 	ErrModuleCircularDependency = 63
 	ErrLibraryNotFound          = 64
+	ErrVendorPackageNotFound    = 65
+	ErrInvalidVendorManifest    = 66
 	// internal error
 	ErrUnexpectedCase           = 70
 	ErrUnexpectedEmptyExecLogic = 71
 	ErrUnexpectedAssign         = 72
 	ErrUnexpectedParamWildcard  = 73
+	ErrUnexpectedRethrow        = 74
 	// type error
 	ErrInvalidExprType            = 80
 	ErrInvalidFuncVariable        = 81
@@ -51,15 +60,33 @@ const (
 	ErrInvalidExceptionObjectType = 86
 	ErrInvalidClassType           = 87
 	// arith error
-	ErrArithDivZero          = 90
-	ErrArithRootLessThanZero = 91
+	ErrArithDivZero              = 90
+	ErrArithRootLessThanZero     = 91
+	ErrArithIncompatibleUnits    = 92
+	ErrArithExchangeRateNotFound = 93
+	ErrArithOutOfDomain          = 94
 	// input error
 	ErrInputValueNotFound = 95
+	// interrupt error - execution is stopped by the host, and it's NOT catchable
+	// by 拦截 blocks
+	ErrExecTimeout         = 100
+	ErrExecCancelled       = 101
+	ErrExecStepsExceeded   = 102
+	ErrCallDepthExceeded   = 103
+	ErrElementSizeExceeded = 104
+	// the range of interrupt errors (see IsInterrupt())
+	errInterruptBegin = ErrExecTimeout
+	errInterruptEnd   = ErrElementSizeExceeded
+
+	ErrPermissionDenied = 110
 )
 
 var typeNameMap = map[string]string{
 	"string":   "文本",
 	"number":   "数值",
+	"decimal":  "小数",
+	"currency": "货币",
+	"datetime": "日期",
 	"integer":  "整数",
 	"function": "方法",
 	"bool":     "逻辑",
@@ -211,6 +238,24 @@ func LibraryNotFound(name string) *RuntimeError {
 	}
 }
 
+// VendorPackageNotFound -
+func VendorPackageNotFound(name string) *RuntimeError {
+	return &RuntimeError{
+		Code:    ErrVendorPackageNotFound,
+		Message: fmt.Sprintf("未找到「%s」依赖包", name),
+		Extra:   name,
+	}
+}
+
+// InvalidVendorManifest -
+func InvalidVendorManifest(name string, reason string) *RuntimeError {
+	return &RuntimeError{
+		Code:    ErrInvalidVendorManifest,
+		Message: fmt.Sprintf("依赖包「%s」的描述文件无效：%s", name, reason),
+		Extra:   name,
+	}
+}
+
 // ImportSameModule -
 func ImportSameModule(name string) *RuntimeError {
 	return &RuntimeError{
@@ -274,6 +319,14 @@ func UnexpectedParamWildcard() *RuntimeError {
 	}
 }
 
+func UnexpectedRethrow() *RuntimeError {
+	return &RuntimeError{
+		Code:    ErrUnexpectedRethrow,
+		Message: "「抛出！」只能在拦截块中使用",
+		Extra:   nil,
+	}
+}
+
 //// type errors
 
 // InvalidExprType -
@@ -397,6 +450,36 @@ func ArithRootLessThanZero() *RuntimeError {
 	}
 }
 
+func ArithIncompatibleUnits(leftUnit string, rightUnit string) *RuntimeError {
+	if leftUnit == "" {
+		leftUnit = "（无单位）"
+	}
+	if rightUnit == "" {
+		rightUnit = "（无单位）"
+	}
+	return &RuntimeError{
+		Code:    ErrArithIncompatibleUnits,
+		Message: fmt.Sprintf("单位「%s」与「%s」不兼容，无法进行此运算", leftUnit, rightUnit),
+		Extra:   nil,
+	}
+}
+
+func ArithExchangeRateNotFound(from string, to string) *RuntimeError {
+	return &RuntimeError{
+		Code:    ErrArithExchangeRateNotFound,
+		Message: fmt.Sprintf("无法获取「%s」兑「%s」的汇率", from, to),
+		Extra:   nil,
+	}
+}
+
+func ArithOutOfDomain(name string) *RuntimeError {
+	return &RuntimeError{
+		Code:    ErrArithOutOfDomain,
+		Message: fmt.Sprintf("计算%s时，参数超出定义域", name),
+		Extra:   name,
+	}
+}
+
 func InputValueNotFound(tag string) *RuntimeError {
 	return &RuntimeError{
 		Code:    ErrInputValueNotFound,
@@ -405,6 +488,74 @@ func InputValueNotFound(tag string) *RuntimeError {
 	}
 }
 
+// ExecTimeout -
+func ExecTimeout() *RuntimeError {
+	return &RuntimeError{
+		Code:    ErrExecTimeout,
+		Message: "程序执行超时，已被强制终止",
+		Extra:   nil,
+	}
+}
+
+// ExecCancelled -
+func ExecCancelled() *RuntimeError {
+	return &RuntimeError{
+		Code:    ErrExecCancelled,
+		Message: "程序执行已被取消",
+		Extra:   nil,
+	}
+}
+
+// ExecStepsExceeded -
+func ExecStepsExceeded(maxSteps int) *RuntimeError {
+	return &RuntimeError{
+		Code:    ErrExecStepsExceeded,
+		Message: fmt.Sprintf("执行语句数超过上限（%d条），已被强制终止", maxSteps),
+		Extra:   maxSteps,
+	}
+}
+
+// CallDepthExceeded -
+func CallDepthExceeded(maxDepth int) *RuntimeError {
+	return &RuntimeError{
+		Code:    ErrCallDepthExceeded,
+		Message: fmt.Sprintf("调用层数超过上限（%d层），可能存在无限递归", maxDepth),
+		Extra:   maxDepth,
+	}
+}
+
+// ElementSizeExceeded -
+func ElementSizeExceeded(typeName string, maxSize int) *RuntimeError {
+	label := typeName
+	if v, ok := typeNameMap[typeName]; ok {
+		label = v
+	}
+	return &RuntimeError{
+		Code:    ErrElementSizeExceeded,
+		Message: fmt.Sprintf("「%s」的长度超过上限（%d）", label, maxSize),
+		Extra:   maxSize,
+	}
+}
+
+// PermissionDenied - the operation is not allowed by the permission policy of the interpreter.
+// resType: library, file, host
+func PermissionDenied(resType string, name string) *RuntimeError {
+	resLabels := map[string]string{
+		"library": "导入库",
+		"file":    "访问文件",
+		"host":    "访问主机",
+	}
+	label := resType
+	if v, ok := resLabels[resType]; ok {
+		label = v
+	}
+	return &RuntimeError{
+		Code:    ErrPermissionDenied,
+		Message: fmt.Sprintf("没有权限%s「%s」", label, name),
+		Extra:   name,
+	}
+}
+
 // // SLOT
 func NewErrorSLOT(info string) error {
 	return fmt.Errorf(info)
@@ -0,0 +1,183 @@
+package exec
+
+import (
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/syntax"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+// bytecodeOps - implements r.BytecodeOps with the same helpers of the AST evaluator
+type bytecodeOps struct{}
+
+var bcOps = bytecodeOps{}
+
+func (bytecodeOps) Binary(vm *r.VM, op uint8, left r.Element, right r.Element) (r.Element, error) {
+	switch op {
+	case syntax.ArithModulo:
+		return moduloOperate(vm, left, right)
+	case syntax.ArithAdd, syntax.ArithSub, syntax.ArithMul, syntax.ArithDiv, syntax.ArithIntDiv:
+		return arithOperate(vm, op, left, right)
+	}
+
+	result, err := compareOperate(op, left, right)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (bytecodeOps) IsTrue(elem r.Element) (bool, error) {
+	v, ok := elem.(*value.Bool)
+	if !ok {
+		return false, zerr.InvalidExprType("bool")
+	}
+	return v.GetValue(), nil
+}
+
+func (bytecodeOps) Duplicate(elem r.Element) r.Element {
+	return value.DuplicateValue(elem)
+}
+
+func (bytecodeOps) CheckSize(vm *r.VM, elem r.Element) error {
+	return checkElementSize(vm, elem)
+}
+
+func (bytecodeOps) BuildArray(items []r.Element) r.Element {
+	return value.NewArray(items)
+}
+
+func (bytecodeOps) BuildHashMap(keys []string, values []r.Element) r.Element {
+	var pairs []value.KVPair
+	for idx, key := range keys {
+		pairs = append(pairs, value.KVPair{Key: key, Value: values[idx]})
+	}
+	return value.NewHashMap(pairs)
+}
+
+func (bytecodeOps) GetMember(root r.Element, name string) (r.Element, error) {
+	return value.NewMemberIV(root, name).ReduceRHS()
+}
+
+func (bytecodeOps) SetMember(root r.Element, name string, elem r.Element) error {
+	return value.NewMemberIV(root, name).ReduceLHS(elem)
+}
+
+func (bytecodeOps) GetIndex(root r.Element, index r.Element) (r.Element, error) {
+	iv, err := newIndexIV(root, index)
+	if err != nil {
+		return nil, err
+	}
+	return iv.ReduceRHS()
+}
+
+func (bytecodeOps) SetIndex(root r.Element, index r.Element, elem r.Element) error {
+	iv, err := newIndexIV(root, index)
+	if err != nil {
+		return err
+	}
+	return iv.ReduceLHS(elem)
+}
+
+func (bytecodeOps) CallFunction(vm *r.VM, name string, params []r.Element) (r.Element, error) {
+	return execDirectFunction(vm, r.NewIDName(name), params)
+}
+
+func (bytecodeOps) CallMethod(vm *r.VM, root r.Element, name string, params []r.Element) (r.Element, error) {
+	return execMethodFunction(vm, root, r.NewIDName(name), params)
+}
+
+func (bytecodeOps) NewObject(vm *r.VM, class r.Element, className string, params []r.Element) (r.Element, error) {
+	return newObjectFromClass(class, params)
+}
+
+func (bytecodeOps) ThrowException(vm *r.VM, class r.Element, className string, params []r.Element) error {
+	return newExceptionSignal(vm, class, className, params)
+}
+
+func (bytecodeOps) Rethrow(vm *r.VM) error {
+	return evalRethrowStmt(vm)
+}
+
+func (bytecodeOps) Iterate(target r.Element) (r.Iterator, error) {
+	switch tv := target.(type) {
+	case *value.Array:
+		return &arrayIterator{items: tv.GetValue()}, nil
+	case *value.HashMap:
+		return &hashMapIterator{hashMap: tv, keys: tv.GetKeyOrder()}, nil
+	case r.IterableElement:
+		return tv.Iterate()
+	}
+	return nil, zerr.InvalidExprType("array", "hashmap")
+}
+
+func (bytecodeOps) MakeClosure(vm *r.VM, node *syntax.FunctionExpr) r.Element {
+	return compileClosure(vm, node)
+}
+
+// arrayIterator - in iterate statement, index starts from 1 instead of 0
+type arrayIterator struct {
+	items []r.Element
+	idx   int
+}
+
+func (it *arrayIterator) Next() (r.Element, r.Element, bool) {
+	if it.idx >= len(it.items) {
+		return nil, nil, false
+	}
+	it.idx++
+	return value.NewNumber(float64(it.idx)), it.items[it.idx-1], true
+}
+
+type hashMapIterator struct {
+	hashMap *value.HashMap
+	keys    []string
+	idx     int
+}
+
+func (it *hashMapIterator) Next() (r.Element, r.Element, bool) {
+	if it.idx >= len(it.keys) {
+		return nil, nil, false
+	}
+	key := it.keys[it.idx]
+	it.idx++
+	return value.NewString(key), it.hashMap.GetValue()[key], true
+}
+
+// execCompiledBlock - the bytecode version of evalExecBlock() after params are checked
+func execCompiledBlock(vm *r.VM, blockModule *r.Module, csCount int, execBlock *syntax.ExecBlock, compiled *compiledExecBlock, params []r.Element) (r.Element, error) {
+	slots := make([]r.Element, compiled.numSlots)
+	if compiled.slotMode {
+		copy(slots, params)
+	} else {
+		for idx, param := range execBlock.InputBlock {
+			idTag, err := MatchIDName(param)
+			if err != nil {
+				return nil, err
+			}
+			if err := vm.DeclareElement(idTag, params[idx]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	rtnValue, err := func() (r.Element, error) {
+		if err := evalDeclarations(vm, execBlock.StmtBlock); err != nil {
+			return nil, err
+		}
+		return vm.RunChunk(compiled.main, slots, bcOps)
+	}()
+	if err != nil {
+		rtnValue, err = handleExceptionSignal(vm, blockModule, csCount, execBlock.CatchBlock, err, func(idx int) error {
+			_, err := vm.RunChunk(compiled.catchBlocks[idx], slots, bcOps)
+			return err
+		})
+	}
+	if compiled.finallyBlock != nil {
+		return handleFinallyBlock(vm, csCount, rtnValue, err, func() error {
+			_, err := vm.RunChunk(compiled.finallyBlock, slots, bcOps)
+			return err
+		})
+	}
+	return rtnValue, err
+}
@@ -0,0 +1,162 @@
+package exec
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	"github.com/DemoHn/Zn/pkg/io"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/syntax"
+	"github.com/DemoHn/Zn/pkg/syntax/zh"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+// TestBytecode_ExamplePrograms - the example programs are compiled entirely, and both engines yield the same result
+// (获取IP.zn requires network, and 计算国际物流费.zn could not be parsed at all)
+func TestBytecode_ExamplePrograms(t *testing.T) {
+	cases := []struct {
+		file  string
+		input r.ElementMap
+	}{
+		{"企业税务计算.zn", r.ElementMap{
+			"销售额":  value.NewNumber(3000000),
+			"采购成本": value.NewNumber(1200000),
+			"人工成本": value.NewNumber(500000),
+		}},
+		{"冒泡排序.zn", r.ElementMap{"数组文本": value.NewString("123，456，12.4，125，-3")}},
+		{"求斐波那契数.zn", r.ElementMap{"N": value.NewNumber(12)}},
+		{"关键词词频统计.zn", r.ElementMap{}},
+		{"换算温度.zn", r.ElementMap{}},
+		{"最小二乘法.zn", r.ElementMap{}},
+		{"计算FBA配送费.zn", r.ElementMap{}},
+		{"计算快递费用.zn", r.ElementMap{}},
+		{"读博决策树.zn", r.ElementMap{}},
+		{"高考录取.zn", r.ElementMap{}},
+		{"鸡兔同笼.zn", r.ElementMap{}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.file, func(t *testing.T) {
+			example, err := os.ReadFile("../../doc/zh-cn/snippets/example/" + tt.file)
+			if err != nil {
+				t.Fatalf("read example failed: %v", err)
+			}
+			code := string(example)
+
+			// ensure the program is really executed by the bytecode engine
+			program := parseBytecodeProgram(t, code)
+			if _, err := compileExecBlock(program.ExecBlock, false); err != nil {
+				t.Fatalf("compile program failed: %v", err)
+			}
+
+			// some examples end with an exception - then both engines shall fail the same way
+			expected, expectedErr := NewInterpreter("test").LoadScript([]rune(code)).Execute(tt.input)
+			runBothEngines(t, code, tt.input, nil, func(t *testing.T, result r.Element, err error) {
+				if expectedErr != nil {
+					if err == nil || err.Error() != expectedErr.Error() {
+						t.Errorf("expect error %v, got %v", expectedErr, err)
+					}
+					return
+				}
+				expectResult(expected.String())(t, result, err)
+			})
+		})
+	}
+}
+
+func TestBytecode_Compile(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		slotMode bool
+		expected []string
+	}{
+		{
+			name:     "main program in name mode",
+			code:     "令A = 1\nA = A + 2",
+			slotMode: false,
+			expected: []string{"BEGIN_SCOPE", "DECLARE_NAME 0 (A)", "STORE_NAME 0 (A)", "END_SCOPE"},
+		},
+		{
+			name:     "function block in slot mode",
+			code:     "输入X\n令A = X\n每当A < 10：\n    A = A + 1\n输出A",
+			slotMode: true,
+			expected: []string{"DECLARE_LOCAL 1", "LOAD_LOCAL 0", "STORE_LOCAL 1", "RETURN"},
+		},
+		{
+			name:     "block with declarations in name mode",
+			code:     "输入X\n如何F？\n    输出1\n输出X",
+			slotMode: true,
+			expected: []string{"LOAD_NAME 0 (X)", "RETURN"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parseBytecodeProgram(t, tt.code)
+			compiled, err := compileExecBlock(program.ExecBlock, tt.slotMode)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			asm := compiled.main.Disassemble()
+			for _, item := range tt.expected {
+				if !strings.Contains(asm, item) {
+					t.Errorf("expect instruction %s, got:\n%s", item, asm)
+				}
+			}
+		})
+	}
+}
+
+func TestBytecode_NotCompilable(t *testing.T) {
+	cases := []struct {
+		name string
+		code string
+	}{
+		{"redeclare in same scope", "输入X\n令A = 1\n令A = X"},
+		{"assign to const", "令A 恒为 1\nA = 2"},
+		{"break outside loop", "结束循环"},
+		{"call local variable", "输入X\n（X）"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parseBytecodeProgram(t, tt.code)
+			if _, err := compileExecBlock(program.ExecBlock, true); err != errNotCompilable {
+				t.Errorf("expect errNotCompilable, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBytecode_Interrupt(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	code := "如何死循环？\n    令A = 0\n    每当真：\n        A = A + 1\n\n（死循环）"
+	_, err := NewInterpreter("test").SetBytecode(true).
+		LoadScript([]rune(code)).ExecuteContext(ctx, r.ElementMap{})
+	if c := getRuntimeErrorCode(err); c != zerr.ErrExecTimeout {
+		t.Fatalf("expect ErrExecTimeout, got %v", err)
+	}
+
+	_, err = NewInterpreter("test").SetBytecode(true).
+		SetExecLimits(r.ExecLimits{MaxSteps: 100}).
+		LoadScript([]rune(code)).Execute(r.ElementMap{})
+	if c := getRuntimeErrorCode(err); c != zerr.ErrExecStepsExceeded {
+		t.Fatalf("expect ErrExecStepsExceeded, got %v", err)
+	}
+}
+
+func parseBytecodeProgram(t *testing.T, code string) *syntax.Program {
+	source, _ := io.NewByteStream([]byte(code)).ReadAll()
+	program, err := syntax.NewParser(source, zh.NewParserZH()).Compile()
+	if err != nil {
+		t.Fatalf("parse code failed: %v", err)
+	}
+	return program
+}
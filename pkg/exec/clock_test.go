@@ -0,0 +1,70 @@
+package exec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	libTime "github.com/DemoHn/Zn/stdlib/time"
+)
+
+func TestClock_FrozenTime(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	clock := r.NewFrozenClock(time.Date(2024, 3, 15, 8, 30, 0, 0, loc))
+
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name:     "now & today",
+			code:     "【（当前时间：“Asia/Shanghai”），（今天：“Asia/Shanghai”），（当前时间：“UTC”）】",
+			expected: "[2024-03-15 08:30:00，2024-03-15，2024-03-15 00:30:00]",
+		},
+		{
+			name:     "parse & format",
+			code:     "令A = （解析时间：“2024年3月5日”、“YYYY年M月D日”、“UTC”）\n【A，（格式化时间：A、“M/D hh:mm ZZ”），（格式化时间：A、“hh:mm”、“Asia/Tokyo”）】",
+			expected: "[2024-03-05 00:00:00，3/5 00:00 +00:00，09:00]",
+		},
+		{
+			name:     "sleep doesn't advance the frozen time",
+			code:     "令A = （当前时间）\n（等待：3秒）\n（经过时间：A）",
+			expected: "0秒",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, "导入《@时间》\n"+tt.code, r.ElementMap{}, func(z *Interpreter) *Interpreter {
+				return z.SetExternalLibs([]*r.Library{libTime.Export()}).SetClock(clock)
+			}, expectResult(tt.expected))
+		})
+	}
+
+	// measure the elapsed time by advancing the clock
+	code := "导入《@时间》\n令A = （当前时间：“Asia/Shanghai”）\n输出以A（格式化：“hh:mm”）"
+	clock.Advance(90 * time.Minute)
+	result, err := NewInterpreter("test").SetExternalLibs([]*r.Library{libTime.Export()}).SetClock(clock).
+		LoadScript([]rune(code)).Execute(r.ElementMap{})
+	if err != nil || result.String() != "10:00" {
+		t.Errorf("expect 10:00 after advancing the clock, got %v (error: %v)", result, err)
+	}
+}
+
+func TestClock_SleepInterrupted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewInterpreter("test").SetExternalLibs([]*r.Library{libTime.Export()}).
+		LoadScript([]rune("导入《@时间》\n（等待：10min）")).ExecuteContext(ctx, r.ElementMap{})
+	if code := getRuntimeErrorCode(err); code != zerr.ErrExecTimeout {
+		t.Errorf("expect ErrExecTimeout, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("expect sleeping to be interrupted")
+	}
+}
@@ -0,0 +1,721 @@
+package exec
+
+import (
+	"errors"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/syntax"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+// compiler.go compiles exec blocks (the program & function bodies) into bytecode
+// chunks that are executed by r.VM.RunChunk(). It's an alternative engine of the
+// AST evaluator (eval.go) and MUST keep the same semantics with it.
+//
+// There're two compile modes:
+//
+//  1. name mode: variables are declared & accessed by name, with the same scopes
+//     as the AST evaluator. It's used for the main program (whose variables may be
+//     referenced by functions) and exec blocks that declare classes/functions.
+//  2. slot mode: local variables of functions are resolved to slots at compile
+//     time. Names that are not declared locally are still looked up by name.
+//
+// If a block contains something that can't be compiled faithfully (e.g. a
+// redeclared variable, which is a runtime error for the AST evaluator), compiling
+// stops with errNotCompilable and the block is evaluated by the AST evaluator instead.
+
+// errNotCompilable - the block could not be compiled; evaluate it from AST instead
+var errNotCompilable = errors.New("exec block is not compilable")
+
+// compiledExecBlock - the compiled result of an exec block
+type compiledExecBlock struct {
+	slotMode bool
+	numSlots int
+	main     *r.Chunk
+	// catchBlocks - compiled chunks of each catch block (same order as ExecBlock.CatchBlock)
+	catchBlocks []*r.Chunk
+	// finallyBlock - compiled chunk of the 善后 block (nil if not exists)
+	finallyBlock *r.Chunk
+}
+
+type compilerLocal struct {
+	name    string
+	depth   int
+	slot    int
+	isConst bool
+}
+
+type compilerLoop struct {
+	// scopeDepth - number of begun scopes when entering the loop body
+	scopeDepth  int
+	continuePos int
+	breakJumps  []int
+}
+
+type bytecodeCompiler struct {
+	chunk    *r.Chunk
+	slotMode bool
+	// locals - declared local variables (slot mode ONLY); the latter ones shadow the former ones
+	locals   []compilerLocal
+	depth    int
+	numSlots int
+	// scopeDepth - number of OpBeginScope emitted for current position (name mode ONLY)
+	scopeDepth int
+	loops      []*compilerLoop
+	nullConst  int
+}
+
+// tryCompileExecBlock - compile the exec block if the bytecode engine is enabled.
+// It returns nil if the engine is disabled or the block is not compilable.
+func tryCompileExecBlock(vm *r.VM, execBlock *syntax.ExecBlock, slotMode bool) *compiledExecBlock {
+	if !vm.IsBytecodeEnabled() || execBlock == nil {
+		return nil
+	}
+	compiled, err := compileExecBlock(execBlock, slotMode)
+	if err != nil {
+		return nil
+	}
+	return compiled
+}
+
+func compileExecBlock(execBlock *syntax.ExecBlock, slotMode bool) (*compiledExecBlock, error) {
+	// classes & functions are declared by name in the same scope of params, so
+	// params have to be declared by name as well
+	for _, stmt := range execBlock.StmtBlock.Children {
+		switch stmt.(type) {
+		case *syntax.ClassDeclareStmt, *syntax.FunctionDeclareStmt:
+			slotMode = false
+		}
+	}
+
+	c := &bytecodeCompiler{slotMode: slotMode}
+	// params are declared at depth 0
+	if slotMode {
+		for _, param := range execBlock.InputBlock {
+			name, err := MatchIDName(param)
+			if err != nil {
+				return nil, errNotCompilable
+			}
+			if _, err := c.declareLocal(name.GetLiteral(), false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	numParams := len(c.locals)
+
+	// compile main block
+	c.resetChunk()
+	if err := c.compileBlock(execBlock.StmtBlock, true); err != nil {
+		return nil, err
+	}
+	compiled := &compiledExecBlock{
+		slotMode: slotMode,
+		main:     c.chunk,
+	}
+
+	// compile catch blocks - only params are visible inside them
+	for _, catchItem := range execBlock.CatchBlock {
+		c.locals = c.locals[:numParams]
+		c.resetChunk()
+		if err := c.compileBlock(catchItem.StmtBlock, false); err != nil {
+			return nil, err
+		}
+		compiled.catchBlocks = append(compiled.catchBlocks, c.chunk)
+	}
+	// compile finally block - same as catch blocks
+	if execBlock.FinallyBlock != nil {
+		c.locals = c.locals[:numParams]
+		c.resetChunk()
+		if err := c.compileBlock(execBlock.FinallyBlock, false); err != nil {
+			return nil, err
+		}
+		compiled.finallyBlock = c.chunk
+	}
+
+	compiled.numSlots = c.numSlots
+	compiled.main.NumSlots = c.numSlots
+	for _, chunk := range compiled.catchBlocks {
+		chunk.NumSlots = c.numSlots
+	}
+	if compiled.finallyBlock != nil {
+		compiled.finallyBlock.NumSlots = c.numSlots
+	}
+	return compiled, nil
+}
+
+func (c *bytecodeCompiler) resetChunk() {
+	c.chunk = r.NewChunk()
+	c.nullConst = c.chunk.AddConst(value.NewNull())
+	c.depth = 0
+	c.scopeDepth = 0
+	c.loops = nil
+}
+
+func (c *bytecodeCompiler) emit(op r.Opcode, a int, b int) int {
+	return c.chunk.Emit(op, a, b)
+}
+
+//// locals
+
+// declareLocal - allocate a new slot for the local variable on current depth
+func (c *bytecodeCompiler) declareLocal(name string, isConst bool) (int, error) {
+	// those cases will yield errors (or different results) from the AST evaluator
+	if _, inGlobals := globalValues[name]; inGlobals || name == EVConstThisVariableName {
+		return 0, errNotCompilable
+	}
+	for i := len(c.locals) - 1; i >= 0 && c.locals[i].depth == c.depth; i-- {
+		if c.locals[i].name == name {
+			return 0, errNotCompilable
+		}
+	}
+	slot := c.numSlots
+	c.numSlots++
+	c.locals = append(c.locals, compilerLocal{name: name, depth: c.depth, slot: slot, isConst: isConst})
+	return slot, nil
+}
+
+func (c *bytecodeCompiler) resolveLocal(name string) (compilerLocal, bool) {
+	for i := len(c.locals) - 1; i >= 0; i-- {
+		if c.locals[i].name == name {
+			return c.locals[i], true
+		}
+	}
+	return compilerLocal{}, false
+}
+
+// emitDeclare - declare the top item of stack as a variable (the item is kept)
+func (c *bytecodeCompiler) emitDeclare(id *syntax.ID, isConst bool) error {
+	name, err := MatchIDName(id)
+	if err != nil {
+		return errNotCompilable
+	}
+	if c.slotMode {
+		slot, err := c.declareLocal(name.GetLiteral(), isConst)
+		if err != nil {
+			return err
+		}
+		c.emit(r.OpDeclareLocal, slot, 0)
+		return nil
+	}
+
+	constFlag := 0
+	if isConst {
+		constFlag = 1
+	}
+	c.emit(r.OpDeclareName, c.chunk.AddName(name.GetLiteral()), constFlag)
+	return nil
+}
+
+// emitStore - set the top item of stack to a variable (the item is kept)
+func (c *bytecodeCompiler) emitStore(name string) error {
+	if local, ok := c.resolveLocal(name); ok {
+		if local.isConst {
+			return errNotCompilable
+		}
+		c.emit(r.OpStoreLocal, local.slot, 0)
+		return nil
+	}
+	c.emit(r.OpStoreName, c.chunk.AddName(name), 0)
+	return nil
+}
+
+func (c *bytecodeCompiler) emitLoad(name string) {
+	if local, ok := c.resolveLocal(name); ok {
+		c.emit(r.OpLoadLocal, local.slot, 0)
+		return
+	}
+	c.emit(r.OpLoadName, c.chunk.AddName(name), 0)
+}
+
+//// blocks & statements
+
+// beginBlock - begin a new scope (like vm.BeginScope() in evalPureStmtBlock)
+func (c *bytecodeCompiler) beginBlock() {
+	c.depth++
+	if !c.slotMode {
+		c.emit(r.OpBeginScope, 0, 0)
+		c.scopeDepth++
+	}
+}
+
+func (c *bytecodeCompiler) endBlock() {
+	for len(c.locals) > 0 && c.locals[len(c.locals)-1].depth >= c.depth {
+		c.locals = c.locals[:len(c.locals)-1]
+	}
+	c.depth--
+	if !c.slotMode {
+		c.emit(r.OpEndScope, 0, 0)
+		c.scopeDepth--
+	}
+}
+
+// compileBlock - compile the block just like evalPureStmtBlock(). For top-level blocks,
+// the value of each statement will be saved as the result of chunk.
+func (c *bytecodeCompiler) compileBlock(stmtBlock *syntax.StmtBlock, topLevel bool) error {
+	c.beginBlock()
+	for _, stmt := range stmtBlock.Children {
+		if err := c.compileStatement(stmt, topLevel); err != nil {
+			return err
+		}
+	}
+	c.endBlock()
+	return nil
+}
+
+func (c *bytecodeCompiler) compileStatement(stmt syntax.Statement, topLevel bool) error {
+	switch stmt.(type) {
+	case *syntax.ClassDeclareStmt, *syntax.FunctionDeclareStmt:
+		// they are declared before executing the block
+		return nil
+	}
+	c.emit(r.OpLine, stmt.GetCurrentLine(), 0)
+
+	var err error
+	switch v := stmt.(type) {
+	case *syntax.VarDeclareStmt:
+		err = c.compileVarDeclareStmt(v)
+	case *syntax.WhileLoopStmt:
+		err = c.compileWhileLoopStmt(v)
+	case *syntax.BranchStmt:
+		err = c.compileBranchStmt(v)
+	case *syntax.EmptyStmt:
+	case *syntax.IterateStmt:
+		err = c.compileIterateStmt(v)
+	case *syntax.FunctionReturnStmt:
+		if err := c.compileExpression(v.ReturnExpr); err != nil {
+			return err
+		}
+		c.emit(r.OpReturn, 0, 0)
+		return nil
+	case *syntax.ThrowExceptionStmt:
+		err = c.compileThrowExceptionStmt(v)
+	case *syntax.RethrowStmt:
+		c.emit(r.OpRethrow, 0, 0)
+	case *syntax.ContinueStmt:
+		err = c.compileLoopJump(false)
+	case *syntax.BreakStmt:
+		err = c.compileLoopJump(true)
+	case syntax.Expression:
+		if err := c.compileExpression(v); err != nil {
+			return err
+		}
+		if topLevel {
+			c.emit(r.OpSetResult, 0, 0)
+		} else {
+			c.emit(r.OpPop, 0, 0)
+		}
+		return nil
+	default:
+		return errNotCompilable
+	}
+	if err != nil {
+		return err
+	}
+
+	// non-expression statements yield Null
+	if topLevel {
+		c.emit(r.OpConst, c.nullConst, 0)
+		c.emit(r.OpSetResult, 0, 0)
+	}
+	return nil
+}
+
+func (c *bytecodeCompiler) compileVarDeclareStmt(node *syntax.VarDeclareStmt) error {
+	for _, vpair := range node.AssignPair {
+		if vpair.Type != syntax.VDTypeAssign && vpair.Type != syntax.VDTypeAssignConst {
+			continue
+		}
+		if err := c.compileExpression(vpair.AssignExpr); err != nil {
+			return err
+		}
+		// each variable holds a duplicated value of the previous one
+		for _, v := range vpair.Variables {
+			c.emit(r.OpCopyValue, 0, 0)
+			if err := c.emitDeclare(v, vpair.Type == syntax.VDTypeAssignConst); err != nil {
+				return err
+			}
+		}
+		c.emit(r.OpPop, 0, 0)
+	}
+	return nil
+}
+
+func (c *bytecodeCompiler) compileWhileLoopStmt(node *syntax.WhileLoopStmt) error {
+	loopStart := c.emit(r.OpCheckInterrupt, 0, 0)
+	if err := c.compileExpression(node.TrueExpr); err != nil {
+		return err
+	}
+	exitJump := c.emit(r.OpJumpIfFalse, 0, 0)
+
+	loop := &compilerLoop{scopeDepth: c.scopeDepth, continuePos: loopStart}
+	c.loops = append(c.loops, loop)
+	if err := c.compileBlock(node.LoopBlock, false); err != nil {
+		return err
+	}
+	c.loops = c.loops[:len(c.loops)-1]
+	c.emit(r.OpJump, loopStart, 0)
+
+	c.chunk.PatchJump(exitJump)
+	for _, pos := range loop.breakJumps {
+		c.chunk.PatchJump(pos)
+	}
+	return nil
+}
+
+func (c *bytecodeCompiler) compileBranchStmt(node *syntax.BranchStmt) error {
+	var endJumps []int
+
+	conds := append([]syntax.Expression{node.IfTrueExpr}, node.OtherExprs...)
+	blocks := append([]*syntax.StmtBlock{node.IfTrueBlock}, node.OtherBlocks...)
+	for idx, cond := range conds {
+		if err := c.compileExpression(cond); err != nil {
+			return err
+		}
+		nextJump := c.emit(r.OpJumpIfFalse, 0, 0)
+		if err := c.compileBlock(blocks[idx], false); err != nil {
+			return err
+		}
+		endJumps = append(endJumps, c.emit(r.OpJump, 0, 0))
+		c.chunk.PatchJump(nextJump)
+	}
+
+	if node.HasElse {
+		if err := c.compileBlock(node.IfFalseBlock, false); err != nil {
+			return err
+		}
+	}
+	for _, pos := range endJumps {
+		c.chunk.PatchJump(pos)
+	}
+	return nil
+}
+
+func (c *bytecodeCompiler) compileIterateStmt(node *syntax.IterateStmt) error {
+	nameLen := len(node.IndexNames)
+	if nameLen > 2 {
+		return errNotCompilable
+	}
+	// same names will be redeclared on the same scope
+	if nameLen == 2 && node.IndexNames[0].GetLiteral() == node.IndexNames[1].GetLiteral() {
+		return errNotCompilable
+	}
+
+	c.beginBlock()
+	if err := c.compileExpression(node.IterateExpr); err != nil {
+		return err
+	}
+	// declare indication variables (initial value = Null)
+	var names []string
+	for _, id := range node.IndexNames {
+		c.emit(r.OpConst, c.nullConst, 0)
+		if err := c.emitDeclare(id, false); err != nil {
+			return err
+		}
+		c.emit(r.OpPop, 0, 0)
+		names = append(names, id.GetLiteral())
+	}
+	c.emit(r.OpIterInit, 0, 0)
+
+	// stack after OpIterNext: (key, value)
+	loopStart := c.emit(r.OpIterNext, 0, 0)
+	switch nameLen {
+	case 0:
+		c.emit(r.OpPop, 0, 0)
+		c.emit(r.OpPop, 0, 0)
+	case 1:
+		if err := c.emitStore(names[0]); err != nil {
+			return err
+		}
+		c.emit(r.OpPop, 0, 0)
+		c.emit(r.OpPop, 0, 0)
+	case 2:
+		if err := c.emitStore(names[1]); err != nil {
+			return err
+		}
+		c.emit(r.OpPop, 0, 0)
+		if err := c.emitStore(names[0]); err != nil {
+			return err
+		}
+		c.emit(r.OpPop, 0, 0)
+	}
+
+	loop := &compilerLoop{scopeDepth: c.scopeDepth, continuePos: loopStart}
+	c.loops = append(c.loops, loop)
+	if err := c.compileBlock(node.IterateBlock, false); err != nil {
+		return err
+	}
+	c.loops = c.loops[:len(c.loops)-1]
+	c.emit(r.OpJump, loopStart, 0)
+
+	c.chunk.PatchJump(loopStart)
+	for _, pos := range loop.breakJumps {
+		c.chunk.PatchJump(pos)
+	}
+	c.emit(r.OpIterEnd, 0, 0)
+	c.endBlock()
+	return nil
+}
+
+// compileLoopJump - compile 结束循环 (isBreak = true) or 继续循环 (isBreak = false)
+func (c *bytecodeCompiler) compileLoopJump(isBreak bool) error {
+	if len(c.loops) == 0 {
+		// the signal may be handled by the loop of caller
+		return errNotCompilable
+	}
+	loop := c.loops[len(c.loops)-1]
+	// end scopes begun inside the loop body
+	for i := c.scopeDepth; i > loop.scopeDepth; i-- {
+		c.emit(r.OpEndScope, 0, 0)
+	}
+	if isBreak {
+		loop.breakJumps = append(loop.breakJumps, c.emit(r.OpJump, 0, 0))
+	} else {
+		c.emit(r.OpJump, loop.continuePos, 0)
+	}
+	return nil
+}
+
+func (c *bytecodeCompiler) compileThrowExceptionStmt(node *syntax.ThrowExceptionStmt) error {
+	className, err := MatchIDName(node.ExceptionClass)
+	if err != nil {
+		return errNotCompilable
+	}
+	c.emitLoad(className.GetLiteral())
+	if err := c.compileExpressions(node.Params); err != nil {
+		return err
+	}
+	c.emit(r.OpThrow, c.chunk.AddName(className.GetLiteral()), len(node.Params))
+	return nil
+}
+
+//// expressions
+
+func (c *bytecodeCompiler) compileExpressions(exprs []syntax.Expression) error {
+	for _, expr := range exprs {
+		if err := c.compileExpression(expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *bytecodeCompiler) compileExpression(expr syntax.Expression) error {
+	switch e := expr.(type) {
+	case *syntax.VarAssignExpr:
+		return c.compileVarAssignExpr(e)
+	case *syntax.LogicExpr:
+		if e.Type == syntax.LogicAND || e.Type == syntax.LogicOR {
+			return c.compileLogicCombiner(e)
+		}
+		return c.compileBinary(e.Type, e.LeftExpr, e.RightExpr)
+	case *syntax.ArithExpr:
+		return c.compileBinary(e.Type, e.LeftExpr, e.RightExpr)
+	case *syntax.MemberExpr:
+		return c.compileMemberExpr(e, false)
+	case *syntax.String:
+		c.emit(r.OpConst, c.chunk.AddConst(value.NewString(e.GetLiteral())), 0)
+	case *syntax.ID:
+		idValue, err := MatchIDType(e)
+		if err != nil {
+			return errNotCompilable
+		}
+		switch t := idValue.(type) {
+		case *r.IDName:
+			c.emitLoad(t.GetLiteral())
+		case *r.IDNumber:
+			num, err := newIDNumberValue(t)
+			if err != nil {
+				return errNotCompilable
+			}
+			c.emit(r.OpConst, c.chunk.AddConst(num), 0)
+		case *r.IDCurrency:
+			cur, err := newIDCurrencyValue(t)
+			if err != nil {
+				return errNotCompilable
+			}
+			c.emit(r.OpConst, c.chunk.AddConst(cur), 0)
+		case *r.IDDateTime:
+			c.emit(r.OpConst, c.chunk.AddConst(newIDDateTimeValue(t)), 0)
+		default:
+			return errNotCompilable
+		}
+	case *syntax.ArrayExpr:
+		if err := c.compileExpressions(e.Items); err != nil {
+			return err
+		}
+		c.emit(r.OpBuildArray, len(e.Items), 0)
+	case *syntax.HashMapExpr:
+		var keys []string
+		for _, item := range e.KVPair {
+			switch k := item.Key.(type) {
+			case *syntax.String:
+				keys = append(keys, k.GetLiteral())
+			case *syntax.ID:
+				if _, err := MatchIDType(k); err != nil {
+					return errNotCompilable
+				}
+				keys = append(keys, k.GetLiteral())
+			default:
+				return errNotCompilable
+			}
+			if err := c.compileExpression(item.Value); err != nil {
+				return err
+			}
+		}
+		c.emit(r.OpBuildHashMap, len(keys), c.chunk.AddNameList(keys))
+	case *syntax.FuncCallExpr:
+		return c.compileFuncCallExpr(e)
+	case *syntax.MemberMethodExpr:
+		return c.compileMemberMethodExpr(e)
+	case *syntax.ObjNewExpr:
+		className, err := MatchIDName(e.ClassName)
+		if err != nil {
+			return errNotCompilable
+		}
+		c.emitLoad(className.GetLiteral())
+		if err := c.compileExpressions(e.Params); err != nil {
+			return err
+		}
+		c.emit(r.OpNewObject, c.chunk.AddName(className.GetLiteral()), len(e.Params))
+	case *syntax.FunctionExpr:
+		// closures capture variables from the scope, which doesn't include slots
+		if c.slotMode {
+			return errNotCompilable
+		}
+		c.emit(r.OpMakeClosure, c.chunk.AddFuncExpr(e), 0)
+	default:
+		return errNotCompilable
+	}
+	return nil
+}
+
+func (c *bytecodeCompiler) compileBinary(op uint8, left syntax.Expression, right syntax.Expression) error {
+	if err := c.compileExpression(left); err != nil {
+		return err
+	}
+	if err := c.compileExpression(right); err != nil {
+		return err
+	}
+	c.emit(r.OpBinary, int(op), 0)
+	return nil
+}
+
+// A 且 B, A 或 B (short-circuit)
+func (c *bytecodeCompiler) compileLogicCombiner(expr *syntax.LogicExpr) error {
+	if err := c.compileExpression(expr.LeftExpr); err != nil {
+		return err
+	}
+	jumpOp := r.OpJumpIfFalseOrPop
+	if expr.Type == syntax.LogicOR {
+		jumpOp = r.OpJumpIfTrueOrPop
+	}
+	endJump := c.emit(jumpOp, 0, 0)
+	if err := c.compileExpression(expr.RightExpr); err != nil {
+		return err
+	}
+	c.emit(r.OpAssertBool, 0, 0)
+	c.chunk.PatchJump(endJump)
+	return nil
+}
+
+// compileMemberExpr - push root (and index) of the member expr, then get or set
+// (when isAssign = true) the member
+func (c *bytecodeCompiler) compileMemberExpr(expr *syntax.MemberExpr, isAssign bool) error {
+	getOp, setOp := r.OpGetMember, r.OpSetMember
+	switch expr.RootType {
+	case syntax.RootTypeProp: // 其 XX
+		c.emit(r.OpLoadThis, 0, 0)
+	case syntax.RootTypeExpr: // A 之 B, A # 0
+		if err := c.compileExpression(expr.Root); err != nil {
+			return err
+		}
+		switch expr.MemberType {
+		case syntax.MemberID:
+		case syntax.MemberIndex:
+			if err := c.compileExpression(expr.MemberIndex); err != nil {
+				return err
+			}
+			getOp, setOp = r.OpGetIndex, r.OpSetIndex
+		default:
+			return errNotCompilable
+		}
+	default:
+		return errNotCompilable
+	}
+
+	op := getOp
+	if isAssign {
+		op = setOp
+	}
+	if op == r.OpGetMember || op == r.OpSetMember {
+		c.emit(op, c.chunk.AddName(expr.MemberID.GetLiteral()), 0)
+	} else {
+		c.emit(op, 0, 0)
+	}
+	return nil
+}
+
+func (c *bytecodeCompiler) compileVarAssignExpr(expr *syntax.VarAssignExpr) error {
+	if err := c.compileExpression(expr.AssignExpr); err != nil {
+		return err
+	}
+	c.emit(r.OpCopyValue, 0, 0)
+
+	switch v := expr.TargetVar.(type) {
+	case *syntax.ID:
+		name, err := MatchIDName(v)
+		if err != nil {
+			return errNotCompilable
+		}
+		return c.emitStore(name.GetLiteral())
+	case *syntax.MemberExpr:
+		if v.MemberType != syntax.MemberID && v.MemberType != syntax.MemberIndex {
+			return errNotCompilable
+		}
+		return c.compileMemberExpr(v, true)
+	}
+	return errNotCompilable
+}
+
+// （显示：A、B、C），得到D
+func (c *bytecodeCompiler) compileFuncCallExpr(expr *syntax.FuncCallExpr) error {
+	funcName, err := MatchIDName(expr.FuncName)
+	if err != nil {
+		return errNotCompilable
+	}
+	// functions are always found by name
+	if _, ok := c.resolveLocal(funcName.GetLiteral()); ok {
+		return errNotCompilable
+	}
+	if err := c.compileExpressions(expr.Params); err != nil {
+		return err
+	}
+	c.emit(r.OpCallFunction, c.chunk.AddName(funcName.GetLiteral()), len(expr.Params))
+
+	if expr.YieldResult != nil {
+		return c.emitDeclare(expr.YieldResult, true)
+	}
+	return nil
+}
+
+// 以 A （执行：B、C、D），得到E
+func (c *bytecodeCompiler) compileMemberMethodExpr(expr *syntax.MemberMethodExpr) error {
+	if err := c.compileExpression(expr.Root); err != nil {
+		return err
+	}
+	for _, methodExpr := range expr.MethodChain {
+		funcName, err := MatchIDName(methodExpr.FuncName)
+		if err != nil {
+			return errNotCompilable
+		}
+		if err := c.compileExpressions(methodExpr.Params); err != nil {
+			return err
+		}
+		c.emit(r.OpCallMethod, c.chunk.AddName(funcName.GetLiteral()), len(methodExpr.Params))
+	}
+
+	if expr.YieldResult != nil {
+		return c.emitDeclare(expr.YieldResult, false)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+	libCsv "github.com/DemoHn/Zn/stdlib/csv"
+)
+
+// TestCSVLibrary - use @CSV in programs; the functions are tested in stdlib/csv
+func TestCSVLibrary(t *testing.T) {
+	const data = "订单号,客户,金额\n" +
+		"A-1024,\"上海某某贸易有限公司, 浦东分公司\",1200.50\n" +
+		"B-77,\"他说\"\"你好\"\"\",80\n"
+
+	cases := []struct {
+		name     string
+		code     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "parse with header",
+			code:     "令行 = （解析CSV：数据）\n【行之长度，行#1#“客户”，行#2#“客户”，行#1之所有索引】",
+			input:    data,
+			expected: "[2，上海某某贸易有限公司, 浦东分公司，他说\"你好\"，[订单号，客户，金额]]",
+		},
+		{
+			name:     "round trip",
+			code:     "（生成CSV：（解析CSV：数据））",
+			input:    data,
+			expected: "订单号,客户,金额\nA-1024,\"上海某某贸易有限公司, 浦东分公司\",1200.50\nB-77,\"他说\"\"你好\"\"\",80\n",
+		},
+		{
+			name:     "wrong number of fields",
+			code:     "（解析CSV：数据）",
+			input:    "a,b\n1,2,3\n",
+			hasError: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			check := expectResult(tt.expected)
+			if tt.hasError {
+				check = expectError()
+			}
+			runBothEngines(t, "导入《@CSV》\n输入数据\n"+tt.code, r.ElementMap{"数据": value.NewString(tt.input)}, func(z *Interpreter) *Interpreter {
+				return z.SetExternalLibs([]*r.Library{libCsv.Export()})
+			}, check)
+		})
+	}
+}
+
+func TestCSVLibrary_Files(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "订单.csv")
+	// exported by Excel: starts with BOM and ends lines with CRLF
+	content := "\xEF\xBB\xBF订单号,金额\r\nA-1,10\r\nA-2,20\r\nA-3,30\r\n"
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outputFile := filepath.Join(dir, "输出.csv")
+
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name:     "stream rows",
+			code:     "令R = 【】\n以I、行遍历（逐行读取CSV：文件）：\n    如果I == 3：\n        结束循环\n    以R（后增：行#“订单号”）\nR",
+			expected: "[A-1，A-2]",
+		},
+		{
+			name:     "stream rows twice",
+			code:     "令N = 0\n令S = （逐行读取CSV：文件）\n遍历S：\n    N = N + 1\n遍历S：\n    N = N + 1\nN",
+			expected: "6",
+		},
+		{
+			name:     "write & read file",
+			code:     "（写入CSV：目标、【【“名称” = “甲”】】、【“编码” = “UTF-8-BOM”】）\n（读取CSV：目标）",
+			expected: "[[名称=甲]]",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, "导入《@CSV》\n输入文件、目标\n"+tt.code, r.ElementMap{"文件": value.NewString(inputFile), "目标": value.NewString(outputFile)}, func(z *Interpreter) *Interpreter {
+				return z.SetExternalLibs([]*r.Library{libCsv.Export()})
+			}, expectResult(tt.expected))
+		})
+	}
+}
+
+func TestCSVLibrary_PermissionPolicy(t *testing.T) {
+	dir := t.TempDir()
+	policy := &r.PermissionPolicy{FileRoots: []string{filepath.Join(dir, "数据")}}
+	for _, code := range []string{
+		"（读取CSV：“/etc/passwd”）",
+		"（逐行读取CSV：“/etc/passwd”）",
+		"（写入CSV：“" + filepath.Join(dir, "a.csv") + "”、【】）",
+	} {
+		_, err := NewInterpreter("test").SetPermissionPolicy(policy).
+			SetExternalLibs([]*r.Library{libCsv.Export()}).
+			LoadScript([]rune("导入《@CSV》\n" + code)).Execute(r.ElementMap{})
+		if err == nil {
+			t.Errorf("%s: expect permission error, got nil", code)
+		}
+	}
+}
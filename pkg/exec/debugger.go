@@ -0,0 +1,375 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+// Debugger - an interactive debugger of Zn programs. The program is executed in its own
+// goroutine (see Run()); it pauses at breakpoints (or after stepping) and waits for the
+// next command (Continue / StepIn / StepOver / StepOut / Terminate) from the front-end,
+// e.g. the `zinc debug` command or a DAP server.
+//
+// While the program is paused, the front-end could inspect call frames, local variables
+// of any frame, and evaluate expressions in the paused (top) frame.
+type Debugger struct {
+	mu sync.Mutex
+	// breakpoints - module name -> line numbers (starts from 1)
+	breakpoints map[string]map[int]bool
+	mode        debugMode
+	// stepReason - the reason to report when mode = debugModeStepIn
+	stepReason string
+	// stepDepth - the call depth when StepOver / StepOut is issued
+	stepDepth int
+
+	ctx        context.Context
+	cancel     context.CancelFunc
+	paused     bool
+	evaluating bool
+	vm         *r.VM
+
+	events   chan DebugEvent
+	commands chan debugCommand
+}
+
+type debugMode uint8
+
+const (
+	debugModeRun debugMode = iota
+	debugModeStepIn
+	debugModeStepOver
+	debugModeStepOut
+)
+
+type debugCommand uint8
+
+const (
+	debugCmdContinue debugCommand = iota
+	debugCmdStepIn
+	debugCmdStepOver
+	debugCmdStepOut
+	debugCmdTerminate
+)
+
+// event types
+const (
+	DebugEventStopped = "stopped"
+	DebugEventExited  = "exited"
+)
+
+// stop reasons
+const (
+	DebugReasonEntry      = "entry"
+	DebugReasonBreakpoint = "breakpoint"
+	DebugReasonStep       = "step"
+	DebugReasonPause      = "pause"
+)
+
+// DebugEvent - sent to the front-end when the program is paused or exited
+type DebugEvent struct {
+	// Type - DebugEventStopped or DebugEventExited
+	Type string
+	// Reason - why the program is paused (stopped events ONLY)
+	Reason string
+	// Module, Line - where the program is paused (stopped events ONLY)
+	Module string
+	Line   int
+	// Result, Error - the result of the program (exited events ONLY)
+	Result r.Element
+	Error  error
+}
+
+// DebugFrame - a call frame of the paused program
+type DebugFrame struct {
+	// ID - index of the frame in the call stack (0 = the bottom frame)
+	ID     int
+	Module string
+	// Line - current line of the frame (starts from 1); 0 for native code
+	Line int
+	// Source - source text of current line
+	Source string
+	// Kind - 脚本 / 函数 / 拦截异常
+	Kind string
+}
+
+// ErrNotPaused - inspection or stepping commands are issued while the program is running
+var ErrNotPaused = errors.New("程序未处于暂停状态")
+
+// NewDebugger - create a debugger. If stopOnEntry is true, the program pauses before
+// the first statement.
+func NewDebugger(stopOnEntry bool) *Debugger {
+	d := &Debugger{
+		breakpoints: map[string]map[int]bool{},
+		mode:        debugModeRun,
+		events:      make(chan DebugEvent, 1),
+		commands:    make(chan debugCommand),
+	}
+	if stopOnEntry {
+		d.mode = debugModeStepIn
+		d.stepReason = DebugReasonEntry
+	}
+	return d
+}
+
+// Run - execute the loaded program of the interpreter with the debugger attached in a new
+// goroutine. The front-end receives stopped events and the final exited event from Events().
+// NOTE: the bytecode engine is disabled since locals of compiled functions are not kept in scopes.
+func (d *Debugger) Run(z *Interpreter, varInputs r.ElementMap) {
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	z.SetBytecode(false).SetDebugHook(d.hook)
+
+	go func() {
+		defer d.cancel()
+		result, err := z.ExecuteContext(d.ctx, varInputs)
+		d.events <- DebugEvent{Type: DebugEventExited, Result: result, Error: err}
+	}()
+}
+
+// Events - the channel of debug events
+func (d *Debugger) Events() <-chan DebugEvent {
+	return d.events
+}
+
+// SetBreakpoints - replace all breakpoints of the module (MODULE_NAME_MAIN for the main module)
+func (d *Debugger) SetBreakpoints(module string, lines []int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lineMap := map[int]bool{}
+	for _, line := range lines {
+		lineMap[line] = true
+	}
+	d.breakpoints[module] = lineMap
+}
+
+// AddBreakpoint - add a breakpoint at the line of the module
+func (d *Debugger) AddBreakpoint(module string, line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.breakpoints[module]; !ok {
+		d.breakpoints[module] = map[int]bool{}
+	}
+	d.breakpoints[module][line] = true
+}
+
+// RemoveBreakpoint - remove the breakpoint at the line of the module
+func (d *Debugger) RemoveBreakpoint(module string, line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.breakpoints[module], line)
+}
+
+// Continue - resume the program until next breakpoint
+func (d *Debugger) Continue() error {
+	return d.sendCommand(debugCmdContinue)
+}
+
+// StepIn - resume the program and pause at the next statement (including statements of callees)
+func (d *Debugger) StepIn() error {
+	return d.sendCommand(debugCmdStepIn)
+}
+
+// StepOver - resume the program and pause at the next statement of current frame (or its callers)
+func (d *Debugger) StepOver() error {
+	return d.sendCommand(debugCmdStepOver)
+}
+
+// StepOut - resume the program and pause after current frame returns
+func (d *Debugger) StepOut() error {
+	return d.sendCommand(debugCmdStepOut)
+}
+
+// Pause - pause the running program at the next statement
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.paused {
+		d.mode = debugModeStepIn
+		d.stepReason = DebugReasonPause
+	}
+}
+
+// Terminate - stop the program, whatever it's paused or running
+func (d *Debugger) Terminate() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// IsPaused - if the program is paused and waiting for commands
+func (d *Debugger) IsPaused() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.paused
+}
+
+// StackFrames - get call frames of the paused program, the top frame comes first
+func (d *Debugger) StackFrames() ([]DebugFrame, error) {
+	vm, err := d.getPausedVM()
+	if err != nil {
+		return nil, err
+	}
+
+	callStack := vm.GetCallStack()
+	frames := []DebugFrame{}
+	for i := len(callStack) - 1; i >= 0; i-- {
+		frame := callStack[i]
+		module := frame.GetModule()
+		debugFrame := DebugFrame{
+			ID:     i,
+			Module: module.GetName(),
+			Kind:   getDebugFrameKind(frame),
+		}
+		if module.GetID() != r.NATIVE_CODE_MODULE_ID && module.GetProgram() != nil {
+			debugFrame.Line = frame.GetCurrentLine() + 1
+			debugFrame.Source = frame.GetSourceTextLine(frame.GetCurrentLine())
+		}
+		frames = append(frames, debugFrame)
+	}
+	return frames, nil
+}
+
+// Variables - get local variables of the frame (see DebugFrame.ID)
+func (d *Debugger) Variables(frameID int) ([]r.FrameVariable, error) {
+	vm, err := d.getPausedVM()
+	if err != nil {
+		return nil, err
+	}
+	if frameID < 0 || frameID >= len(vm.GetCallStack()) {
+		return nil, zerr.NewErrorSLOT("调用帧不存在")
+	}
+	return vm.GetFrameVariables(frameID), nil
+}
+
+// Evaluate - evaluate the expression in the paused frame. Breakpoints are ignored
+// during the evaluation.
+func (d *Debugger) Evaluate(expr string) (r.Element, error) {
+	vm, err := d.getPausedVM()
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	d.evaluating = true
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.evaluating = false
+		d.mu.Unlock()
+	}()
+
+	// frames of failed calls are kept for displaying errors - drop them
+	csCount := len(vm.GetCallStack())
+	result, err := evalExpressionText(vm, expr)
+	for len(vm.GetCallStack()) > csCount {
+		vm.PopCallFrame()
+	}
+	return result, err
+}
+
+func (d *Debugger) sendCommand(cmd debugCommand) error {
+	d.mu.Lock()
+	paused := d.paused
+	d.mu.Unlock()
+	if !paused {
+		return ErrNotPaused
+	}
+
+	d.commands <- cmd
+	return nil
+}
+
+func (d *Debugger) getPausedVM() (*r.VM, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.paused {
+		return nil, ErrNotPaused
+	}
+	return d.vm, nil
+}
+
+// hook - called before evaluating each statement (see r.DebugHook)
+func (d *Debugger) hook(vm *r.VM) error {
+	callStack := vm.GetCallStack()
+	depth := len(callStack)
+	frame := callStack[depth-1]
+	module := frame.GetModule().GetName()
+	line := frame.GetCurrentLine() + 1
+
+	d.mu.Lock()
+	if d.evaluating {
+		d.mu.Unlock()
+		return nil
+	}
+	reason := ""
+	switch d.mode {
+	case debugModeStepIn:
+		reason = d.stepReason
+	case debugModeStepOver:
+		if depth <= d.stepDepth {
+			reason = DebugReasonStep
+		}
+	case debugModeStepOut:
+		if depth < d.stepDepth {
+			reason = DebugReasonStep
+		}
+	}
+	if reason == "" && d.breakpoints[module][line] {
+		reason = DebugReasonBreakpoint
+	}
+	if reason == "" {
+		d.mu.Unlock()
+		return nil
+	}
+	d.paused = true
+	d.vm = vm
+	d.mu.Unlock()
+
+	d.events <- DebugEvent{Type: DebugEventStopped, Reason: reason, Module: module, Line: line}
+
+	// wait for the next command
+	var cmd debugCommand
+	select {
+	case cmd = <-d.commands:
+	case <-d.ctx.Done():
+		cmd = debugCmdTerminate
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused = false
+	switch cmd {
+	case debugCmdContinue:
+		d.mode = debugModeRun
+	case debugCmdStepIn:
+		d.mode = debugModeStepIn
+		d.stepReason = DebugReasonStep
+	case debugCmdStepOver:
+		d.mode = debugModeStepOver
+		d.stepDepth = depth
+	case debugCmdStepOut:
+		d.mode = debugModeStepOut
+		d.stepDepth = depth
+	case debugCmdTerminate:
+		return zerr.ExecCancelled()
+	}
+	return nil
+}
+
+func getDebugFrameKind(frame *r.CallFrame) string {
+	switch frame.GetCallType() {
+	case r.CALL_TYPE_FUNCTION:
+		return "函数"
+	case r.CALL_TYPE_EXCEPTION_BLOCK:
+		return "拦截异常"
+	default:
+		return "脚本"
+	}
+}
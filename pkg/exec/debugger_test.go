@@ -0,0 +1,139 @@
+package exec
+
+import (
+	"testing"
+	"time"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+const debuggerTestCode = `令A = 1
+如何加倍？
+    输入X
+    令Y = X * 2
+    输出Y
+
+令B = （加倍：A）
+令C = B + 1
+C`
+
+func startDebugger(t *testing.T, stopOnEntry bool, breakpoints []int) *Debugger {
+	d := NewDebugger(stopOnEntry)
+	d.SetBreakpoints(MODULE_NAME_MAIN, breakpoints)
+	d.Run(NewInterpreter("test").LoadScript([]rune(debuggerTestCode)), r.ElementMap{})
+	return d
+}
+
+func waitDebugEvent(t *testing.T, d *Debugger) DebugEvent {
+	select {
+	case ev := <-d.Events():
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatalf("wait debug event timeout")
+		return DebugEvent{}
+	}
+}
+
+func expectStopped(t *testing.T, d *Debugger, reason string, line int) {
+	ev := waitDebugEvent(t, d)
+	if ev.Type != DebugEventStopped || ev.Reason != reason || ev.Line != line {
+		t.Fatalf("expect stopped(%s) at line %d, got %+v", reason, line, ev)
+	}
+}
+
+func getVariableMap(t *testing.T, d *Debugger, frameID int) map[string]string {
+	vars, err := d.Variables(frameID)
+	if err != nil {
+		t.Fatalf("get variables failed: %v", err)
+	}
+	varMap := map[string]string{}
+	for _, v := range vars {
+		varMap[v.Name] = v.Value.String()
+	}
+	return varMap
+}
+
+func TestDebugger_BreakpointAndInspect(t *testing.T) {
+	d := startDebugger(t, false, []int{4})
+	expectStopped(t, d, DebugReasonBreakpoint, 4)
+
+	frames, err := d.StackFrames()
+	if err != nil {
+		t.Fatalf("get stack frames failed: %v", err)
+	}
+	if len(frames) != 2 || frames[0].Line != 4 || frames[0].Kind != "函数" ||
+		frames[1].Line != 7 || frames[1].Source != "令B = （加倍：A）" {
+		t.Fatalf("unexpected stack frames: %+v", frames)
+	}
+
+	// variables of the function frame & the script frame
+	if vars := getVariableMap(t, d, 1); len(vars) != 1 || vars["X"] != "1" {
+		t.Errorf("expect variables of frame #1 = {X: 1}, got %v", vars)
+	}
+	if vars := getVariableMap(t, d, 0); vars["A"] != "1" || vars["X"] != "" {
+		t.Errorf("expect variables of frame #0 contain A = 1 only, got %v", vars)
+	}
+
+	result, err := d.Evaluate("X + 10")
+	if err != nil || result.String() != "11" {
+		t.Errorf("expect evaluated result = 11, got %v (err = %v)", result, err)
+	}
+	// failed evaluation doesn't break the call stack
+	if _, err := d.Evaluate("（不存在函数）"); err == nil {
+		t.Errorf("expect evaluation error, got nil")
+	}
+	if frames, _ := d.StackFrames(); len(frames) != 2 {
+		t.Errorf("expect 2 frames after failed evaluation, got %d", len(frames))
+	}
+
+	// step over -> next line of the function
+	if err := d.StepOver(); err != nil {
+		t.Fatalf("step over failed: %v", err)
+	}
+	expectStopped(t, d, DebugReasonStep, 5)
+	if vars := getVariableMap(t, d, 1); vars["Y"] != "2" {
+		t.Errorf("expect Y = 2, got %v", vars)
+	}
+
+	// step out -> next statement of the caller
+	d.StepOut()
+	expectStopped(t, d, DebugReasonStep, 8)
+	if result, _ := d.Evaluate("B"); result == nil || result.String() != "2" {
+		t.Errorf("expect B = 2, got %v", result)
+	}
+
+	d.Continue()
+	ev := waitDebugEvent(t, d)
+	if ev.Type != DebugEventExited || ev.Error != nil || ev.Result.String() != "3" {
+		t.Fatalf("expect exited with result 3, got %+v", ev)
+	}
+	if err := d.Continue(); err != ErrNotPaused {
+		t.Errorf("expect ErrNotPaused, got %v", err)
+	}
+}
+
+func TestDebugger_StepIn(t *testing.T) {
+	d := startDebugger(t, true, nil)
+	expectStopped(t, d, DebugReasonEntry, 1)
+
+	for _, line := range []int{7, 4, 5, 8, 9} {
+		d.StepIn()
+		expectStopped(t, d, DebugReasonStep, line)
+	}
+	d.StepIn()
+	if ev := waitDebugEvent(t, d); ev.Type != DebugEventExited {
+		t.Fatalf("expect exited event, got %+v", ev)
+	}
+}
+
+func TestDebugger_Terminate(t *testing.T) {
+	d := startDebugger(t, false, []int{5})
+	expectStopped(t, d, DebugReasonBreakpoint, 5)
+
+	d.Terminate()
+	ev := waitDebugEvent(t, d)
+	if ev.Type != DebugEventExited || getRuntimeErrorCode(ev.Error) != zerr.ErrExecCancelled {
+		t.Fatalf("expect exited with ErrExecCancelled, got %+v", ev)
+	}
+}
@@ -0,0 +1,51 @@
+package exec
+
+import (
+	"testing"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	libEncoding "github.com/DemoHn/Zn/stdlib/encoding"
+)
+
+// TestEncodingLibrary - use @编码 in programs; the functions are tested in stdlib/encoding
+func TestEncodingLibrary(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "base64 & base32",
+			code:     "【（Base64编码：“你好，Zn”），（Base64解码：“5L2g5aW977yMWm4=”），（Base64编码：“~~~”、“URL”），（Base64编码：“Zn”、“无填充”），（Base32编码：“Zn”），（Base32解码：“LJXA====”）】",
+			expected: "[5L2g5aW977yMWm4=，你好，Zn，fn5-，Wm4，LJXA====，Zn]",
+		},
+		{
+			name:     "build query",
+			code:     "令参数 = 【“timestamp” = 1700000000，“q” = “a b”，“tag” = 【“x”，“y”】】\n【（生成查询文本：参数），（生成查询文本：参数、真）】",
+			expected: "[timestamp=1700000000&q=a+b&tag=x&tag=y，q=a+b&tag=x&tag=y&timestamp=1700000000]",
+		},
+		{
+			name:     "digests",
+			code:     "【（MD5：“abc”），（SHA1：“abc”），（摘要：“abc”、“sha-256”），（SHA256：“abc”、“Base64”）】",
+			expected: "[900150983cd24fb0d6963f7d28e17f72，a9993e364706816aba3e25717850c26c9cd0d89d，ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad，ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=]",
+		},
+		{
+			name:     "invalid base64",
+			code:     "（Base64解码：“!!!”）",
+			hasError: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			check := expectResult(tt.expected)
+			if tt.hasError {
+				check = expectError()
+			}
+			runBothEngines(t, "导入《@编码》\n"+tt.code, r.ElementMap{}, func(z *Interpreter) *Interpreter {
+				return z.SetExternalLibs([]*r.Library{libEncoding.Export()})
+			}, check)
+		})
+	}
+}
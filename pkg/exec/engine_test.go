@@ -0,0 +1,66 @@
+package exec
+
+import (
+	"testing"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+// forBothEngines - run the test on both engines (walking the AST & the bytecode engine) as subtests
+func forBothEngines(t *testing.T, fn func(t *testing.T, bytecode bool)) {
+	for _, bytecode := range []bool{false, true} {
+		name := "ast"
+		if bytecode {
+			name = "bytecode"
+		}
+		t.Run(name, func(t *testing.T) {
+			fn(t, bytecode)
+		})
+	}
+}
+
+// runBothEngines - execute the code on both engines and check the result of each one.
+// setup configures the interpreter (e.g. external libs, clock) and could be nil.
+func runBothEngines(t *testing.T, code string, input r.ElementMap, setup func(*Interpreter) *Interpreter, check func(*testing.T, r.Element, error)) {
+	forBothEngines(t, func(t *testing.T, bytecode bool) {
+		interpreter := NewInterpreter("test").SetBytecode(bytecode)
+		if setup != nil {
+			interpreter = setup(interpreter)
+		}
+		result, err := interpreter.LoadScript([]rune(code)).Execute(input)
+		check(t, result, err)
+	})
+}
+
+// expectResult - the program yields a result that is displayed as expected
+func expectResult(expected string) func(*testing.T, r.Element, error) {
+	return func(t *testing.T, result r.Element, err error) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.String() != expected {
+			t.Errorf("expect %s, got %s", expected, result.String())
+		}
+	}
+}
+
+// expectErrorCode - the program fails with a runtime error of errCode (0 for errors without code)
+func expectErrorCode(errCode int) func(*testing.T, r.Element, error) {
+	return func(t *testing.T, result r.Element, err error) {
+		if err == nil {
+			t.Fatalf("expect error code %d, got nil", errCode)
+		}
+		if code := getRuntimeErrorCode(err); code != errCode {
+			t.Errorf("expect error code %d, got %v", errCode, err)
+		}
+	}
+}
+
+// expectError - the program fails with any error
+func expectError() func(*testing.T, r.Element, error) {
+	return func(t *testing.T, result r.Element, err error) {
+		if err == nil {
+			t.Errorf("expect error, got nil")
+		}
+	}
+}
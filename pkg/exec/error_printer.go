@@ -66,8 +66,17 @@ func (sw *SyntaxErrorWrapper) Error() string {
 }
 
 type RuntimeErrorWrapper struct {
-	vm  *r.VM
-	err error
+	// traces - the call stack where the error occurs (the bottom frame comes first)
+	traces []errorTrace
+	err    error
+}
+
+// errorTrace - one frame of the call stack for displaying errors
+type errorTrace struct {
+	isNativeModule bool
+	moduleName     string
+	lineNum        int
+	lineText       string
 }
 
 func WrapRuntimeError(vm *r.VM, err error) error {
@@ -95,17 +104,78 @@ func WrapRuntimeError(vm *r.VM, err error) error {
 				}
 			}
 
+			// use the call stack where the exception is thrown, since frames of VM may have
+			// been changed (e.g. the exception is thrown again by 抛出！ inside a catch block)
+			traces := getExceptionTraces(exception)
+			if len(traces) == 0 {
+				traces = getCallStackTraces(vm)
+			}
 			return &RuntimeErrorWrapper{
-				vm:  vm,
-				err: errors.New(errContent),
+				traces: traces,
+				err:    errors.New(errContent),
 			}
 		}
 
 		return &RuntimeErrorWrapper{
-			vm:  vm,
-			err: realErr,
+			traces: getCallStackTraces(vm),
+			err:    realErr,
+		}
+	}
+}
+
+// getCallStackTraces - get traces from current call stack of VM
+func getCallStackTraces(vm *r.VM) []errorTrace {
+	var traces []errorTrace
+	for _, frame := range vm.GetCallStack() {
+		module := frame.GetModule()
+		if module == nil {
+			continue
+		}
+		trace := errorTrace{
+			isNativeModule: module.GetID() == r.NATIVE_CODE_MODULE_ID || module.GetProgram() == nil,
+			moduleName:     module.GetName(),
+			lineNum:        frame.GetCurrentLine() + 1,
+		}
+		if !trace.isNativeModule {
+			trace.lineText = frame.GetSourceTextLine(frame.GetCurrentLine())
+		}
+		traces = append(traces, trace)
+	}
+	return traces
+}
+
+// getExceptionTraces - get traces from 调用栈 property of the exception (see captureCallStack)
+func getExceptionTraces(exception r.Element) []errorTrace {
+	stackElem, err := exception.GetProperty(EVConstExceptionStackProperty)
+	if err != nil {
+		return nil
+	}
+	stack, ok := stackElem.(*value.Array)
+	if !ok {
+		return nil
+	}
+
+	var traces []errorTrace
+	for _, item := range stack.GetValue() {
+		frame, ok := item.(*value.HashMap)
+		if !ok {
+			return nil
+		}
+		trace := errorTrace{}
+		if v, ok := frame.GetValue()["模块"].(*value.String); ok {
+			trace.moduleName = v.GetValue()
+		}
+		if v, ok := frame.GetValue()["行号"].(*value.Number); ok {
+			trace.lineNum = int(v.GetValue())
 		}
+		if v, ok := frame.GetValue()["代码"].(*value.String); ok {
+			trace.lineText = v.GetValue()
+		}
+		// line number of native modules is always 0
+		trace.isNativeModule = trace.lineNum == 0
+		traces = append(traces, trace)
 	}
+	return traces
 }
 
 func (rw *RuntimeErrorWrapper) Error() string {
@@ -115,36 +185,21 @@ func (rw *RuntimeErrorWrapper) Error() string {
 
 	if werr, ok := rw.err.(*zerr.RuntimeError); ok {
 		code = werr.Code
+		if werr.IsInterrupt() {
+			errClass = "执行中断"
+		}
 	}
 
-	callStack := rw.vm.GetCallStack()
-	if len(callStack) > 0 {
-		// append head lines
-		headTrace := callStack[0]
-		module := headTrace.GetModule()
-		isNativeModule := module.GetID() == r.NATIVE_CODE_MODULE_ID || module.GetProgram() == nil
-		errLines = append(errLines, fmtErrorLocationHeadLine(isNativeModule, module.GetName(), headTrace.GetCurrentLine()+1))
-
-		// get line text
-		if !isNativeModule {
-			if lineText := headTrace.GetSourceTextLine(headTrace.GetCurrentLine()); lineText != "" {
-				errLines = append(errLines, fmtErrorSourceTextLine(lineText))
-			}
+	for idx, trace := range rw.traces {
+		// append head line for the first trace, and body lines for others
+		if idx == 0 {
+			errLines = append(errLines, fmtErrorLocationHeadLine(trace.isNativeModule, trace.moduleName, trace.lineNum))
+		} else {
+			errLines = append(errLines, fmtErrorLocationBodyLine(trace.isNativeModule, trace.moduleName, trace.lineNum))
 		}
-
-		// append body
-		for _, tr := range callStack[1:] {
-			trModule := tr.GetModule()
-			isNativeModule = trModule.GetID() == r.NATIVE_CODE_MODULE_ID || module.GetProgram() == nil
-			if trModule != nil {
-				errLines = append(errLines, fmtErrorLocationBodyLine(isNativeModule, trModule.GetName(), tr.GetCurrentLine()+1))
-				// get line text
-				if !isNativeModule {
-					if lineText := tr.GetSourceTextLine(tr.GetCurrentLine()); lineText != "" {
-						errLines = append(errLines, fmtErrorSourceTextLine(lineText))
-					}
-				}
-			}
+		// get line text
+		if !trace.isNativeModule && trace.lineText != "" {
+			errLines = append(errLines, fmtErrorSourceTextLine(trace.lineText))
 		}
 	}
 
@@ -15,7 +15,10 @@ import (
 const (
 	EVConstExceptionClassName       = "异常"
 	EVConstExceptionContentProperty = "内容"
+	EVConstExceptionCodeProperty    = "代码"
+	EVConstExceptionStackProperty   = "调用栈"
 	EVConstThisVariableName         = "此"
+	EVConstParentVariableName       = "父类"
 	MODULE_NAME_MAIN                = "主模块"
 )
 
@@ -35,7 +38,9 @@ const (
 func EvalMainModule(vm *r.VM, program *syntax.Program, varInputs r.ElementMap) (r.Element, error) {
 	// allocate module first
 	module := vm.AllocateModule(MODULE_NAME_MAIN, program)
-	vm.PushCallFrame(r.NewScriptCallFrame(module))
+	if err := vm.PushCallFrame(r.NewScriptCallFrame(module)); err != nil {
+		return nil, err
+	}
 
 	elem, err := evalProgram(vm, program, varInputs)
 	// pop current callframe only there is no ERROR
@@ -82,17 +87,20 @@ func evalProgram(vm *r.VM, program *syntax.Program, varInputs r.ElementMap) (r.E
 				return nil, zerr.InputValueNotFound(inputNameStr)
 			}
 		}
-		return evalExecBlock(vm, program.ExecBlock, paramList)
+		compiled := tryCompileExecBlock(vm, program.ExecBlock, false)
+		return evalExecBlock(vm, program.ExecBlock, compiled, paramList)
 	}
 
 	return value.NewNull(), nil
 }
 
-func evalExecBlock(vm *r.VM, execBlock *syntax.ExecBlock, params []r.Element) (r.Element, error) {
+// evalExecBlock - evaluate the exec block from AST, or execute its compiled bytecode if compiled != nil
+func evalExecBlock(vm *r.VM, execBlock *syntax.ExecBlock, compiled *compiledExecBlock, params []r.Element) (r.Element, error) {
 	vm.BeginScope()
 	defer vm.EndScope()
 
 	blockModule := vm.GetCurrentModule()
+	csCount := len(vm.GetCallStack())
 	// 1.0 inject 此 value from callFrame's context (for method functions ONLY)
 	if vm.GetCurrentCallFrame() != nil && vm.GetCurrentCallFrame().IsFunctionCallFrame() {
 		thisValue := vm.GetThisValue()
@@ -107,6 +115,10 @@ func evalExecBlock(vm *r.VM, execBlock *syntax.ExecBlock, params []r.Element) (r
 		return nil, zerr.MismatchParamLengthError(inputParamNum, len(params))
 	}
 
+	if compiled != nil {
+		return execCompiledBlock(vm, blockModule, csCount, execBlock, compiled, params)
+	}
+
 	for idx, param := range execBlock.InputBlock {
 		idTag, err := MatchIDName(param)
 		if err != nil {
@@ -123,33 +135,50 @@ func evalExecBlock(vm *r.VM, execBlock *syntax.ExecBlock, params []r.Element) (r
 	rtnValue, stmtBlockErr := evalStmtBlock(vm, execBlock.StmtBlock)
 
 	if stmtBlockErr != nil {
-		return handleExceptionSignal(vm, blockModule, execBlock.CatchBlock, stmtBlockErr)
+		rtnValue, stmtBlockErr = handleExceptionSignal(vm, blockModule, csCount, execBlock.CatchBlock, stmtBlockErr, func(idx int) error {
+			_, err := evalPureStmtBlock(vm, execBlock.CatchBlock[idx].StmtBlock)
+			return err
+		})
 	}
 
+	if execBlock.FinallyBlock != nil {
+		return handleFinallyBlock(vm, csCount, rtnValue, stmtBlockErr, func() error {
+			_, err := evalPureStmtBlock(vm, execBlock.FinallyBlock)
+			return err
+		})
+	}
 	return rtnValue, stmtBlockErr
 }
 
 func evalStmtBlock(vm *r.VM, stmtBlock *syntax.StmtBlock) (r.Element, error) {
+	if err := evalDeclarations(vm, stmtBlock); err != nil {
+		return nil, err
+	}
+	return evalPureStmtBlock(vm, stmtBlock)
+}
+
+// evalDeclarations - declare classes & functions of the block before executing other statements
+func evalDeclarations(vm *r.VM, stmtBlock *syntax.StmtBlock) error {
 	for _, stmtX := range stmtBlock.Children {
 		switch v := stmtX.(type) {
 		case *syntax.ClassDeclareStmt:
 			// declare class
 			if err := evalClassDeclareStmt(vm, v); err != nil {
-				return nil, err
+				return err
 			}
 		case *syntax.FunctionDeclareStmt:
 			if v.DeclareType == syntax.DeclareTypeConstructor {
 				if err := evalConstructorDeclareStmt(vm, v); err != nil {
-					return nil, err
+					return err
 				}
 			} else {
 				if err := evalFunctionDeclareStmt(vm, v); err != nil {
-					return nil, err
+					return err
 				}
 			}
 		}
 	}
-	return evalPureStmtBlock(vm, stmtBlock)
+	return nil
 }
 
 // evalPureStmtBlock - evaluate statement block without classDef/funcDef/import statements
@@ -177,45 +206,47 @@ func evalPureStmtBlock(vm *r.VM, stmtBlock *syntax.StmtBlock) (r.Element, error)
 	return rtnValue, err
 }
 
-func handleExceptionSignal(vm *r.VM, blockModule *r.Module, catchBlock []*syntax.CatchBlockPair, blockErr error) (r.Element, error) {
-	// try to find if the blockErr is an exception signal
-	exception, realErr := extractSignalValue(blockErr, zerr.SigTypeException)
-
-	// so, if the blockErr is not an exception signal, return it directly
-	if realErr != nil {
-		return nil, realErr
+// handleExceptionSignal - find the catch block that matches the exception, then execute it by execCatchBlock(idx)
+// csCount is the number of call frames when the block begins.
+func handleExceptionSignal(vm *r.VM, blockModule *r.Module, csCount int, catchBlock []*syntax.CatchBlockPair, blockErr error, execCatchBlock func(idx int) error) (r.Element, error) {
+	if len(catchBlock) == 0 {
+		return nil, blockErr
 	}
+	// try to find if the blockErr is an exception signal (or a catchable runtime error)
+	exception := toExceptionValue(vm, blockErr)
 
-	// by default, we use "异常" to match *value.Exception type exceptions
-	var objClassName = ""
-	switch v := exception.(type) {
-	case *value.Exception:
-		objClassName = EVConstExceptionClassName
-	case *value.Object:
-		objClassName = v.GetObjectName()
+	// so, if the blockErr is not catchable, return it directly
+	if exception == nil {
+		return nil, blockErr
 	}
 
 	// iterate catchBlocks to match
-	for _, catchBlockItem := range catchBlock {
+	for idx, catchBlockItem := range catchBlock {
 		classID, err := MatchIDName(catchBlockItem.ExceptionClass)
 		if err != nil {
 			return nil, err
 		}
 
-		// if exception block matches exception className
-		if objClassName != "" && classID.GetLiteral() == objClassName {
+		// if exception block matches exception class (or its parent classes)
+		if matchExceptionClass(vm, exception, classID) {
+			// frames of the failed calls are kept for displaying errors - drop them since
+			// the exception is handled
+			for len(vm.GetCallStack()) > csCount {
+				vm.PopCallFrame()
+			}
 			expCallFrame := r.NewExceptionCallFrame(blockModule, exception)
-			vm.PushCallFrame(expCallFrame)
+			if err := vm.PushCallFrame(expCallFrame); err != nil {
+				return nil, err
+			}
 			// do execution (with "this" value = exception value)
-			_, err := evalPureStmtBlock(vm, catchBlockItem.StmtBlock)
-			if err == nil {
-				// get return value from exception block
-				rtnValue := vm.GetReturnValue()
-				vm.PopCallFrame()
-
-				return rtnValue, nil
+			if err := execCatchBlock(idx); err != nil {
+				return nil, err
 			}
-			return nil, err
+			// get return value from exception block
+			rtnValue := vm.GetReturnValue()
+			vm.PopCallFrame()
+
+			return rtnValue, nil
 		}
 	}
 
@@ -223,12 +254,77 @@ func handleExceptionSignal(vm *r.VM, blockModule *r.Module, catchBlock []*syntax
 	return nil, blockErr
 }
 
+// handleFinallyBlock - execute the 善后 block by execFinallyBlock() after the main block & catch blocks,
+// no matter whether they succeed or not. If 输出 is executed inside the 善后 block, its value overrides
+// the result (and the error) of previous blocks.
+func handleFinallyBlock(vm *r.VM, csCount int, rtnValue r.Element, blockErr error, execFinallyBlock func() error) (r.Element, error) {
+	// interrupt errors stop the whole program immediately
+	if rerr, ok := blockErr.(*zerr.RuntimeError); ok && rerr.IsInterrupt() {
+		return nil, blockErr
+	}
+
+	// frames of the failed calls are dropped during the execution of 善后 block, and restored afterwards
+	// for displaying errors
+	failedFrames := append([]*r.CallFrame{}, vm.GetCallStack()[csCount:]...)
+	for len(vm.GetCallStack()) > csCount {
+		vm.PopCallFrame()
+	}
+
+	lastReturnValue := vm.GetReturnValue()
+	vm.SetReturnValue(nil)
+	if err := execFinallyBlock(); err != nil {
+		return nil, err
+	}
+	if finallyValue := vm.GetReturnValue(); finallyValue != nil {
+		return finallyValue, nil
+	}
+	vm.SetReturnValue(lastReturnValue)
+
+	if blockErr != nil {
+		for _, frame := range failedFrames {
+			if err := vm.PushCallFrame(frame); err != nil {
+				return nil, err
+			}
+		}
+		return nil, blockErr
+	}
+	return rtnValue, nil
+}
+
+// matchExceptionClass - if the exception is an instance of the class (or its subclasses)
+func matchExceptionClass(vm *r.VM, exception r.Element, classID *r.IDName) bool {
+	switch v := exception.(type) {
+	case *value.Exception:
+		// by default, we use "异常" to match *value.Exception type exceptions
+		return classID.GetLiteral() == EVConstExceptionClassName
+	case *value.Object:
+		classElem, err := vm.FindElement(classID)
+		if err != nil {
+			return classID.GetLiteral() == v.GetObjectName()
+		}
+		if cmodel, ok := classElem.(*value.ClassModel); ok {
+			return v.IsInstanceOf(cmodel)
+		}
+	}
+	return false
+}
+
 //// eval statements
 
 // EvalStatement - eval statement
 func evalStatement(vm *r.VM, stmt syntax.Statement) (r.Element, error) {
 	// set current line
 	vm.SetCurrentLine(stmt.GetCurrentLine())
+	// stop execution if timeout or cancelled
+	if err := vm.CheckInterrupt(); err != nil {
+		return nil, err
+	}
+	if err := vm.CountStep(); err != nil {
+		return nil, err
+	}
+	if err := vm.CallDebugHook(); err != nil {
+		return nil, err
+	}
 
 	switch v := stmt.(type) {
 	case *syntax.VarDeclareStmt:
@@ -259,6 +355,8 @@ func evalStatement(vm *r.VM, stmt syntax.Statement) (r.Element, error) {
 		return rtnValue, nil
 	case *syntax.ThrowExceptionStmt:
 		return value.NewNull(), evalThrowExceptionStmt(vm, v)
+	case *syntax.RethrowStmt:
+		return value.NewNull(), evalRethrowStmt(vm)
 	case *syntax.ContinueStmt:
 		// send continue signal
 		return value.NewNull(), zerr.NewContinueSignal()
@@ -303,7 +401,7 @@ func evalVarDeclareStmt(vm *r.VM, node *syntax.VarDeclareStmt) error {
 					err2 = vm.DeclareElement(vtag, obj)
 				}
 				if err2 != nil {
-					return err
+					return err2
 				}
 			}
 		}
@@ -384,11 +482,16 @@ func evalConstructorDeclareStmt(vm *r.VM, node *syntax.FunctionDeclareStmt) erro
 	//// there are some different Factors from normal method function:
 	// 1. no outerScope (clousure scope)
 	// 2. no 此 const variable inside the fn scope
+	// 3. with 父类 declared if the class has a parent class
+	compiled := tryCompileExecBlock(vm, node.ExecBlock, true)
+	parent := cmodel.GetParent()
 	constructorLogic := func(instance r.Element, elems []r.Element) (r.Element, error) {
 		// set "this" value
-		vm.PushCallFrame(r.NewFunctionCallFrame(module, instance))
+		if err := vm.PushCallFrame(r.NewFunctionCallFrame(module, instance)); err != nil {
+			return nil, err
+		}
 
-		if _, err := evalExecBlock(vm, node.ExecBlock, elems); err != nil {
+		if err := execConstructorBlock(vm, node.ExecBlock, compiled, instance, parent, elems); err != nil {
 			return nil, err
 		}
 
@@ -400,6 +503,17 @@ func evalConstructorDeclareStmt(vm *r.VM, node *syntax.FunctionDeclareStmt) erro
 	return nil
 }
 
+// execConstructorBlock - execute the constructor's block (with 父类 declared if the class has a parent class)
+func execConstructorBlock(vm *r.VM, execBlock *syntax.ExecBlock, compiled *compiledExecBlock, instance r.Element, parent *value.ClassModel, params []r.Element) error {
+	vm.BeginScope()
+	defer vm.EndScope()
+	if err := declareParentObject(vm, instance, parent); err != nil {
+		return err
+	}
+	_, err := evalExecBlock(vm, execBlock, compiled, params)
+	return err
+}
+
 // eval 创建XX：P1，P2，P3，...！
 // ensure VDAssignPair.Type MUST BE syntax.VDTypeObjNew
 func evalNewObject(vm *r.VM, node *syntax.ObjNewExpr) (r.Element, error) {
@@ -425,8 +539,17 @@ func evalNewObject(vm *r.VM, node *syntax.ObjNewExpr) (r.Element, error) {
 	return constructRef.Construct(cParams)
 }
 
+// newObjectFromClass - construct an object from the class element (for bytecode)
+func newObjectFromClass(classElem r.Element, params []r.Element) (r.Element, error) {
+	constructRef, ok := classElem.(r.ConstructableElement)
+	if !ok {
+		return nil, zerr.InvalidParamType("classRef")
+	}
+	return constructRef.Construct(params)
+}
+
 func evalImportStmt(vm *r.VM, node *syntax.ImportStmt) error {
-	extLibName := node.ImportName.GetLiteral()
+	extLibName := resolveVendorImportName(vm.GetCurrentModule().GetName(), node.ImportName.GetLiteral())
 
 	var extModule *r.Module
 	nameInfo := r.ParseLibName(extLibName)
@@ -438,7 +561,9 @@ func evalImportStmt(vm *r.VM, node *syntax.ImportStmt) error {
 			return err
 		}
 		// push a temp callframe to load library
-		vm.PushCallFrame(r.NewScriptCallFrame(extModule))
+		if err := vm.PushCallFrame(r.NewScriptCallFrame(extModule)); err != nil {
+			return err
+		}
 
 		// duplicate export values into module
 		for k, v := range library.GetAllExportValues() {
@@ -446,8 +571,7 @@ func evalImportStmt(vm *r.VM, node *syntax.ImportStmt) error {
 		}
 		vm.PopCallFrame()
 		// Continue to import logic below instead of returning
-	case r.LIB_TYPE_VENDOR:
-	case r.LIB_TYPE_CUSTOM:
+	case r.LIB_TYPE_VENDOR, r.LIB_TYPE_CUSTOM:
 		if extModule = vm.FindModuleByName(extLibName); extModule == nil {
 			newModule, err := execAnotherModule(vm, nameInfo)
 			if err != nil {
@@ -493,6 +617,9 @@ func evalWhileLoopStmt(vm *r.VM, node *syntax.WhileLoopStmt) error {
 	// set context's current scope with new one
 
 	for {
+		if err := vm.CheckInterrupt(); err != nil {
+			return err
+		}
 		// #1. first execute expr
 		trueExpr, err := evalExpression(vm, node.TrueExpr)
 		if err != nil {
@@ -519,6 +646,10 @@ func evalWhileLoopStmt(vm *r.VM, node *syntax.WhileLoopStmt) error {
 			}
 			return err
 		}
+		// stop the loop once a value is returned inside the block
+		if vm.GetReturnValue() != nil {
+			return nil
+		}
 	}
 }
 
@@ -583,6 +714,9 @@ func evalIterateStmt(vm *r.VM, node *syntax.IterateStmt) error {
 	// execIterationBlock, including set "currentKey" and "currentValue" to scope,
 	// and preDefined indication variables
 	execIterationBlockFn := func(key r.Element, v r.Element) error {
+		if err := vm.CheckInterrupt(); err != nil {
+			return err
+		}
 		// set pre-defined value
 		if nameLen == 1 {
 			if err := vm.SetElement(valueSlot, v); err != nil {
@@ -596,8 +730,14 @@ func evalIterateStmt(vm *r.VM, node *syntax.IterateStmt) error {
 				return err
 			}
 		}
-		_, err := evalPureStmtBlock(vm, node.IterateBlock)
-		return err
+		if _, err := evalPureStmtBlock(vm, node.IterateBlock); err != nil {
+			return err
+		}
+		// stop the iteration once a value is returned inside the block
+		if vm.GetReturnValue() != nil {
+			return zerr.NewBreakSignal()
+		}
+		return nil
 	}
 
 	// define indication variables as "currentKey" and "currentValue" under new iterScope
@@ -673,6 +813,31 @@ func evalIterateStmt(vm *r.VM, node *syntax.IterateStmt) error {
 				return err
 			}
 		}
+	case r.IterableElement:
+		// e.g. rows of a file - items are read lazily, and the resources are released
+		// once the iteration ends
+		it, err := tv.Iterate()
+		if err != nil {
+			return err
+		}
+		defer r.CloseIterator(it)
+		for {
+			key, v, ok := it.Next()
+			if !ok {
+				return r.IteratorError(it)
+			}
+			if err := execIterationBlockFn(key, v); err != nil {
+				if s, ok := err.(*zerr.Signal); ok {
+					if s.SigType == zerr.SigTypeContinue {
+						continue
+					}
+					if s.SigType == zerr.SigTypeBreak {
+						return nil
+					}
+				}
+				return err
+			}
+		}
 	default:
 		return zerr.InvalidExprType("array", "hashmap")
 	}
@@ -682,6 +847,18 @@ func evalIterateStmt(vm *r.VM, node *syntax.IterateStmt) error {
 
 // // execute expressions
 func evalExpression(vm *r.VM, expr syntax.Expression) (r.Element, error) {
+	result, err := evalExpressionResult(vm, expr)
+	if err != nil {
+		return nil, err
+	}
+	// check if the result exceeds the size limits
+	if err := checkElementSize(vm, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func evalExpressionResult(vm *r.VM, expr syntax.Expression) (r.Element, error) {
 	switch e := expr.(type) {
 	case *syntax.VarAssignExpr:
 		return evalVarAssignExpr(vm, e)
@@ -709,6 +886,8 @@ func evalExpression(vm *r.VM, expr syntax.Expression) (r.Element, error) {
 		return evalMemberMethodExpr(vm, e)
 	case *syntax.ObjNewExpr:
 		return evalNewObject(vm, e)
+	case *syntax.FunctionExpr:
+		return compileClosure(vm, e), nil
 	default:
 		return nil, zerr.InvalidExprType()
 	}
@@ -743,6 +922,10 @@ func evalMemberMethodExpr(vm *r.VM, expr *syntax.MemberMethodExpr) (r.Element, e
 		if err != nil {
 			return nil, err
 		}
+		// methods like 添加 may change the root element in place
+		if err := checkElementSize(vm, vlast); err != nil {
+			return nil, err
+		}
 		vlast = v
 	}
 
@@ -819,8 +1002,7 @@ func evalThrowExceptionStmt(vm *r.VM, node *syntax.ThrowExceptionStmt) error {
 		return err
 	}
 
-	cmodel, ok := expClassModel.(*value.ClassModel)
-	if !ok {
+	if _, ok := expClassModel.(*value.ClassModel); !ok {
 		return zerr.InvalidExceptionType(expClassID.GetLiteral())
 	}
 	// exec expressions, similiar to "新建XX" statement
@@ -833,11 +1015,38 @@ func evalThrowExceptionStmt(vm *r.VM, node *syntax.ThrowExceptionStmt) error {
 		exprs = append(exprs, exprI)
 	}
 
+	return newExceptionSignal(vm, expClassModel, expClassID.GetLiteral(), exprs)
+}
+
+// 抛出！ - throw the exception caught by current catch block again
+func evalRethrowStmt(vm *r.VM) error {
+	callFrame := vm.GetCurrentCallFrame()
+	if callFrame == nil || !callFrame.IsExceptionCallFrame() {
+		return zerr.UnexpectedRethrow()
+	}
+	return zerr.NewExceptionSignal(vm.GetThisValue())
+}
+
+// newExceptionSignal - build exception value from the exception class, and wrap it as a signal.
+// The call stack where the exception is thrown is recorded to its 调用栈 property.
+func newExceptionSignal(vm *r.VM, classElem r.Element, className string, params []r.Element) error {
+	cmodel, ok := classElem.(*value.ClassModel)
+	if !ok {
+		return zerr.InvalidExceptionType(className)
+	}
 	// build exception value!
-	exceptionObj, err := cmodel.Construct(exprs)
+	exceptionObj, err := cmodel.Construct(params)
 	if err != nil {
 		return err
 	}
+
+	switch e := exceptionObj.(type) {
+	case *value.Exception:
+		e.SetCallStack(captureCallStack(vm))
+	case *value.Object:
+		// classes that are not inherited from 异常 may have no such property - just ignore it
+		_ = e.SetProperty(EVConstExceptionStackProperty, captureCallStack(vm))
+	}
 	return zerr.NewExceptionSignal(exceptionObj)
 }
 
@@ -856,6 +1065,11 @@ func evalLogicComparator(vm *r.VM, expr *syntax.LogicExpr) (*value.Bool, error)
 		return nil, err
 	}
 
+	return compareOperate(logicType, left, right)
+}
+
+// compareOperate - compare left & right value by the logic type
+func compareOperate(logicType uint8, left r.Element, right r.Element) (*value.Bool, error) {
 	var cmpRes bool
 	var cmpErr error
 	// #3. do comparison
@@ -893,12 +1107,20 @@ func compareLogicXEQ(left r.Element, right r.Element) (bool, error) {
 			return true, nil
 		}
 		return false, nil
-	case *value.Number:
-		// compare right value - number only
-		if vr, ok := right.(*value.Number); ok {
-			return vl.GetValue() == vr.GetValue(), nil
+	case *value.Number, *value.Decimal, *value.Currency, *value.DateTime:
+		// compare right value - number, decimal, currency or datetime only
+		cmpResult, ok, err := compareNumeric(vl, right)
+		if err != nil {
+			// numbers (or currencies) with incompatible units are never equal
+			if e, isRuntimeErr := err.(*zerr.RuntimeError); isRuntimeErr && e.Code == zerr.ErrArithIncompatibleUnits {
+				return false, nil
+			}
+			return false, err
 		}
-		return false, nil
+		if !ok {
+			return false, nil
+		}
+		return cmpResult == 0, nil
 	case *value.String:
 		// compare right value - string only
 		if vr, ok := right.(*value.String); ok {
@@ -964,95 +1186,161 @@ func compareLogicXEQ(left r.Element, right r.Element) (bool, error) {
 
 // [number] == [number] -> [bool]
 func compareLogicEQ(left r.Element, right r.Element) (bool, error) {
-	if vl, ok := left.(*value.Number); ok {
-		if vr, ok2 := right.(*value.Number); ok2 {
-			return vl.GetValue() == vr.GetValue(), nil
-		}
-		return false, zerr.InvalidCompareRType("number")
-	}
-	return false, zerr.InvalidCompareLType("number")
+	cmpResult, err := compareNumericOperands(left, right)
+	return cmpResult == 0, err
 }
 
 // [number] < [number] -> [bool]
 func compareLogicLT(left r.Element, right r.Element) (bool, error) {
-	if vl, ok := left.(*value.Number); ok {
-		if vr, ok2 := right.(*value.Number); ok2 {
-			return vl.GetValue() < vr.GetValue(), nil
-		}
-		return false, zerr.InvalidCompareRType("number")
-	}
-	return false, zerr.InvalidCompareLType("number")
+	cmpResult, err := compareNumericOperands(left, right)
+	return cmpResult < 0, err
 }
 
 // [number] <= [number] -> [bool]
 func compareLogicLTE(left r.Element, right r.Element) (bool, error) {
-	if vl, ok := left.(*value.Number); ok {
-		if vr, ok2 := right.(*value.Number); ok2 {
-			return vl.GetValue() <= vr.GetValue(), nil
-		}
-		return false, zerr.InvalidCompareRType("number")
-	}
-	return false, zerr.InvalidCompareLType("number")
+	cmpResult, err := compareNumericOperands(left, right)
+	return cmpResult <= 0, err
 }
 
 // [number] > [number] -> [bool]
 func compareLogicGT(left r.Element, right r.Element) (bool, error) {
-	if vl, ok := left.(*value.Number); ok {
-		if vr, ok2 := right.(*value.Number); ok2 {
-			return vl.GetValue() > vr.GetValue(), nil
-		}
-		return false, zerr.InvalidCompareRType("number")
-	}
-	return false, zerr.InvalidCompareLType("number")
+	cmpResult, err := compareNumericOperands(left, right)
+	return cmpResult > 0, err
 }
 
 // [number] >= [number] -> [bool]
 func compareLogicGTE(left r.Element, right r.Element) (bool, error) {
+	cmpResult, err := compareNumericOperands(left, right)
+	return cmpResult >= 0, err
+}
+
+// compareNumericOperands - compare two numbers (or decimals, currencies, datetimes), returns -1, 0 or 1.
+// Both operands MUST be numbers or decimals, or currencies, or datetimes.
+func compareNumericOperands(left r.Element, right r.Element) (int, error) {
+	switch left.(type) {
+	case *value.Number, *value.Decimal, *value.Currency, *value.DateTime:
+		cmpResult, ok, err := compareNumeric(left, right)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, zerr.InvalidCompareRType("number", "decimal", "currency", "datetime")
+		}
+		return cmpResult, nil
+	}
+	return 0, zerr.InvalidCompareLType("number", "decimal", "currency", "datetime")
+}
+
+// compareNumeric - compare a number (or decimal) with the right value, returns ok = false if the
+// right value is not a number or decimal. If either of them is a decimal, they're compared as decimals.
+// Currencies could only be compared with currencies, and datetimes with datetimes (earlier < later).
+func compareNumeric(left r.Element, right r.Element) (int, bool, error) {
+	dtL, okL := left.(*value.DateTime)
+	dtR, okR := right.(*value.DateTime)
+	if okL || okR {
+		if !okL || !okR {
+			return 0, false, nil
+		}
+		return value.CompareDateTimes(dtL, dtR), true, nil
+	}
+	curL, okL := left.(*value.Currency)
+	curR, okR := right.(*value.Currency)
+	if okL || okR {
+		if !okL || !okR {
+			return 0, false, nil
+		}
+		cmpResult, err := value.CompareCurrencies(curL, curR)
+		if err != nil {
+			return 0, false, err
+		}
+		return cmpResult, true, nil
+	}
 	if vl, ok := left.(*value.Number); ok {
-		if vr, ok2 := right.(*value.Number); ok2 {
-			return vl.GetValue() >= vr.GetValue(), nil
+		if vr, ok := right.(*value.Number); ok {
+			cmpResult, err := value.CompareNumbers(vl, vr)
+			if err != nil {
+				return 0, false, err
+			}
+			return cmpResult, true, nil
 		}
-		return false, zerr.InvalidCompareRType("number")
 	}
-	return false, zerr.InvalidCompareLType("number")
+	switch right.(type) {
+	case *value.Number, *value.Decimal:
+		dl, err := value.ToDecimal(left)
+		if err != nil {
+			return 0, false, err
+		}
+		dr, err := value.ToDecimal(right)
+		if err != nil {
+			return 0, false, err
+		}
+		return dl.Cmp(dr), true, nil
+	}
+	return 0, false, nil
 }
 
-func evalArithExpr(vm *r.VM, expr *syntax.ArithExpr) (*value.Number, error) {
+func evalArithExpr(vm *r.VM, expr *syntax.ArithExpr) (r.Element, error) {
 	// exec left Expr
 	leftExpr, err := evalExpression(vm, expr.LeftExpr)
 	if err != nil {
 		return nil, err
 	}
-
-	leftNum, ok := leftExpr.(*value.Number)
-	if !ok {
-		return nil, zerr.InvalidExprType("number")
-	}
 	// exec right expr
 	rightExpr, err := evalExpression(vm, expr.RightExpr)
 	if err != nil {
 		return nil, err
 	}
 
-	rightNum, ok := rightExpr.(*value.Number)
-	if !ok {
-		return nil, zerr.InvalidExprType("number")
+	return arithOperate(vm, expr.Type, leftExpr, rightExpr)
+}
+
+// arithOperate - calculate `left [arithType] right` (except ArithModulo). Both operands should be
+// numbers, decimals, currencies or datetimes; if either of them is a decimal, the result is a decimal.
+func arithOperate(vm *r.VM, arithType uint8, left r.Element, right r.Element) (r.Element, error) {
+	switch left.(type) {
+	case *value.Number, *value.Decimal, *value.Currency, *value.DateTime:
+	default:
+		return nil, zerr.InvalidExprType("number", "decimal", "currency", "datetime")
+	}
+	switch right.(type) {
+	case *value.Number, *value.Decimal, *value.Currency, *value.DateTime:
+	default:
+		return nil, zerr.InvalidExprType("number", "decimal", "currency", "datetime")
+	}
+
+	_, dtL := left.(*value.DateTime)
+	_, dtR := right.(*value.DateTime)
+	if dtL || dtR {
+		return arithDateTimeOperate(arithType, left, right)
+	}
+
+	_, curL := left.(*value.Currency)
+	_, curR := right.(*value.Currency)
+	if curL || curR {
+		return arithCurrencyOperate(vm, arithType, left, right)
 	}
 
-	// calculate num
-	switch expr.Type {
+	leftNum, okL := left.(*value.Number)
+	rightNum, okR := right.(*value.Number)
+	if !okL || !okR {
+		return arithDecimalOperate(vm, arithType, left, right)
+	}
+
+	// calculate num (with units)
+	switch arithType {
 	case syntax.ArithAdd:
-		return value.NewNumber(leftNum.GetValue() + rightNum.GetValue()), nil
+		return value.AddNumbers(leftNum, rightNum)
 	case syntax.ArithSub:
-		return value.NewNumber(leftNum.GetValue() - rightNum.GetValue()), nil
+		return value.SubNumbers(leftNum, rightNum)
 	case syntax.ArithMul:
-		return value.NewNumber(leftNum.GetValue() * rightNum.GetValue()), nil
+		return value.MulNumbers(leftNum, rightNum)
 	case syntax.ArithDiv:
-		if rightNum.GetValue() == 0 {
-			return nil, zerr.ArithDivZero()
-		}
-		return value.NewNumber(leftNum.GetValue() / rightNum.GetValue()), nil
+		return value.DivNumbers(leftNum, rightNum)
 	case syntax.ArithIntDiv:
+		// `|` is valid for plain numbers only
+		if err := assertPlainNumbers(leftNum, rightNum); err != nil {
+			return nil, err
+		}
 		// python style intDiv, where result close to the closet lower integer e.g. -15 // 2 = -8 (instead of -7)
 		if rightNum.GetValue() == 0 {
 			return nil, zerr.ArithDivZero()
@@ -1061,7 +1349,138 @@ func evalArithExpr(vm *r.VM, expr *syntax.ArithExpr) (*value.Number, error) {
 			math.Floor(leftNum.GetValue() / rightNum.GetValue()),
 		), nil
 	}
-	return nil, zerr.UnexpectedCase("运算项", fmt.Sprintf("%d", expr.Type))
+	return nil, zerr.UnexpectedCase("运算项", fmt.Sprintf("%d", arithType))
+}
+
+// assertPlainNumbers - some operators (e.g. `%`, `|`) are only valid for numbers without unit
+func assertPlainNumbers(left *value.Number, right *value.Number) error {
+	if left.GetUnit() != nil || right.GetUnit() != nil {
+		return zerr.ArithIncompatibleUnits(left.GetUnit().String(), right.GetUnit().String())
+	}
+	return nil
+}
+
+// arithCurrencyOperate - calculate `left [arithType] right` where at least one side is a currency:
+//  1. currency ± currency (of the same code)
+//  2. currency × factor, factor × currency, currency ÷ factor (factor: number or decimal)
+//  3. currency ÷ currency (of the same code) -> decimal
+func arithCurrencyOperate(vm *r.VM, arithType uint8, left r.Element, right r.Element) (r.Element, error) {
+	curL, okL := left.(*value.Currency)
+	curR, okR := right.(*value.Currency)
+
+	switch arithType {
+	case syntax.ArithAdd, syntax.ArithSub:
+		if okL && okR {
+			if arithType == syntax.ArithAdd {
+				return value.AddCurrencies(curL, curR)
+			}
+			return value.SubCurrencies(curL, curR)
+		}
+	case syntax.ArithMul:
+		if okL && !okR {
+			factor, err := value.ToDecimal(right)
+			if err != nil {
+				return nil, err
+			}
+			return value.MulCurrency(curL, factor), nil
+		}
+		if okR && !okL {
+			factor, err := value.ToDecimal(left)
+			if err != nil {
+				return nil, err
+			}
+			return value.MulCurrency(curR, factor), nil
+		}
+	case syntax.ArithDiv:
+		if okL && okR {
+			if curL.GetCode() != curR.GetCode() {
+				return nil, zerr.ArithIncompatibleUnits(curL.GetCode(), curR.GetCode())
+			}
+			return value.DivDecimals(vm, curL.GetAmount(), curR.GetAmount())
+		}
+		if okL {
+			divisor, err := value.ToDecimal(right)
+			if err != nil {
+				return nil, err
+			}
+			return value.DivCurrency(curL, divisor)
+		}
+	}
+	return nil, zerr.ArithIncompatibleUnits(getUnitName(left), getUnitName(right))
+}
+
+// arithDateTimeOperate - calculate `left [arithType] right` where at least one side is a datetime:
+//  1. datetime ± duration, duration + datetime (duration: number with unit of 时间, e.g. 3天)
+//  2. datetime - datetime -> duration
+func arithDateTimeOperate(arithType uint8, left r.Element, right r.Element) (r.Element, error) {
+	dtL, okL := left.(*value.DateTime)
+	dtR, okR := right.(*value.DateTime)
+	numL, isNumL := left.(*value.Number)
+	numR, isNumR := right.(*value.Number)
+
+	switch arithType {
+	case syntax.ArithAdd:
+		if okL && isNumR {
+			return value.AddDuration(dtL, numR, 1)
+		}
+		if isNumL && okR {
+			return value.AddDuration(dtR, numL, 1)
+		}
+	case syntax.ArithSub:
+		if okL && okR {
+			return value.SubDateTimes(dtL, dtR), nil
+		}
+		if okL && isNumR {
+			return value.AddDuration(dtL, numR, -1)
+		}
+	}
+	return nil, zerr.ArithIncompatibleUnits(getUnitName(left), getUnitName(right))
+}
+
+// getUnitName - get the unit (or currency code) of a number-like value for error messages
+func getUnitName(elem r.Element) string {
+	switch v := elem.(type) {
+	case *value.DateTime:
+		return "日期"
+	case *value.Currency:
+		return v.GetCode()
+	case *value.Number:
+		return v.GetUnit().String()
+	}
+	return ""
+}
+
+// arithDecimalOperate - calculate `left [arithType] right` as decimals
+func arithDecimalOperate(vm *r.VM, arithType uint8, left r.Element, right r.Element) (*value.Decimal, error) {
+	leftDec, err := value.ToDecimal(left)
+	if err != nil {
+		return nil, err
+	}
+	rightDec, err := value.ToDecimal(right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch arithType {
+	case syntax.ArithAdd:
+		return leftDec.Add(rightDec), nil
+	case syntax.ArithSub:
+		return leftDec.Sub(rightDec), nil
+	case syntax.ArithMul:
+		return leftDec.Mul(rightDec), nil
+	case syntax.ArithDiv:
+		return value.DivDecimals(vm, leftDec, rightDec)
+	case syntax.ArithIntDiv:
+		return leftDec.DivRound(rightDec, 0, value.RoundFloor)
+	case syntax.ArithModulo:
+		// r = a - q * b, where q = a 'intdiv' b
+		q, err := leftDec.DivRound(rightDec, 0, value.RoundFloor)
+		if err != nil {
+			return nil, err
+		}
+		return leftDec.Sub(q.Mul(rightDec)), nil
+	}
+	return nil, zerr.UnexpectedCase("运算项", fmt.Sprintf("%d", arithType))
 }
 
 // evalArithTypeModuloExpr - handle special case of ArithExpr where Type = ArithModulo (%)
@@ -1079,11 +1498,19 @@ func evalArithTypeModuloExpr(vm *r.VM, expr *syntax.ArithExpr) (r.Element, error
 		return nil, err
 	}
 
+	return moduloOperate(vm, leftExpr, rightExpr)
+}
+
+// moduloOperate - calculate `left % right` (number modulo or string format)
+func moduloOperate(vm *r.VM, leftExpr r.Element, rightExpr r.Element) (r.Element, error) {
 	// handle CASE 1
 	if leftNum, okL := leftExpr.(*value.Number); okL {
 		if rightNum, okR := rightExpr.(*value.Number); okR {
 			// a/b = q with remainder r, where b*q + r = a and 0 <= abs(r) < b
 			// so q = a 'intdiv' b, r = a - q * b
+			if err := assertPlainNumbers(leftNum, rightNum); err != nil {
+				return nil, err
+			}
 			a := leftNum.GetValue()
 			b := rightNum.GetValue()
 			if b == 0 {
@@ -1095,6 +1522,20 @@ func evalArithTypeModuloExpr(vm *r.VM, expr *syntax.ArithExpr) (r.Element, error
 		}
 	}
 
+	// handle CASE 1 for decimals
+	switch leftExpr.(type) {
+	case *value.Number, *value.Decimal:
+		switch rightExpr.(type) {
+		case *value.Number, *value.Decimal:
+			return arithDecimalOperate(vm, syntax.ArithModulo, leftExpr, rightExpr)
+		}
+	}
+
+	// `%` is not valid for currencies
+	if _, ok := leftExpr.(*value.Currency); ok {
+		return nil, zerr.ArithIncompatibleUnits(getUnitName(leftExpr), getUnitName(rightExpr))
+	}
+
 	// handle CASE 2
 	if leftStr, okL := leftExpr.(*value.String); okL {
 		if rightArr, okR := rightExpr.(*value.Array); okR {
@@ -1124,12 +1565,19 @@ func evalPrimeExpr(vm *r.VM, expr syntax.Expression) (r.Element, error) {
 		case *r.IDName:
 			return vm.FindElement(t)
 		case *r.IDNumber:
-			return value.NewNumber(t.GetValue()), nil
+			return newIDNumberValue(t)
+		case *r.IDCurrency:
+			return newIDCurrencyValue(t)
+		case *r.IDDateTime:
+			return newIDDateTimeValue(t), nil
 		default:
-			// currently idValue only have IDName or IDNumber
+			// currently idValue only have IDName, IDNumber, IDCurrency or IDDateTime
 			return nil, zerr.UnexpectedCase("ID格式", fmt.Sprintf("%T", t))
 		}
 	case *syntax.ArrayExpr:
+		if err := vm.GetExecLimits().CheckSize("array", len(e.Items)); err != nil {
+			return nil, err
+		}
 		var znObjs []r.Element
 		for _, item := range e.Items {
 			expr, err := evalExpression(vm, item)
@@ -1141,6 +1589,9 @@ func evalPrimeExpr(vm *r.VM, expr syntax.Expression) (r.Element, error) {
 
 		return value.NewArray(znObjs), nil
 	case *syntax.HashMapExpr:
+		if err := vm.GetExecLimits().CheckSize("hashmap", len(e.KVPair)); err != nil {
+			return nil, err
+		}
 		var znPairs []value.KVPair
 		for _, item := range e.KVPair {
 			// the key of KVPair MUST BE one of the following types
@@ -1207,7 +1658,11 @@ func evalVarAssignExpr(vm *r.VM, expr *syntax.VarAssignExpr) (r.Element, error)
 			if err != nil {
 				return nil, err
 			}
-			return vr, iv.ReduceLHS(vr)
+			if err := iv.ReduceLHS(vr); err != nil {
+				return nil, err
+			}
+			// new key may be added to the root hashmap
+			return vr, checkElementSize(vm, iv.GetRoot())
 		}
 		return nil, zerr.UnexpectedAssign()
 	default:
@@ -1219,24 +1674,46 @@ func evalVarAssignExpr(vm *r.VM, expr *syntax.VarAssignExpr) (r.Element, error)
 func execAnotherModule(vm *r.VM, libInfo r.LibNameInfo) (*r.Module, error) {
 	name := libInfo.OriginalName
 	if finder := vm.GetModuleCodeFinder(); finder != nil {
-		source, err := finder(false, libInfo)
-		if err != nil {
-			return nil, zerr.ModuleNotFound(name)
-		}
+		var program *syntax.Program
+		if loader := vm.GetModuleProgramLoader(); loader != nil {
+			// #1. load parsed program directly
+			loadedProgram, err := loader(false, libInfo)
+			if err != nil {
+				switch err.(type) {
+				// keep runtime errors (e.g. invalid vendor manifest) & syntax errors as is
+				case *zerr.RuntimeError, *SyntaxErrorWrapper:
+					return nil, err
+				}
+				return nil, zerr.ModuleNotFound(name)
+			}
+			program = loadedProgram
+		} else {
+			source, err := finder(false, libInfo)
+			if err != nil {
+				// keep runtime errors (e.g. invalid vendor manifest) as is
+				if _, ok := err.(*zerr.RuntimeError); ok {
+					return nil, err
+				}
+				return nil, zerr.ModuleNotFound(name)
+			}
 
-		// #1. parse program
-		p := syntax.NewParser(source, zh.NewParserZH())
+			// #1. parse program
+			p := syntax.NewParser(source, zh.NewParserZH())
 
-		program, err := p.Compile()
-		if err != nil {
-			// moduleName
-			return nil, WrapSyntaxError(p, name, err)
+			parsedProgram, err := p.Compile()
+			if err != nil {
+				// moduleName
+				return nil, WrapSyntaxError(p, name, err)
+			}
+			program = parsedProgram
 		}
 
 		// #2. allocate new module
 		module := vm.AllocateModule(name, program)
 		callFrame := r.NewScriptCallFrame(module)
-		vm.PushCallFrame(callFrame)
+		if err := vm.PushCallFrame(callFrame); err != nil {
+			return nil, err
+		}
 
 		// #3. eval program
 		if _, err := evalProgram(vm, program, nil); err != nil {
@@ -1271,28 +1748,7 @@ func getMemberExprIV(vm *r.VM, expr *syntax.MemberExpr) (*value.IV, error) {
 			if err != nil {
 				return nil, err
 			}
-			switch v := valRoot.(type) {
-			case *value.Array:
-				vr, ok := idx.(*value.Number)
-				if !ok {
-					return nil, zerr.InvalidExprType("integer")
-				}
-				vri := int(vr.GetValue())
-				return value.NewArrayIV(v, vri), nil
-			case *value.HashMap:
-				var s string
-				switch x := idx.(type) {
-				// regard decimal value directly as string
-				case *value.Number:
-					s = x.String()
-				case *value.String:
-					s = x.String()
-				default:
-					return nil, zerr.InvalidExprType("integer", "string")
-				}
-				return value.NewHashMapIV(v, s), nil
-			}
-			return nil, zerr.InvalidExprType("array", "hashmap")
+			return newIndexIV(valRoot, idx)
 		}
 		return nil, zerr.UnexpectedCase("子项类型", fmt.Sprintf("%d", expr.MemberType))
 	}
@@ -1300,6 +1756,32 @@ func getMemberExprIV(vm *r.VM, expr *syntax.MemberExpr) (*value.IV, error) {
 	return nil, zerr.UnexpectedCase("根元素类型", fmt.Sprintf("%d", expr.RootType))
 }
 
+// newIndexIV - build IV for `root # index`
+func newIndexIV(valRoot r.Element, idx r.Element) (*value.IV, error) {
+	switch v := valRoot.(type) {
+	case *value.Array:
+		vr, ok := idx.(*value.Number)
+		if !ok {
+			return nil, zerr.InvalidExprType("integer")
+		}
+		vri := int(vr.GetValue())
+		return value.NewArrayIV(v, vri), nil
+	case *value.HashMap:
+		var s string
+		switch x := idx.(type) {
+		// regard decimal value directly as string
+		case *value.Number:
+			s = x.String()
+		case *value.String:
+			s = x.String()
+		default:
+			return nil, zerr.InvalidExprType("integer", "string")
+		}
+		return value.NewHashMapIV(v, s), nil
+	}
+	return nil, zerr.InvalidExprType("array", "hashmap")
+}
+
 // // helpers
 // exprsToValues - []syntax.Expression -> []eval.r.Value
 func exprsToValues(vm *r.VM, exprs []syntax.Expression) ([]r.Element, error) {
@@ -1326,3 +1808,19 @@ func extractSignalValue(err error, sigType uint8) (r.Element, error) {
 	}
 	return nil, err
 }
+
+// checkElementSize - check if the size of array / hashmap / string exceeds the limits of VM.
+// Methods that may create huge elements are checked before the allocation (see
+// r.VMMethodElement); this one checks elements that grow step by step (e.g. 添加 in loops).
+func checkElementSize(vm *r.VM, elem r.Element) error {
+	limits := vm.GetExecLimits()
+	switch v := elem.(type) {
+	case *value.Array:
+		return limits.CheckSize("array", v.Length())
+	case *value.HashMap:
+		return limits.CheckSize("hashmap", len(v.GetKeyOrder()))
+	case *value.String:
+		return limits.CheckSize("string", len(v.GetValue()))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package exec
 
 import (
+	zerr "github.com/DemoHn/Zn/pkg/error"
 	r "github.com/DemoHn/Zn/pkg/runtime"
 	"github.com/DemoHn/Zn/pkg/syntax"
 	"github.com/DemoHn/Zn/pkg/value"
@@ -11,6 +12,23 @@ func compileClass(vm *r.VM, classID *r.IDName, classNode *syntax.ClassDeclareStm
 	className := classID.GetLiteral()
 	ref := value.NewClassModel(className)
 
+	// set parent class (定义X继承Y), which MUST be defined before
+	if classNode.ParentClass != nil {
+		parentID, err := MatchIDName(classNode.ParentClass)
+		if err != nil {
+			return nil, err
+		}
+		parentElem, err := vm.FindElement(parentID)
+		if err != nil {
+			return nil, err
+		}
+		parentModel, ok := parentElem.(*value.ClassModel)
+		if !ok {
+			return nil, zerr.InvalidClassType(parentID.GetLiteral())
+		}
+		ref.SetParent(parentModel)
+	}
+
 	// init prop list and its default value
 	for _, propPair := range classNode.PropertyList {
 		propID := propPair.PropertyID.GetLiteral()
@@ -25,14 +43,62 @@ func compileClass(vm *r.VM, classID *r.IDName, classNode *syntax.ClassDeclareStm
 	// add getters
 	for _, gNode := range classNode.GetterList {
 		getterTag := gNode.Name.GetLiteral()
-		ref.DefineCompProperty(getterTag, compileFunction(vm, gNode))
+		ref.DefineCompProperty(getterTag, compileMethod(vm, gNode, ref.GetParent()))
 	}
 
 	// add methods
 	for _, mNode := range classNode.MethodList {
 		mTag := mNode.Name.GetLiteral()
-		ref.DefineMethod(mTag, compileFunction(vm, mNode))
+		ref.DefineMethod(mTag, compileMethod(vm, mNode, ref.GetParent()))
 	}
 
 	return ref, nil
 }
+
+// compileMethod - create a method Function of the class. Unlike compileFunction, a method
+// may be called on instances of subclasses (which may be defined in other modules), or via
+// 父类之（…）; thus it's always executed in the module where it's defined, with 此 = the
+// receiver object.
+func compileMethod(vm *r.VM, node *syntax.FunctionDeclareStmt, parent *value.ClassModel) *value.Function {
+	module := vm.GetCurrentModule()
+	compiled := tryCompileExecBlock(vm, node.ExecBlock, true)
+
+	var execMethod = func(receiver r.Element, params []r.Element) (r.Element, error) {
+		if parent == nil {
+			return evalExecBlock(vm, node.ExecBlock, compiled, params)
+		}
+		vm.BeginScope()
+		defer vm.EndScope()
+		if err := declareParentObject(vm, receiver, parent); err != nil {
+			return nil, err
+		}
+		return evalExecBlock(vm, node.ExecBlock, compiled, params)
+	}
+
+	var mainLogicHandler = func(receiver r.Element, params []r.Element) (r.Element, error) {
+		if vm.GetCurrentModule() == module && vm.GetThisValue() == receiver {
+			return execMethod(receiver, params)
+		}
+		fnCallFrame := r.NewFunctionCallFrame(module, receiver)
+		if err := vm.PushCallFrame(fnCallFrame); err != nil {
+			return nil, err
+		}
+		result, err := execMethod(receiver, params)
+		if err == nil {
+			vm.PopCallFrame()
+		}
+		return result, err
+	}
+
+	return value.NewFunction(mainLogicHandler)
+}
+
+// declareParentObject - declare 父类 in current scope for methods & constructors of a class
+// that has a parent class
+func declareParentObject(vm *r.VM, receiver r.Element, parent *value.ClassModel) error {
+	obj, ok := receiver.(*value.Object)
+	if parent == nil || !ok {
+		return nil
+	}
+	return vm.DeclareConstElement(r.NewIDName(EVConstParentVariableName), value.NewParentObject(obj, parent))
+}
@@ -0,0 +1,141 @@
+package exec
+
+import (
+	"testing"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+const classInheritanceTestHeader = `
+定义订单：
+    其金额 = 0
+    其状态 = “新建”
+
+    如何描述？
+        输出“订单：{}” % 【其金额】
+
+    如何计算费用？
+        输出其金额
+
+如何新建订单？
+    输入金额
+    其金额 = 金额
+
+定义退款订单继承订单：
+    其原因 = “”
+
+    如何描述？
+        令基础 = 以父类（描述）
+        输出“{}（退款）” % 【基础】
+
+如何新建退款订单？
+    输入金额、原因
+    以父类（构造：金额）
+    其原因 = 原因
+
+定义跨境订单继承退款订单：
+    如何计算费用？
+        输出以父类（计算费用）+ 5
+`
+
+func TestEvalClassInheritance(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name: "inherit properties & methods",
+			code: `
+令A = （新建退款订单：100、“损坏”）
+【A之金额，A之状态，A之原因，以A（计算费用）】`,
+			expected: "[100，新建，损坏，100]",
+		},
+		{
+			name: "override methods & call methods of the parent class",
+			code: `
+令A = （新建退款订单：100、“损坏”）
+以A（描述）`,
+			expected: "订单：100（退款）",
+		},
+		{
+			name: "inherit constructor & multi-level inheritance",
+			code: `
+令A = （新建跨境订单：20、“丢失”）
+【以A（描述），以A（计算费用），A之原因】`,
+			expected: "[订单：20（退款），25，丢失]",
+		},
+		{
+			name: "catch exceptions of subclasses",
+			code: `
+定义余额不足异常继承异常：
+    其代码 = 42
+
+定义透支异常继承余额不足异常：
+    其代码 = 7
+
+如何支付？
+    输入类型
+    如果类型 == 1：
+        抛出余额不足异常：“余额不足”！
+    抛出透支异常：“透支”！
+    拦截余额不足异常：
+        输出“{}：{}” % 【其内容，其代码】
+
+如何支付2？
+    抛出透支异常：“透支”！
+    拦截异常：
+        输出其内容
+
+【（支付：1），（支付：2），（支付2）】`,
+			expected: "[余额不足：42，透支：7，透支]",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, classInheritanceTestHeader+tt.code, r.ElementMap{}, nil, expectResult(tt.expected))
+		})
+	}
+}
+
+func TestEvalClassInheritance_Errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		code    string
+		errCode int
+	}{
+		{
+			name:    "parent class not defined",
+			code:    "定义退款订单继承某订单：\n    其原因 = “”",
+			errCode: zerr.ErrNameNotDefined,
+		},
+		{
+			name:    "parent is not a class",
+			code:    "如何某订单？\n    输出1\n\n定义退款订单继承某订单：\n    其原因 = “”",
+			errCode: zerr.ErrInvalidClassType,
+		},
+		{
+			name: "catch block of parent class does not match",
+			code: classInheritanceTestHeader + `
+定义余额不足异常继承异常：
+    其代码 = 42
+
+如何支付？
+    抛出异常：“未知”！
+    拦截余额不足异常：
+        输出其内容
+
+（支付）`,
+			// uncaught exceptions have no error code
+			errCode: 0,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, tt.code, r.ElementMap{}, nil, expectErrorCode(tt.errCode))
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package exec
+
+import (
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+// built-in exception classes - all of them inherit from 异常, thus `拦截异常` still catches
+// all errors, while `拦截索引异常` catches index errors ONLY.
+const (
+	EVConstIndexExceptionClassName      = "索引异常"
+	EVConstNameExceptionClassName       = "标识异常"
+	EVConstPropertyExceptionClassName   = "属性异常"
+	EVConstParamExceptionClassName      = "参数异常"
+	EVConstModuleExceptionClassName     = "模块异常"
+	EVConstInternalExceptionClassName   = "内部异常"
+	EVConstTypeExceptionClassName       = "类型异常"
+	EVConstArithExceptionClassName      = "算术异常"
+	EVConstInputExceptionClassName      = "输入异常"
+	EVConstPermissionExceptionClassName = "权限异常"
+)
+
+// getRuntimeExceptionClassName - map RuntimeError codes to the names of built-in exception classes
+// (see pkg/error/runtime_error.go for code ranges)
+func getRuntimeExceptionClassName(code int) string {
+	switch {
+	case code == zerr.ErrIndexOutOfRange, code == zerr.ErrIndexKeyNotFound:
+		return EVConstIndexExceptionClassName
+	case code >= zerr.ErrNameNotDefined && code <= zerr.ErrAssignToConstant:
+		return EVConstNameExceptionClassName
+	case code >= zerr.ErrPropertyNotFound && code <= zerr.ErrThisValueNotFound:
+		return EVConstPropertyExceptionClassName
+	case code >= zerr.ErrLeastParamsError && code <= zerr.ErrExactParamsError:
+		return EVConstParamExceptionClassName
+	case code >= zerr.ErrModuleNotFound && code <= zerr.ErrInvalidVendorManifest:
+		return EVConstModuleExceptionClassName
+	case code >= zerr.ErrUnexpectedCase && code <= zerr.ErrUnexpectedRethrow:
+		return EVConstInternalExceptionClassName
+	case code == zerr.ErrInvalidExceptionClass,
+		code >= zerr.ErrInvalidExprType && code <= zerr.ErrInvalidClassType:
+		return EVConstTypeExceptionClassName
+	case code >= zerr.ErrArithDivZero && code <= zerr.ErrArithOutOfDomain:
+		return EVConstArithExceptionClassName
+	case code == zerr.ErrInputValueNotFound:
+		return EVConstInputExceptionClassName
+	case code == zerr.ErrPermissionDenied:
+		return EVConstPermissionExceptionClassName
+	}
+	return EVConstExceptionClassName
+}
+
+// newRuntimeExceptionModels - create built-in exception classes (inherit from 异常)
+func newRuntimeExceptionModels(exceptionModel *value.ClassModel) map[string]*value.ClassModel {
+	models := map[string]*value.ClassModel{}
+	for _, name := range []string{
+		EVConstIndexExceptionClassName,
+		EVConstNameExceptionClassName,
+		EVConstPropertyExceptionClassName,
+		EVConstParamExceptionClassName,
+		EVConstModuleExceptionClassName,
+		EVConstInternalExceptionClassName,
+		EVConstTypeExceptionClassName,
+		EVConstArithExceptionClassName,
+		EVConstInputExceptionClassName,
+		EVConstPermissionExceptionClassName,
+	} {
+		models[name] = value.NewClassModel(name).SetParent(exceptionModel)
+	}
+	return models
+}
+
+// toExceptionValue - convert the error of a block to the exception value to be matched
+// by catch blocks. Besides exceptions thrown by 抛出, runtime errors (except interrupt errors
+// like timeout) are also converted to built-in exceptions.
+// Returns nil if the error is not catchable.
+func toExceptionValue(vm *r.VM, err error) r.Element {
+	if exception, realErr := extractSignalValue(err, zerr.SigTypeException); realErr == nil {
+		return exception
+	}
+
+	switch e := err.(type) {
+	case *value.Exception:
+		// native errors (wrapped by Function.Exec)
+		if e.GetCallStack().Length() == 0 {
+			e.SetCallStack(captureCallStack(vm))
+		}
+		return e
+	case *zerr.RuntimeError:
+		if e.IsInterrupt() {
+			return nil
+		}
+		model := ZnConstExceptionClass
+		if m, ok := ZnConstRuntimeExceptionClasses[getRuntimeExceptionClassName(e.Code)]; ok {
+			model = m
+		}
+		return value.NewObject(model, map[string]r.Element{
+			EVConstExceptionContentProperty: value.NewString(e.Message),
+			EVConstExceptionCodeProperty:    value.NewNumber(float64(e.Code)),
+			EVConstExceptionStackProperty:   captureCallStack(vm),
+		})
+	}
+	return nil
+}
+
+// captureCallStack - get current call stack of VM as an array of hashmaps (the bottom frame comes first):
+// 【“模块” = ‹模块名›，“行号” = ‹行号›，“代码” = ‹当前行代码›】
+func captureCallStack(vm *r.VM) *value.Array {
+	frames := []r.Element{}
+	for _, frame := range vm.GetCallStack() {
+		module := frame.GetModule()
+		line := 0
+		source := ""
+		if module.GetID() != r.NATIVE_CODE_MODULE_ID && module.GetProgram() != nil {
+			line = frame.GetCurrentLine() + 1
+			source = frame.GetSourceTextLine(frame.GetCurrentLine())
+		}
+		frames = append(frames, value.NewHashMap([]value.KVPair{
+			{Key: "模块", Value: value.NewString(module.GetName())},
+			{Key: "行号", Value: value.NewNumber(float64(line))},
+			{Key: "代码", Value: value.NewString(source)},
+		}))
+	}
+	return value.NewArray(frames)
+}
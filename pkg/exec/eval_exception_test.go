@@ -0,0 +1,440 @@
+package exec
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+func TestEvalRuntimeException(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name: "catch runtime errors by built-in exception class",
+			code: `
+如何除？
+    输入A、B
+    输出A / B
+    拦截算术异常：
+        输出“{}：{}” % 【其代码，其内容】
+
+（除：1、0）`,
+			expected: "90：被除数不得为0",
+		},
+		{
+			name: "catch runtime errors from nested calls",
+			code: `
+如何取值？
+    输入列表、索引
+    输出列表#索引
+
+如何安全取值？
+    输入列表、索引
+    输出（取值：列表、索引）
+    拦截索引异常：
+        输出空
+
+【（安全取值：【1，2】、1），（安全取值：【1，2】、5）】`,
+			expected: "[1，空]",
+		},
+		{
+			name: "catch runtime errors by 异常",
+			code: `
+如何读取？
+    输出某变量
+    拦截异常：
+        输出其代码
+
+（读取）`,
+			expected: "42",
+		},
+		{
+			name: "the first matched catch block is executed",
+			code: `
+如何读取？
+    令A = 【】
+    输出A之某属性
+    拦截索引异常：
+        输出“索引”
+    拦截属性异常：
+        输出“属性”
+    拦截异常：
+        输出“其他”
+
+（读取）`,
+			expected: "属性",
+		},
+		{
+			name: "call stack of runtime errors",
+			code: `
+如何除？
+    输入A、B
+    输出A / B
+
+如何计算？
+    输出（除：1、0）
+    拦截异常：
+        令栈 = 其调用栈
+        输出【栈之长度，栈#3#“行号”，栈#3#“代码”】
+
+（计算）`,
+			expected: "[3，4，输出A / B]",
+		},
+		{
+			name: "throw built-in exceptions",
+			code: `
+如何检查？
+    抛出参数异常：“参数不得为空”！
+    拦截参数异常：
+        输出【其内容，其代码】
+
+（检查）`,
+			expected: "[参数不得为空，0]",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, tt.code, r.ElementMap{}, nil, expectResult(tt.expected))
+		})
+	}
+}
+
+func TestEvalRuntimeException_NotCaught(t *testing.T) {
+	cases := []struct {
+		name    string
+		limits  r.ExecLimits
+		code    string
+		errCode int
+	}{
+		{
+			name: "catch block of other class",
+			code: `
+如何除？
+    输入A、B
+    输出A / B
+    拦截索引异常：
+        输出0
+
+（除：1、0）`,
+			errCode: zerr.ErrArithDivZero,
+		},
+		{
+			name:   "interrupt errors",
+			limits: r.ExecLimits{MaxCallDepth: 20},
+			code: `
+如何递归？
+    输入N
+    输出（递归：N + 1）
+    拦截异常：
+        输出0
+
+（递归：1）`,
+			errCode: zerr.ErrCallDepthExceeded,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, tt.code, r.ElementMap{}, func(z *Interpreter) *Interpreter {
+				return z.SetExecLimits(tt.limits)
+			}, expectErrorCode(tt.errCode))
+		})
+	}
+}
+
+func TestEvalRuntimeException_DropCallFrames(t *testing.T) {
+	// frames of failed calls should be dropped after the exception is caught,
+	// otherwise the call depth exceeds soon
+	code := `
+如何除？
+    输入A、B
+    输出A / B
+
+如何安全除？
+    输入A、B
+    输出（除：A、B）
+    拦截算术异常：
+        输出0
+
+令I = 0
+每当I < 50：
+    （安全除：1、0）
+    I = I + 1
+I`
+	runBothEngines(t, code, r.ElementMap{}, func(z *Interpreter) *Interpreter {
+		return z.SetExecLimits(r.ExecLimits{MaxCallDepth: 20})
+	}, expectResult("50"))
+}
+
+func TestEvalFinallyBlock(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name: "finally block is executed after return",
+			code: `
+令日志 = 【】
+如何执行？
+    输出1
+    善后：
+        以日志（后增：“善后”）
+
+令结果 = （执行）
+【结果，日志】`,
+			expected: "[1，[善后]]",
+		},
+		{
+			name: "finally block is executed after the exception is caught",
+			code: `
+令日志 = 【】
+如何除？
+    输入A、B
+    输出A / B
+    拦截算术异常：
+        以日志（后增：“拦截”）
+        输出0
+    善后：
+        以日志（后增：“善后”）
+
+令结果 = （除：1、0）
+【结果，日志】`,
+			expected: "[0，[拦截，善后]]",
+		},
+		{
+			name: "finally block is executed when the exception is not caught",
+			code: `
+令日志 = 【】
+如何除？
+    输入A、B
+    输出A / B
+    善后：
+        以日志（后增：“善后”）
+
+如何安全除？
+    输出（除：1、0）
+    拦截异常：
+        输出日志
+
+（安全除）`,
+			expected: "[善后]",
+		},
+		{
+			name: "return value of finally block overrides others",
+			code: `
+如何执行？
+    抛出异常：“错误”！
+    善后：
+        输出“善后”
+
+（执行）`,
+			expected: "善后",
+		},
+		{
+			name: "rethrow the caught exception",
+			code: `
+定义余额不足异常继承异常：
+    其代码 = 42
+
+令日志 = 【】
+如何支付？
+    抛出余额不足异常：“余额不足”！
+    拦截异常：
+        以日志（后增：其内容）
+        抛出！
+
+如何安全支付？
+    输出（支付）
+    拦截余额不足异常：
+        以日志（后增：其代码）
+        输出日志
+
+（安全支付）`,
+			expected: "[余额不足，42]",
+		},
+		{
+			name: "rethrow runtime errors & keep the call stack",
+			code: `
+如何除？
+    输入A、B
+    输出A / B
+
+如何计算？
+    输出（除：1、0）
+    拦截算术异常：
+        抛出！
+
+如何安全计算？
+    输出（计算）
+    拦截异常：
+        令栈 = 其调用栈
+        输出【其代码，栈之长度】
+
+（安全计算）`,
+			expected: "[90，4]",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, tt.code, r.ElementMap{}, nil, expectResult(tt.expected))
+		})
+	}
+}
+
+func TestEvalFinallyBlock_Errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		code    string
+		errCode int
+	}{
+		{
+			name: "uncaught errors are thrown after finally block",
+			code: `
+如何除？
+    输入A、B
+    输出A / B
+    善后：
+        令X = 1
+
+（除：1、0）`,
+			errCode: zerr.ErrArithDivZero,
+		},
+		{
+			name: "errors of catch block are thrown after finally block",
+			code: `
+如何读取？
+    输出某变量
+    拦截异常：
+        输出1 / 0
+    善后：
+        令X = 1
+
+（读取）`,
+			errCode: zerr.ErrArithDivZero,
+		},
+		{
+			name: "errors of finally block",
+			code: `
+如何读取？
+    输出1
+    善后：
+        令X = 某变量
+
+（读取）`,
+			errCode: zerr.ErrNameNotDefined,
+		},
+		{
+			name:    "rethrow outside catch block",
+			code:    "如何读取？\n    抛出！\n\n（读取）",
+			errCode: zerr.ErrUnexpectedRethrow,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, tt.code, r.ElementMap{}, nil, expectErrorCode(tt.errCode))
+		})
+	}
+}
+
+func TestEvalException_CallStack(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name: "call stack of thrown exceptions",
+			code: `
+如何检查？
+    抛出异常：“错误”！
+
+如何运行？
+    （检查）
+    拦截异常：
+        令栈 = 其调用栈
+        输出【栈之长度，栈#3#“模块”，栈#3#“行号”，栈#3#“代码”，栈#2#“行号”】
+
+（运行）`,
+			expected: "[3，主模块，3，抛出异常：“错误”！，6]",
+		},
+		{
+			name: "call stack of thrown exceptions of subclasses",
+			code: `
+定义余额不足异常继承异常：
+    其代码 = 42
+
+如何支付？
+    抛出余额不足异常：“余额不足”！
+    拦截余额不足异常：
+        令栈 = 其调用栈
+        输出【栈之长度，栈#2#“行号”】
+
+（支付）`,
+			expected: "[2，6]",
+		},
+		{
+			name: "call stack is kept after rethrown",
+			code: `
+如何检查？
+    抛出异常：“错误”！
+
+如何运行？
+    （检查）
+    拦截异常：
+        抛出！
+
+如何安全运行？
+    （运行）
+    拦截异常：
+        令栈 = 其调用栈
+        输出【栈之长度，栈#4#“行号”】
+
+（安全运行）`,
+			expected: "[4，3]",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, tt.code, r.ElementMap{}, nil, expectResult(tt.expected))
+		})
+	}
+}
+
+func TestDisplayError_ExceptionCallStack(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"主程序.zn": "导入《订单》\n\n如何运行？\n    （检查订单）\n    拦截异常：\n        抛出！\n\n（运行）",
+		"订单.zn":  "如何检查订单？\n    令金额 = 0\n    抛出异常：“金额不得为0”！",
+	})
+
+	forBothEngines(t, func(t *testing.T, bytecode bool) {
+		_, err := NewInterpreter("test").SetBytecode(bytecode).
+			LoadFile(filepath.Join(root, "主程序.zn")).Execute(r.ElementMap{})
+		if err == nil {
+			t.Fatal("expect error, got nil")
+		}
+		// the full chain where the exception is thrown is displayed, instead of where it's rethrown
+		errText := DisplayError(err)
+		for _, expect := range []string{
+			"在主模块中，位于第 8 行发生异常：\n    （运行）",
+			"来自主模块，第 4 行：\n    （检查订单）",
+			"来自“订单”模块，第 3 行：\n    抛出异常：“金额不得为0”！",
+			"运行异常：金额不得为0",
+		} {
+			if !strings.Contains(errText, expect) {
+				t.Errorf("expect error text contains %s, got:\n%s", expect, errText)
+			}
+		}
+	})
+}
@@ -10,11 +10,50 @@ import (
 )
 
 // compileFunction - create a Function object (with default param handler logic)
-// from Zn code (*syntax.BlockStmt). It's the constructor of 如何XX or (anoymous function in the future)
+// from Zn code (*syntax.BlockStmt). It's the constructor of 如何XX
 func compileFunction(vm *r.VM, node *syntax.FunctionDeclareStmt) *value.Function {
+	// 1. compile exec block to bytecode (if enabled)
+	compiled := tryCompileExecBlock(vm, node.ExecBlock, true)
 	var mainLogicHandler = func(receiver r.Element, params []r.Element) (r.Element, error) {
 		// 2. do eval exec block
-		return evalExecBlock(vm, node.ExecBlock, params)
+		return evalExecBlock(vm, node.ExecBlock, compiled, params)
+	}
+
+	return value.NewFunction(mainLogicHandler)
+}
+
+// compileClosure - create a Function object from an anonymous function (如何（X）：...).
+// Unlike compileFunction, variables visible at where it's defined are captured, thus
+// they're still available after the defining function returns.
+func compileClosure(vm *r.VM, node *syntax.FunctionExpr) *value.Function {
+	module := vm.GetCurrentModule()
+	closure := vm.CaptureClosure()
+	compiled := tryCompileExecBlock(vm, node.ExecBlock, true)
+
+	var execClosure = func(params []r.Element) (r.Element, error) {
+		vm.BeginScope()
+		defer vm.EndScope()
+		if err := vm.DeclareClosure(closure); err != nil {
+			return nil, err
+		}
+		return evalExecBlock(vm, node.ExecBlock, compiled, params)
+	}
+
+	var mainLogicHandler = func(receiver r.Element, params []r.Element) (r.Element, error) {
+		if vm.GetCurrentModule() == module {
+			return execClosure(params)
+		}
+		// the closure is called outside the module where it's defined (e.g. from another
+		// module, or native methods like 以数组（映射：...）) - switch back to its module first
+		fnCallFrame := r.NewFunctionCallFrame(module, nil)
+		if err := vm.PushCallFrame(fnCallFrame); err != nil {
+			return nil, err
+		}
+		result, err := execClosure(params)
+		if err == nil {
+			vm.PopCallFrame()
+		}
+		return result, err
 	}
 
 	return value.NewFunction(mainLogicHandler)
@@ -65,15 +104,32 @@ func execMethodFunction(vm *r.VM, root r.Element, funcName *r.IDName, params []r
 			return nil, err
 		}
 		fnCallFrame := r.NewFunctionCallFrame(refModule, root)
-		vm.PushCallFrame(fnCallFrame)
+		if err := vm.PushCallFrame(fnCallFrame); err != nil {
+			return nil, err
+		}
+	case *value.ParentObject:
+		// 以父类（XX） - 此 is still the object itself
+		fnCallFrame := r.NewFunctionCallFrame(vm.GetCurrentModule(), robj.GetObject())
+		if err := vm.PushCallFrame(fnCallFrame); err != nil {
+			return nil, err
+		}
 	default:
 		// for other types, we suppose it is from native code -
 		// usually for internal types like Number, String, Boolean, etc.
 		fnCallFrame := r.NewFunctionCallFrame(r.NativeCodeModule, root)
-		vm.PushCallFrame(fnCallFrame)
+		if err := vm.PushCallFrame(fnCallFrame); err != nil {
+			return nil, err
+		}
 	}
 
-	elem, err := root.ExecMethod(funcName.GetLiteral(), params)
+	var elem r.Element
+	var err error
+	// methods that depend on the VM, e.g. 以金额（兑换：币种） uses the exchange rates of VM
+	if vmRoot, ok := root.(r.VMMethodElement); ok {
+		elem, err = vmRoot.ExecMethodVM(vm, funcName.GetLiteral(), params)
+	} else {
+		elem, err = root.ExecMethod(funcName.GetLiteral(), params)
+	}
 	if err == nil {
 		vm.PopCallFrame()
 	}
@@ -90,7 +146,9 @@ func execDirectFunction(vm *r.VM, funcName *r.IDName, params []r.Element) (r.Ele
 	}
 	// pushCallFrame
 	fnCallFrame := r.NewFunctionCallFrame(module, nil)
-	vm.PushCallFrame(fnCallFrame)
+	if err := vm.PushCallFrame(fnCallFrame); err != nil {
+		return nil, err
+	}
 
 	// assert value is function type
 	fn, ok := elem.(*value.Function)
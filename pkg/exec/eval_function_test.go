@@ -0,0 +1,85 @@
+package exec
+
+import (
+	"testing"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+func TestEvalFunctionExpr(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name:     "inline form",
+			code:     "令加法 = 如何（X、Y）：X + Y\n（加法：1、2）",
+			expected: "3",
+		},
+		{
+			name:     "inline form without params",
+			code:     "令F = 如何：“你好”\n（F）",
+			expected: "你好",
+		},
+		{
+			name: "capture variables after the function returns",
+			code: `
+如何生成计数器？
+    输入起始
+    令N = 起始
+    输出如何？
+        N = N + 1
+        输出N
+
+令A = （生成计数器：10）
+令B = （生成计数器：20）
+（A）
+【（A），（B）】`,
+			expected: "[12，21]",
+		},
+		{
+			name: "share variables with the defining scope",
+			code: `
+令总和 = 0
+令累加 = 如何（X）：总和 = 总和 + X
+（累加：5）
+（累加：6）
+总和`,
+			expected: "11",
+		},
+		{
+			name: "params shadow captured variables",
+			code: `
+令X = 100
+令F = 如何（X）：X * 2
+【（F：1），X】`,
+			expected: "[2，100]",
+		},
+		{
+			name: "callbacks of array methods",
+			code: `
+令倍数 = 3
+令数组A = 【1，2，3，4】
+【以数组A（映射：如何（X）：X * 倍数），以数组A（过滤：如何（X）：X > 2）】`,
+			expected: "[[3，6，9，12]，[3，4]]",
+		},
+		{
+			name: "higher-order functions",
+			code: `
+如何组合？
+    输入F、G
+    输出如何（X）：（F：（G：X））
+
+令H = （组合：如何（X）：X + 1、如何（X）：X * 10）
+（H：2）`,
+			expected: "21",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, tt.code, r.ElementMap{}, nil, expectResult(tt.expected))
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	zerr "github.com/DemoHn/Zn/pkg/error"
 	"github.com/DemoHn/Zn/pkg/io"
 	"github.com/DemoHn/Zn/pkg/runtime"
 	"github.com/DemoHn/Zn/pkg/syntax"
@@ -207,22 +208,429 @@ func TestEvalWhileLoopStmt_OKCases(t *testing.T) {
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
-			vm := setupMockContext()
-			injectValuesToRootScope(vm, tt.initValue)
+			forBothEngines(t, func(t *testing.T, bytecode bool) {
+				vm := setupMockContext()
+				injectValuesToRootScope(vm, tt.initValue)
 
-			ss, err := setupStmtFromCode(tt.code)
-			if err != nil {
-				t.Errorf("FATAL got error:%v", err)
-				return
+				if bytecode {
+					// compile the whole program (that contains the loop ONLY) and execute it
+					program := parseBytecodeProgram(t, tt.code)
+					compiled, err := compileExecBlock(program.ExecBlock, false)
+					if err != nil {
+						t.Fatalf("compile program failed: %v", err)
+					}
+					vm.SetBytecode(true)
+					if _, err := evalExecBlock(vm, program.ExecBlock, compiled, nil); err != nil {
+						t.Fatalf("expect OK, but got error: %v", err)
+					}
+				} else {
+					ss, err := setupStmtFromCode(tt.code)
+					if err != nil {
+						t.Fatalf("FATAL got error:%v", err)
+					}
+
+					// run the core function: evalWhileLoopStmt
+					if err := evalWhileLoopStmt(vm, ss.(*syntax.WhileLoopStmt)); err != nil {
+						t.Fatalf("expect OK, but got error: %v", err)
+					}
+				}
+
+				tt.expectLogic(vm, t)
+			})
+		})
+	}
+}
+
+func TestEvalProgram(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name:     "branch statement",
+			code:     "令A = 5\n令B = “”\n如果A > 10：\n    B = “大”\n再如A > 3：\n    B = “中”\n否则：\n    B = “小”\nB",
+			expected: "中",
+		},
+		{
+			name:     "iterate array with index",
+			code:     "令S = 0\n以I、X遍历【10，20，30】：\n    S = S + I * X\nS",
+			expected: "140",
+		},
+		{
+			name:     "iterate hashmap",
+			code:     "令K = 【】\n令S = 0\n以键、值遍历【“甲” = 1，“乙” = 2，“丙” = 3】：\n    以K（后增：键）\n    S = S + 值\n【K，S】",
+			expected: "[[甲，乙，丙]，6]",
+		},
+		{
+			name:     "continue & break iteration",
+			code:     "令S = 0\n以X遍历【1，2，3，4，5，6】：\n    如果X == 2：\n        继续循环\n    如果X == 5：\n        结束循环\n    S = S + X\nS",
+			expected: "8",
+		},
+		{
+			name:     "return inside iteration",
+			code:     "如何查找？\n    输入数组、目标\n    以I、X遍历数组：\n        如果X == 目标：\n            输出I\n    输出-1\n【（查找：【3，5，7】、5），（查找：【3，5，7】、9）】",
+			expected: "[2，-1]",
+		},
+		{
+			name:     "return inside nested loops",
+			code:     "如何F？\n    令X = 0\n    每当真：\n        X = X + 1\n        以Y遍历【1，2】：\n            如果X * Y > 5：\n                输出X * 10 + Y\n(F)",
+			expected: "32",
+		},
+		{
+			name:     "logic short-circuit",
+			code:     "令K = 【】\n如何计数？\n    以K（后增：1）\n    输出真\n令A = 假，且（计数）\n令B = 真，或（计数）\n令C = 真，且（计数）\n【A，B，C，K之数目】",
+			expected: "[假，真，真，1]",
+		},
+		{
+			name:     "recursive function",
+			code:     "如何阶乘？\n    输入N\n    如果N <= 1：\n        输出1\n    输出N * （阶乘：N - 1）\n（阶乘：10）",
+			expected: "3.6288e+06",
+		},
+		{
+			name:     "assign members",
+			code:     "令A = 【1，2，3】\n令H = 【“x” = 1】\nA#2 = 20\nH#{“y”} = 2\n【A，H】",
+			expected: "[[1，20，3]，[x=1，y=2]]",
+		},
+		{
+			name:     "object properties & methods",
+			code:     "定义点：\n    其X = 0\n    其Y = 0\n    如何距离？\n        输出其X * 其X + 其Y * 其Y\n如何新建点？\n    输入X、Y\n    其X = X\n    其Y = Y\n令P = （新建点：3、4）\nP之X = 6\n【P之X，以P（距离）】",
+			expected: "[6，52]",
+		},
+		{
+			name:     "format string",
+			code:     "“{}有{#.1}个” % 【“张三”，3.14159】",
+			expected: "张三有3.1个",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, tt.code, runtime.ElementMap{}, nil, expectResult(tt.expected))
+		})
+	}
+}
+
+func TestEvalDecimal(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name:     "exact arithmetic",
+			code:     "令A = （新建小数：“0.1”）\n【A + 0.2，A * 3，（新建小数：1）/ 3，A - 1】",
+			expected: "[0.3，0.3，0.3333333333333333，-0.9]",
+		},
+		{
+			name:     "int division & modulo",
+			code:     "令A = （新建小数：“-7.5”）\n【A | 2，A % 2，7 % （新建小数：“2.5”）】",
+			expected: "[-4，0.5，2.0]",
+		},
+		{
+			name:     "compare with numbers",
+			code:     "令A = （新建小数：“0.3”）\n【A == 0.1 + 0.2，A == 0.3，A > 0.29，0.31 >= A，A < （新建小数：“0.30”）】",
+			expected: "[假，真，真，真，假]",
+		},
+		{
+			name:     "large amounts",
+			code:     "令A = （新建小数：“12,345,678,901,234,567.89”）\nA + （新建小数：“0.01”）",
+			expected: "12345678901234567.90",
+		},
+		{
+			name:     "round with scale & rounding modes",
+			code:     "令A = （新建小数：“2.345”）\n【以A（保留：2），以A（保留：2、“银行家舍入”），以A（保留：1、“截断”），（新建小数：1.005、2）之文本】",
+			expected: "[2.35，2.34，2.3，1.01]",
+		},
+		{
+			name:     "methods & properties",
+			code:     "令A = （新建小数：“19.99”）\n【以A（乘：3），以A（加：0.01、1），A之精度，A之数值】",
+			expected: "[59.97，21.00，2，19.99]",
+		},
+		{
+			name:     "format string",
+			code:     "令A = （新建小数：“1234567890123.455”）\n“{#.2}” % 【A】",
+			expected: "1234567890123.46",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, tt.code, runtime.ElementMap{}, nil, expectResult(tt.expected))
+		})
+	}
+}
+
+func TestEvalDecimal_FAIL(t *testing.T) {
+	cases := []struct {
+		name    string
+		code    string
+		errCode int
+	}{
+		{
+			name:    "huge scale to round",
+			code:    "令A = （新建小数：“2.345”）\n以A（保留：1000000000）",
+			errCode: zerr.ErrInvalidParamType,
+		},
+		{
+			name:    "non-integer scale to round",
+			code:    "以2.345（保留：1.5）",
+			errCode: zerr.ErrInvalidParamType,
+		},
+		{
+			name:    "huge scale of the constructor",
+			code:    "（新建小数：1、1000000000）",
+			errCode: zerr.ErrInvalidParamType,
+		},
+		{
+			name: "huge exponent",
+			code: "（新建小数：“1e999999999”）",
+		},
+		{
+			name: "huge precision of format string",
+			code: "“{#.999999999}” % 【（新建小数：“1.5”）】",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// errors without code are thrown as exceptions
+			check := expectError()
+			if tt.errCode != 0 {
+				check = expectErrorCode(tt.errCode)
+			}
+			runBothEngines(t, tt.code, runtime.ElementMap{}, nil, check)
+		})
+	}
+}
+
+func TestEvalDecimal_DivisionContext(t *testing.T) {
+	code := "令A = （新建小数：2）\n【A / 3，以A（除：3），（新建小数：1）/ 8，（新建小数：“1.00”）/ 4】"
+	cases := []struct {
+		name     string
+		ctx      *runtime.DecimalContext
+		expected string
+	}{
+		{"default", nil, "[0.6666666666666667，0.6666666666666667，0.125，0.25]"},
+		{"scale & rounding mode", &runtime.DecimalContext{DivisionScale: 2, RoundingMode: value.RoundHalfEven}, "[0.67，0.67，0.12，0.25]"},
+		{"truncate", &runtime.DecimalContext{DivisionScale: 4, RoundingMode: value.RoundDown}, "[0.6666，0.6666，0.125，0.25]"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, code, runtime.ElementMap{}, func(z *Interpreter) *Interpreter {
+				return z.SetDecimalContext(tt.ctx)
+			}, expectResult(tt.expected))
+		})
+	}
+
+	// invalid context
+	runBothEngines(t, code, runtime.ElementMap{}, func(z *Interpreter) *Interpreter {
+		return z.SetDecimalContext(&runtime.DecimalContext{DivisionScale: value.DecimalMaxScale + 1, RoundingMode: value.RoundHalfUp})
+	}, expectErrorCode(zerr.ErrInvalidParamType))
+}
+
+func TestEvalNumberUnits(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+		errCode  int
+	}{
+		{
+			name:     "add & sub with units",
+			code:     "【2.0CBM + 3.3CBM，2.3kg + 500g，3.6km/h + 4.0m/s，10箱 - 3箱】",
+			expected: "[5.3CBM，2800g，5m/s，7箱]",
+		},
+		{
+			name:     "mul & div with coefficients",
+			code:     "令距离 = 2.5km/s\n【2 * 3m，距离 / 2，2km/h * 15min，1*10^-6CBM * 2】",
+			expected: "[6m，1.25km/s，0.5km，2e-06CBM]",
+		},
+		{
+			name:     "compare with units",
+			code:     "【1km == 1000m，1kg == 1m，2kg > 500g，1kg == 1】",
+			expected: "[真，假，真，假]",
+		},
+		{
+			name:     "properties & methods",
+			code:     "令重量 = 2.5kg\n【重量之数字，重量之单位，以重量（转换：“斤”），以重量（加：500g、1kg），“{#.2}” % 【3.14159km】】",
+			expected: "[2.5，kg，5斤，4kg，3.14km]",
+		},
+		{
+			name:    "add incompatible units",
+			code:    "2kg + 3m",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:    "add plain number & unit",
+			code:    "2 + 3CBM",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:    "mul units",
+			code:    "1m * 1m",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:    "modulo with units",
+			code:    "5kg % 2",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:    "compare incompatible units",
+			code:    "1kg > 1m",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:    "decimal with units",
+			code:    "（新建小数：“0.1”）+ 1kg",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			check := expectResult(tt.expected)
+			if tt.errCode != 0 {
+				check = expectErrorCode(tt.errCode)
 			}
+			runBothEngines(t, tt.code, runtime.ElementMap{}, nil, check)
+		})
+	}
+}
+
+func TestEvalCurrency(t *testing.T) {
+	rates := runtime.FixedExchangeRates{"USD/CNY": "7.1234", "HKD/CNY": "0.92"}
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+		errCode  int
+	}{
+		{
+			name:     "literals",
+			code:     "【$20.3，CNY$100，HK$2.5，€5，-¥8.5，$20.0000】",
+			expected: "[USD$20.30，CNY$100.00，HKD$2.50，EUR$5.00，-CNY$8.50，USD$20.0000]",
+		},
+		{
+			name:     "exact arithmetic",
+			code:     "【¥0.1 + ¥0.2，$10 * 0.333，HK$100 / 3，3 * $1.5，$10 / $4】",
+			expected: "[CNY$0.30，USD$3.33，HKD$33.33，USD$4.50，2.50]",
+		},
+		{
+			name:     "compare",
+			code:     "【$1 > $0.5，$1 == $1.00，$1 == ¥1，$1 == 1】",
+			expected: "[真，真，假，假]",
+		},
+		{
+			name:     "exchange",
+			code:     "令运费 = HK$100\n【以$10（兑换：“CNY”），以¥100（兑换：“USD”），以运费（兑换：“CNY”）+ ¥8，以$5（兑换：“USD”）】",
+			expected: "[CNY$71.23，USD$14.04，CNY$100.00，USD$5.00]",
+		},
+		{
+			name:     "constructor & properties",
+			code:     "令A = （新建货币：“USD”、“1234.5”）\n【A之币种，A之金额，A之精度，以A（保留：0），“{$}” % 【A】】",
+			expected: "[USD，1234.50，2，USD$1235，$1,234.50]",
+		},
+		{
+			name:    "add different currencies",
+			code:    "$1 + ¥1",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:    "add currency & number",
+			code:    "$1 + 1",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:    "exchange rate not found",
+			code:    "以$1（兑换：“EUR”）",
+			errCode: zerr.ErrArithExchangeRateNotFound,
+		},
+	}
 
-			// run the core function: evalWhileLoopStmt
-			if err := evalWhileLoopStmt(vm, ss.(*syntax.WhileLoopStmt)); err != nil {
-				t.Errorf("expect OK, but got error: %v", err)
-				return
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			check := expectResult(tt.expected)
+			if tt.errCode != 0 {
+				check = expectErrorCode(tt.errCode)
 			}
+			runBothEngines(t, tt.code, runtime.ElementMap{}, func(z *Interpreter) *Interpreter {
+				return z.SetExchangeRateProvider(rates)
+			}, check)
+		})
+	}
+
+	// without the provider, currencies could not be exchanged
+	_, err := NewInterpreter("test").LoadScript([]rune("以$1（兑换：“CNY”）")).Execute(runtime.ElementMap{})
+	if code := getRuntimeErrorCode(err); code != zerr.ErrArithExchangeRateNotFound {
+		t.Errorf("expect error code %d, got %v", zerr.ErrArithExchangeRateNotFound, err)
+	}
+}
+
+func TestEvalDateTime(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+		errCode  int
+	}{
+		{
+			name:     "literals",
+			code:     "【2024-03-15，2024年3月5日，2024年3月15日8时30分，2024年3月15日20点】",
+			expected: "[2024-03-15，2024-03-05，2024-03-15 08:30:00，2024-03-15 20:00:00]",
+		},
+		{
+			name:     "arithmetic",
+			code:     "令A = 2024-02-27\n【A + 3天，A - 1周，A + 36h，2024年3月15日8时30分 - 90min，2024-03-01 - A，2024年3月15日8时30分 - 2024-03-15】",
+			expected: "[2024-03-01，2024-02-20，2024-02-28 12:00:00，2024-03-15 07:00:00，3天，510分钟]",
+		},
+		{
+			name:     "compare",
+			code:     "【2024-03-15 < 2024年3月15日8时，2024-03-15 == 2024年3月15日，2024-03-15 为 2024-03-15，2024-03-15 为 1】",
+			expected: "[真，真，真，假]",
+		},
+		{
+			name:     "properties & methods",
+			code:     "令A = 2024年1月31日8时30分\n【A之年，A之月，A之日，A之星期，A之时，A之分，A之日期，以A（加月：1），以A（格式化：“YYYY年M月D日 W”）】",
+			expected: "[2024，1，31，3，8，30，2024-01-31，2024-02-29 08:30:00，2024年1月31日 星期三]",
+		},
+		{
+			name:     "timezones",
+			code:     "令A = （新建日期时间：“2024-03-15T08:30:00+08:00”）\n【以A（转时区：“UTC”），以A（格式化：“hh:mm ZZ”、“Asia/Tokyo”），以A（转时区：“UTC”）之时区】",
+			expected: "[2024-03-15 00:30:00，09:30 +09:00，UTC]",
+		},
+		{
+			name:     "constructors",
+			code:     "【（新建日期：“2024年3月5日”、“YYYY年M月D日”），（新建日期：2024、2、29），（新建日期时间：2024、3、15、8、30）】",
+			expected: "[2024-03-05，2024-02-29，2024-03-15 08:30:00]",
+		},
+		{
+			name:    "add plain number",
+			code:    "2024-03-15 + 3",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:    "add two dates",
+			code:    "2024-03-15 + 2024-03-15",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+	}
 
-			tt.expectLogic(vm, t)
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			check := expectResult(tt.expected)
+			if tt.errCode != 0 {
+				check = expectErrorCode(tt.errCode)
+			}
+			runBothEngines(t, tt.code, runtime.ElementMap{}, nil, check)
 		})
 	}
+
+	// invalid dates are rejected before execution
+	_, err := NewInterpreter("test").LoadScript([]rune("2023-02-29")).Execute(runtime.ElementMap{})
+	if err == nil {
+		t.Errorf("expect error for invalid date, got nil")
+	}
 }
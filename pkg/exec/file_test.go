@@ -0,0 +1,90 @@
+package exec
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	zio "github.com/DemoHn/Zn/pkg/io"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+	libFile "github.com/DemoHn/Zn/stdlib/file"
+)
+
+// encodeText - encode the text (e.g. in GB18030) for test files
+func encodeText(t *testing.T, text string, encoding string) []byte {
+	enc, err := zio.GetEncoding(encoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := enc.NewWriter(&buf).Write([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFileLibrary_Encoding(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "客户.txt")
+	if err := os.WriteFile(inputFile, encodeText(t, "上海某某贸易有限公司，𠀀", "GB18030"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outputFile := filepath.Join(dir, "输出.txt")
+
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "read GB18030 file",
+			code:     "（读取文件：文件、“GB18030”）",
+			expected: "上海某某贸易有限公司，𠀀",
+		},
+		{
+			name:     "write & read GBK file",
+			code:     "（写入文件：目标、“上海，浦东”、“gbk”）\n（读取文件：目标、“GBK”）",
+			expected: "上海，浦东",
+		},
+		{
+			name:     "write chars out of GBK",
+			code:     "（写入文件：目标、“𠀀”、“GBK”）",
+			hasError: true,
+		},
+		{
+			name:     "unsupported encoding",
+			code:     "（读取文件：文件、“Big5”）",
+			hasError: true,
+		},
+		{
+			name:     "too many params",
+			code:     "（读取文件：文件、“GBK”、“UTF-8”）",
+			hasError: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			check := expectResult(tt.expected)
+			if tt.hasError {
+				check = expectError()
+			}
+			input := r.ElementMap{"文件": value.NewString(inputFile), "目标": value.NewString(outputFile)}
+			runBothEngines(t, "导入《@文件》\n输入文件、目标\n"+tt.code, input, func(z *Interpreter) *Interpreter {
+				return z.SetExternalLibs([]*r.Library{libFile.Export()})
+			}, check)
+		})
+	}
+
+	// the file is written in GBK
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte("\xC9\xCF\xBA\xA3\xA3\xAC\xC6\xD6\xB6\xAB")) {
+		t.Errorf("expect the file is written in GBK, got %q", data)
+	}
+}
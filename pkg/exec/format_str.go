@@ -2,6 +2,8 @@ package exec
 
 import (
 	"fmt"
+	"math/big"
+	"strconv"
 	"strings"
 
 	zerr "github.com/DemoHn/Zn/pkg/error"
@@ -115,11 +117,21 @@ func formatString(formatStr *value.String, params *value.Array) (*value.String,
     1b. '#.N' -> format numbers, where N is the number of digits after the decimal point.
 
     1c. '#+' -> format numbers, add a '+' sign for positive numbers and 0
+
+ 2. start with '$' - only Currencies are allowed to format
+    2a. '$' -> format currencies with the symbol and thousands separators, e.g. USD$1234.5 --> "$1,234.50"
+
+    2b. '$.N' -> same as above, but rounded to N digits after the decimal point (四舍五入)
+
+ 3. start with '@' - only DateTimes are allowed to format
+    3a. '@' -> same as the default format, e.g. 2024-03-15 08:30:00
+
+    3b. '@LAYOUT' -> format by the layout, e.g. '@YYYY年M月D日' --> "2024年3月15日" (see 以X（格式化：格式）for all tokens)
 */
 func elementToString(formatter string, elem r.Element) (string, error) {
 	if formatter == "" {
 		switch elem.(type) {
-		case *value.String, *value.Number, *value.Bool, *value.Array, *value.HashMap, *value.Null:
+		case *value.String, *value.Number, *value.Decimal, *value.Currency, *value.DateTime, *value.Bool, *value.Array, *value.HashMap, *value.Null:
 			return elem.String(), nil
 		default:
 			return "", zerr.InvalidParamType("")
@@ -128,18 +140,36 @@ func elementToString(formatter string, elem r.Element) (string, error) {
 
 	// if formatter starts from #
 	if strings.HasPrefix(formatter, "#") {
-		num, ok := elem.(*value.Number)
-		if !ok {
-			return "", zerr.NewErrorSLOT("格式化字符串只能用于数字")
+		switch elem.(type) {
+		case *value.Number, *value.Decimal:
+			return parseNumberFormatter(formatter[1:], elem)
+		}
+		return "", zerr.NewErrorSLOT("格式化字符串只能用于数字")
+	}
+
+	// if formatter starts from $
+	if strings.HasPrefix(formatter, "$") {
+		if cur, ok := elem.(*value.Currency); ok {
+			return parseCurrencyFormatter(formatter[1:], cur)
 		}
+		return "", zerr.NewErrorSLOT("格式化字符串只能用于货币")
+	}
 
-		return parseNumberFormatter(formatter[1:], num)
+	// if formatter starts from @
+	if strings.HasPrefix(formatter, "@") {
+		if dt, ok := elem.(*value.DateTime); ok {
+			if formatter == "@" {
+				return dt.String(), nil
+			}
+			return dt.Format(formatter[1:]), nil
+		}
+		return "", zerr.NewErrorSLOT("格式化字符串只能用于日期")
 	}
 
 	return "", zerr.NewErrorSLOT("无效的格式化字符串")
 }
 
-func parseNumberFormatter(formatter string, value *value.Number) (string, error) {
+func parseNumberFormatter(formatter string, elem r.Element) (string, error) {
 	// formatter: [+][.precision][E|%]
 	const (
 		sBegin          = 1
@@ -198,6 +228,9 @@ func parseNumberFormatter(formatter string, value *value.Number) (string, error)
 				switch state {
 				case sFixedSign:
 					numFixedPrecision = numFixedPrecision*10 + int(ch-'0')
+					if numFixedPrecision > value.DecimalMaxScale {
+						return "", zerr.NewErrorSLOT("无效的格式化字符串")
+					}
 				default:
 					return "", zerr.NewErrorSLOT("无效的格式化字符串")
 				}
@@ -225,8 +258,71 @@ func parseNumberFormatter(formatter string, value *value.Number) (string, error)
 	}
 
 	// 3. stringify number
+	var num float64
+	var unitSuffix string
+	switch v := elem.(type) {
+	case *value.Number:
+		// numbers with unit are rendered with their unit, e.g. {#.2} of 3.14159km -> 3.14km
+		num = v.GetValue()
+		unitSuffix = v.GetUnit().String()
+	case *value.Decimal:
+		// for fixed-point formats (e.g. {#.2}), decimals are rounded exactly instead of
+		// being converted to float64
+		if flagFixed && !flagScientific {
+			return formatFixedDecimal(v, numFixedPrecision, flagPositive, flagPercent), nil
+		}
+		num = v.Float64()
+	}
 	if flagPercent { // multiply 100 for percentage, then add "%"
-		return fmt.Sprintf(fmtStr, value.GetValue()*100) + "%", nil
+		return fmt.Sprintf(fmtStr, num*100) + "%", nil
+	}
+	return fmt.Sprintf(fmtStr, num) + unitSuffix, nil
+}
+
+// parseCurrencyFormatter - format currencies with the symbol and thousands separators
+func parseCurrencyFormatter(formatter string, cur *value.Currency) (string, error) {
+	amount := cur.GetAmount()
+	// formatter: [.precision]
+	if formatter != "" {
+		precision, err := strconv.Atoi(strings.TrimPrefix(formatter, "."))
+		if !strings.HasPrefix(formatter, ".") || err != nil || precision < 0 || precision > value.DecimalMaxScale {
+			return "", zerr.NewErrorSLOT("无效的格式化字符串")
+		}
+		amount = amount.Round(precision, value.RoundHalfUp)
+	}
+
+	digits := amount.String()
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	intPart, fracPart, hasDot := strings.Cut(digits, ".")
+
+	// add thousands separators, e.g. 1234567 -> 1,234,567
+	var sb strings.Builder
+	for i, ch := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteRune(',')
+		}
+		sb.WriteRune(ch)
+	}
+	if hasDot {
+		sb.WriteString("." + fracPart)
+	}
+	return sign + cur.GetSymbol() + sb.String(), nil
+}
+
+// formatFixedDecimal - format decimals with N digits after the decimal point (四舍五入)
+func formatFixedDecimal(dec *value.Decimal, precision int, flagPositive bool, flagPercent bool) string {
+	if flagPercent {
+		dec = dec.Mul(value.NewDecimal(big.NewInt(100), 0))
+	}
+	result := dec.Round(precision, value.RoundHalfUp).String()
+	if flagPositive && !strings.HasPrefix(result, "-") {
+		result = "+" + result
+	}
+	if flagPercent {
+		result += "%"
 	}
-	return fmt.Sprintf(fmtStr, value.GetValue()), nil
+	return result
 }
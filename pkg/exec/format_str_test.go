@@ -2,6 +2,7 @@ package exec
 
 import (
 	"testing"
+	"time"
 
 	"github.com/DemoHn/Zn/pkg/runtime"
 	"github.com/DemoHn/Zn/pkg/value"
@@ -141,3 +142,104 @@ func TestFormatStr_Number(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatStr_Decimal(t *testing.T) {
+	dec := func(s string) runtime.Element {
+		d, _ := value.NewDecimalFromString(s)
+		return d
+	}
+	cases := []fmtCase{
+		{
+			"{}|{#.2}|{#+.1}|{#.1%}",
+			[]runtime.Element{
+				dec("1234567890123456.125"),
+				dec("1234567890123456.125"),
+				dec("0.05"),
+				dec("0.12345"),
+			},
+			"1234567890123456.125|1234567890123456.13|+0.1|12.3%",
+		},
+		{
+			"{#}{#.2E}",
+			[]runtime.Element{
+				dec("12.3456789"),
+				dec("1234.5"),
+			},
+			"12.34571.23E+03",
+		},
+	}
+
+	for _, c := range cases {
+		paramArr := value.NewArray(c.params)
+		res, err := formatString(value.NewString(c.formatter), paramArr)
+
+		if err != nil {
+			t.Errorf("formatString('%s'): expect '%s', got error: %s", c.formatter, c.expected, err.Error())
+		} else if res.String() != c.expected {
+			t.Errorf("formatString('%s'): expect '%s', result: '%s'", c.formatter, c.expected, res.String())
+		}
+	}
+}
+
+func TestFormatStr_Currency(t *testing.T) {
+	cur := func(code string, amount string) runtime.Element {
+		d, _ := value.NewDecimalFromString(amount)
+		return value.NewCurrency(code, d)
+	}
+	cases := []fmtCase{
+		{
+			"{$}|{$.0}|{$}|{}",
+			[]runtime.Element{
+				cur("USD", "1234567.5"),
+				cur("CNY", "-8.5"),
+				cur("THB", "100"),
+				cur("HKD", "3"),
+			},
+			"$1,234,567.50|-¥9|THB 100.00|HKD$3.00",
+		},
+	}
+
+	for _, c := range cases {
+		paramArr := value.NewArray(c.params)
+		res, err := formatString(value.NewString(c.formatter), paramArr)
+
+		if err != nil {
+			t.Errorf("formatString('%s'): expect '%s', got error: %s", c.formatter, c.expected, err.Error())
+		} else if res.String() != c.expected {
+			t.Errorf("formatString('%s'): expect '%s', result: '%s'", c.formatter, c.expected, res.String())
+		}
+	}
+
+	// {$} is for currencies only
+	if _, err := formatString(value.NewString("{$}"), value.NewArray([]runtime.Element{value.NewNumber(1)})); err == nil {
+		t.Errorf("formatString('{$}') with a number: expect error")
+	}
+}
+
+func TestFormatStr_DateTime(t *testing.T) {
+	loc, _ := value.LoadLocation("+08:00")
+	dt := value.NewDateTime(time.Date(2024, 3, 5, 8, 30, 0, 0, loc), false)
+	cases := []fmtCase{
+		{
+			"{}|{@}|{@YYYY年M月D日 W}|{@hh:mm ZZ}",
+			[]runtime.Element{dt, dt, dt, value.NewDate(2024, 3, 5, loc)},
+			"2024-03-05 08:30:00|2024-03-05 08:30:00|2024年3月5日 星期二|00:00 +08:00",
+		},
+	}
+
+	for _, c := range cases {
+		paramArr := value.NewArray(c.params)
+		res, err := formatString(value.NewString(c.formatter), paramArr)
+
+		if err != nil {
+			t.Errorf("formatString('%s'): expect '%s', got error: %s", c.formatter, c.expected, err.Error())
+		} else if res.String() != c.expected {
+			t.Errorf("formatString('%s'): expect '%s', result: '%s'", c.formatter, c.expected, res.String())
+		}
+	}
+
+	// {@} is for datetimes only
+	if _, err := formatString(value.NewString("{@}"), value.NewArray([]runtime.Element{value.NewNumber(1)})); err == nil {
+		t.Errorf("formatString('{@}') with a number: expect error")
+	}
+}
@@ -2,9 +2,10 @@ package exec
 
 import (
 	"fmt"
-	"math/rand"
+	"math/big"
 	"os"
 	"strings"
+	"time"
 
 	r "github.com/DemoHn/Zn/pkg/runtime"
 	"github.com/DemoHn/Zn/pkg/value"
@@ -17,7 +18,10 @@ var (
 	ZnConstNull           = value.NewNull()
 	ZnConstExceptionClass = newExceptionModel()
 	ZnConstDisplayFunc    = newDisplayFunc()
-	ZnConstGetRandomFloat = newGetRandomFloatFunc()
+	ZnConstGetRandomFloat = newGetRandomFloatFunc(r.NewRandom(time.Now().UnixNano()))
+
+	// ZnConstRuntimeExceptionClasses - built-in exception classes for runtime errors (e.g. 索引异常)
+	ZnConstRuntimeExceptionClasses = newRuntimeExceptionModels(ZnConstExceptionClass)
 )
 
 // globalValues -
@@ -39,22 +43,41 @@ func init() {
 		"显示":   ZnConstDisplayFunc,
 		"取随机数": ZnConstGetRandomFloat,
 		"数值":   &value.Number{},
+		"小数":   value.NewDecimal(big.NewInt(0), 0),
+		"货币":   value.NewCurrency("CNY", value.NewDecimal(big.NewInt(0), 0)),
+		"日期":   value.NewDateTime(time.Unix(0, 0), true),
+		"日期时间": value.NewDateTime(time.Unix(0, 0), false),
+	}
+	for name, model := range ZnConstRuntimeExceptionClasses {
+		globalValues[name] = model
 	}
 
 	GlobalValues = globalValues
 }
 
 func newExceptionModel() *value.ClassModel {
+	model := value.NewClassModel(EVConstExceptionClassName)
 	constructorFunc := func(receiver r.Element, values []r.Element) (r.Element, error) {
 		if err := value.ValidateExactParams(values, "string"); err != nil {
 			return nil, err
 		}
 
 		message := values[0].(*value.String)
+		// for subclasses of 异常 (定义X继承异常), set 内容 of the instance directly
+		if obj, ok := receiver.(*value.Object); ok && obj.GetModel() != model {
+			if err := obj.SetProperty(EVConstExceptionContentProperty, message); err != nil {
+				return nil, err
+			}
+			return obj, nil
+		}
 		return value.NewException(message.String()), nil
 	}
 
-	return value.NewClassModel("异常").SetConstructor(constructorFunc)
+	return model.
+		DefineProperty(EVConstExceptionContentProperty, value.NewString("")).
+		DefineProperty(EVConstExceptionCodeProperty, value.NewNumber(0)).
+		DefineProperty(EVConstExceptionStackProperty, value.NewEmptyArray()).
+		SetConstructor(constructorFunc)
 }
 
 func newDisplayFunc() *value.Function {
@@ -72,9 +95,21 @@ func newDisplayFunc() *value.Function {
 	return value.NewFunction(displayExecutor)
 }
 
-func newGetRandomFloatFunc() *value.Function {
+// newGlobalValues - global values of an execution, where （取随机数） gets random numbers from
+// the generator of the VM, so that the results could be reproduced by the seed.
+func newGlobalValues(random *r.Random) map[string]r.Element {
+	values := make(map[string]r.Element, len(globalValues))
+	for name, v := range globalValues {
+		values[name] = v
+	}
+	values["取随机数"] = newGetRandomFloatFunc(random)
+	return values
+}
+
+// newGetRandomFloatFunc - （取随机数）, get a random number in [0, 1) from the generator
+func newGetRandomFloatFunc(random *r.Random) *value.Function {
 	getRandomFloatExecutor := func(receiver r.Element, params []r.Element) (r.Element, error) {
-		return value.NewNumber(rand.Float64()), nil
+		return value.NewNumber(random.Float64()), nil
 	}
 
 	return value.NewFunction(getRandomFloatExecutor)
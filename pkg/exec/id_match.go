@@ -1,12 +1,15 @@
 package exec
 
 import (
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	zerr "github.com/DemoHn/Zn/pkg/error"
 	r "github.com/DemoHn/Zn/pkg/runtime"
 	"github.com/DemoHn/Zn/pkg/syntax"
+	"github.com/DemoHn/Zn/pkg/value"
 )
 
 /**
@@ -16,12 +19,14 @@ representation types, including variable name, number, datetime, unit value, eve
 This module aims to breakdown the identifier token to match more specific idTypes for
 further code execution usages: For example, if an identifier is matched as a "number", then it's impossible to be assigned to another value!
 
-So far, we support only two idTypes, which corresponds to its specific format; For other formats of this identifier token, we throw an error as "SemanticError"!
+So far, we support four idTypes, which corresponds to its specific format; For other formats of this identifier token, we throw an error as "SemanticError"!
 
 1. idNumber
 
 ID Format: all char shall be a number ('0-9') or decimal point (.) or exponential indicator (E / *10^) or number flag (+/-); and all char follows the Number Construction Regexp to make it represent ONE number!
 
+A number could be followed by a unit (乘法单位 + optional 除法单位, see 草案14), e.g. `80kg`, `3.6km/h`, `1*10^-6CBM`.
+
 Example:
 ```
 +12.5
@@ -36,9 +41,39 @@ INVALID Example: (will throw SemanticError)
 -1/2
 2.3.5
 ++18
+2km/h/s
+```
+
+2. idCurrency
+
+ID Format: [-] + currency prefix + digits with an optional decimal part (see 草案14). The currency prefix could be a currency code with `$` (e.g. `CNY$`), or a symbol: `$` (USD), `US$`, `HK$`, `¥` (CNY), `€`, `£`.
+
+Example:
+```
+$20.3
+CNY$100
+-¥8.50
+```
+
+3. idDateTime
+
+ID Format: a date as `YYYY-M-D` or `YYYY年M月D日`; the latter could be followed by a time as `h时m分s秒` (`时` could be written as `点`; `分` and `秒` are optional). NOTE: `:` is not allowed in identifiers, thus `08:30` is NOT supported.
+
+Example:
+```
+2024-03-15
+2024年3月15日
+2024年3月15日8时30分
+2024年3月15日20点
+```
+
+INVALID Example: (will throw SemanticError)
+```
+2023-02-29
+2024年3月15日25时
 ```
 
-2. idName
+4. idName
 
 ID Format: the leading chars SHOULD NOT be numbers chars (any valid chars but not 0-9)
 
@@ -56,19 +91,43 @@ _window_handler2
 
 func MatchIDType(id *syntax.ID) (r.IDType, error) {
 	idStr := id.GetLiteral()
-	// #1. match number
-	isNumber, err := tryParseNumber(id)
+	// #1. match datetime (before number, otherwise `2024年3月15日` would be parsed as a number with unit)
+	dt, err := tryParseDateTime(id)
+	if err != nil {
+		return nil, err
+	}
+	if dt != nil {
+		return dt, nil
+	}
+
+	// #2. match number
+	isNumber, unit, err := tryParseNumber(id)
 	if err != nil {
 		return nil, err
 	}
 	if isNumber {
+		numStr := strings.TrimSuffix(idStr, unit)
 		return &r.IDNumber{
 			Literal:  idStr,
-			NumValue: parseIDNumberToFloat64(idStr),
+			NumValue: parseIDNumberToFloat64(numStr),
+			Unit:     unit,
+		}, nil
+	}
+
+	// #3. match currency
+	isCurrency, code, amount, err := tryParseCurrency(id)
+	if err != nil {
+		return nil, err
+	}
+	if isCurrency {
+		return &r.IDCurrency{
+			Literal: idStr,
+			Code:    code,
+			Amount:  amount,
 		}, nil
 	}
 
-	// #2. match name
+	// #4. match name
 	return &r.IDName{
 		Literal: idStr,
 	}, nil
@@ -102,7 +161,9 @@ func MatchIDNumber(id *syntax.ID) (*r.IDNumber, error) {
 
 // regex: ^[-+]?[0-9]+\.?[0-9]+((([eE][-+])|(\*(10)?\^[-+]?))[0-9]+)?$
 // ref: https://github.com/DemoHn/Zn/issues/4
-func tryParseNumber(id *syntax.ID) (bool, error) {
+//
+// the remaining chars after the number are returned as unit (e.g. `128kg` -> "kg")
+func tryParseNumber(id *syntax.ID) (bool, string, error) {
 	charArr := []rune(id.GetLiteral())
 
 	// hand-written regex parser
@@ -212,22 +273,106 @@ func tryParseNumber(id *syntax.ID) (bool, error) {
 end:
 	// NO chars parsed, maybe the token is idName (instead of idNumber)
 	if parsedChars == 0 {
-		return false, nil
+		return false, "", nil
 	}
 	// only + or - is parsed
 	if state == sIntPMFlag {
-		return false, nil
+		return false, "", nil
 	}
 	// Parsing flow NOT FINISH: e.g. `15.` got error where no number after decimal point
 	if !syntax.ContainsInt(state, endStates) {
-		return false, zerr.InvalidIDFormat(id.GetLiteral())
+		return false, "", zerr.InvalidIDFormat(id.GetLiteral())
+	}
+	// There're still characters after number: e.g. `128kg` - parse them as unit
+	unit := string(charArr[parsedChars:])
+	if _, err := value.ParseUnit(unit); err != nil {
+		return false, "", zerr.InvalidIDFormat(id.GetLiteral())
+	}
+
+	return true, unit, nil
+}
+
+// tryParseCurrency - parse currency literals like `$20.3`, `CNY$100`, `-¥8.50`.
+// If the chars after the currency prefix are not started with a digit (e.g. `$abc`),
+// it's still an idName.
+func tryParseCurrency(id *syntax.ID) (bool, string, string, error) {
+	literal := id.GetLiteral()
+	sign := ""
+	if strings.HasPrefix(literal, "-") {
+		sign, literal = "-", literal[1:]
+	}
+
+	// find the prefix: all chars before the first digit
+	idx := strings.IndexFunc(literal, func(ch rune) bool { return ch >= '0' && ch <= '9' })
+	if idx <= 0 {
+		return false, "", "", nil
+	}
+	code, ok := value.ParseCurrencyPrefix(literal[:idx])
+	if !ok {
+		return false, "", "", nil
+	}
+
+	// amount: digits with an optional decimal part
+	amount := literal[idx:]
+	intPart, fracPart, hasDot := strings.Cut(amount, ".")
+	if !isDigits(intPart) || (hasDot && !isDigits(fracPart)) {
+		return false, "", "", zerr.InvalidIDFormat(id.GetLiteral())
+	}
+	return true, code, sign + amount, nil
+}
+
+var (
+	dateHyphenRegex = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	dateZhRegex     = regexp.MustCompile(`^(\d{4})年(\d{1,2})月(\d{1,2})日(?:(\d{1,2})[时点](?:(\d{1,2})分(?:(\d{1,2})秒)?)?)?$`)
+	datePrefixRegex = regexp.MustCompile(`^\d{4}[-年]`)
+)
+
+// tryParseDateTime - parse date & datetime literals like `2024-03-15`, `2024年3月15日8时30分`.
+// If the literal looks like a date (4 digits followed by `-` or `年`) but it's not a valid one
+// (e.g. `2023-02-29`), an error is thrown.
+func tryParseDateTime(id *syntax.ID) (*r.IDDateTime, error) {
+	literal := id.GetLiteral()
+	if !datePrefixRegex.MatchString(literal) {
+		return nil, nil
+	}
+
+	matches := dateHyphenRegex.FindStringSubmatch(literal)
+	if matches == nil {
+		matches = dateZhRegex.FindStringSubmatch(literal)
+	}
+	if matches == nil {
+		return nil, zerr.InvalidIDFormat(literal)
+	}
+
+	fields := [6]int{}
+	for i, m := range matches[1:] {
+		fields[i], _ = strconv.Atoi(m)
 	}
-	// There're still characters after number: e.g. `128kg`
-	if parsedChars < len(charArr) {
-		return false, zerr.InvalidIDFormat(id.GetLiteral())
+	if !value.IsValidDate(fields[0], fields[1], fields[2]) || fields[3] > 23 || fields[4] > 59 || fields[5] > 59 {
+		return nil, zerr.InvalidIDFormat(literal)
 	}
+	return &r.IDDateTime{
+		Literal:  literal,
+		Year:     fields[0],
+		Month:    fields[1],
+		Day:      fields[2],
+		Hour:     fields[3],
+		Minute:   fields[4],
+		Second:   fields[5],
+		DateOnly: len(matches) < 5 || matches[4] == "",
+	}, nil
+}
 
-	return true, nil
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 func parseIDNumberToFloat64(idStr string) float64 {
@@ -237,3 +382,27 @@ func parseIDNumberToFloat64(idStr string) float64 {
 	f, _ := strconv.ParseFloat(v, 64)
 	return f
 }
+
+// newIDNumberValue - build the number value (with unit) from an idNumber
+func newIDNumberValue(id *r.IDNumber) (*value.Number, error) {
+	unit, err := value.ParseUnit(id.Unit)
+	if err != nil {
+		return nil, err
+	}
+	return value.NewNumberWithUnit(id.GetValue(), unit), nil
+}
+
+// newIDCurrencyValue - build the currency value from an idCurrency
+func newIDCurrencyValue(id *r.IDCurrency) (*value.Currency, error) {
+	amount, err := value.NewDecimalFromString(id.Amount)
+	if err != nil {
+		return nil, zerr.InvalidIDFormat(id.GetLiteral())
+	}
+	return value.NewCurrency(id.Code, amount), nil
+}
+
+// newIDDateTimeValue - build the date (or datetime) value from an idDateTime, in the local timezone
+func newIDDateTimeValue(id *r.IDDateTime) *value.DateTime {
+	t := time.Date(id.Year, time.Month(id.Month), id.Day, id.Hour, id.Minute, id.Second, 0, time.Local)
+	return value.NewDateTime(t, id.DateOnly)
+}
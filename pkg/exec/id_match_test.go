@@ -2,8 +2,10 @@ package exec
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
+	"github.com/DemoHn/Zn/pkg/runtime"
 	"github.com/DemoHn/Zn/pkg/syntax"
 )
 
@@ -11,6 +13,7 @@ type tryMatchNumberCase struct {
 	literal      string
 	expectError  bool
 	expectResult bool
+	expectUnit   string
 }
 
 func TestTryMatchNumber(t *testing.T) {
@@ -72,15 +75,44 @@ func TestTryMatchNumber(t *testing.T) {
 			expectError:  false,
 			expectResult: false,
 		},
-		// #3. [FAIL] more chars after number
+		// #3. [OK] numbers with unit
 		{
-			literal:      "+2X",
+			literal:      "+2箱",
+			expectError:  false,
+			expectResult: true,
+			expectUnit:   "箱",
+		},
+		{
+			literal:      "25.8km/h",
+			expectError:  false,
+			expectResult: true,
+			expectUnit:   "km/h",
+		},
+		{
+			literal:      "1*10^-6CBM",
+			expectError:  false,
+			expectResult: true,
+			expectUnit:   "CBM",
+		},
+		{
+			literal:      "80立方米",
+			expectError:  false,
+			expectResult: true,
+			expectUnit:   "立方米",
+		},
+		// #4. [FAIL] invalid chars after number
+		{
+			literal:      "2km/h/s",
 			expectError:  true,
 			expectResult: false,
 		},
-		// (we don't support unit type yet)
 		{
-			literal:      "25.8km/h",
+			literal:      "5kg/",
+			expectError:  true,
+			expectResult: false,
+		},
+		{
+			literal:      "2-3",
 			expectError:  true,
 			expectResult: false,
 		},
@@ -89,6 +121,16 @@ func TestTryMatchNumber(t *testing.T) {
 			expectError:  true,
 			expectResult: false,
 		},
+		{
+			literal:      "10%3",
+			expectError:  true,
+			expectResult: false,
+		},
+		{
+			literal:      "2x",
+			expectError:  true,
+			expectResult: false,
+		},
 	}
 
 	for idx, tt := range cases {
@@ -96,7 +138,7 @@ func TestTryMatchNumber(t *testing.T) {
 			id := &syntax.ID{}
 			id.SetLiteral([]rune(tt.literal))
 
-			res, err := tryParseNumber(id)
+			res, unit, err := tryParseNumber(id)
 			if (tt.expectError == false && err != nil) || (tt.expectError == true && err == nil) {
 				t.Errorf("expect no error, got error: %s", err)
 				return
@@ -105,6 +147,83 @@ func TestTryMatchNumber(t *testing.T) {
 			if res != tt.expectResult {
 				t.Errorf("expect result = %v, got %v", tt.expectResult, res)
 			}
+			if unit != tt.expectUnit {
+				t.Errorf("expect unit = %s, got %s", tt.expectUnit, unit)
+			}
+		})
+	}
+}
+
+func TestTryMatchCurrency(t *testing.T) {
+	cases := []struct {
+		literal      string
+		expectError  bool
+		expectResult bool
+		expectCode   string
+		expectAmount string
+	}{
+		{literal: "$20.3", expectResult: true, expectCode: "USD", expectAmount: "20.3"},
+		{literal: "CNY$100", expectResult: true, expectCode: "CNY", expectAmount: "100"},
+		{literal: "HK$2.50", expectResult: true, expectCode: "HKD", expectAmount: "2.50"},
+		{literal: "-¥8.5", expectResult: true, expectCode: "CNY", expectAmount: "-8.5"},
+		{literal: "$abc", expectResult: false},
+		{literal: "XY$12", expectResult: false},
+		{literal: "$20.3.4", expectError: true},
+		{literal: "$20kg", expectError: true},
+	}
+
+	for idx, tt := range cases {
+		t.Run(fmt.Sprintf("test TryMatchCurrency#%d", idx+1), func(t *testing.T) {
+			id := &syntax.ID{}
+			id.SetLiteral([]rune(tt.literal))
+
+			res, code, amount, err := tryParseCurrency(id)
+			if (tt.expectError == false && err != nil) || (tt.expectError == true && err == nil) {
+				t.Errorf("expect error = %v, got error: %v", tt.expectError, err)
+				return
+			}
+			if res != tt.expectResult || code != tt.expectCode || amount != tt.expectAmount {
+				t.Errorf("expect (%v, %s, %s), got (%v, %s, %s)", tt.expectResult, tt.expectCode, tt.expectAmount, res, code, amount)
+			}
+		})
+	}
+}
+
+func TestTryMatchDateTime(t *testing.T) {
+	cases := []struct {
+		literal     string
+		expectError bool
+		expected    *runtime.IDDateTime
+	}{
+		{literal: "2024-03-15", expected: &runtime.IDDateTime{Year: 2024, Month: 3, Day: 15, DateOnly: true}},
+		{literal: "2024-3-5", expected: &runtime.IDDateTime{Year: 2024, Month: 3, Day: 5, DateOnly: true}},
+		{literal: "2024年3月15日", expected: &runtime.IDDateTime{Year: 2024, Month: 3, Day: 15, DateOnly: true}},
+		{literal: "2024年3月15日8时30分", expected: &runtime.IDDateTime{Year: 2024, Month: 3, Day: 15, Hour: 8, Minute: 30}},
+		{literal: "2024年3月15日20点0分5秒", expected: &runtime.IDDateTime{Year: 2024, Month: 3, Day: 15, Hour: 20, Second: 5}},
+		{literal: "2024", expected: nil},
+		{literal: "2024kg", expected: nil},
+		{literal: "2023-02-29", expectError: true},
+		{literal: "2024-13-01", expectError: true},
+		{literal: "2024年3月15日25时", expectError: true},
+		{literal: "2024-03-15x", expectError: true},
+	}
+
+	for idx, tt := range cases {
+		t.Run(fmt.Sprintf("test TryMatchDateTime#%d", idx+1), func(t *testing.T) {
+			id := &syntax.ID{}
+			id.SetLiteral([]rune(tt.literal))
+
+			res, err := tryParseDateTime(id)
+			if (tt.expectError == false && err != nil) || (tt.expectError == true && err == nil) {
+				t.Errorf("expect error = %v, got error: %v", tt.expectError, err)
+				return
+			}
+			if tt.expected != nil {
+				tt.expected.Literal = tt.literal
+			}
+			if !reflect.DeepEqual(res, tt.expected) {
+				t.Errorf("expect %+v, got %+v", tt.expected, res)
+			}
 		})
 	}
 }
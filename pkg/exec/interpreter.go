@@ -1,11 +1,14 @@
 package exec
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
+	"time"
 
 	zerr "github.com/DemoHn/Zn/pkg/error"
 	"github.com/DemoHn/Zn/pkg/io"
@@ -24,10 +27,60 @@ type Interpreter struct {
 	// by default, the value is nil, that means the finder could not found any module code at all!
 	moduleCodeFinder r.ModuleCodeFinder
 
+	// modulePathFinder - given a module name, find the path of its module file.
+	// it's set by LoadFile() ONLY, so that parsed programs could be cached by path.
+	modulePathFinder func(isMain bool, info r.LibNameInfo) (string, error)
+
+	// moduleNameFinder - the reverse of modulePathFinder: given a module file, find its module names.
+	// it's set by LoadFile() ONLY.
+	moduleNameFinder func(path string) []string
+
+	// programCache - [optional] reuse parsed programs of module files across executions
+	programCache *ProgramCache
+
 	// externalLibs - loadable external libraries for 导入 statement
 	// by default, ALL StandardLibs are included
 	externalLibs []*r.Library
 
+	// vendorPaths - dirs to search vendor packages (imported by 导入《#包名》) in order.
+	// if not set, DefaultVendorPaths() will be used when loading a file.
+	vendorPaths []string
+
+	// execLimits - resource limits (steps, call depth, element sizes) of each execution.
+	// by default, only the call depth is limited.
+	execLimits r.ExecLimits
+
+	// permissionPolicy - restricts libraries, files & hosts that scripts could access.
+	// by default (nil), there's no restriction.
+	permissionPolicy *r.PermissionPolicy
+
+	// rateProvider - [optional] provides exchange rates for currency conversion (兑换).
+	// by default (nil), currencies could not be converted.
+	rateProvider r.ExchangeRateProvider
+
+	// decimalContext - [optional] how decimals are divided.
+	// by default (nil), quotients keep 16 digits after the decimal point at least (四舍五入).
+	decimalContext *r.DecimalContext
+
+	// clock - [optional] provides the current time for libraries (e.g. @时间).
+	// by default (nil), the system clock is used.
+	clock r.Clock
+
+	// randomSeed - [optional] the seed of random numbers (e.g. 取随机数) for each execution,
+	// so that the results are reproducible. by default (nil), it's seeded by the time.
+	randomSeed *int64
+
+	// sourceEncoding - [optional] the encoding of module files (e.g. "GB18030").
+	// by default (empty), it's detected from the content of each file.
+	sourceEncoding string
+
+	// bytecode - execute programs with the bytecode engine instead of walking the AST.
+	// by default, it's disabled.
+	bytecode bool
+
+	// debugHook - [optional] called before evaluating each statement, see Debugger
+	debugHook r.DebugHook
+
 	// mainServer - [optional] the main server instance of this interpreter
 	// we can build the additional server to serve incoming HTTP requests and
 	// send response back.
@@ -66,6 +119,81 @@ func (z *Interpreter) SetExternalLibs(libs []*r.Library) *Interpreter {
 	return z
 }
 
+// SetVendorPaths - set dirs to search vendor packages (in order)
+func (z *Interpreter) SetVendorPaths(paths []string) *Interpreter {
+	z.vendorPaths = paths
+	return z
+}
+
+// SetExecLimits - set resource limits for executing untrusted code
+func (z *Interpreter) SetExecLimits(limits r.ExecLimits) *Interpreter {
+	z.execLimits = limits
+	return z
+}
+
+// SetPermissionPolicy - set the permission policy for executing untrusted code
+func (z *Interpreter) SetPermissionPolicy(policy *r.PermissionPolicy) *Interpreter {
+	z.permissionPolicy = policy
+	return z
+}
+
+// SetExchangeRateProvider - set the provider of exchange rates for currency conversion,
+// e.g. r.FixedExchangeRates for tests
+func (z *Interpreter) SetExchangeRateProvider(provider r.ExchangeRateProvider) *Interpreter {
+	z.rateProvider = provider
+	return z
+}
+
+// SetDecimalContext - set how decimals are divided, e.g. r.DecimalContext{DivisionScale: 4,
+// RoundingMode: value.RoundHalfEven}; nil for the defaults
+func (z *Interpreter) SetDecimalContext(ctx *r.DecimalContext) *Interpreter {
+	z.decimalContext = ctx
+	return z
+}
+
+// SetClock - set the clock of libraries, e.g. r.NewFrozenClock() to freeze the time for tests
+func (z *Interpreter) SetClock(clock r.Clock) *Interpreter {
+	z.clock = clock
+	return z
+}
+
+// SetRandomSeed - set the seed of random numbers, every execution starts with the same sequence
+func (z *Interpreter) SetRandomSeed(seed int64) *Interpreter {
+	z.randomSeed = &seed
+	return z
+}
+
+// SetSourceEncoding - set the encoding of module files loaded by LoadFile(), e.g. "GB18030"
+// for files saved by legacy Windows editors. By default, it's detected from the content.
+func (z *Interpreter) SetSourceEncoding(encoding string) *Interpreter {
+	z.sourceEncoding = encoding
+	return z
+}
+
+// SetProgramCache - reuse parsed programs of the loaded file (and imported modules) across
+// executions. It's useful for long-running servers that execute the same file on every request.
+func (z *Interpreter) SetProgramCache(cache *ProgramCache) *Interpreter {
+	z.programCache = cache
+	return z
+}
+
+// GetProgramCache - get the program cache (nil if not set)
+func (z *Interpreter) GetProgramCache() *ProgramCache {
+	return z.programCache
+}
+
+// SetBytecode - enable/disable the (experimental) bytecode engine
+func (z *Interpreter) SetBytecode(enabled bool) *Interpreter {
+	z.bytecode = enabled
+	return z
+}
+
+// SetDebugHook - call the hook before evaluating each statement (nil to disable)
+func (z *Interpreter) SetDebugHook(hook r.DebugHook) *Interpreter {
+	z.debugHook = hook
+	return z
+}
+
 ///// load functions //////
 
 func (z *Interpreter) LoadScript(source []rune) *Interpreter {
@@ -82,13 +210,14 @@ func (z *Interpreter) LoadScript(source []rune) *Interpreter {
 			return nil, zerr.NewErrorSLOT("在脚本模式下，不支持导入其他模块！")
 		}
 	}
+	z.modulePathFinder = nil
+	z.moduleNameFinder = nil
 
 	return z
 }
 
 func (z *Interpreter) LoadFile(file string) *Interpreter {
-	// set moduleCodeFinder
-	z.moduleCodeFinder = func(isMain bool, info r.LibNameInfo) ([]rune, error) {
+	modulePathFinder := func(isMain bool, info r.LibNameInfo) (string, error) {
 		// get dir & fileName -
 		// e.g. when exec "/home/user/xxxx/module/a.zn":
 		//  - dir=/home/user/xxxx/module
@@ -96,6 +225,11 @@ func (z *Interpreter) LoadFile(file string) *Interpreter {
 		rootDir := filepath.Dir(file)
 		_, fileName := filepath.Split(file)
 
+		vendorPaths := z.vendorPaths
+		if vendorPaths == nil {
+			vendorPaths = DefaultVendorPaths(rootDir)
+		}
+
 		var moduleFullPath string
 
 		if isMain {
@@ -103,11 +237,14 @@ func (z *Interpreter) LoadFile(file string) *Interpreter {
 			moduleFullPath = path.Join(rootDir, fileName)
 		} else {
 			switch info.LibType {
-			case r.LIB_TYPE_STD:
-				return []rune{}, nil // return empty source for STD modules
 			case r.LIB_TYPE_VENDOR:
+				vendorModulePath, err := findVendorModulePath(vendorPaths, info)
+				if err != nil {
+					return "", err
+				}
+				moduleFullPath = vendorModulePath
 			case r.LIB_TYPE_CUSTOM:
-				dirs := info.LibPath
+				dirs := append([]string{}, info.LibPath...)
 				// add .zn for last item
 				dirs[len(dirs)-1] = dirs[len(dirs)-1] + ".zn"
 
@@ -115,11 +252,50 @@ func (z *Interpreter) LoadFile(file string) *Interpreter {
 			}
 		}
 		if _, err := os.Stat(moduleFullPath); os.IsNotExist(err) {
-			return nil, zerr.ModuleNotFound(info.OriginalName)
+			return "", zerr.ModuleNotFound(info.OriginalName)
+		}
+		return moduleFullPath, nil
+	}
+	// set modulePathFinder
+	z.modulePathFinder = modulePathFinder
+
+	// set moduleNameFinder
+	z.moduleNameFinder = func(modulePath string) []string {
+		rootDir := filepath.Dir(file)
+		vendorPaths := z.vendorPaths
+		if vendorPaths == nil {
+			vendorPaths = DefaultVendorPaths(rootDir)
+		}
+
+		if filepath.Clean(modulePath) == filepath.Clean(file) {
+			return []string{MODULE_NAME_MAIN}
+		}
+		// vendor packages might be placed under rootDir (e.g. <rootDir>/zn_vendor), so find them first
+		for _, vendorPath := range vendorPaths {
+			if isPathInDir(vendorPath, modulePath) {
+				return findVendorModuleNames(vendorPaths, modulePath)
+			}
+		}
+		rel, err := filepath.Rel(rootDir, modulePath)
+		if err != nil || !isPathInDir(rootDir, modulePath) || filepath.Ext(rel) != ".zn" {
+			return []string{}
+		}
+		dirs := strings.Split(strings.TrimSuffix(rel, ".zn"), string(filepath.Separator))
+		return []string{strings.Join(dirs, "-")}
+	}
+
+	// set moduleCodeFinder
+	z.moduleCodeFinder = func(isMain bool, info r.LibNameInfo) ([]rune, error) {
+		if !isMain && info.LibType == r.LIB_TYPE_STD {
+			return []rune{}, nil // return empty source for STD modules
+		}
+		moduleFullPath, err := modulePathFinder(isMain, info)
+		if err != nil {
+			return nil, err
 		}
 
 		// read source code from the parsed modulePath
-		in, err := io.NewFileStream(moduleFullPath)
+		in, err := io.NewFileStreamWithEncoding(moduleFullPath, z.sourceEncoding)
 		if err != nil {
 			return nil, err
 		}
@@ -130,33 +306,86 @@ func (z *Interpreter) LoadFile(file string) *Interpreter {
 	return z
 }
 
+// FindModulePath - get the file path of the module (MODULE_NAME_MAIN for the main module).
+// It's available ONLY after LoadFile().
+func (z *Interpreter) FindModulePath(moduleName string) (string, error) {
+	if z.modulePathFinder == nil {
+		return "", zerr.ModuleNotFound(moduleName)
+	}
+	if moduleName == MODULE_NAME_MAIN {
+		return z.modulePathFinder(true, r.LibNameInfo{LibType: r.LIB_TYPE_CUSTOM, LibPath: []string{}})
+	}
+	return z.modulePathFinder(false, r.ParseLibName(moduleName))
+}
+
+// FindModuleNames - get the names of the module loaded from the file path (the main module, a custom
+// module under the dir of main module, or a vendor module); an empty list is returned if the file
+// could not be loaded as a module. It's available ONLY after LoadFile().
+func (z *Interpreter) FindModuleNames(path string) []string {
+	if z.moduleNameFinder == nil {
+		return []string{}
+	}
+	return z.moduleNameFinder(path)
+}
+
 func (z *Interpreter) Execute(varInputs r.ElementMap) (r.Element, error) {
+	return z.ExecuteContext(context.Background(), varInputs)
+}
+
+// ExecuteContext - the same as Execute(), but the execution will be stopped once ctx is done
+// (timeout or cancelled), with an ExecTimeout / ExecCancelled error returned.
+func (z *Interpreter) ExecuteContext(ctx context.Context, varInputs r.ElementMap) (r.Element, error) {
 	// #1. get the main source
 	if z.moduleCodeFinder == nil {
 		return nil, fmt.Errorf("code script/file not loaded")
 	}
 
 	finder := z.moduleCodeFinder
-	// #2. load main module
-	source, err := finder(true, r.LibNameInfo{
+	loader := z.getModuleProgramLoader()
+	// #2. load main module & compile the program -
+	// currently from source code to AST, in the future, we will support compiling to bytecode
+	mainInfo := r.LibNameInfo{
 		OriginalName: "",
 		LibType:      r.LIB_TYPE_CUSTOM,
 		LibPath:      []string{},
-	})
-	if err != nil {
-		return nil, err
 	}
+	var program *syntax.Program
+	if loader != nil {
+		p, err := loader(true, mainInfo)
+		if err != nil {
+			return nil, err
+		}
+		program = p
+	} else {
+		source, err := finder(true, mainInfo)
+		if err != nil {
+			return nil, err
+		}
 
-	// #3. compile the program -
-	// currently from source code to AST, in the future, we will support compiling to bytecode
-	parser := syntax.NewParser(source, zh.NewParserZH())
-	program, err := parser.Compile()
-	if err != nil {
-		return nil, WrapSyntaxError(parser, MODULE_NAME_MAIN, err)
+		parser := syntax.NewParser(source, zh.NewParserZH())
+		p, err := parser.Compile()
+		if err != nil {
+			return nil, WrapSyntaxError(parser, MODULE_NAME_MAIN, err)
+		}
+		program = p
 	}
 
-	vm := r.InitVM(GlobalValues)
+	random := r.NewRandom(time.Now().UnixNano())
+	if z.randomSeed != nil {
+		random = r.NewRandom(*z.randomSeed)
+	}
+	vm := r.InitVM(newGlobalValues(random))
+	vm.SetRandom(random)
+	vm.SetContext(ctx)
+	vm.SetExecLimits(z.execLimits)
+	vm.SetPermissionPolicy(z.permissionPolicy)
+	vm.SetExchangeRateProvider(z.rateProvider)
+	vm.SetDecimalContext(z.decimalContext)
+	vm.SetClock(z.clock)
+	vm.SetBytecode(z.bytecode)
+	vm.SetDebugHook(z.debugHook)
 	vm.SetModuleCodeFinder(finder)
+	vm.SetModuleProgramLoader(loader)
 	vm.LoadExternalLibs(z.externalLibs)
 	// #4. eval program
 	rtnValue, err := EvalMainModule(vm, program, varInputs)
@@ -168,6 +397,25 @@ func (z *Interpreter) Execute(varInputs r.ElementMap) (r.Element, error) {
 	return rtnValue, nil
 }
 
+// getModuleProgramLoader - load module programs from the program cache (if set)
+func (z *Interpreter) getModuleProgramLoader() r.ModuleProgramLoader {
+	cache, pathFinder, encoding := z.programCache, z.modulePathFinder, z.sourceEncoding
+	if cache == nil || pathFinder == nil {
+		return nil
+	}
+	return func(isMain bool, info r.LibNameInfo) (*syntax.Program, error) {
+		moduleName := info.OriginalName
+		if isMain {
+			moduleName = MODULE_NAME_MAIN
+		}
+		modulePath, err := pathFinder(isMain, info)
+		if err != nil {
+			return nil, err
+		}
+		return cache.load(modulePath, moduleName, encoding)
+	}
+}
+
 func (z *Interpreter) ExecuteVarInputText(exprStr string) (r.ElementMap, error) {
 	return ExecVarInputText(exprStr)
 }
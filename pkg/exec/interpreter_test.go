@@ -0,0 +1,221 @@
+package exec
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+func getRuntimeErrorCode(err error) int {
+	if werr, ok := err.(*RuntimeErrorWrapper); ok {
+		if rerr, ok := werr.err.(*zerr.RuntimeError); ok {
+			return rerr.Code
+		}
+	}
+	return 0
+}
+
+func TestExecuteContext_Interrupt(t *testing.T) {
+	cases := []struct {
+		name string
+		code string
+	}{
+		{
+			name: "infinite while loop",
+			code: "令A = 0\n每当真：\n    A = A + 1",
+		},
+		{
+			name: "infinite loop inside function with catch block",
+			code: `
+如何死循环？
+    每当真：
+        令A = 1
+    拦截异常：
+        输出“不应被拦截”
+
+（死循环）`,
+		},
+		{
+			name: "infinite iterate loop",
+			code: `
+令数组 = 【1，2，3】
+每当真：
+    以X遍历数组：
+        继续循环`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			forBothEngines(t, func(t *testing.T, bytecode bool) {
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				defer cancel()
+
+				_, err := NewInterpreter("test").SetBytecode(bytecode).LoadScript([]rune(tt.code)).ExecuteContext(ctx, r.ElementMap{})
+				if code := getRuntimeErrorCode(err); code != zerr.ErrExecTimeout {
+					t.Fatalf("expect ErrExecTimeout, got %v", err)
+				}
+			})
+		})
+	}
+}
+
+func TestExecuteContext_Cancelled(t *testing.T) {
+	forBothEngines(t, func(t *testing.T, bytecode bool) {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+
+		code := "如何循环？\n    每当真：\n        令A = 1\n\n（循环）"
+		_, err := NewInterpreter("test").SetBytecode(bytecode).LoadScript([]rune(code)).ExecuteContext(ctx, r.ElementMap{})
+		if code := getRuntimeErrorCode(err); code != zerr.ErrExecCancelled {
+			t.Fatalf("expect ErrExecCancelled, got %v", err)
+		}
+
+		// the error message should contain the call stack
+		errText := DisplayError(err)
+		for _, expect := range []string{"执行中断[101]", "位于第 5 行", "来自主模块，第 3 行", "令A = 1"} {
+			if !strings.Contains(errText, expect) {
+				t.Errorf("expect error text contains %s, got:\n%s", expect, errText)
+			}
+		}
+	})
+}
+
+func TestExecuteContext_OK(t *testing.T) {
+	forBothEngines(t, func(t *testing.T, bytecode bool) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		result, err := NewInterpreter("test").SetBytecode(bytecode).LoadScript([]rune("令A = 0\n每当A < 10：\n    A = A + 1\n输出A")).ExecuteContext(ctx, r.ElementMap{})
+		expectResult("10")(t, result, err)
+	})
+}
+
+func TestExecLimits(t *testing.T) {
+	cases := []struct {
+		name    string
+		limits  r.ExecLimits
+		code    string
+		errCode int
+	}{
+		{
+			name:    "step budget exhausted",
+			limits:  r.ExecLimits{MaxSteps: 100},
+			code:    "令A = 0\n每当真：\n    A = A + 1",
+			errCode: zerr.ErrExecStepsExceeded,
+		},
+		{
+			name:    "call depth exceeded",
+			limits:  r.ExecLimits{MaxCallDepth: 50},
+			code:    "如何递归？\n    输入N\n    输出（递归：N + 1）\n\n（递归：1）",
+			errCode: zerr.ErrCallDepthExceeded,
+		},
+		{
+			name:    "call depth exceeded by default",
+			limits:  r.ExecLimits{},
+			code:    "如何递归？\n    输入N\n    输出（递归：N + 1）\n\n（递归：1）",
+			errCode: zerr.ErrCallDepthExceeded,
+		},
+		{
+			name:    "array size exceeded",
+			limits:  r.ExecLimits{MaxArraySize: 5},
+			code:    "令A = 【】\n每当真：\n    以A（后增：1）",
+			errCode: zerr.ErrElementSizeExceeded,
+		},
+		{
+			name:    "hashmap size exceeded",
+			limits:  r.ExecLimits{MaxHashMapSize: 2},
+			code:    "令A = 【“X” = 1，“Y” = 2】\nA#{“Z”} = 3",
+			errCode: zerr.ErrElementSizeExceeded,
+		},
+		{
+			name:    "string size exceeded",
+			limits:  r.ExecLimits{MaxStringSize: 16},
+			code:    "令A = “”\n每当真：\n    A = “{}文本” % 【A】",
+			errCode: zerr.ErrElementSizeExceeded,
+		},
+		{
+			name:    "string size exceeded before replacing",
+			limits:  r.ExecLimits{MaxStringSize: 20000},
+			code:    "令S = “aaaaaaaaaa”\n每当真：\n    S = 以S（替换：“a”、S）",
+			errCode: zerr.ErrElementSizeExceeded,
+		},
+		{
+			name:    "string size exceeded before joining",
+			limits:  r.ExecLimits{MaxStringSize: 64},
+			code:    "令S = “0123456789”\n以【S，S，S，S，S，S，S】（拼接：S）",
+			errCode: zerr.ErrElementSizeExceeded,
+		},
+		{
+			name:    "array literal size exceeded",
+			limits:  r.ExecLimits{MaxArraySize: 3},
+			code:    "令A = 【1，2，3，4】",
+			errCode: zerr.ErrElementSizeExceeded,
+		},
+		{
+			name:   "limits could not be caught",
+			limits: r.ExecLimits{MaxSteps: 100},
+			code: `
+如何死循环？
+    每当真：
+        令A = 1
+    拦截异常：
+        输出“不应被拦截”
+
+（死循环）`,
+			errCode: zerr.ErrExecStepsExceeded,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runBothEngines(t, tt.code, r.ElementMap{}, func(z *Interpreter) *Interpreter {
+				return z.SetExecLimits(tt.limits)
+			}, expectErrorCode(tt.errCode))
+		})
+	}
+}
+
+func TestExecLimits_OK(t *testing.T) {
+	limits := r.ExecLimits{MaxSteps: 100, MaxCallDepth: 20, MaxArraySize: 10, MaxHashMapSize: 10, MaxStringSize: 64}
+	code := "令A = 【】\n令B = 0\n每当B < 10：\n    以A（后增：B）\n    B = B + 1\n输出A之数目"
+
+	runBothEngines(t, code, r.ElementMap{}, func(z *Interpreter) *Interpreter {
+		return z.SetExecLimits(limits)
+	}, expectResult("10"))
+}
+
+func TestLoadFile_SourceEncoding(t *testing.T) {
+	root := t.TempDir()
+	mainFile := filepath.Join(root, "主程序.zn")
+	// saved by Notepad in GBK
+	writeTestFiles(t, root, map[string]string{
+		"主程序.zn": string(encodeText(t, "导入《工具》\n输出（添加单位：18）", "GBK")),
+		"工具.zn":  string(encodeText(t, "如何添加单位？\n    输入数\n    输出“{}元” % 【数】", "GBK")),
+	})
+
+	for _, encoding := range []string{"", "GB18030", "GBK"} {
+		for _, cache := range []*ProgramCache{nil, NewProgramCache()} {
+			result, err := NewInterpreter("test").SetSourceEncoding(encoding).SetProgramCache(cache).
+				LoadFile(mainFile).Execute(r.ElementMap{})
+			if err != nil {
+				t.Fatalf("encoding=%s: expect no error, got %v", encoding, err)
+			}
+			if result.String() != "18元" {
+				t.Errorf("encoding=%s: expect result = 18元, got %s", encoding, result.String())
+			}
+		}
+	}
+
+	if _, err := NewInterpreter("test").SetSourceEncoding("Big5").LoadFile(mainFile).Execute(r.ElementMap{}); err == nil {
+		t.Errorf("expect error for unsupported encoding, got nil")
+	}
+}
@@ -0,0 +1,74 @@
+package exec
+
+import (
+	"testing"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	libMath "github.com/DemoHn/Zn/stdlib/math"
+)
+
+// TestMathLibrary - use @数学 in programs; the functions are tested in stdlib/math
+func TestMathLibrary(t *testing.T) {
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+		errCode  int
+	}{
+		{
+			name:     "round with modes",
+			code:     "【（保留：2.675、2），（保留：2.665、2、“银行家舍入”），（保留：$10.125、2、“银行家舍入”），以19.999（保留：1）】",
+			expected: "[2.68，2.66，USD$10.12，20]",
+		},
+		{
+			name:     "max & min",
+			code:     "令价格 = 【12.5，8，20.25】\n【（最大值：价格），（最小值：价格），（最大值：3、7、5），（最小值：1kg、800g、2斤）】",
+			expected: "[20.25，8，7，800g]",
+		},
+		{
+			name:     "clamp & integer checks",
+			code:     "【（限制：120、0、100），（限制：-5、0、100），（是否整数：3），（是否整数：3.5），12之是整数】",
+			expected: "[100，0，真，假，真]",
+		},
+		{
+			name:    "incompatible units",
+			code:    "（最大值：1kg、2m）",
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			check := expectResult(tt.expected)
+			if tt.errCode != 0 {
+				check = expectErrorCode(tt.errCode)
+			}
+			runBothEngines(t, "导入《@数学》\n"+tt.code, r.ElementMap{}, func(z *Interpreter) *Interpreter {
+				return z.SetExternalLibs([]*r.Library{libMath.Export()})
+			}, check)
+		})
+	}
+}
+
+func TestMathLibrary_RandomSeed(t *testing.T) {
+	// （取随机数） is also seeded when it's called by native methods (e.g. 映射)
+	code := "导入《@数学》\n【（取随机数），（随机数），（随机整数：1、6），（随机选择：【“甲”，“乙”，“丙”】），以【1，2】（映射：取随机数）】"
+	run := func(seed int64) string {
+		result, err := NewInterpreter("test").SetExternalLibs([]*r.Library{libMath.Export()}).SetRandomSeed(seed).
+			LoadScript([]rune(code)).Execute(r.ElementMap{})
+		if err != nil {
+			t.Fatalf("seed=%d: unexpected error: %v", seed, err)
+		}
+		return result.String()
+	}
+
+	// the same seed gives the same results in every execution
+	expected := run(42)
+	runBothEngines(t, code, r.ElementMap{}, func(z *Interpreter) *Interpreter {
+		return z.SetExternalLibs([]*r.Library{libMath.Export()}).SetRandomSeed(42)
+	}, expectResult(expected))
+	if result := run(7); result == expected {
+		t.Errorf("expect different results for different seeds, got %s", result)
+	}
+}
@@ -0,0 +1,62 @@
+package exec
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	libFile "github.com/DemoHn/Zn/stdlib/file"
+	libJson "github.com/DemoHn/Zn/stdlib/json"
+)
+
+func TestPermissionPolicy_ImportLibrary(t *testing.T) {
+	policy := &r.PermissionPolicy{AllowedLibs: []string{"@JSON"}}
+	setup := func(z *Interpreter) *Interpreter {
+		return z.SetExternalLibs([]*r.Library{libFile.Export(), libJson.Export()}).SetPermissionPolicy(policy)
+	}
+
+	t.Run("allowed library", func(t *testing.T) {
+		runBothEngines(t, "导入《@JSON》\n令A = 1\nA", r.ElementMap{}, setup, expectResult("1"))
+	})
+	t.Run("denied library", func(t *testing.T) {
+		runBothEngines(t, "导入《@文件》\n令A = 1", r.ElementMap{}, setup, expectErrorCode(zerr.ErrPermissionDenied))
+	})
+}
+
+func TestPermissionPolicy_FileRoots(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"数据/A.txt": "内容A",
+	})
+
+	policy := &r.PermissionPolicy{FileRoots: []string{filepath.Join(root, "数据")}}
+	setup := func(z *Interpreter) *Interpreter {
+		return z.SetExternalLibs([]*r.Library{libFile.Export()}).SetPermissionPolicy(policy)
+	}
+
+	readCode := "导入《@文件》\n输出（读取文件：“" + filepath.Join(root, "数据", "A.txt") + "”）"
+	t.Run("read a file inside the root", func(t *testing.T) {
+		runBothEngines(t, readCode, r.ElementMap{}, setup, expectResult("内容A"))
+	})
+
+	// the exception could be caught
+	t.Run("read a file outside the root", func(t *testing.T) {
+		code := "导入《@文件》\n如何读取？\n    （读取文件：“/etc/passwd”）\n    拦截异常：\n        输出其内容\n\n输出（读取）"
+		runBothEngines(t, code, r.ElementMap{}, setup, func(t *testing.T, result r.Element, err error) {
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !strings.Contains(result.String(), "没有权限访问文件") {
+				t.Errorf("expect permission denied exception, got %s", result.String())
+			}
+		})
+	})
+
+	t.Run("the shared library without policy is not affected", func(t *testing.T) {
+		runBothEngines(t, readCode, r.ElementMap{}, func(z *Interpreter) *Interpreter {
+			return z.SetExternalLibs([]*r.Library{libFile.Export()})
+		}, expectResult("内容A"))
+	})
+}
@@ -0,0 +1,172 @@
+package exec
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DemoHn/Zn/pkg/io"
+	"github.com/DemoHn/Zn/pkg/syntax"
+	"github.com/DemoHn/Zn/pkg/syntax/zh"
+)
+
+// ProgramCache - caches parsed programs (AST) of module files across executions, so that
+// a long-running server doesn't need to re-read & re-parse the entry file and all imported
+// modules on every request.
+//
+// Programs are keyed by the absolute path of the module file. A cached program is reused
+// as long as the file is unchanged: the mtime & size of the file are checked first; if
+// they differ, the content hash is compared - so touching a file without changing its
+// content won't invalidate the program.
+//
+// ProgramCache is safe for concurrent use.
+type ProgramCache struct {
+	mu      sync.Mutex
+	entries map[string]*programCacheEntry
+	stats   ProgramCacheStats
+}
+
+type programCacheEntry struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+	program *syntax.Program
+}
+
+// ProgramCacheStats - metrics of a program cache
+type ProgramCacheStats struct {
+	// Hits - number of loads that reuse a cached program
+	Hits int64
+	// Misses - number of loads that parse the file
+	Misses int64
+	// Invalidations - number of cached programs that are dropped because the file is changed
+	Invalidations int64
+	// Entries - number of cached programs
+	Entries int
+	// ParseTime - total time spent on reading & parsing files (on misses)
+	ParseTime time.Duration
+}
+
+// HitRate - hits / (hits + misses); returns 0 if nothing is loaded yet
+func (s ProgramCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// AvgParseTime - average time spent on parsing a file
+func (s ProgramCacheStats) AvgParseTime() time.Duration {
+	if s.Misses == 0 {
+		return 0
+	}
+	return s.ParseTime / time.Duration(s.Misses)
+}
+
+func NewProgramCache() *ProgramCache {
+	return &ProgramCache{
+		entries: map[string]*programCacheEntry{},
+	}
+}
+
+// Load - get the parsed program of the module file. If the file is not cached or has been
+// changed since last load, it will be parsed again. moduleName is used to display syntax errors.
+func (c *ProgramCache) Load(file string, moduleName string) (*syntax.Program, error) {
+	return c.load(file, moduleName, "")
+}
+
+// load - get the parsed program of the module file of the encoding (empty = detect from the content)
+func (c *ProgramCache) load(file string, moduleName string, encoding string) (*syntax.Program, error) {
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// #1. fast path: mtime & size unchanged
+	c.mu.Lock()
+	entry, cached := c.entries[absPath]
+	if cached && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return entry.program, nil
+	}
+	c.mu.Unlock()
+
+	// #2. read the file and compare its content hash
+	startTime := time.Now()
+	in, err := io.NewFileStreamWithEncoding(absPath, encoding)
+	if err != nil {
+		return nil, err
+	}
+	source, err := in.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(string(source)))
+
+	if cached && entry.hash == hash {
+		c.mu.Lock()
+		c.entries[absPath] = &programCacheEntry{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+			hash:    hash,
+			program: entry.program,
+		}
+		c.stats.Hits++
+		c.mu.Unlock()
+		return entry.program, nil
+	}
+
+	// #3. parse the program
+	parser := syntax.NewParser(source, zh.NewParserZH())
+	program, parseErr := parser.Compile()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Misses++
+	c.stats.ParseTime += time.Since(startTime)
+	if cached {
+		c.stats.Invalidations++
+		delete(c.entries, absPath)
+	}
+	if parseErr != nil {
+		return nil, WrapSyntaxError(parser, moduleName, parseErr)
+	}
+	c.entries[absPath] = &programCacheEntry{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		hash:    hash,
+		program: program,
+	}
+	return program, nil
+}
+
+// Invalidate - remove the cached program of the file
+func (c *ProgramCache) Invalidate(file string) {
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[absPath]; ok {
+		delete(c.entries, absPath)
+		c.stats.Invalidations++
+	}
+}
+
+// Stats - get a snapshot of cache metrics
+func (c *ProgramCache) Stats() ProgramCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
@@ -0,0 +1,106 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+func TestProgramCache_Load(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "主程序.zn")
+	writeTestFiles(t, root, map[string]string{
+		"主程序.zn": "输出1",
+	})
+
+	cache := NewProgramCache()
+	program1, err := cache.Load(file, MODULE_NAME_MAIN)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	// unchanged file - reuse the program
+	program2, _ := cache.Load(file, MODULE_NAME_MAIN)
+	if program1 != program2 {
+		t.Errorf("expect the cached program to be reused")
+	}
+
+	// touch the file without changing its content - still reuse the program
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(file, future, future); err != nil {
+		t.Fatalf("chtimes failed: %v", err)
+	}
+	program3, _ := cache.Load(file, MODULE_NAME_MAIN)
+	if program1 != program3 {
+		t.Errorf("expect the cached program to be reused after touching the file")
+	}
+
+	// change the content - parse again
+	writeTestFiles(t, root, map[string]string{
+		"主程序.zn": "输出2",
+	})
+	program4, _ := cache.Load(file, MODULE_NAME_MAIN)
+	if program1 == program4 {
+		t.Errorf("expect the program to be parsed again after changing the file")
+	}
+
+	// syntax errors are not cached
+	writeTestFiles(t, root, map[string]string{
+		"主程序.zn": "令令",
+	})
+	if _, err := cache.Load(file, MODULE_NAME_MAIN); err == nil {
+		t.Errorf("expect syntax error, got nil")
+	}
+
+	stats := cache.Stats()
+	expected := ProgramCacheStats{Hits: 2, Misses: 3, Invalidations: 2, Entries: 0}
+	if stats.Hits != expected.Hits || stats.Misses != expected.Misses ||
+		stats.Invalidations != expected.Invalidations || stats.Entries != expected.Entries {
+		t.Errorf("expect stats = %+v, got %+v", expected, stats)
+	}
+	if stats.HitRate() != 0.4 {
+		t.Errorf("expect hit rate = 0.4, got %v", stats.HitRate())
+	}
+}
+
+func TestProgramCache_Interpreter(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"主程序.zn": "导入《工具》\n输出（计算总价：2、10）",
+		"工具.zn":  "如何计算总价？\n    输入数量、单价\n    输出数量 * 单价",
+	})
+
+	cache := NewProgramCache()
+	interpreter := NewInterpreter("test").SetProgramCache(cache)
+	mainFile := filepath.Join(root, "主程序.zn")
+	for i := 0; i < 3; i++ {
+		result, err := interpreter.LoadFile(mainFile).Execute(r.ElementMap{})
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if result.String() != "20" {
+			t.Errorf("expect result = 20, got %s", result.String())
+		}
+	}
+	// main module & imported module are both cached
+	if stats := cache.Stats(); stats.Misses != 2 || stats.Hits != 4 {
+		t.Errorf("expect 2 misses & 4 hits, got %+v", stats)
+	}
+
+	// the imported module is changed
+	writeTestFiles(t, root, map[string]string{
+		"工具.zn": "如何计算总价？\n    输入数量、单价\n    输出数量 * 单价 + 1",
+	})
+	result, err := interpreter.LoadFile(mainFile).Execute(r.ElementMap{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if result.String() != "21" {
+		t.Errorf("expect result = 21, got %s", result.String())
+	}
+	if stats := cache.Stats(); stats.Invalidations != 1 {
+		t.Errorf("expect 1 invalidation, got %+v", stats)
+	}
+}
@@ -0,0 +1,52 @@
+package exec
+
+import (
+	"testing"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	libRegex "github.com/DemoHn/Zn/stdlib/regex"
+)
+
+// TestRegexLibrary - use @正则 in programs; the executors are tested in stdlib/regex
+func TestRegexLibrary(t *testing.T) {
+	const text = "令文本 = “订单A-1024于3月发货，电话13800138000；订单B-77未发货”\n"
+	cases := []struct {
+		name     string
+		code     string
+		expected string
+		hasError bool
+	}{
+		{
+			name:     "test & find",
+			code:     "令R = （编译正则：“1[3-9]\\d{9}”）\n【以R（测试：文本），以R（查找：文本），（正则查找：“x+”、文本），（正则测试：“^订单”、文本）】",
+			expected: "[真，13800138000，空，真]",
+		},
+		{
+			name:     "new regex & replace",
+			code:     "令R = （新建正则：“订单(?P<类别>[A-Z])-(?P<编号>\\d+)”）\n【以R（替换：文本、“#${编号}”），以R（提取全部：文本）#2#“编号”】",
+			expected: "[#1024于3月发货，电话13800138000；#77未发货，77]",
+		},
+		{
+			name:     "pattern property",
+			code:     "（编译正则：“[(?P<x>]”）之模式",
+			expected: "[(?P<x>]",
+		},
+		{
+			name:     "invalid pattern",
+			code:     "（正则测试：“(”、文本）",
+			hasError: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			check := expectResult(tt.expected)
+			if tt.hasError {
+				check = expectError()
+			}
+			runBothEngines(t, "导入《@正则》\n"+text+tt.code, r.ElementMap{}, func(z *Interpreter) *Interpreter {
+				return z.SetExternalLibs([]*r.Library{libRegex.Export()})
+			}, check)
+		})
+	}
+}
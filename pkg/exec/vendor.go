@@ -0,0 +1,161 @@
+package exec
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+const (
+	// VendorManifestFile - the manifest file placed at the root dir of each vendor package
+	VendorManifestFile = "zinc-package.json"
+	// VendorLocalDir - project-local vendor dir, relative to the dir of main module
+	VendorLocalDir = "zn_vendor"
+	// VendorUserCacheDir - per-user vendor cache dir, relative to $HOME
+	VendorUserCacheDir = ".zinc/vendor"
+)
+
+// VendorManifest - describes a vendor package, e.g.:
+//
+//	{
+//	    "name": "订单工具",
+//	    "version": "1.2.0",
+//	    "entry": "主模块.zn"
+//	}
+type VendorManifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Entry - the entry module file (relative to the package dir) to load when
+	// importing the package itself (e.g. 导入《#订单工具》)
+	Entry string `json:"entry"`
+}
+
+// DefaultVendorPaths - by default, vendor packages are searched from:
+// 1. <rootDir>/zn_vendor
+// 2. $HOME/.zinc/vendor
+func DefaultVendorPaths(rootDir string) []string {
+	paths := []string{filepath.Join(rootDir, VendorLocalDir)}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, VendorUserCacheDir))
+	}
+	return paths
+}
+
+// ReadVendorManifest - read & validate the manifest file of a vendor package
+func ReadVendorManifest(pkgDir string) (*VendorManifest, error) {
+	pkgName := filepath.Base(pkgDir)
+	data, err := os.ReadFile(filepath.Join(pkgDir, VendorManifestFile))
+	if err != nil {
+		return nil, zerr.InvalidVendorManifest(pkgName, "无法读取"+VendorManifestFile)
+	}
+
+	var manifest VendorManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, zerr.InvalidVendorManifest(pkgName, "解析JSON失败 - "+err.Error())
+	}
+	if manifest.Name != pkgName {
+		return nil, zerr.InvalidVendorManifest(pkgName, "包名「"+manifest.Name+"」与目录名不一致")
+	}
+	if manifest.Version == "" {
+		return nil, zerr.InvalidVendorManifest(pkgName, "未设置版本号")
+	}
+	return &manifest, nil
+}
+
+// findVendorModulePath - get the full path of a vendor module from the first vendor path that
+// contains the package. For LibPath = [P, A, B], the module file is <vendorPath>/P/A/B.zn;
+// for LibPath = [P], the module file is the entry module defined in P's manifest.
+func findVendorModulePath(vendorPaths []string, info r.LibNameInfo) (string, error) {
+	if len(info.LibPath) == 0 || info.LibPath[0] == "" {
+		return "", zerr.ModuleNotFound(info.OriginalName)
+	}
+	pkgName := info.LibPath[0]
+
+	for _, vendorPath := range vendorPaths {
+		pkgDir := filepath.Join(vendorPath, pkgName)
+		if stat, err := os.Stat(pkgDir); err != nil || !stat.IsDir() {
+			continue
+		}
+
+		manifest, err := ReadVendorManifest(pkgDir)
+		if err != nil {
+			return "", err
+		}
+
+		// import the entry module
+		if len(info.LibPath) == 1 {
+			if manifest.Entry == "" {
+				return "", zerr.InvalidVendorManifest(pkgName, "未设置入口模块")
+			}
+			entryPath := filepath.Join(pkgDir, manifest.Entry)
+			if !isPathInDir(pkgDir, entryPath) {
+				return "", zerr.InvalidVendorManifest(pkgName, "入口模块「"+manifest.Entry+"」不在包目录内")
+			}
+			return entryPath, nil
+		}
+
+		dirs := append([]string{}, info.LibPath[1:]...)
+		// add .zn for last item
+		dirs[len(dirs)-1] = dirs[len(dirs)-1] + ".zn"
+		modulePath := filepath.Join(pkgDir, filepath.Join(dirs...))
+		if !isPathInDir(pkgDir, modulePath) {
+			return "", zerr.ModuleNotFound(info.OriginalName)
+		}
+		return modulePath, nil
+	}
+
+	return "", zerr.VendorPackageNotFound(pkgName)
+}
+
+// findVendorModuleNames - the reverse of findVendorModulePath: get the names of the vendor module
+// from its file path. The entry module has two names (e.g. #P and #P-入口), for it could be imported
+// by either one. The path must be exactly the one found by findVendorModulePath(), i.e. packages with
+// the same name in former vendor paths win.
+func findVendorModuleNames(vendorPaths []string, modulePath string) []string {
+	names := []string{}
+	for _, vendorPath := range vendorPaths {
+		if !isPathInDir(vendorPath, modulePath) {
+			continue
+		}
+		rel, err := filepath.Rel(vendorPath, modulePath)
+		if err != nil || filepath.Ext(rel) != ".zn" {
+			return names
+		}
+		dirs := strings.Split(strings.TrimSuffix(rel, ".zn"), string(filepath.Separator))
+		if len(dirs) < 2 {
+			return names
+		}
+
+		for _, name := range []string{"#" + dirs[0], "#" + strings.Join(dirs, "-")} {
+			path, err := findVendorModulePath(vendorPaths, r.ParseLibName(name))
+			if err == nil && filepath.Clean(path) == filepath.Clean(modulePath) {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return names
+}
+
+// resolveVendorImportName - custom modules imported by a vendor module are from the same
+// vendor package, e.g. 导入《工具》 in module "#P-A" refers to module "#P-工具".
+func resolveVendorImportName(importerName string, libName string) string {
+	importer := r.ParseLibName(importerName)
+	if importer.LibType != r.LIB_TYPE_VENDOR || r.ParseLibName(libName).LibType != r.LIB_TYPE_CUSTOM {
+		return libName
+	}
+	return "#" + importer.LibPath[0] + "-" + libName
+}
+
+// isPathInDir - if the (cleaned) path is dir itself or inside dir
+func isPathInDir(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
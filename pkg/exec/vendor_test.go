@@ -0,0 +1,206 @@
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		fullPath := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("write file failed: %v", err)
+		}
+	}
+}
+
+func TestVendorModule_Import(t *testing.T) {
+	projectDir := t.TempDir()
+	userCacheDir := t.TempDir()
+
+	writeTestFiles(t, projectDir, map[string]string{
+		"主程序.zn": "导入《#订单工具》\n导入《#订单工具-折扣》之计算折扣\n导入《#格式工具》\n输出（添加单位：（计算总价：2、10） - （计算折扣：20））",
+		// project-local package
+		"zn_vendor/订单工具/zinc-package.json": `{"name": "订单工具", "version": "1.0.0", "entry": "入口.zn"}`,
+		"zn_vendor/订单工具/入口.zn":             "如何计算总价？\n    输入数量、单价\n    输出数量 * 单价",
+		"zn_vendor/订单工具/折扣.zn":             "如何计算折扣？\n    输入总价\n    输出总价 * 0.1",
+	})
+	// package from per-user cache
+	writeTestFiles(t, userCacheDir, map[string]string{
+		"格式工具/zinc-package.json": `{"name": "格式工具", "version": "0.2.0", "entry": "主模块.zn"}`,
+		"格式工具/主模块.zn":            "如何添加单位？\n    输入数\n    输出“{}元” % 【数】",
+	})
+
+	interpreter := NewInterpreter("test").
+		SetVendorPaths([]string{filepath.Join(projectDir, VendorLocalDir), userCacheDir})
+
+	result, err := interpreter.LoadFile(filepath.Join(projectDir, "主程序.zn")).Execute(r.ElementMap{})
+	if err != nil {
+		t.Fatalf("expect no error, got: %v", err)
+	}
+	if result.String() != "18元" {
+		t.Errorf("expect result = 18元, got %s", result.String())
+	}
+}
+
+func TestVendorModule_ImportCustomModule(t *testing.T) {
+	projectDir := t.TempDir()
+	writeTestFiles(t, projectDir, map[string]string{
+		// 《工具》 of the main module & of the vendor package are different modules
+		"主程序.zn": "导入《工具》\n导入《#订单工具》\n【（名称），（计算总价：2、10）】",
+		"工具.zn":  "如何名称？\n    输出“主程序工具”",
+		"zn_vendor/订单工具/zinc-package.json": `{"name": "订单工具", "version": "1.0.0", "entry": "入口.zn"}`,
+		"zn_vendor/订单工具/入口.zn":             "导入《工具》之乘\n如何计算总价？\n    输入数量、单价\n    输出（乘：数量、单价）",
+		"zn_vendor/订单工具/工具.zn":             "如何乘？\n    输入A、B\n    输出A * B",
+	})
+
+	interpreter := NewInterpreter("test").
+		SetVendorPaths([]string{filepath.Join(projectDir, VendorLocalDir)})
+
+	result, err := interpreter.LoadFile(filepath.Join(projectDir, "主程序.zn")).Execute(r.ElementMap{})
+	if err != nil {
+		t.Fatalf("expect no error, got: %v", err)
+	}
+	if result.String() != "[主程序工具，20]" {
+		t.Errorf("expect result = [主程序工具，20], got %s", result.String())
+	}
+}
+
+func TestVendorModule_FAIL(t *testing.T) {
+	cases := []struct {
+		name    string
+		files   map[string]string
+		errCode int
+	}{
+		{
+			name: "package not found",
+			files: map[string]string{
+				"主程序.zn": "导入《#不存在的包》",
+			},
+			errCode: zerr.ErrVendorPackageNotFound,
+		},
+		{
+			name: "manifest not found",
+			files: map[string]string{
+				"主程序.zn":            "导入《#工具》",
+				"zn_vendor/工具/A.zn": "令A = 1",
+			},
+			errCode: zerr.ErrInvalidVendorManifest,
+		},
+		{
+			name: "package name mismatch",
+			files: map[string]string{
+				"主程序.zn":                         "导入《#工具》",
+				"zn_vendor/工具/zinc-package.json": `{"name": "其他工具", "version": "1.0.0", "entry": "A.zn"}`,
+				"zn_vendor/工具/A.zn":              "令A = 1",
+			},
+			errCode: zerr.ErrInvalidVendorManifest,
+		},
+		{
+			name: "no entry module",
+			files: map[string]string{
+				"主程序.zn":                         "导入《#工具》",
+				"zn_vendor/工具/zinc-package.json": `{"name": "工具", "version": "1.0.0"}`,
+			},
+			errCode: zerr.ErrInvalidVendorManifest,
+		},
+		{
+			name: "entry module outside the package",
+			files: map[string]string{
+				"主程序.zn":                         "导入《#工具》",
+				"zn_vendor/工具/zinc-package.json": `{"name": "工具", "version": "1.0.0", "entry": "../../主程序.zn"}`,
+			},
+			errCode: zerr.ErrInvalidVendorManifest,
+		},
+		{
+			name: "module outside the package",
+			files: map[string]string{
+				"主程序.zn":                         "导入《#工具-..-..-秘密》",
+				"秘密.zn":                          "令A = 1",
+				"zn_vendor/工具/zinc-package.json": `{"name": "工具", "version": "1.0.0", "entry": "A.zn"}`,
+			},
+			errCode: zerr.ErrModuleNotFound,
+		},
+		{
+			name: "module not found in package",
+			files: map[string]string{
+				"主程序.zn":                         "导入《#工具-不存在》",
+				"zn_vendor/工具/zinc-package.json": `{"name": "工具", "version": "1.0.0", "entry": "A.zn"}`,
+			},
+			errCode: zerr.ErrModuleNotFound,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			writeTestFiles(t, projectDir, tt.files)
+
+			interpreter := NewInterpreter("test").
+				SetVendorPaths([]string{filepath.Join(projectDir, VendorLocalDir)})
+			_, err := interpreter.LoadFile(filepath.Join(projectDir, "主程序.zn")).Execute(r.ElementMap{})
+			if err == nil {
+				t.Fatalf("expect error, got nil")
+			}
+
+			werr, ok := err.(*RuntimeErrorWrapper)
+			if !ok {
+				t.Fatalf("expect RuntimeErrorWrapper, got %T", err)
+			}
+			if rerr, ok := werr.err.(*zerr.RuntimeError); !ok || rerr.Code != tt.errCode {
+				t.Errorf("expect error code = %d, got %v", tt.errCode, werr.err)
+			}
+		})
+	}
+}
+
+func TestInterpreter_FindModuleNames(t *testing.T) {
+	projectDir := t.TempDir()
+	userCacheDir := t.TempDir()
+	writeTestFiles(t, projectDir, map[string]string{
+		"主程序.zn":   "令A = 1",
+		"模块/工具.zn": "令A = 1",
+		"zn_vendor/订单工具/zinc-package.json": `{"name": "订单工具", "version": "1.0.0", "entry": "入口.zn"}`,
+		"zn_vendor/订单工具/入口.zn":             "令A = 1",
+		"zn_vendor/订单工具/子模块/折扣.zn":         "令A = 1",
+	})
+	writeTestFiles(t, userCacheDir, map[string]string{
+		// shadowed by the project-local package
+		"订单工具/zinc-package.json": `{"name": "订单工具", "version": "0.1.0", "entry": "入口.zn"}`,
+		"订单工具/入口.zn":             "令A = 1",
+		"格式工具/zinc-package.json": `{"name": "格式工具", "version": "0.2.0", "entry": "主模块.zn"}`,
+		"格式工具/其他.zn":             "令A = 1",
+	})
+
+	interpreter := NewInterpreter("test").
+		SetVendorPaths([]string{filepath.Join(projectDir, VendorLocalDir), userCacheDir}).
+		LoadFile(filepath.Join(projectDir, "主程序.zn"))
+
+	cases := []struct {
+		path     string
+		expected []string
+	}{
+		{filepath.Join(projectDir, "主程序.zn"), []string{MODULE_NAME_MAIN}},
+		{filepath.Join(projectDir, "模块/工具.zn"), []string{"模块-工具"}},
+		{filepath.Join(projectDir, "zn_vendor/订单工具/入口.zn"), []string{"#订单工具", "#订单工具-入口"}},
+		{filepath.Join(projectDir, "zn_vendor/订单工具/子模块/折扣.zn"), []string{"#订单工具-子模块-折扣"}},
+		{filepath.Join(projectDir, "zn_vendor/订单工具/zinc-package.json"), []string{}},
+		{filepath.Join(userCacheDir, "订单工具/入口.zn"), []string{}},
+		{filepath.Join(userCacheDir, "格式工具/其他.zn"), []string{"#格式工具-其他"}},
+		{filepath.Join(filepath.Dir(projectDir), "其他.zn"), []string{}},
+	}
+	for _, tt := range cases {
+		names := interpreter.FindModuleNames(tt.path)
+		if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("expect module names of %s = %v, got %v", tt.path, tt.expected, names)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package framing
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadWriteMessage(t *testing.T) {
+	var buf bytes.Buffer
+	for _, msg := range []interface{}{map[string]int{"seq": 1}, "中文"} {
+		if err := WriteMessage(&buf, msg); err != nil {
+			t.Fatalf("write message failed: %v", err)
+		}
+	}
+
+	reader := bufio.NewReader(&buf)
+	for _, expected := range []string{`{"seq":1}`, `"中文"`} {
+		data, err := ReadMessage(reader)
+		if err != nil {
+			t.Fatalf("read message failed: %v", err)
+		}
+		if string(data) != expected {
+			t.Errorf("expect %s, got %s", expected, data)
+		}
+	}
+}
+
+func TestReadMessage_FAIL(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{"missing Content-Length", "Content-Type: application/json\r\n\r\n{}"},
+		{"invalid Content-Length", "Content-Length: abc\r\n\r\n{}"},
+		{"incomplete content", "Content-Length: 10\r\n\r\n{}"},
+		{"incomplete header", "Content-Length: 2\r\n"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadMessage(bufio.NewReader(strings.NewReader(tt.input))); err == nil {
+				t.Errorf("expect error, got nil")
+			}
+		})
+	}
+}
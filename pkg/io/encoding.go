@@ -0,0 +1,97 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding - the text encoding of source files & data files (e.g. CSV files read by @CSV).
+// Texts in Zn are always UTF-8, so an encoding converts the data from/to UTF-8.
+type Encoding interface {
+	// NewReader - wrap the reader of encoded data to get UTF-8 text
+	NewReader(r io.Reader) io.Reader
+	// NewWriter - wrap the writer to write UTF-8 text as encoded data
+	NewWriter(w io.Writer) io.Writer
+}
+
+// utf8BOM - the BOM of UTF-8 (U+FEFF) in bytes
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// encodings - supported encodings; the keys are normalized names (see normalizeEncodingName)
+var encodings = map[string]Encoding{
+	"UTF8":    utf8Encoding{writeBOM: false},
+	"UTF8BOM": utf8Encoding{writeBOM: true},
+	"GB18030": gb18030Encoding{gbk: false},
+	"GBK":     gb18030Encoding{gbk: true},
+	"GB2312":  gb18030Encoding{gbk: true},
+	"CP936":   gb18030Encoding{gbk: true},
+}
+
+// GetEncoding - get the encoding by name, e.g. "UTF-8"; names are case-insensitive and
+// "-", "_" are ignored. An empty name means UTF-8.
+func GetEncoding(name string) (Encoding, error) {
+	if name == "" {
+		return encodings["UTF8"], nil
+	}
+	if enc, ok := encodings[normalizeEncodingName(name)]; ok {
+		return enc, nil
+	}
+	return nil, fmt.Errorf("不支持的编码「%s」", name)
+}
+
+// DetectEncoding - detect the encoding of data: UTF-8 if it starts with the BOM or it's a
+// valid UTF-8 text; otherwise it's regarded as GB18030 (e.g. files saved by Notepad on
+// Windows in simplified Chinese).
+func DetectEncoding(data []byte) Encoding {
+	if bytes.HasPrefix(data, utf8BOM) || utf8.Valid(data) {
+		return encodings["UTF8"]
+	}
+	return encodings["GB18030"]
+}
+
+func normalizeEncodingName(name string) string {
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToUpper(name))
+}
+
+// utf8Encoding - UTF-8, the BOM at the beginning is removed when reading.
+// If writeBOM = true, the BOM is written at the beginning (so that Excel could
+// recognize the encoding of CSV files).
+type utf8Encoding struct {
+	writeBOM bool
+}
+
+func (e utf8Encoding) NewReader(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if head, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(head, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+	return br
+}
+
+func (e utf8Encoding) NewWriter(w io.Writer) io.Writer {
+	if e.writeBOM {
+		return &bomWriter{w: w, bom: utf8BOM}
+	}
+	return w
+}
+
+// bomWriter - write the BOM before the first write
+type bomWriter struct {
+	w       io.Writer
+	bom     []byte
+	written bool
+}
+
+func (b *bomWriter) Write(p []byte) (int, error) {
+	if !b.written {
+		b.written = true
+		if _, err := b.w.Write(b.bom); err != nil {
+			return 0, err
+		}
+	}
+	return b.w.Write(p)
+}
@@ -0,0 +1,154 @@
+package io
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEncoding_UTF8(t *testing.T) {
+	cases := []struct {
+		name     string
+		encoding string
+		data     string
+		expected string
+	}{
+		{name: "default encoding", encoding: "", data: "猪头", expected: "猪头"},
+		{name: "remove BOM", encoding: "utf-8", data: "\xEF\xBB\xBF猪头", expected: "猪头"},
+		{name: "remove BOM only at the beginning", encoding: "UTF8", data: "猪\xEF\xBB\xBF头", expected: "猪\xEF\xBB\xBF头"},
+		{name: "read UTF-8-BOM", encoding: "UTF-8-BOM", data: "\xEF\xBB\xBF猪头", expected: "猪头"},
+		{name: "empty data", encoding: "UTF-8", data: "", expected: ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := GetEncoding(tt.encoding)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			data, err := io.ReadAll(enc.NewReader(strings.NewReader(tt.data)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expect %q, got %q", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestEncoding_WriteBOM(t *testing.T) {
+	for name, expected := range map[string]string{
+		"UTF-8":     "猪头",
+		"utf_8_bom": "\xEF\xBB\xBF猪头",
+	} {
+		enc, err := GetEncoding(name)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		var buf bytes.Buffer
+		w := enc.NewWriter(&buf)
+		w.Write([]byte("猪"))
+		w.Write([]byte("头"))
+		if buf.String() != expected {
+			t.Errorf("%s: expect %q, got %q", name, expected, buf.String())
+		}
+	}
+
+	if _, err := GetEncoding("EBCDIC"); err == nil {
+		t.Errorf("expect error for unsupported encoding, got nil")
+	}
+}
+
+func TestEncoding_GB18030(t *testing.T) {
+	cases := []struct {
+		name    string
+		text    string
+		encoded string
+	}{
+		{name: "ASCII", text: "abc,123", encoded: "abc,123"},
+		{name: "two-byte chars", text: "中文,猪头", encoded: "\xD6\xD0\xCE\xC4,\xD6\xED\xCD\xB7"},
+		{name: "four-byte chars in BMP", text: "¥ㄱ", encoded: "\x81\x30\x84\x36\x81\x39\xA9\x33"},
+		{name: "supplementary chars", text: "𠀀", encoded: "\x95\x32\x82\x36"},
+		{name: "private use chars", text: "\uE000\uE4C6\uE766\uE864", encoded: "\xAA\xA1\xA1\x40\xA2\xAB\xFE\xA0"},
+	}
+
+	enc, err := GetEncoding("gb-18030")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := io.ReadAll(enc.NewReader(strings.NewReader(tt.encoded)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.text {
+				t.Errorf("decode: expect %q, got %q", tt.text, data)
+			}
+
+			// write rune by rune in bytes, so that a char is split into multiple writes
+			var buf bytes.Buffer
+			w := enc.NewWriter(&buf)
+			for i := 0; i < len(tt.text); i++ {
+				if _, err := w.Write([]byte{tt.text[i]}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if buf.String() != tt.encoded {
+				t.Errorf("encode: expect %q, got %q", tt.encoded, buf.String())
+			}
+		})
+	}
+}
+
+func TestEncoding_EuroSign(t *testing.T) {
+	for name, expected := range map[string]string{
+		"GB18030": "\xA2\xE3",
+		"GBK":     "\x80",
+	} {
+		enc, _ := GetEncoding(name)
+		var buf bytes.Buffer
+		if _, err := enc.NewWriter(&buf).Write([]byte("€")); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if buf.String() != expected {
+			t.Errorf("%s: expect %q, got %q", name, expected, buf.String())
+		}
+		// both bytes are decoded as the euro sign
+		for _, data := range []string{"\x80", "\xA2\xE3"} {
+			text, _ := io.ReadAll(enc.NewReader(strings.NewReader(data)))
+			if string(text) != "€" {
+				t.Errorf("%s: expect %q decoded as €, got %q", name, data, text)
+			}
+		}
+	}
+}
+
+func TestEncoding_GB18030_Invalid(t *testing.T) {
+	enc, _ := GetEncoding("GBK")
+	// invalid bytes are decoded as U+FFFD, an ASCII trail byte is kept
+	data, _ := io.ReadAll(enc.NewReader(strings.NewReader("\xD6\xD0\x81 A\xFF\xD6")))
+	if string(data) != "中� A��" {
+		t.Errorf("expect %q, got %q", "中� A��", data)
+	}
+
+	// chars out of GBK could not be written as GBK
+	if _, err := enc.NewWriter(&bytes.Buffer{}).Write([]byte("𠀀")); err == nil {
+		t.Errorf("expect error for chars out of GBK, got nil")
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	for data, expected := range map[string]Encoding{
+		"猪头":                    utf8Encoding{},
+		"\xEF\xBB\xBF猪头":        utf8Encoding{},
+		"\xD6\xED\xCD\xB7":      gb18030Encoding{},
+		"令A = \xD6\xED\xCD\xB7": gb18030Encoding{},
+	} {
+		if enc := DetectEncoding([]byte(data)); enc != expected {
+			t.Errorf("%q: expect %#v, got %#v", data, expected, enc)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package io
 
 import (
+	"bytes"
 	"io"
 	"os"
 
@@ -19,19 +20,36 @@ const (
 	BOM              = 0xFEFF
 )
 
-// NewFileStream - create file stream
+// NewFileStream - create file stream, the encoding (UTF-8 or GB18030) is detected from the content
 func NewFileStream(path string) (*FileStream, error) {
+	return NewFileStreamWithEncoding(path, "")
+}
+
+// NewFileStreamWithEncoding - create file stream of the encoding (e.g. "GB18030", see GetEncoding).
+// If encoding is empty, it's detected from the content (see DetectEncoding).
+func NewFileStreamWithEncoding(path string, encoding string) (*FileStream, error) {
 	if _, err := os.Stat(path); err != nil {
 		return nil, zerr.FileNotFound(path)
 	}
+	var enc Encoding
+	if encoding != "" {
+		e, err := GetEncoding(encoding)
+		if err != nil {
+			return nil, zerr.UnsupportedEncoding(path, encoding)
+		}
+		enc = e
+	}
 
-	reader, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, zerr.ReadFileError(err, path)
+	}
+	if enc == nil {
+		enc = DetectEncoding(data)
 	}
 
 	return &FileStream{
-		reader:    reader,
+		reader:    enc.NewReader(bytes.NewReader(data)),
 		encBuffer: []byte{},
 		path:      path,
 		hasRead:   false,
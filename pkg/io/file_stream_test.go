@@ -53,6 +53,18 @@ func TestFileStream_ReadAll(t *testing.T) {
 			assertD: []rune("猪头"),
 			assertE: nil,
 		},
+		{
+			name: "GB18030 file",
+			data: bytes.Repeat([]byte{0xD6, 0xED, 0xCD, 0xB7, 0x0A}, 10284),
+			assertD: []rune(strings.Repeat("猪头\n", 10284)),
+			assertE: nil,
+		},
+		{
+			name: "U+FFFD in the file",
+			data: []byte("猪\uFFFD头"),
+			assertD: []rune("猪\uFFFD头"),
+			assertE: nil,
+		},
 	}
 
 	for _, tt := range cases {
@@ -78,3 +90,23 @@ func TestFileStream_ReadAll(t *testing.T) {
 		})
 	}
 }
+
+func TestNewFileStreamWithEncoding(t *testing.T) {
+	file, _ := setup()
+	defer teardown(file)
+	// "猪头" in GBK
+	_ = ioutil.WriteFile(file, []byte{0xD6, 0xED, 0xCD, 0xB7}, 0644)
+
+	s, err := NewFileStreamWithEncoding(file, "GBK")
+	if err != nil {
+		t.Fatalf("expect no error, but error occured: %s", err)
+	}
+	data, _ := s.ReadAll()
+	if string(data) != "猪头" {
+		t.Fatalf("expect 猪头, got %s", string(data))
+	}
+
+	if _, err := NewFileStreamWithEncoding(file, "Big5"); err == nil {
+		t.Fatalf("expect error, but NO error occured")
+	}
+}
@@ -0,0 +1,220 @@
+package io
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// gb18030Encoding - GB18030, the national standard encoding of Chinese that covers all Unicode
+// chars: ASCII in 1 byte, GBK chars in 2 bytes and others in 4 bytes. Since GBK (and GB2312)
+// is a subset of GB18030, it's also used to read GBK files; if gbk = true, chars that
+// are not in GBK could not be written. Following the WHATWG Encoding Standard, the euro sign
+// is written as 0x80 in GBK and 0xA2E3 in GB18030; both of them are decoded as the euro sign.
+//
+// The conversion is done by golang.org/x/text, except the two-byte sequences of private use
+// chars (see gb18030PUARuns). Invalid bytes are decoded as U+FFFD.
+type gb18030Encoding struct {
+	gbk bool
+}
+
+// gb18030PUARuns - two-byte sequences of private use chars (U+E000 - U+E864) besides the
+// user-defined areas (see forEachGB18030PUA). WHATWG maps them to PUA chars, while x/text
+// leaves them undecoded and writes the chars in 4 bytes. Each item is [the first sequence,
+// the first char, count]; the sequences and chars of an item are both consecutive.
+var gb18030PUARuns = [][3]uint16{
+	{0xA2AB, 0xE766, 6}, {0xA2E4, 0xE76D, 1}, {0xA2EF, 0xE76E, 2}, {0xA2FD, 0xE770, 2},
+	{0xA4F4, 0xE772, 11}, {0xA5F7, 0xE77D, 8}, {0xA6B9, 0xE785, 8}, {0xA6D9, 0xE78D, 7},
+	{0xA6EC, 0xE794, 2}, {0xA6F3, 0xE796, 1}, {0xA6F6, 0xE797, 9}, {0xA7C2, 0xE7A0, 15},
+	{0xA7F2, 0xE7AF, 13}, {0xA896, 0xE7BC, 11}, {0xA8BC, 0xE7C7, 1}, {0xA8C1, 0xE7C9, 4},
+	{0xA8EA, 0xE7CD, 21}, {0xA958, 0xE7E2, 1}, {0xA95B, 0xE7E3, 1}, {0xA95D, 0xE7E4, 3},
+	{0xA997, 0xE7F4, 13}, {0xA9F0, 0xE801, 15}, {0xD7FA, 0xE810, 5}, {0xFE51, 0xE816, 3},
+	{0xFE59, 0xE81E, 1}, {0xFE61, 0xE826, 1}, {0xFE66, 0xE82B, 2}, {0xFE6C, 0xE831, 2},
+	{0xFE76, 0xE83B, 1}, {0xFE7E, 0xE843, 1}, {0xFE90, 0xE854, 2}, {0xFEA0, 0xE864, 1},
+}
+
+var (
+	gb18030PUAOnce      sync.Once
+	gb18030PUADecodeMap map[uint16]rune
+	gb18030PUAEncodeMap map[rune]uint16
+	// gb18030Decoder - GBK is also decoded as GB18030 (WHATWG); the decoder has no state
+	gb18030Decoder = simplifiedchinese.GB18030.NewDecoder()
+)
+
+// forEachGB18030PUA - iterate the two-byte sequences of private use chars
+func forEachGB18030PUA(fn func(seq uint16, ch rune)) {
+	ch := rune(0xE000)
+	// user-defined areas: AAA1 - AFFE, F8A1 - FEFE (94 chars each row)
+	for _, leads := range [][2]uint16{{0xAA, 0xAF}, {0xF8, 0xFE}} {
+		for lead := leads[0]; lead <= leads[1]; lead++ {
+			for trail := uint16(0xA1); trail <= 0xFE; trail++ {
+				fn(lead<<8|trail, ch)
+				ch++
+			}
+		}
+	}
+	// user-defined area: A140 - A7A0 (96 chars each row, 0x7F is excluded)
+	for lead := uint16(0xA1); lead <= 0xA7; lead++ {
+		for trail := uint16(0x40); trail <= 0xA0; trail++ {
+			if trail != 0x7F {
+				fn(lead<<8|trail, ch)
+				ch++
+			}
+		}
+	}
+	for _, item := range gb18030PUARuns {
+		for i := uint16(0); i < item[2]; i++ {
+			fn(item[0]+i, rune(item[1]+i))
+		}
+	}
+}
+
+func initGB18030PUA() {
+	gb18030PUAOnce.Do(func() {
+		gb18030PUADecodeMap = map[uint16]rune{}
+		gb18030PUAEncodeMap = map[rune]uint16{}
+		forEachGB18030PUA(func(seq uint16, ch rune) {
+			gb18030PUADecodeMap[seq] = ch
+			gb18030PUAEncodeMap[ch] = seq
+		})
+	})
+}
+
+func (e gb18030Encoding) NewReader(r io.Reader) io.Reader {
+	initGB18030PUA()
+	return &gb18030Reader{r: r}
+}
+
+func (e gb18030Encoding) NewWriter(w io.Writer) io.Writer {
+	initGB18030PUA()
+	encoder := simplifiedchinese.GB18030.NewEncoder()
+	if e.gbk {
+		encoder = simplifiedchinese.GBK.NewEncoder()
+	}
+	return &gb18030Writer{w: w, enc: e, encoder: encoder}
+}
+
+func (e gb18030Encoding) name() string {
+	if e.gbk {
+		return "GBK"
+	}
+	return "GB18030"
+}
+
+// gb18030Reader - decode GB18030 bytes to UTF-8 text
+type gb18030Reader struct {
+	r   io.Reader
+	buf [defaultReadBlock]byte
+	// src - bytes read but not decoded yet (an incomplete sequence)
+	src []byte
+	// dst - decoded text that is not returned yet
+	dst []byte
+	err error
+}
+
+func (g *gb18030Reader) Read(p []byte) (int, error) {
+	for len(g.dst) == 0 {
+		if g.err != nil {
+			return 0, g.err
+		}
+		n, err := g.r.Read(g.buf[:])
+		g.src = append(g.src, g.buf[:n]...)
+		g.err = err
+		g.decode(err != nil)
+	}
+	n := copy(p, g.dst)
+	g.dst = g.dst[n:]
+	return n, nil
+}
+
+func (g *gb18030Reader) decode(atEOF bool) {
+	for len(g.src) > 0 {
+		ch, size := decodeGB18030(g.src, atEOF)
+		if size == 0 {
+			break
+		}
+		g.dst = utf8.AppendRune(g.dst, ch)
+		g.src = g.src[size:]
+	}
+}
+
+// decodeGB18030 - decode the first char of b, returns the char and the number of bytes
+// consumed. If b is an incomplete sequence and atEOF = false, size = 0.
+func decodeGB18030(b []byte, atEOF bool) (rune, int) {
+	// find the sequence of the first char, then decode it alone
+	size := 1
+	if b[0] > 0x80 && b[0] < 0xFF {
+		if len(b) < 2 {
+			if !atEOF {
+				return 0, 0
+			}
+		} else if b[1] >= 0x30 && b[1] <= 0x39 {
+			size = 4
+			if len(b) < 4 {
+				if !atEOF {
+					return 0, 0
+				}
+				size = len(b)
+			}
+		} else {
+			if ch, ok := gb18030PUADecodeMap[uint16(b[0])<<8|uint16(b[1])]; ok {
+				return ch, 2
+			}
+			size = 2
+		}
+	}
+
+	var buf [4 * utf8.UTFMax]byte
+	nDst, nSrc, _ := gb18030Decoder.Transform(buf[:], b[:size], true)
+	ch, n := utf8.DecodeRune(buf[:nDst])
+	// more than one char is decoded only if the first byte is invalid, e.g. an ASCII trail byte
+	// is not consumed
+	if n < nDst {
+		return ch, 1
+	}
+	return ch, nSrc
+}
+
+// gb18030Writer - encode UTF-8 text to GB18030 (or GBK) bytes
+type gb18030Writer struct {
+	w       io.Writer
+	enc     gb18030Encoding
+	encoder *encoding.Encoder
+	// pending - an incomplete UTF-8 char at the end of last write
+	pending []byte
+}
+
+func (g *gb18030Writer) Write(p []byte) (int, error) {
+	src := append(g.pending, p...)
+	dst := make([]byte, 0, len(src))
+	var buf [4]byte
+	for len(src) > 0 {
+		if !utf8.FullRune(src) {
+			break
+		}
+		ch, size := utf8.DecodeRune(src)
+		if ch == utf8.RuneError && size == 1 {
+			return 0, fmt.Errorf("写入的内容不是有效的UTF-8文本")
+		}
+		if seq, ok := gb18030PUAEncodeMap[ch]; ok {
+			dst = append(dst, byte(seq>>8), byte(seq))
+		} else {
+			nDst, _, err := g.encoder.Transform(buf[:], src[:size], true)
+			if err != nil {
+				return 0, fmt.Errorf("字符「%c」无法以%s编码", ch, g.enc.name())
+			}
+			dst = append(dst, buf[:nDst]...)
+		}
+		src = src[size:]
+	}
+	g.pending = append([]byte{}, src...)
+
+	if _, err := g.w.Write(dst); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
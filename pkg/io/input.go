@@ -26,7 +26,8 @@ func readRune(r io.Reader, remains []byte, b int) ([]rune, []byte, error) {
 	buf := append(remains, p[:t]...)
 	for len(buf) > 0 {
 		ru, size := utf8.DecodeRune(buf)
-		if ru == utf8.RuneError {
+		// an incomplete (or invalid) char, but U+FFFD itself is a valid char
+		if ru == utf8.RuneError && size <= 1 {
 			return rs, buf, nil
 		}
 
@@ -0,0 +1,166 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/DemoHn/Zn/pkg/exec"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/syntax"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+// builtinTypeNames - the order of built-in types in completion items
+var builtinTypeNames = []string{"数值", "小数", "货币", "日期", "文本", "逻辑", "数组", "列表"}
+
+var builtinTypeMembers = value.GetBuiltinTypeMembers()
+
+// delimiters - chars that never appear in an identifier
+const delimiters = " \t（）()：:、，,。；;“”「」【】《》=+-*/%#！!？?<>"
+
+// findMemberRoot - for 「X之…」, get X
+func findMemberRoot(prefix string) (string, bool) {
+	chars := []rune(prefix)
+	idx := -1
+	for i := len(chars) - 1; i >= 0; i-- {
+		if chars[i] == '之' {
+			idx = i
+			break
+		}
+	}
+	if idx <= 0 || strings.ContainsAny(string(chars[idx+1:]), delimiters) {
+		return "", false
+	}
+	return findRootBefore(chars[:idx])
+}
+
+// findMethodRoot - for 「以X（…」, get X
+func findMethodRoot(prefix string) (string, bool) {
+	chars := []rune(prefix)
+	idx := -1
+	for i := len(chars) - 1; i >= 0; i-- {
+		if chars[i] == '（' {
+			idx = i
+			break
+		}
+	}
+	if idx <= 0 || strings.ContainsAny(string(chars[idx+1:]), delimiters) {
+		return "", false
+	}
+	leadIdx := -1
+	for i := idx - 1; i >= 0; i-- {
+		if chars[i] == '以' {
+			leadIdx = i
+			break
+		}
+	}
+	if leadIdx < 0 {
+		return "", false
+	}
+	root := strings.TrimSpace(string(chars[leadIdx+1 : idx]))
+	return root, root != ""
+}
+
+// findRootBefore - get the last identifier (or string / array literal) of chars
+func findRootBefore(chars []rune) (string, bool) {
+	end := len(chars)
+	for end > 0 && (chars[end-1] == ' ' || chars[end-1] == '\t') {
+		end--
+	}
+	if end == 0 {
+		return "", false
+	}
+
+	switch chars[end-1] {
+	case '”', '】':
+		open := map[rune]rune{'”': '“', '】': '【'}[chars[end-1]]
+		for i := end - 2; i >= 0; i-- {
+			if chars[i] == open {
+				return string(chars[i:end]), true
+			}
+		}
+		return "", false
+	}
+
+	start := end
+	for start > 0 && !strings.ContainsRune(delimiters, chars[start-1]) {
+		start--
+	}
+	root := string(chars[start:end])
+	return root, root != ""
+}
+
+// inferTypes - guess the type of the root expression by literals or its declaration;
+// returns all built-in types if unknown.
+func (d *Document) inferTypes(root string) []string {
+	switch {
+	case strings.HasPrefix(root, "“"), strings.HasPrefix(root, "「"):
+		return []string{"文本"}
+	case strings.HasPrefix(root, "【"):
+		return []string{"数组", "列表"}
+	case root == "真" || root == "假":
+		return []string{"逻辑"}
+	}
+	if idType, err := exec.MatchIDType(&syntax.ID{PrimeExpr: syntax.PrimeExpr{Literal: root}}); err == nil {
+		if _, ok := idType.(*r.IDNumber); ok {
+			return []string{"数值"}
+		}
+		if _, ok := idType.(*r.IDCurrency); ok {
+			return []string{"货币"}
+		}
+		if _, ok := idType.(*r.IDDateTime); ok {
+			return []string{"日期"}
+		}
+	}
+
+	if d.lastProgram != nil {
+		var typeName string
+		walk(d.lastProgram, func(node syntax.Node) {
+			stmt, ok := node.(*syntax.VarDeclareStmt)
+			if !ok || typeName != "" {
+				return
+			}
+			for _, pair := range stmt.AssignPair {
+				for _, id := range pair.Variables {
+					if id.GetLiteral() == root {
+						typeName = getExprTypeName(pair.AssignExpr)
+					}
+				}
+			}
+		})
+		if typeName != "" {
+			return []string{typeName}
+		}
+	}
+	return builtinTypeNames
+}
+
+func getExprTypeName(expr syntax.Expression) string {
+	switch e := expr.(type) {
+	case *syntax.String:
+		return "文本"
+	case *syntax.ArrayExpr:
+		return "数组"
+	case *syntax.HashMapExpr:
+		return "列表"
+	case *syntax.ArithExpr:
+		return "数值"
+	case *syntax.LogicExpr:
+		return "逻辑"
+	case *syntax.ID:
+		if e.GetLiteral() == "真" || e.GetLiteral() == "假" {
+			return "逻辑"
+		}
+		if idType, err := exec.MatchIDType(e); err == nil {
+			if _, ok := idType.(*r.IDNumber); ok {
+				return "数值"
+			}
+			if _, ok := idType.(*r.IDCurrency); ok {
+				return "货币"
+			}
+			if _, ok := idType.(*r.IDDateTime); ok {
+				return "日期"
+			}
+		}
+	}
+	return ""
+}
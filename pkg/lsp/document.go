@@ -0,0 +1,568 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	"github.com/DemoHn/Zn/pkg/exec"
+	"github.com/DemoHn/Zn/pkg/io"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/syntax"
+	"github.com/DemoHn/Zn/pkg/syntax/zh"
+)
+
+// Document - a parsed Zn source file
+type Document struct {
+	URI  string
+	Path string
+	// lines - raw source lines (with indents), used to locate identifiers in a line
+	lines [][]rune
+	// program - the parsed AST; nil if the source has syntax errors
+	program *syntax.Program
+	// lastProgram - the last successfully parsed AST, for completion while typing
+	lastProgram *syntax.Program
+	diagnostics []Diagnostic
+}
+
+// declaration - a function / class / method / property declared in the program
+type declaration struct {
+	name     string
+	kind     int
+	line     int
+	inputs   []string
+	children []*declaration
+}
+
+// NewDocument - parse the source text; prev is the previous version of the document (if any)
+func NewDocument(uri string, text string, prev *Document) *Document {
+	doc := &Document{
+		URI:         uri,
+		Path:        uriToPath(uri),
+		diagnostics: []Diagnostic{},
+	}
+	for _, line := range strings.Split(text, "\n") {
+		doc.lines = append(doc.lines, []rune(strings.TrimSuffix(line, "\r")))
+	}
+
+	doc.parse(text)
+	doc.lastProgram = doc.program
+	if doc.program == nil && prev != nil {
+		doc.lastProgram = prev.lastProgram
+	}
+	return doc
+}
+
+// Diagnostics - syntax errors & semantic errors (from MatchIDType) of the document
+func (d *Document) Diagnostics() []Diagnostic {
+	return d.diagnostics
+}
+
+func (d *Document) parse(text string) {
+	source, err := io.NewByteStream([]byte(text)).ReadAll()
+	if err != nil {
+		d.addDiagnostic(Range{}, 0, err.Error())
+		return
+	}
+
+	parser := syntax.NewParser(source, zh.NewParserZH())
+	program, err := parser.Parse()
+	if err != nil {
+		if serr, ok := err.(*zerr.SyntaxError); ok {
+			line := parser.FindLineIdx(serr.Cursor, 0)
+			character := 0
+			if info := parser.GetLineInfo(line); info != nil {
+				character = serr.Cursor - info.StartIdx
+			}
+			start := d.clampPosition(line, character)
+			end := d.clampPosition(line, start.Character+1)
+			d.addDiagnostic(Range{Start: start, End: end}, serr.Code, serr.Error())
+		} else {
+			d.addDiagnostic(Range{}, 0, err.Error())
+		}
+		return
+	}
+	d.program = program
+
+	// semantic errors - invalid identifiers
+	walk(program, func(node syntax.Node) {
+		if id, ok := node.(*syntax.ID); ok {
+			if _, err := exec.MatchIDType(id); err != nil {
+				code := 0
+				if serr, ok := err.(*zerr.SemanticError); ok {
+					code = serr.Code
+				}
+				d.addDiagnostic(d.idRange(&id.PrimeExpr), code, err.Error())
+			}
+		}
+	})
+}
+
+func (d *Document) addDiagnostic(rg Range, code int, message string) {
+	d.diagnostics = append(d.diagnostics, Diagnostic{
+		Range:    rg,
+		Severity: SeverityError,
+		Code:     code,
+		Source:   "zinc",
+		Message:  message,
+	})
+}
+
+// DocumentSymbols - functions & classes (with methods, getters & properties) of the document
+func (d *Document) DocumentSymbols() []DocumentSymbol {
+	if d.program == nil {
+		return []DocumentSymbol{}
+	}
+	return d.toDocumentSymbols(collectDeclarations(d.program.ExecBlock))
+}
+
+func (d *Document) toDocumentSymbols(decls []*declaration) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, decl := range decls {
+		nameRange := d.nameRange(decl.line, decl.name)
+		symbols = append(symbols, DocumentSymbol{
+			Name:           decl.name,
+			Detail:         decl.detail(),
+			Kind:           decl.kind,
+			Range:          d.lineRange(decl.line),
+			SelectionRange: nameRange,
+			Children:       d.toDocumentSymbols(decl.children),
+		})
+	}
+	return symbols
+}
+
+// Definition - find where the identifier at pos is declared, including declarations
+// of imported modules (导入《…》)
+func (d *Document) Definition(pos Position, loader ModuleLoader) *Location {
+	if d.program == nil {
+		return nil
+	}
+	// the module name of import statement - go to the module file
+	for _, stmt := range d.program.ImportBlock {
+		if d.containsPos(d.idRange(&stmt.ImportName.PrimeExpr), pos) {
+			if doc := d.loadModule(stmt.ImportName.GetLiteral(), loader); doc != nil {
+				return &Location{URI: doc.URI}
+			}
+			return nil
+		}
+	}
+
+	name := d.identifierAt(pos)
+	if name == "" {
+		return nil
+	}
+	if line, ok := findDefinition(d.program, name); ok {
+		return &Location{URI: d.URI, Range: d.nameRange(line, name)}
+	}
+	if doc, line, ok := d.findImportedDefinition(name, loader); ok {
+		return &Location{URI: doc.URI, Range: doc.nameRange(line, name)}
+	}
+	return nil
+}
+
+// Hover - display the inputs of the function (or the method) at pos
+func (d *Document) Hover(pos Position, loader ModuleLoader) *Hover {
+	if d.program == nil {
+		return nil
+	}
+	name := d.identifierAt(pos)
+	if name == "" {
+		return nil
+	}
+
+	decl := findDeclaration(collectDeclarations(d.program.ExecBlock), name)
+	if decl == nil {
+		if doc, _, ok := d.findImportedDefinition(name, loader); ok {
+			decl = findDeclaration(collectDeclarations(doc.program.ExecBlock), name)
+		}
+	}
+	if decl == nil {
+		return nil
+	}
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: decl.markdown()}}
+}
+
+// Completion - complete members of built-in types after 「之」 or inside 「以…（」;
+// otherwise, complete declared names of the document
+func (d *Document) Completion(pos Position) []CompletionItem {
+	items := []CompletionItem{}
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return items
+	}
+	line := d.lines[pos.Line]
+	prefix := string(line[:d.runeColumn(pos.Line, pos.Character)])
+
+	// #1. 「X之」 -> properties
+	if root, ok := findMemberRoot(prefix); ok {
+		for _, typeName := range d.inferTypes(root) {
+			for _, prop := range builtinTypeMembers[typeName].Properties {
+				items = append(items, CompletionItem{Label: prop, Kind: CompletionKindProperty, Detail: typeName + "·属性"})
+			}
+		}
+		return items
+	}
+	// #2. 「以X（」 -> methods
+	if root, ok := findMethodRoot(prefix); ok {
+		for _, typeName := range d.inferTypes(root) {
+			for _, method := range builtinTypeMembers[typeName].Methods {
+				items = append(items, CompletionItem{Label: method, Kind: CompletionKindMethod, Detail: typeName + "·方法"})
+			}
+		}
+		return items
+	}
+
+	// #3. declared names
+	if d.lastProgram == nil {
+		return items
+	}
+	seen := map[string]bool{}
+	for _, decl := range collectDeclarations(d.lastProgram.ExecBlock) {
+		kind := CompletionKindFunction
+		if decl.kind == SymbolKindClass {
+			kind = CompletionKindClass
+		}
+		seen[decl.name] = true
+		items = append(items, CompletionItem{Label: decl.name, Kind: kind, Detail: decl.detail()})
+	}
+	walk(d.lastProgram, func(node syntax.Node) {
+		if stmt, ok := node.(*syntax.VarDeclareStmt); ok {
+			for _, pair := range stmt.AssignPair {
+				for _, id := range pair.Variables {
+					if name := id.GetLiteral(); !seen[name] {
+						seen[name] = true
+						items = append(items, CompletionItem{Label: name, Kind: CompletionKindVariable})
+					}
+				}
+			}
+		}
+	})
+	return items
+}
+
+///// identifiers & positions /////
+
+// identifierAt - get the identifier (in AST) that covers the position
+func (d *Document) identifierAt(pos Position) string {
+	name := ""
+	posCol := d.runeColumn(pos.Line, pos.Character)
+	walk(d.program, func(node syntax.Node) {
+		id, ok := node.(*syntax.ID)
+		if !ok || id.GetCurrentLine() != pos.Line {
+			return
+		}
+		literal := id.GetLiteral()
+		for _, col := range d.findInLine(pos.Line, literal) {
+			if posCol >= col && posCol <= col+len([]rune(literal)) && len(literal) > len(name) {
+				name = literal
+			}
+		}
+	})
+	return name
+}
+
+// findInLine - find all columns (rune indexes) of the text in the line
+func (d *Document) findInLine(line int, text string) []int {
+	cols := []int{}
+	if line < 0 || line >= len(d.lines) || text == "" {
+		return cols
+	}
+	lineText := d.lines[line]
+	target := []rune(text)
+	for i := 0; i+len(target) <= len(lineText); i++ {
+		if string(lineText[i:i+len(target)]) == text {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+// nameRange - the range of the (first) name in the line
+func (d *Document) nameRange(line int, name string) Range {
+	cols := d.findInLine(line, name)
+	if len(cols) == 0 {
+		return d.lineRange(line)
+	}
+	return Range{
+		Start: d.position(line, cols[0]),
+		End:   d.position(line, cols[0]+len([]rune(name))),
+	}
+}
+
+func (d *Document) idRange(expr *syntax.PrimeExpr) Range {
+	return d.nameRange(expr.GetCurrentLine(), expr.GetLiteral())
+}
+
+func (d *Document) lineRange(line int) Range {
+	end := 0
+	if line >= 0 && line < len(d.lines) {
+		end = len(d.lines[line])
+	}
+	return Range{Start: Position{Line: line}, End: d.position(line, end)}
+}
+
+func (d *Document) containsPos(rg Range, pos Position) bool {
+	return rg.Start.Line == pos.Line && pos.Character >= rg.Start.Character && pos.Character <= rg.End.Character
+}
+
+// clampPosition - get the position of the rune index in the line, which is clamped into the document
+func (d *Document) clampPosition(line int, col int) Position {
+	if len(d.lines) == 0 {
+		return Position{}
+	}
+	if line >= len(d.lines) {
+		line = len(d.lines) - 1
+	}
+	if col > len(d.lines[line]) {
+		col = len(d.lines[line])
+	}
+	if col < 0 {
+		col = 0
+	}
+	return d.position(line, col)
+}
+
+// LSP counts characters of a line in UTF-16 code units, while lines of the document are
+// kept as runes - so positions are converted on the way in (runeColumn) & out (position).
+
+// position - get the position of the rune index in the line
+func (d *Document) position(line int, col int) Position {
+	character := 0
+	if line >= 0 && line < len(d.lines) {
+		for _, c := range d.lines[line][:col] {
+			character += utf16Len(c)
+		}
+	}
+	return Position{Line: line, Character: character}
+}
+
+// runeColumn - get the rune index of the character (in UTF-16 code units) in the line; a
+// character inside a surrogate pair refers to the rune itself
+func (d *Document) runeColumn(line int, character int) int {
+	if line < 0 || line >= len(d.lines) {
+		return 0
+	}
+	units := 0
+	for col, c := range d.lines[line] {
+		units += utf16Len(c)
+		if units > character {
+			return col
+		}
+	}
+	return len(d.lines[line])
+}
+
+// utf16Len - runes out of the BMP (e.g. emojis, rare CJK characters) are encoded as surrogate pairs
+func utf16Len(c rune) int {
+	if c >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+///// imported modules /////
+
+// ModuleLoader - get the document of the module file (e.g. from opened documents or the disk)
+type ModuleLoader func(path string) *Document
+
+// LoadDocumentFile - a ModuleLoader that reads the module file from disk
+func LoadDocumentFile(path string) *Document {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return NewDocument(pathToURI(path), string(data), nil)
+}
+
+// loadModule - find the module file of the imported name, with the same rule as `zinc` executes it
+func (d *Document) loadModule(moduleName string, loader ModuleLoader) *Document {
+	if d.Path == "" || r.ParseLibName(moduleName).LibType == r.LIB_TYPE_STD {
+		return nil
+	}
+	path, err := exec.NewInterpreter("").LoadFile(d.Path).FindModulePath(moduleName)
+	if err != nil {
+		return nil
+	}
+	return loader(path)
+}
+
+func (d *Document) findImportedDefinition(name string, loader ModuleLoader) (*Document, int, bool) {
+	for _, stmt := range d.program.ImportBlock {
+		if len(stmt.ImportItems) > 0 {
+			imported := false
+			for _, item := range stmt.ImportItems {
+				imported = imported || item.GetLiteral() == name
+			}
+			if !imported {
+				continue
+			}
+		}
+		doc := d.loadModule(stmt.ImportName.GetLiteral(), loader)
+		if doc == nil || doc.program == nil {
+			continue
+		}
+		if decl := findDeclaration(collectDeclarations(doc.program.ExecBlock), name); decl != nil {
+			return doc, decl.line, true
+		}
+	}
+	return nil, 0, false
+}
+
+///// declarations /////
+
+// collectDeclarations - functions & classes declared in the block; nested functions
+// are children of the outer function
+func collectDeclarations(execBlock *syntax.ExecBlock) []*declaration {
+	if execBlock == nil {
+		return []*declaration{}
+	}
+	return collectBlockDeclarations(execBlock.StmtBlock)
+}
+
+func collectBlockDeclarations(block *syntax.StmtBlock) []*declaration {
+	decls := []*declaration{}
+	if block == nil {
+		return decls
+	}
+	for _, stmt := range block.Children {
+		switch n := stmt.(type) {
+		case *syntax.FunctionDeclareStmt:
+			decl := newFunctionDeclaration(n)
+			decl.children = collectDeclarations(n.ExecBlock)
+			decls = append(decls, decl)
+		case *syntax.ClassDeclareStmt:
+			decl := &declaration{
+				name: n.ClassName.GetLiteral(),
+				kind: SymbolKindClass,
+				line: n.ClassName.GetCurrentLine(),
+			}
+			for _, prop := range n.PropertyList {
+				decl.children = append(decl.children, &declaration{
+					name: prop.PropertyID.GetLiteral(),
+					kind: SymbolKindField,
+					line: prop.PropertyID.GetCurrentLine(),
+				})
+			}
+			for _, method := range n.MethodList {
+				methodDecl := newFunctionDeclaration(method)
+				methodDecl.kind = SymbolKindMethod
+				decl.children = append(decl.children, methodDecl)
+			}
+			for _, getter := range n.GetterList {
+				getterDecl := newFunctionDeclaration(getter)
+				getterDecl.kind = SymbolKindProperty
+				decl.children = append(decl.children, getterDecl)
+			}
+			decls = append(decls, decl)
+		case *syntax.BranchStmt:
+			decls = append(decls, collectBlockDeclarations(n.IfTrueBlock)...)
+			for _, otherBlock := range n.OtherBlocks {
+				decls = append(decls, collectBlockDeclarations(otherBlock)...)
+			}
+			decls = append(decls, collectBlockDeclarations(n.IfFalseBlock)...)
+		case *syntax.WhileLoopStmt:
+			decls = append(decls, collectBlockDeclarations(n.LoopBlock)...)
+		case *syntax.IterateStmt:
+			decls = append(decls, collectBlockDeclarations(n.IterateBlock)...)
+		}
+	}
+	return decls
+}
+
+func newFunctionDeclaration(stmt *syntax.FunctionDeclareStmt) *declaration {
+	decl := &declaration{
+		name:   stmt.Name.GetLiteral(),
+		kind:   SymbolKindFunction,
+		line:   stmt.Name.GetCurrentLine(),
+		inputs: []string{},
+	}
+	if stmt.DeclareType == syntax.DeclareTypeConstructor {
+		decl.kind = SymbolKindConstructor
+	}
+	if stmt.ExecBlock != nil {
+		for _, id := range stmt.ExecBlock.InputBlock {
+			decl.inputs = append(decl.inputs, id.GetLiteral())
+		}
+	}
+	return decl
+}
+
+// findDeclaration - find the declaration by name (depth-first)
+func findDeclaration(decls []*declaration, name string) *declaration {
+	for _, decl := range decls {
+		if decl.name == name && decl.kind != SymbolKindConstructor {
+			return decl
+		}
+		if found := findDeclaration(decl.children, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findDefinition - find the line where the name is declared: functions & classes first,
+// then variables, inputs, iterate names & yield results
+func findDefinition(program *syntax.Program, name string) (int, bool) {
+	if decl := findDeclaration(collectDeclarations(program.ExecBlock), name); decl != nil {
+		return decl.line, true
+	}
+
+	line, found := 0, false
+	match := func(id *syntax.ID) {
+		if !found && id != nil && id.GetLiteral() == name {
+			line, found = id.GetCurrentLine(), true
+		}
+	}
+	walk(program, func(node syntax.Node) {
+		switch n := node.(type) {
+		case *syntax.VarDeclareStmt:
+			for _, pair := range n.AssignPair {
+				for _, id := range pair.Variables {
+					match(id)
+				}
+			}
+		case *syntax.ExecBlock:
+			for _, id := range n.InputBlock {
+				match(id)
+			}
+		case *syntax.IterateStmt:
+			for _, id := range n.IndexNames {
+				match(id)
+			}
+		case *syntax.FuncCallExpr:
+			match(n.YieldResult)
+		case *syntax.MemberMethodExpr:
+			match(n.YieldResult)
+		}
+	})
+	return line, found
+}
+
+func (decl *declaration) detail() string {
+	switch decl.kind {
+	case SymbolKindFunction, SymbolKindMethod, SymbolKindConstructor:
+		if len(decl.inputs) > 0 {
+			return "输入" + strings.Join(decl.inputs, "、")
+		}
+	}
+	return ""
+}
+
+func (decl *declaration) markdown() string {
+	switch decl.kind {
+	case SymbolKindClass:
+		return fmt.Sprintf("**定义%s**", decl.name)
+	case SymbolKindProperty:
+		return fmt.Sprintf("**何为%s？**", decl.name)
+	case SymbolKindField:
+		return fmt.Sprintf("**其%s**", decl.name)
+	}
+	text := fmt.Sprintf("**如何%s？**", decl.name)
+	if len(decl.inputs) > 0 {
+		text += "\n\n输入：" + strings.Join(decl.inputs, "、")
+	} else {
+		text += "\n\n（无输入）"
+	}
+	return text
+}
@@ -0,0 +1,162 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const mainTestCode = `导入《工具》
+
+令数组A = 【1，2】
+令问候 = “你好”
+如何计算？
+    输入甲、乙
+    输出甲 + 乙
+
+定义狗：
+    其名 = “旺财”
+
+    如何叫？
+        输出“汪”
+
+（计算：1、2）
+（加一：2）`
+
+const toolTestCode = `如何加一？
+    输入X
+    输出X + 1`
+
+func newTestDocument(t *testing.T) *Document {
+	dir := t.TempDir()
+	mainFile := filepath.Join(dir, "主.zn")
+	if err := os.WriteFile(mainFile, []byte(mainTestCode), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "工具.zn"), []byte(toolTestCode), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return NewDocument(pathToURI(mainFile), mainTestCode, nil)
+}
+
+func TestDocument_Diagnostics(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		expected []Range
+	}{
+		{"no errors", mainTestCode, []Range{}},
+		{"syntax error", "令A = 1\n令B = （", []Range{{Start: Position{1, 6}, End: Position{1, 6}}}},
+		{"semantic error", "令A = 1\n令B = 2.3.5", []Range{{Start: Position{1, 5}, End: Position{1, 10}}}},
+		// characters are counted in UTF-16 code units - 😀 is a surrogate pair
+		{"semantic error after emoji", "令A = 【“😀”，2.3.5】", []Range{{Start: Position{0, 11}, End: Position{0, 16}}}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := NewDocument("file:///a.zn", tt.text, nil)
+			ranges := []Range{}
+			for _, diag := range doc.Diagnostics() {
+				ranges = append(ranges, diag.Range)
+			}
+			if !reflect.DeepEqual(ranges, tt.expected) {
+				t.Errorf("expect ranges %v, got %v (diagnostics: %v)", tt.expected, ranges, doc.Diagnostics())
+			}
+		})
+	}
+}
+
+func TestDocument_DocumentSymbols(t *testing.T) {
+	symbols := newTestDocument(t).DocumentSymbols()
+	if len(symbols) != 2 {
+		t.Fatalf("expect 2 symbols, got %d", len(symbols))
+	}
+	fn, class := symbols[0], symbols[1]
+	if fn.Name != "计算" || fn.Kind != SymbolKindFunction || fn.Detail != "输入甲、乙" {
+		t.Errorf("unexpected function symbol: %+v", fn)
+	}
+	if fn.SelectionRange != (Range{Start: Position{4, 2}, End: Position{4, 4}}) {
+		t.Errorf("unexpected selection range: %v", fn.SelectionRange)
+	}
+	if class.Name != "狗" || class.Kind != SymbolKindClass || len(class.Children) != 2 {
+		t.Fatalf("unexpected class symbol: %+v", class)
+	}
+	if class.Children[0].Name != "名" || class.Children[1].Name != "叫" || class.Children[1].Kind != SymbolKindMethod {
+		t.Errorf("unexpected class members: %+v", class.Children)
+	}
+}
+
+func TestDocument_Definition(t *testing.T) {
+	doc := newTestDocument(t)
+	toolURI := pathToURI(filepath.Join(filepath.Dir(doc.Path), "工具.zn"))
+
+	cases := []struct {
+		name     string
+		pos      Position
+		expected *Location
+	}{
+		{"local function", Position{14, 2}, &Location{URI: doc.URI, Range: Range{Start: Position{4, 2}, End: Position{4, 4}}}},
+		{"function input", Position{6, 6}, &Location{URI: doc.URI, Range: Range{Start: Position{5, 6}, End: Position{5, 7}}}},
+		{"imported function", Position{15, 2}, &Location{URI: toolURI, Range: Range{Start: Position{0, 2}, End: Position{0, 4}}}},
+		{"module name", Position{0, 3}, &Location{URI: toolURI}},
+		{"no identifier", Position{1, 0}, nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := doc.Definition(tt.pos, LoadDocumentFile)
+			if !reflect.DeepEqual(loc, tt.expected) {
+				t.Errorf("expect %+v, got %+v", tt.expected, loc)
+			}
+		})
+	}
+}
+
+func TestDocument_Hover(t *testing.T) {
+	doc := newTestDocument(t)
+
+	hover := doc.Hover(Position{14, 2}, LoadDocumentFile)
+	if hover == nil || hover.Contents.Value != "**如何计算？**\n\n输入：甲、乙" {
+		t.Errorf("unexpected hover of local function: %+v", hover)
+	}
+	hover = doc.Hover(Position{15, 2}, LoadDocumentFile)
+	if hover == nil || hover.Contents.Value != "**如何加一？**\n\n输入：X" {
+		t.Errorf("unexpected hover of imported function: %+v", hover)
+	}
+	if hover = doc.Hover(Position{2, 1}, LoadDocumentFile); hover != nil {
+		t.Errorf("expect no hover of variables, got %+v", hover)
+	}
+}
+
+func TestDocument_Completion(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		pos      Position
+		contains []string
+		excludes []string
+	}{
+		{"array properties", "令A = 【1，2】\n令B = A之", Position{1, 7}, []string{"长度"}, []string{"拼接"}},
+		{"string methods", "令A = “你好”\n以A（", Position{1, 3}, []string{"拼接"}, []string{"长度"}},
+		{"string literal", "令B = “你好”之", Position{0, 10}, []string{"长度"}, []string{"首"}},
+		{"after emoji", "令B = “😀”之 + 1", Position{0, 10}, []string{"长度"}, []string{"首"}},
+		{"declared names", "令数量 = 1\n如何计算？\n    输出1\n", Position{3, 0}, []string{"数量", "计算"}, []string{"长度"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]bool{}
+			for _, item := range NewDocument("file:///a.zn", tt.text, nil).Completion(tt.pos) {
+				labels[item.Label] = true
+			}
+			for _, label := range tt.contains {
+				if !labels[label] {
+					t.Errorf("expect %s in completion items, got %v", label, labels)
+				}
+			}
+			for _, label := range tt.excludes {
+				if labels[label] {
+					t.Errorf("expect %s not in completion items", label)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/DemoHn/Zn/pkg/framing"
+)
+
+// Server - a Language Server Protocol (LSP) server for Zn source files, so that editors
+// could display diagnostics, document symbols, definitions, hovers & completions.
+//
+// Supported requests: initialize, shutdown, textDocument/documentSymbol,
+// textDocument/definition, textDocument/hover & textDocument/completion.
+// Supported notifications: initialized, exit, textDocument/didOpen, textDocument/didChange
+// (full sync ONLY) & textDocument/didClose.
+type Server struct {
+	reader  *bufio.Reader
+	writer  io.Writer
+	version string
+
+	writeMu sync.Mutex
+	// documents - uri -> opened document
+	documents map[string]*Document
+}
+
+// NewServer - create a LSP server that reads messages from in and writes to out
+func NewServer(in io.Reader, out io.Writer, version string) *Server {
+	return &Server{
+		reader:    bufio.NewReader(in),
+		writer:    out,
+		version:   version,
+		documents: map[string]*Document{},
+	}
+}
+
+// Serve - handle messages until `exit` is received or the input is closed
+func (s *Server) Serve() error {
+	for {
+		data, err := framing.ReadMessage(s.reader)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := s.handleMessage(&msg)
+		// notifications have no responses
+		if msg.ID == nil {
+			continue
+		}
+		resp := rpcMessage{JSONRPC: "2.0", ID: msg.ID, Error: rpcErr}
+		if rpcErr == nil {
+			// `result` must be present (even if it's null) on success
+			resp.Result = json.RawMessage("null")
+			if result != nil {
+				resp.Result = result
+			}
+		}
+		if err := s.send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handleMessage(msg *rpcMessage) (interface{}, *rpcError) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":       1,
+				"documentSymbolProvider": true,
+				"definitionProvider":     true,
+				"hoverProvider":          true,
+				"completionProvider": map[string]interface{}{
+					"triggerCharacters": []string{"之", "（"},
+				},
+			},
+			"serverInfo": map[string]string{
+				"name":    "zinc-lsp",
+				"version": s.version,
+			},
+		}, nil
+	case "initialized", "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.updateDocument(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.updateDocument(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params textDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.documents, params.TextDocument.URI)
+		// clear diagnostics of the closed document
+		s.publishDiagnostics(params.TextDocument.URI, []Diagnostic{})
+		return nil, nil
+	case "textDocument/documentSymbol":
+		var params textDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if doc := s.documents[params.TextDocument.URI]; doc != nil {
+			return doc.DocumentSymbols(), nil
+		}
+		return []DocumentSymbol{}, nil
+	case "textDocument/definition", "textDocument/hover", "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		doc := s.documents[params.TextDocument.URI]
+		if doc == nil {
+			return nil, nil
+		}
+		switch msg.Method {
+		case "textDocument/definition":
+			if loc := doc.Definition(params.Position, s.loadModule); loc != nil {
+				return loc, nil
+			}
+		case "textDocument/hover":
+			if hover := doc.Hover(params.Position, s.loadModule); hover != nil {
+				return hover, nil
+			}
+		case "textDocument/completion":
+			return doc.Completion(params.Position), nil
+		}
+		return nil, nil
+	}
+
+	if msg.ID == nil {
+		// ignore unknown notifications (e.g. $/cancelRequest)
+		return nil, nil
+	}
+	return nil, &rpcError{Code: errCodeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+func (s *Server) updateDocument(uri string, text string) {
+	doc := NewDocument(uri, text, s.documents[uri])
+	s.documents[uri] = doc
+	s.publishDiagnostics(uri, doc.Diagnostics())
+}
+
+// loadModule - a ModuleLoader that prefers opened (maybe unsaved) documents
+func (s *Server) loadModule(path string) *Document {
+	for _, doc := range s.documents {
+		if doc.Path != "" && filepath.Clean(doc.Path) == filepath.Clean(path) {
+			return doc
+		}
+	}
+	return LoadDocumentFile(path)
+}
+
+func (s *Server) publishDiagnostics(uri string, diagnostics []Diagnostic) {
+	params, _ := json.Marshal(publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+	s.send(rpcMessage{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  params,
+	})
+}
+
+func (s *Server) send(msg rpcMessage) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return framing.WriteMessage(s.writer, msg)
+}
+
+func invalidParams(err error) *rpcError {
+	return &rpcError{Code: errCodeInvalidParams, Message: err.Error()}
+}
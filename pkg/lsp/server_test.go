@@ -0,0 +1,78 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DemoHn/Zn/pkg/framing"
+)
+
+func TestServer_Serve(t *testing.T) {
+	var input bytes.Buffer
+	send := func(id int, method string, params interface{}) {
+		msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+		if id > 0 {
+			msg["id"] = id
+		}
+		framing.WriteMessage(&input, msg)
+	}
+	doc := map[string]string{"uri": "file:///a.zn"}
+	send(1, "initialize", map[string]interface{}{})
+	send(0, "initialized", map[string]interface{}{})
+	send(0, "textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]string{"uri": "file:///a.zn", "text": "如何计算？\n    输入A\n    输出A\n\n（计算：1）"},
+	})
+	send(2, "textDocument/documentSymbol", map[string]interface{}{"textDocument": doc})
+	send(0, "textDocument/didChange", map[string]interface{}{
+		"textDocument":   doc,
+		"contentChanges": []map[string]string{{"text": "令A = （"}},
+	})
+	send(3, "textDocument/unknown", map[string]interface{}{})
+	send(4, "shutdown", nil)
+	send(0, "exit", nil)
+
+	var output bytes.Buffer
+	if err := NewServer(&input, &output, "test").Serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(&output)
+	results := []string{}
+	for {
+		data, err := framing.ReadMessage(reader)
+		if err != nil {
+			break
+		}
+		var msg struct {
+			ID     *int            `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			Result json.RawMessage `json:"result"`
+			Error  *rpcError       `json:"error"`
+		}
+		json.Unmarshal(data, &msg)
+		switch {
+		case msg.Method == "textDocument/publishDiagnostics":
+			var params publishDiagnosticsParams
+			json.Unmarshal(msg.Params, &params)
+			results = append(results, fmt.Sprintf("diagnostics:%d", len(params.Diagnostics)))
+		case msg.Error != nil:
+			results = append(results, fmt.Sprintf("%d:error:%d", *msg.ID, msg.Error.Code))
+		case msg.ID != nil && *msg.ID == 2:
+			var symbols []DocumentSymbol
+			json.Unmarshal(msg.Result, &symbols)
+			results = append(results, fmt.Sprintf("2:symbols:%d", len(symbols)))
+		case msg.ID != nil:
+			results = append(results, fmt.Sprintf("%d:ok", *msg.ID))
+		}
+	}
+
+	expected := "1:ok diagnostics:0 2:symbols:1 diagnostics:1 3:error:-32601 4:ok"
+	if got := strings.Join(results, " "); got != expected {
+		t.Errorf("expect messages %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,132 @@
+package lsp
+
+import (
+	"reflect"
+
+	"github.com/DemoHn/Zn/pkg/syntax"
+)
+
+// walk - visit the node and all its descendants in depth-first order
+func walk(node syntax.Node, visit func(node syntax.Node)) {
+	if v := reflect.ValueOf(node); !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return
+	}
+	visit(node)
+
+	switch n := node.(type) {
+	case *syntax.Program:
+		for _, stmt := range n.ImportBlock {
+			walk(stmt, visit)
+		}
+		walk(n.ExecBlock, visit)
+	case *syntax.ExecBlock:
+		for _, id := range n.InputBlock {
+			walk(id, visit)
+		}
+		walk(n.StmtBlock, visit)
+		for _, pair := range n.CatchBlock {
+			walk(pair.ExceptionClass, visit)
+			walk(pair.StmtBlock, visit)
+		}
+		walk(n.FinallyBlock, visit)
+	case *syntax.StmtBlock:
+		for _, stmt := range n.Children {
+			walk(stmt, visit)
+		}
+	case *syntax.VarDeclareStmt:
+		for _, pair := range n.AssignPair {
+			for _, id := range pair.Variables {
+				walk(id, visit)
+			}
+			walk(pair.AssignExpr, visit)
+		}
+	case *syntax.BranchStmt:
+		walk(n.IfTrueExpr, visit)
+		walk(n.IfTrueBlock, visit)
+		for i, expr := range n.OtherExprs {
+			walk(expr, visit)
+			walk(n.OtherBlocks[i], visit)
+		}
+		walk(n.IfFalseBlock, visit)
+	case *syntax.WhileLoopStmt:
+		walk(n.TrueExpr, visit)
+		walk(n.LoopBlock, visit)
+	case *syntax.IterateStmt:
+		walk(n.IterateExpr, visit)
+		for _, id := range n.IndexNames {
+			walk(id, visit)
+		}
+		walk(n.IterateBlock, visit)
+	case *syntax.ImportStmt:
+		walk(n.ImportName, visit)
+		for _, id := range n.ImportItems {
+			walk(id, visit)
+		}
+	case *syntax.FunctionDeclareStmt:
+		walk(n.Name, visit)
+		walk(n.ExecBlock, visit)
+	case *syntax.FunctionReturnStmt:
+		walk(n.ReturnExpr, visit)
+	case *syntax.ClassDeclareStmt:
+		walk(n.ClassName, visit)
+		walk(n.ParentClass, visit)
+		for _, prop := range n.PropertyList {
+			walk(prop, visit)
+		}
+		for _, method := range n.MethodList {
+			walk(method, visit)
+		}
+		for _, getter := range n.GetterList {
+			walk(getter, visit)
+		}
+	case *syntax.PropertyDeclareStmt:
+		walk(n.PropertyID, visit)
+		walk(n.InitValue, visit)
+	case *syntax.ThrowExceptionStmt:
+		walk(n.ExceptionClass, visit)
+		for _, param := range n.Params {
+			walk(param, visit)
+		}
+	case *syntax.ArrayExpr:
+		for _, item := range n.Items {
+			walk(item, visit)
+		}
+	case *syntax.HashMapExpr:
+		for _, pair := range n.KVPair {
+			walk(pair.Key, visit)
+			walk(pair.Value, visit)
+		}
+	case *syntax.VarAssignExpr:
+		walk(n.TargetVar, visit)
+		walk(n.AssignExpr, visit)
+	case *syntax.ObjNewExpr:
+		walk(n.ClassName, visit)
+		for _, param := range n.Params {
+			walk(param, visit)
+		}
+	case *syntax.FunctionExpr:
+		walk(n.ExecBlock, visit)
+	case *syntax.FuncCallExpr:
+		walk(n.FuncName, visit)
+		for _, param := range n.Params {
+			walk(param, visit)
+		}
+		walk(n.YieldResult, visit)
+	case *syntax.MemberExpr:
+		walk(n.Root, visit)
+		walk(n.MemberID, visit)
+		walk(n.MemberIndex, visit)
+	case *syntax.MemberMethodExpr:
+		walk(n.Root, visit)
+		for _, call := range n.MethodChain {
+			walk(call, visit)
+		}
+		walk(n.YieldResult, visit)
+	case *syntax.LogicExpr:
+		walk(n.LeftExpr, visit)
+		walk(n.RightExpr, visit)
+	case *syntax.ArithExpr:
+		walk(n.LeftExpr, visit)
+		walk(n.RightExpr, visit)
+	}
+}
@@ -0,0 +1,232 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DemoHn/Zn/pkg/syntax"
+)
+
+// Opcode - the operation code of one bytecode instruction
+type Opcode uint8
+
+// declare opcodes
+// notation: [A] and [B] are the operands of the instruction; stack items
+// are listed from bottom to top, e.g. (root, value) means `value` is on the top.
+const (
+	OpNop Opcode = iota
+	// OpLine - set current line to [A], then check interrupt & count step (at the beginning of each statement)
+	OpLine
+	// OpCheckInterrupt - check if the execution is timeout or cancelled (at the beginning of each loop)
+	OpCheckInterrupt
+	// OpConst - push a copy of Consts[A]
+	OpConst
+	// OpPop - pop the top item
+	OpPop
+	// OpSetResult - pop the top item as the result of the chunk (for top-level statements)
+	OpSetResult
+	// OpCopyValue - replace the top item with its duplicated value (for assignments)
+	OpCopyValue
+	// OpLoadLocal - push slots[A]
+	OpLoadLocal
+	// OpDeclareLocal - declare slots[A] = top item (the item is kept on the stack)
+	OpDeclareLocal
+	// OpStoreLocal - set slots[A] = top item (the item is kept on the stack)
+	OpStoreLocal
+	// OpLoadName - push the element named Names[A]
+	OpLoadName
+	// OpDeclareName - declare the top item as Names[A] (the item is kept on the stack); B = 1 means const
+	OpDeclareName
+	// OpStoreName - set the element named Names[A] = top item (the item is kept on the stack)
+	OpStoreName
+	// OpLoadThis - push "this" value (其)
+	OpLoadThis
+	// OpBeginScope - begin a new scope of named elements
+	OpBeginScope
+	// OpEndScope - end current scope of named elements
+	OpEndScope
+	// OpJump - jump to [A]
+	OpJump
+	// OpJumpIfFalse - pop a bool and jump to [A] if it's false
+	OpJumpIfFalse
+	// OpJumpIfFalseOrPop - if the top bool is false, keep it and jump to [A]; otherwise pop it (for 且)
+	OpJumpIfFalseOrPop
+	// OpJumpIfTrueOrPop - if the top bool is true, keep it and jump to [A]; otherwise pop it (for 或)
+	OpJumpIfTrueOrPop
+	// OpAssertBool - assert the top item is a bool
+	OpAssertBool
+	// OpBinary - (left, right) -> left [A] right, where [A] is one of syntax.LogicXX / syntax.ArithXX types
+	OpBinary
+	// OpBuildArray - pop [A] items and push an array of them
+	OpBuildArray
+	// OpBuildHashMap - pop [A] values and push a hashmap, keys are Names[B] ~ Names[B+A-1]
+	OpBuildHashMap
+	// OpGetMember - (root) -> root之Names[A]
+	OpGetMember
+	// OpSetMember - (value, root) -> set root之Names[A] = value, and push value
+	OpSetMember
+	// OpGetIndex - (root, index) -> root#index
+	OpGetIndex
+	// OpSetIndex - (value, root, index) -> set root#index = value, and push value
+	OpSetIndex
+	// OpCallFunction - pop [B] params and call function named Names[A]
+	OpCallFunction
+	// OpCallMethod - (root, params...) -> call method Names[A] of root with [B] params
+	OpCallMethod
+	// OpNewObject - (class, params...) -> construct an object from class (named Names[A]) with [B] params
+	OpNewObject
+	// OpThrow - (class, params...) -> throw an exception from class (named Names[A]) with [B] params
+	OpThrow
+	// OpRethrow - throw the exception caught by current catch block again
+	OpRethrow
+	// OpReturn - pop the top item as return value and stop execution
+	OpReturn
+	// OpIterInit - pop an array/hashmap (or an IterableElement) and start iterating it
+	OpIterInit
+	// OpIterNext - push (key, value) of the next item, or jump to [A] if no items left
+	OpIterNext
+	// OpIterEnd - stop the current iteration
+	OpIterEnd
+	// OpMakeClosure - push an anonymous function of FuncExprs[A] that captures current scope
+	OpMakeClosure
+)
+
+var opcodeNames = map[Opcode]string{
+	OpNop:              "NOP",
+	OpLine:             "LINE",
+	OpCheckInterrupt:   "CHECK_INTERRUPT",
+	OpConst:            "CONST",
+	OpPop:              "POP",
+	OpSetResult:        "SET_RESULT",
+	OpCopyValue:        "COPY_VALUE",
+	OpLoadLocal:        "LOAD_LOCAL",
+	OpDeclareLocal:     "DECLARE_LOCAL",
+	OpStoreLocal:       "STORE_LOCAL",
+	OpLoadName:         "LOAD_NAME",
+	OpDeclareName:      "DECLARE_NAME",
+	OpStoreName:        "STORE_NAME",
+	OpLoadThis:         "LOAD_THIS",
+	OpBeginScope:       "BEGIN_SCOPE",
+	OpEndScope:         "END_SCOPE",
+	OpJump:             "JUMP",
+	OpJumpIfFalse:      "JUMP_IF_FALSE",
+	OpJumpIfFalseOrPop: "JUMP_IF_FALSE_OR_POP",
+	OpJumpIfTrueOrPop:  "JUMP_IF_TRUE_OR_POP",
+	OpAssertBool:       "ASSERT_BOOL",
+	OpBinary:           "BINARY",
+	OpBuildArray:       "BUILD_ARRAY",
+	OpBuildHashMap:     "BUILD_HASHMAP",
+	OpGetMember:        "GET_MEMBER",
+	OpSetMember:        "SET_MEMBER",
+	OpGetIndex:         "GET_INDEX",
+	OpSetIndex:         "SET_INDEX",
+	OpCallFunction:     "CALL_FUNCTION",
+	OpCallMethod:       "CALL_METHOD",
+	OpNewObject:        "NEW_OBJECT",
+	OpThrow:            "THROW",
+	OpRethrow:          "RETHROW",
+	OpReturn:           "RETURN",
+	OpIterInit:         "ITER_INIT",
+	OpIterNext:         "ITER_NEXT",
+	OpIterEnd:          "ITER_END",
+	OpMakeClosure:      "MAKE_CLOSURE",
+}
+
+func (op Opcode) String() string {
+	if name, ok := opcodeNames[op]; ok {
+		return name
+	}
+	return fmt.Sprintf("OP(%d)", op)
+}
+
+// Instruction - one bytecode instruction with (at most) 2 operands
+type Instruction struct {
+	Op Opcode
+	A  int
+	B  int
+}
+
+// Chunk - a compiled block of instructions (e.g. the body of a function).
+// Local variables are resolved to slots at compile time, so they could be
+// accessed by index instead of by name.
+type Chunk struct {
+	Code   []Instruction
+	Consts []Element
+	Names  []string
+	// FuncExprs - anonymous functions that are created by OpMakeClosure
+	FuncExprs []*syntax.FunctionExpr
+	// NumSlots - number of local slots required to execute the chunk
+	NumSlots int
+}
+
+func NewChunk() *Chunk {
+	return &Chunk{
+		Code:      []Instruction{},
+		Consts:    []Element{},
+		Names:     []string{},
+		FuncExprs: []*syntax.FunctionExpr{},
+		NumSlots:  0,
+	}
+}
+
+// Emit - append an instruction and return its position
+func (c *Chunk) Emit(op Opcode, a int, b int) int {
+	c.Code = append(c.Code, Instruction{Op: op, A: a, B: b})
+	return len(c.Code) - 1
+}
+
+// PatchJump - set the jump target of the instruction at pos to the end of the chunk
+func (c *Chunk) PatchJump(pos int) {
+	c.Code[pos].A = len(c.Code)
+}
+
+// AddConst - add a constant and return its index
+func (c *Chunk) AddConst(elem Element) int {
+	c.Consts = append(c.Consts, elem)
+	return len(c.Consts) - 1
+}
+
+// AddName - add a name and return its index
+func (c *Chunk) AddName(name string) int {
+	for idx, n := range c.Names {
+		if n == name {
+			return idx
+		}
+	}
+	return c.AddNameList([]string{name})
+}
+
+// AddNameList - add names in order (without deduplication) and return the index of the first one
+func (c *Chunk) AddNameList(names []string) int {
+	c.Names = append(c.Names, names...)
+	return len(c.Names) - len(names)
+}
+
+// AddFuncExpr - add an anonymous function and return its index
+func (c *Chunk) AddFuncExpr(node *syntax.FunctionExpr) int {
+	c.FuncExprs = append(c.FuncExprs, node)
+	return len(c.FuncExprs) - 1
+}
+
+// Disassemble - print all instructions in a human-readable way (for debugging & testing)
+func (c *Chunk) Disassemble() string {
+	var sb strings.Builder
+	for pos, inst := range c.Code {
+		sb.WriteString(fmt.Sprintf("%04d %s", pos, inst.Op.String()))
+		switch inst.Op {
+		case OpConst:
+			sb.WriteString(fmt.Sprintf(" %d (%s)", inst.A, c.Consts[inst.A].String()))
+		case OpLoadName, OpDeclareName, OpStoreName, OpGetMember, OpSetMember:
+			sb.WriteString(fmt.Sprintf(" %d (%s)", inst.A, c.Names[inst.A]))
+		case OpCallFunction, OpCallMethod, OpNewObject, OpThrow:
+			sb.WriteString(fmt.Sprintf(" %d (%s) %d", inst.A, c.Names[inst.A], inst.B))
+		case OpLine, OpLoadLocal, OpDeclareLocal, OpStoreLocal, OpJump, OpJumpIfFalse,
+			OpJumpIfFalseOrPop, OpJumpIfTrueOrPop, OpBinary, OpIterNext, OpMakeClosure:
+			sb.WriteString(fmt.Sprintf(" %d", inst.A))
+		case OpBuildArray, OpBuildHashMap:
+			sb.WriteString(fmt.Sprintf(" %d %d", inst.A, inst.B))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
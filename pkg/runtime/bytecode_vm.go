@@ -0,0 +1,322 @@
+package runtime
+
+import (
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	"github.com/DemoHn/Zn/pkg/syntax"
+)
+
+// BytecodeOps - operations on concrete values that the bytecode VM loop relies on.
+// Since the VM only knows the Element interface, those operations are provided
+// by the evaluator (pkg/exec), so that both engines share the same semantics.
+type BytecodeOps interface {
+	// Binary - calculate `left [op] right` (arith & compare operations)
+	Binary(vm *VM, op uint8, left Element, right Element) (Element, error)
+	// IsTrue - assert the element to be a bool, and get its value
+	IsTrue(elem Element) (bool, error)
+	// Duplicate - copy the value (for constants & assignments)
+	Duplicate(elem Element) Element
+	// CheckSize - check if the element exceeds the size limits of VM
+	CheckSize(vm *VM, elem Element) error
+	BuildArray(items []Element) Element
+	BuildHashMap(keys []string, values []Element) Element
+	GetMember(root Element, name string) (Element, error)
+	SetMember(root Element, name string, elem Element) error
+	GetIndex(root Element, index Element) (Element, error)
+	SetIndex(root Element, index Element, elem Element) error
+	CallFunction(vm *VM, name string, params []Element) (Element, error)
+	CallMethod(vm *VM, root Element, name string, params []Element) (Element, error)
+	NewObject(vm *VM, class Element, className string, params []Element) (Element, error)
+	ThrowException(vm *VM, class Element, className string, params []Element) error
+	Rethrow(vm *VM) error
+	Iterate(target Element) (Iterator, error)
+	// MakeClosure - create an anonymous function that captures current scope
+	MakeClosure(vm *VM, node *syntax.FunctionExpr) Element
+}
+
+// Iterator - iterate items of an array / hashmap
+type Iterator interface {
+	Next() (key Element, value Element, ok bool)
+}
+
+// IterableElement - an element that could be iterated by 遍历 besides arrays & hashmaps,
+// e.g. rows of a CSV file that are read lazily
+type IterableElement interface {
+	Element
+	Iterate() (Iterator, error)
+}
+
+// StreamIterator - an iterator that reads items from external resources (e.g. files).
+// Err() returns the error that stops the iteration halfway, and Close() releases the
+// resources; it's called once the iteration ends (including 结束循环, 输出 and errors).
+type StreamIterator interface {
+	Iterator
+	Err() error
+	Close() error
+}
+
+// IteratorError - get the error that stops the iteration, nil if all items are iterated
+func IteratorError(it Iterator) error {
+	if s, ok := it.(StreamIterator); ok {
+		return s.Err()
+	}
+	return nil
+}
+
+// CloseIterator - release the resources of the iterator (if any)
+func CloseIterator(it Iterator) {
+	if s, ok := it.(StreamIterator); ok {
+		s.Close()
+	}
+}
+
+// RunChunk - execute the compiled chunk with local slots. It returns the return value
+// (if 输出 is executed) or the value of the last top-level statement.
+func (vm *VM) RunChunk(chunk *Chunk, slots []Element, ops BytecodeOps) (Element, error) {
+	var result Element
+	stack := make([]Element, 0, 16)
+	iterators := []Iterator{}
+	// scopeDepth - number of scopes begun by this chunk; those scopes should be
+	// ended if the execution stops halfway (returned or error occurs)
+	scopeDepth := 0
+	defer func() {
+		for ; scopeDepth > 0; scopeDepth-- {
+			vm.EndScope()
+		}
+		for _, it := range iterators {
+			CloseIterator(it)
+		}
+	}()
+
+	pop := func() Element {
+		elem := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return elem
+	}
+	popN := func(n int) []Element {
+		items := make([]Element, n)
+		copy(items, stack[len(stack)-n:])
+		stack = stack[:len(stack)-n]
+		return items
+	}
+	checkSize := func(elem Element) error {
+		if vm.limits.MaxArraySize > 0 || vm.limits.MaxHashMapSize > 0 || vm.limits.MaxStringSize > 0 {
+			return ops.CheckSize(vm, elem)
+		}
+		return nil
+	}
+
+	code := chunk.Code
+	for pc := 0; pc < len(code); pc++ {
+		inst := code[pc]
+		switch inst.Op {
+		case OpNop:
+		case OpLine:
+			vm.SetCurrentLine(inst.A)
+			if err := vm.CheckInterrupt(); err != nil {
+				return nil, err
+			}
+			if err := vm.CountStep(); err != nil {
+				return nil, err
+			}
+			if err := vm.CallDebugHook(); err != nil {
+				return nil, err
+			}
+		case OpCheckInterrupt:
+			if err := vm.CheckInterrupt(); err != nil {
+				return nil, err
+			}
+		case OpConst:
+			stack = append(stack, ops.Duplicate(chunk.Consts[inst.A]))
+		case OpPop:
+			pop()
+		case OpSetResult:
+			result = pop()
+		case OpCopyValue:
+			stack[len(stack)-1] = ops.Duplicate(stack[len(stack)-1])
+		case OpLoadLocal:
+			stack = append(stack, slots[inst.A])
+		case OpDeclareLocal:
+			slots[inst.A] = stack[len(stack)-1]
+		case OpStoreLocal:
+			slots[inst.A] = stack[len(stack)-1]
+		case OpLoadName:
+			elem, err := vm.FindElement(NewIDName(chunk.Names[inst.A]))
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, elem)
+		case OpDeclareName:
+			var err error
+			if inst.B == 1 {
+				err = vm.DeclareConstElement(NewIDName(chunk.Names[inst.A]), stack[len(stack)-1])
+			} else {
+				err = vm.DeclareElement(NewIDName(chunk.Names[inst.A]), stack[len(stack)-1])
+			}
+			if err != nil {
+				return nil, err
+			}
+		case OpStoreName:
+			if err := vm.SetElement(NewIDName(chunk.Names[inst.A]), stack[len(stack)-1]); err != nil {
+				return nil, err
+			}
+		case OpLoadThis:
+			thisValue := vm.GetThisValue()
+			if thisValue == nil {
+				return nil, zerr.ThisValueNotFound()
+			}
+			stack = append(stack, thisValue)
+		case OpBeginScope:
+			vm.BeginScope()
+			scopeDepth++
+		case OpEndScope:
+			vm.EndScope()
+			scopeDepth--
+		case OpJump:
+			pc = inst.A - 1
+		case OpJumpIfFalse:
+			cond, err := ops.IsTrue(pop())
+			if err != nil {
+				return nil, err
+			}
+			if !cond {
+				pc = inst.A - 1
+			}
+		case OpJumpIfFalseOrPop, OpJumpIfTrueOrPop:
+			cond, err := ops.IsTrue(stack[len(stack)-1])
+			if err != nil {
+				return nil, err
+			}
+			if cond == (inst.Op == OpJumpIfTrueOrPop) {
+				pc = inst.A - 1
+			} else {
+				pop()
+			}
+		case OpAssertBool:
+			if _, err := ops.IsTrue(stack[len(stack)-1]); err != nil {
+				return nil, err
+			}
+		case OpBinary:
+			right := pop()
+			left := pop()
+			elem, err := ops.Binary(vm, uint8(inst.A), left, right)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkSize(elem); err != nil {
+				return nil, err
+			}
+			stack = append(stack, elem)
+		case OpBuildArray:
+			if err := vm.limits.CheckSize("array", inst.A); err != nil {
+				return nil, err
+			}
+			stack = append(stack, ops.BuildArray(popN(inst.A)))
+		case OpBuildHashMap:
+			if err := vm.limits.CheckSize("hashmap", inst.A); err != nil {
+				return nil, err
+			}
+			stack = append(stack, ops.BuildHashMap(chunk.Names[inst.B:inst.B+inst.A], popN(inst.A)))
+		case OpGetMember:
+			elem, err := ops.GetMember(pop(), chunk.Names[inst.A])
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, elem)
+		case OpSetMember:
+			root := pop()
+			if err := ops.SetMember(root, chunk.Names[inst.A], stack[len(stack)-1]); err != nil {
+				return nil, err
+			}
+			if err := checkSize(root); err != nil {
+				return nil, err
+			}
+		case OpGetIndex:
+			index := pop()
+			root := pop()
+			elem, err := ops.GetIndex(root, index)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, elem)
+		case OpSetIndex:
+			index := pop()
+			root := pop()
+			if err := ops.SetIndex(root, index, stack[len(stack)-1]); err != nil {
+				return nil, err
+			}
+			if err := checkSize(root); err != nil {
+				return nil, err
+			}
+		case OpCallFunction:
+			elem, err := ops.CallFunction(vm, chunk.Names[inst.A], popN(inst.B))
+			if err != nil {
+				return nil, err
+			}
+			if err := checkSize(elem); err != nil {
+				return nil, err
+			}
+			stack = append(stack, elem)
+		case OpCallMethod:
+			params := popN(inst.B)
+			root := pop()
+			elem, err := ops.CallMethod(vm, root, chunk.Names[inst.A], params)
+			if err != nil {
+				return nil, err
+			}
+			// methods like 添加 may change the root element in place
+			if err := checkSize(root); err != nil {
+				return nil, err
+			}
+			if err := checkSize(elem); err != nil {
+				return nil, err
+			}
+			stack = append(stack, elem)
+		case OpNewObject:
+			params := popN(inst.B)
+			class := pop()
+			elem, err := ops.NewObject(vm, class, chunk.Names[inst.A], params)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, elem)
+		case OpThrow:
+			params := popN(inst.B)
+			class := pop()
+			return nil, ops.ThrowException(vm, class, chunk.Names[inst.A], params)
+		case OpRethrow:
+			return nil, ops.Rethrow(vm)
+		case OpReturn:
+			rtnValue := pop()
+			vm.SetReturnValue(rtnValue)
+			return rtnValue, nil
+		case OpIterInit:
+			iter, err := ops.Iterate(pop())
+			if err != nil {
+				return nil, err
+			}
+			iterators = append(iterators, iter)
+		case OpIterNext:
+			iter := iterators[len(iterators)-1]
+			key, val, ok := iter.Next()
+			if !ok {
+				if err := IteratorError(iter); err != nil {
+					return nil, err
+				}
+				pc = inst.A - 1
+			} else {
+				if err := vm.CheckInterrupt(); err != nil {
+					return nil, err
+				}
+				stack = append(stack, key, val)
+			}
+		case OpIterEnd:
+			CloseIterator(iterators[len(iterators)-1])
+			iterators = iterators[:len(iterators)-1]
+		case OpMakeClosure:
+			stack = append(stack, ops.MakeClosure(vm, chunk.FuncExprs[inst.A]))
+		default:
+			return nil, zerr.UnexpectedCase("指令", inst.Op.String())
+		}
+	}
+	return result, nil
+}
@@ -21,6 +21,10 @@ type CallFrame struct {
 
 	// if returnValue is not nil, it will be returned to the caller
 	returnValue Element
+
+	// scopeBase - number of symbols in the module scope when the frame is pushed;
+	// symbols after it are declared by this frame (and its callees in the same module)
+	scopeBase int
 }
 
 func NewScriptCallFrame(module *Module) *CallFrame {
@@ -88,3 +92,8 @@ func (cf *CallFrame) IsScriptCallFrame() bool {
 func (cf *CallFrame) IsExceptionCallFrame() bool {
 	return cf.callType == CALL_TYPE_EXCEPTION_BLOCK
 }
+
+// GetCallType - get the call type of the frame (CALL_TYPE_SCRIPT / CALL_TYPE_FUNCTION / CALL_TYPE_EXCEPTION_BLOCK)
+func (cf *CallFrame) GetCallType() uint8 {
+	return cf.callType
+}
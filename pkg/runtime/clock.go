@@ -0,0 +1,93 @@
+package runtime
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+)
+
+// Clock - provides the current time & sleeping for libraries (e.g. @时间), so that the time
+// could be frozen for tests and the playground.
+type Clock interface {
+	// Now - get the current time
+	Now() time.Time
+	// Sleep - pause for the duration; returns an ExecTimeout / ExecCancelled error
+	// once ctx is done before that
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// SystemClock - the real clock, it's used by default
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return interruptError(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// FrozenClock - a clock that always returns the same time unless Advance() is called.
+// Sleep() returns immediately without advancing the time, so that the result of
+// scripts are deterministic even if they're executed concurrently.
+type FrozenClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFrozenClock - create a clock frozen at the time
+func NewFrozenClock(now time.Time) *FrozenClock {
+	return &FrozenClock{now: now}
+}
+
+func (c *FrozenClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance - move the frozen time forward by the duration
+func (c *FrozenClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *FrozenClock) Sleep(ctx context.Context, d time.Duration) error {
+	if ctx.Err() != nil {
+		return interruptError(ctx)
+	}
+	return nil
+}
+
+// SetClock - set the clock of libraries (nil = SystemClock)
+func (vm *VM) SetClock(clock Clock) {
+	vm.clock = clock
+}
+
+// GetClock - get the clock of libraries, SystemClock is returned if not set
+func (vm *VM) GetClock() Clock {
+	if vm.clock == nil {
+		return SystemClock
+	}
+	return vm.clock
+}
+
+// interruptError - get the error of a done context
+func interruptError(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return zerr.ExecTimeout()
+	}
+	return zerr.ExecCancelled()
+}
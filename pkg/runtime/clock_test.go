@@ -0,0 +1,40 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrozenClock(t *testing.T) {
+	now := time.Date(2024, 3, 15, 8, 30, 0, 0, time.UTC)
+	clock := NewFrozenClock(now)
+	assert.Equal(t, now, clock.Now())
+
+	// sleeping doesn't advance the time
+	assert.Nil(t, clock.Sleep(context.Background(), time.Hour))
+	assert.Equal(t, now, clock.Now())
+
+	clock.Advance(90 * time.Minute)
+	assert.Equal(t, now.Add(90*time.Minute), clock.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := clock.Sleep(ctx, time.Second)
+	assert.Equal(t, zerr.ErrExecCancelled, err.(*zerr.RuntimeError).Code)
+}
+
+func TestSystemClock_Sleep(t *testing.T) {
+	assert.Nil(t, SystemClock.Sleep(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := SystemClock.Sleep(ctx, time.Hour)
+	assert.Equal(t, zerr.ErrExecTimeout, err.(*zerr.RuntimeError).Code)
+
+	vm := InitVM(map[string]Element{})
+	assert.Equal(t, SystemClock, vm.GetClock())
+}
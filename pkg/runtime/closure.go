@@ -0,0 +1,58 @@
+package runtime
+
+// Closure - variables captured from the scope where an anonymous function is defined.
+// Captured variables share the same cells with the original scope, i.e. assignments
+// from either side are visible to the other.
+type Closure struct {
+	symbols []capturedSymbol
+}
+
+type capturedSymbol struct {
+	name    string
+	isConst bool
+	cell    *valueCell
+	// extModuleID - the module where the (imported) value comes from; -1 if not external
+	extModuleID int
+}
+
+// Capture - capture all visible symbols (the latest one of each name) of the scope
+func (sp *Scope) Capture() *Closure {
+	closure := &Closure{symbols: []capturedSymbol{}}
+	seen := map[string]bool{}
+	for i := sp.localCount - 1; i >= 0; i-- {
+		symbol := sp.locals[i]
+		if seen[symbol.name] {
+			continue
+		}
+		seen[symbol.name] = true
+
+		extModuleID := -1
+		if moduleID, ok := sp.externalRefs[i]; ok {
+			extModuleID = moduleID
+		}
+		closure.symbols = append(closure.symbols, capturedSymbol{
+			name:        symbol.name,
+			isConst:     symbol.isConst,
+			cell:        sp.values[i],
+			extModuleID: extModuleID,
+		})
+	}
+	// keep the declaration order
+	for i, j := 0, len(closure.symbols)-1; i < j; i, j = i+1, j-1 {
+		closure.symbols[i], closure.symbols[j] = closure.symbols[j], closure.symbols[i]
+	}
+	return closure
+}
+
+// DeclareClosure - declare captured symbols in current depth of the scope
+func (sp *Scope) DeclareClosure(closure *Closure) error {
+	for _, symbol := range closure.symbols {
+		if err := sp.declareCell(symbol.name, symbol.cell, symbol.isConst); err != nil {
+			return err
+		}
+		if symbol.extModuleID >= 0 {
+			sp.externalRefs[sp.localCount-1] = symbol.extModuleID
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package runtime
+
+// DebugHook - called before evaluating each statement (by both the AST walker and
+// the bytecode engine) when a debugger is attached. The hook may block (e.g. wait
+// for user commands) and may return an error to stop the execution.
+type DebugHook func(vm *VM) error
+
+// FrameVariable - a local variable that is visible in a call frame
+type FrameVariable struct {
+	Name    string
+	Value   Element
+	IsConst bool
+}
+
+// SetDebugHook - attach a debug hook to the VM (nil to detach)
+func (vm *VM) SetDebugHook(hook DebugHook) {
+	vm.debugHook = hook
+}
+
+// CallDebugHook - call the debug hook (if any); usually it's called at statement boundaries.
+func (vm *VM) CallDebugHook() error {
+	if vm.debugHook == nil {
+		return nil
+	}
+	return vm.debugHook(vm)
+}
+
+// GetFrameVariables - get local variables of the call frame at index frameIdx
+// (0 = bottom of the call stack). Since all call frames of a module share one scope,
+// variables of a frame are the symbols declared after the frame is pushed and before
+// the next frame of the same module is pushed.
+func (vm *VM) GetFrameVariables(frameIdx int) []FrameVariable {
+	if frameIdx < 0 || frameIdx >= vm.csCount {
+		return nil
+	}
+	frame := vm.callStack[frameIdx]
+	scope, ok := vm.valueStack[frame.module.GetID()]
+	if !ok {
+		return nil
+	}
+
+	end := scope.localCount
+	for _, next := range vm.callStack[frameIdx+1 : vm.csCount] {
+		if next.module == frame.module {
+			end = next.scopeBase
+			break
+		}
+	}
+	return scope.GetSymbols(frame.scopeBase, end)
+}
@@ -0,0 +1,19 @@
+package runtime
+
+// DecimalContext - how decimals are divided when the quotient is not exact (e.g. 1 / 3).
+// A nil context means the defaults: 16 digits after the decimal point at least, 四舍五入.
+type DecimalContext struct {
+	// DivisionScale - the minimum number of digits after the decimal point of the quotient
+	DivisionScale int
+	// RoundingMode - the rounding mode of the quotient (e.g. value.RoundHalfEven)
+	RoundingMode uint8
+}
+
+// SetDecimalContext - set how decimals are divided (nil for the defaults)
+func (vm *VM) SetDecimalContext(ctx *DecimalContext) {
+	vm.decimalContext = ctx
+}
+
+func (vm *VM) GetDecimalContext() *DecimalContext {
+	return vm.decimalContext
+}
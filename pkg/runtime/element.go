@@ -27,4 +27,11 @@ type ConstructableElement interface {
 	Construct(params []Element) (Element, error)
 }
 
+// subtype of Element: methods of the element depend on the VM that executes them (e.g. the exec
+// limits); when the method is called from scripts, ExecMethodVM() is used instead of ExecMethod().
+type VMMethodElement interface {
+	Element
+	ExecMethodVM(vm *VM, name string, params []Element) (Element, error)
+}
+
 type FuncExecutor = func(receiver Element, params []Element) (Element, error)
@@ -0,0 +1,46 @@
+package runtime
+
+import (
+	"math/big"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+)
+
+// ExchangeRateProvider - provides exchange rates to convert currencies (e.g. `（兑换：金额、“USD”）`).
+// A nil provider means no exchange rate is available at all.
+type ExchangeRateProvider interface {
+	// GetRate - get the exchange rate of `from` -> `to` (currency codes, e.g. "USD", "CNY"),
+	// i.e. 1 [from] = rate [to]
+	GetRate(from string, to string) (*big.Rat, error)
+}
+
+// FixedExchangeRates - exchange rates from a fixed table (e.g. for tests or daily settlement).
+// The key is "FROM/TO" (e.g. "USD/CNY") and the value is the rate in decimal text (e.g. "7.1").
+// If a rate is not defined, its reverse rate (e.g. "CNY/USD") is used instead.
+type FixedExchangeRates map[string]string
+
+// GetRate -
+func (f FixedExchangeRates) GetRate(from string, to string) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+	if rateStr, ok := f[from+"/"+to]; ok {
+		if rate, ok := new(big.Rat).SetString(rateStr); ok && rate.Sign() > 0 {
+			return rate, nil
+		}
+	} else if rateStr, ok := f[to+"/"+from]; ok {
+		if rate, ok := new(big.Rat).SetString(rateStr); ok && rate.Sign() > 0 {
+			return rate.Inv(rate), nil
+		}
+	}
+	return nil, zerr.ArithExchangeRateNotFound(from, to)
+}
+
+// SetExchangeRateProvider - set the provider of exchange rates for currency conversion
+func (vm *VM) SetExchangeRateProvider(provider ExchangeRateProvider) {
+	vm.rateProvider = provider
+}
+
+func (vm *VM) GetExchangeRateProvider() ExchangeRateProvider {
+	return vm.rateProvider
+}
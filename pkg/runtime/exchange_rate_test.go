@@ -0,0 +1,29 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedExchangeRates_GetRate(t *testing.T) {
+	rates := FixedExchangeRates{"USD/CNY": "7.2", "HKD/CNY": "invalid"}
+
+	rate, err := rates.GetRate("USD", "CNY")
+	assert.Nil(t, err)
+	assert.Equal(t, "36/5", rate.String())
+
+	// derive the reverse rate
+	rate, err = rates.GetRate("CNY", "USD")
+	assert.Nil(t, err)
+	assert.Equal(t, "5/36", rate.String())
+
+	rate, err = rates.GetRate("EUR", "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, "1/1", rate.String())
+
+	_, err = rates.GetRate("USD", "EUR")
+	assert.NotNil(t, err)
+	_, err = rates.GetRate("HKD", "CNY")
+	assert.NotNil(t, err)
+}
@@ -15,6 +15,8 @@ func (id *IDName) GetLiteral() string {
 type IDNumber struct {
 	Literal  string
 	NumValue float64
+	// Unit - the unit suffix of number (e.g. kg, km/h); empty for plain numbers
+	Unit string
 }
 
 func (id *IDNumber) GetLiteral() string {
@@ -25,6 +27,36 @@ func (id *IDNumber) GetValue() float64 {
 	return id.NumValue
 }
 
+// IDCurrency - currency literal, e.g. $20.3, CNY$100
+type IDCurrency struct {
+	Literal string
+	// Code - currency code, e.g. USD
+	Code string
+	// Amount - amount in decimal text, e.g. "20.3"
+	Amount string
+}
+
+func (id *IDCurrency) GetLiteral() string {
+	return id.Literal
+}
+
+// IDDateTime - date or datetime literal, e.g. 2024-03-15, 2024年3月15日8时30分
+type IDDateTime struct {
+	Literal string
+	Year    int
+	Month   int
+	Day     int
+	Hour    int
+	Minute  int
+	Second  int
+	// DateOnly - true if there's no time part (e.g. 2024-03-15)
+	DateOnly bool
+}
+
+func (id *IDDateTime) GetLiteral() string {
+	return id.Literal
+}
+
 func NewIDName(name string) *IDName {
 	return &IDName{
 		Literal: name,
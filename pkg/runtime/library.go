@@ -3,6 +3,9 @@ package runtime
 type Library struct {
 	name         string
 	exportValues map[string]ExportableElement
+	// binder - [optional] build a new library that depends on the VM importing it, e.g.
+	// the permission policy, the clock or the random number generator of the VM
+	binder func(vm *VM) *Library
 }
 
 func NewLibrary(name string) *Library {
@@ -30,6 +33,23 @@ func (l *Library) RegisterFunction(name string, fn ExportableElement) *Library {
 	return l
 }
 
+// SetBinder - set the function to build a library bound to the VM that imports it,
+// e.g. the library checks the permission policy (@文件), or gets the current time from
+// the clock of the VM (@时间).
+func (l *Library) SetBinder(binder func(vm *VM) *Library) *Library {
+	l.binder = binder
+	return l
+}
+
+// Bind - get the library bound to the VM. If the library doesn't depend on
+// the VM, the library itself will be returned.
+func (l *Library) Bind(vm *VM) *Library {
+	if l.binder == nil {
+		return l
+	}
+	return l.binder(vm)
+}
+
 func (l *Library) addExportValue(name string, value ExportableElement) {
 	l.exportValues[name] = value
 }
@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	zerr "github.com/DemoHn/Zn/pkg/error"
+)
+
+// DefaultMaxCallDepth - even if no limits are set, the call depth is still limited
+// to avoid crashing the Go stack by unbounded recursion.
+const DefaultMaxCallDepth = 5000
+
+// ExecLimits - resource limits for executing untrusted scripts (e.g. playground).
+// For all fields, 0 means no limit, except MaxCallDepth (0 means DefaultMaxCallDepth).
+type ExecLimits struct {
+	// MaxSteps - max number of evaluated statements
+	MaxSteps int
+	// MaxCallDepth - max depth of the call stack
+	MaxCallDepth int
+	// MaxArraySize - max number of items of an array
+	MaxArraySize int
+	// MaxHashMapSize - max number of key-value pairs of a hashmap
+	MaxHashMapSize int
+	// MaxStringSize - max length (in bytes) of a string
+	MaxStringSize int
+}
+
+func (vm *VM) SetExecLimits(limits ExecLimits) {
+	if limits.MaxCallDepth <= 0 {
+		limits.MaxCallDepth = DefaultMaxCallDepth
+	}
+	vm.limits = limits
+}
+
+func (vm *VM) GetExecLimits() ExecLimits {
+	return vm.limits
+}
+
+// CheckSize - check if an element of the size (number of items for "array" & "hashmap",
+// length in bytes for "string") exceeds the limits. It's called BEFORE the element is created.
+func (l ExecLimits) CheckSize(typeName string, size int) error {
+	var maxSize int
+	switch typeName {
+	case "array":
+		maxSize = l.MaxArraySize
+	case "hashmap":
+		maxSize = l.MaxHashMapSize
+	case "string":
+		maxSize = l.MaxStringSize
+	}
+	if maxSize > 0 && size > maxSize {
+		return zerr.ElementSizeExceeded(typeName, maxSize)
+	}
+	return nil
+}
+
+// CountStep - count one evaluated statement, and check if the step budget is exhausted
+func (vm *VM) CountStep() error {
+	vm.steps += 1
+	if vm.limits.MaxSteps > 0 && vm.steps > vm.limits.MaxSteps {
+		return zerr.ExecStepsExceeded(vm.limits.MaxSteps)
+	}
+	return nil
+}
+
+// GetSteps - get the number of evaluated statements so far
+func (vm *VM) GetSteps() int {
+	return vm.steps
+}
@@ -44,6 +44,10 @@ const (
 
 type ModuleCodeFinder func(isMain bool, info LibNameInfo) ([]rune, error)
 
+// ModuleProgramLoader - get the parsed program of a module directly (e.g. from a program cache).
+// If set, it's used instead of parsing the source code found by ModuleCodeFinder.
+type ModuleProgramLoader func(isMain bool, info LibNameInfo) (*syntax.Program, error)
+
 // NativeCodeModule is a virtual 'module' for [[native code]] - no program AST, id = -1
 // the module is initialized automatically on init() function
 var NativeCodeModule *Module
@@ -184,6 +188,12 @@ func (g *ModuleGraph) checkCircularDepedencyDFS() bool {
 // originalName - original string passed to parseLibName
 // libType - parsed libType (LIB_TYPE_STD, LIB_TYPE_VENDOR, LIB_TYPE_CUSTOM)
 // libPath - separate full libstring to subPath, e.g. "A-B-C" -> []string{"A", "B", "C"}
+//
+// libName formats:
+// "@A-B"   -> LIB_TYPE_STD, standard library
+// "#P-A-B" -> LIB_TYPE_VENDOR, module "A-B" from vendor package "P" (the first item of libPath is the package name);
+// "#P"     -> LIB_TYPE_VENDOR, the entry module of vendor package "P"
+// "A-B"    -> LIB_TYPE_CUSTOM, module from current project
 func ParseLibName(libName string) LibNameInfo {
 	if strings.HasPrefix(libName, "@") {
 		return LibNameInfo{
@@ -193,6 +203,14 @@ func ParseLibName(libName string) LibNameInfo {
 		}
 	}
 
+	if strings.HasPrefix(libName, "#") {
+		return LibNameInfo{
+			OriginalName: libName,
+			LibType:      LIB_TYPE_VENDOR,
+			LibPath:      strings.Split(libName[1:], "-"),
+		}
+	}
+
 	return LibNameInfo{
 		OriginalName: libName,
 		LibType:      LIB_TYPE_CUSTOM,
@@ -2,6 +2,8 @@ package runtime
 
 import (
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 type graph = map[string][]string
@@ -130,3 +132,25 @@ func transformGraph(g graph) [][2]int {
 	}
 	return res
 }
+
+func TestParseLibName(t *testing.T) {
+	cases := []struct {
+		libName string
+		libType uint8
+		libPath []string
+	}{
+		{"@文件", LIB_TYPE_STD, []string{"文件"}},
+		{"#订单工具", LIB_TYPE_VENDOR, []string{"订单工具"}},
+		{"#订单工具-折扣-计算", LIB_TYPE_VENDOR, []string{"订单工具", "折扣", "计算"}},
+		{"模块A-模块B", LIB_TYPE_CUSTOM, []string{"模块A", "模块B"}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.libName, func(t *testing.T) {
+			info := ParseLibName(tt.libName)
+			assert.Equal(t, tt.libName, info.OriginalName)
+			assert.Equal(t, tt.libType, info.LibType)
+			assert.Equal(t, tt.libPath, info.LibPath)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package runtime
+
+import (
+	"path/filepath"
+	"strings"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+)
+
+// PermissionPolicy - restricts what a script is allowed to do (e.g. scripts from playground).
+// A nil policy means no restriction at all.
+type PermissionPolicy struct {
+	// AllowedLibs - names of libraries that could be imported (e.g. @JSON).
+	// nil means all libraries are allowed.
+	AllowedLibs []string
+	// FileRoots - dirs that @文件 could read/write. Files outside those dirs are denied;
+	// if empty, no file is accessible.
+	FileRoots []string
+	// HTTPHosts - hosts that @HTTP could send requests to. "*.example.com" matches all
+	// subdomains of example.com; if empty, no host is accessible.
+	HTTPHosts []string
+}
+
+// CheckLibrary - check if the library could be imported
+func (p *PermissionPolicy) CheckLibrary(name string) error {
+	if p == nil || p.AllowedLibs == nil {
+		return nil
+	}
+	for _, lib := range p.AllowedLibs {
+		if lib == name {
+			return nil
+		}
+	}
+	return zerr.PermissionDenied("library", name)
+}
+
+// CheckFilePath - check if the file (or dir) is located inside one of FileRoots
+func (p *PermissionPolicy) CheckFilePath(path string) error {
+	if p == nil {
+		return nil
+	}
+	absPath, err := resolvePath(path)
+	if err != nil {
+		return zerr.PermissionDenied("file", path)
+	}
+	for _, root := range p.FileRoots {
+		absRoot, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(absRoot, absPath)
+		if err != nil {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return zerr.PermissionDenied("file", path)
+}
+
+// CheckHTTPHost - check if the host (without port) is one of HTTPHosts
+func (p *PermissionPolicy) CheckHTTPHost(host string) error {
+	if p == nil {
+		return nil
+	}
+	host = strings.ToLower(host)
+	for _, allowed := range p.HTTPHosts {
+		allowed = strings.ToLower(allowed)
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(host, allowed[1:]) {
+				return nil
+			}
+		} else if host == allowed {
+			return nil
+		}
+	}
+	return zerr.PermissionDenied("host", host)
+}
+
+// resolvePath - get the absolute path with symlinks evaluated, so that a
+// symlink inside FileRoots could not escape from them.
+// For files not exist yet (e.g. to be written), resolve its parent dir instead.
+func resolvePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if realPath, err := filepath.EvalSymlinks(absPath); err == nil {
+		return realPath, nil
+	}
+	realDir, err := filepath.EvalSymlinks(filepath.Dir(absPath))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(realDir, filepath.Base(absPath)), nil
+}
@@ -0,0 +1,62 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionPolicy_NilPolicy(t *testing.T) {
+	var policy *PermissionPolicy
+	assert.Nil(t, policy.CheckLibrary("@文件"))
+	assert.Nil(t, policy.CheckFilePath("/etc/passwd"))
+	assert.Nil(t, policy.CheckHTTPHost("example.com"))
+}
+
+func TestPermissionPolicy_CheckLibrary(t *testing.T) {
+	// nil AllowedLibs - all libraries are allowed
+	policy := &PermissionPolicy{}
+	assert.Nil(t, policy.CheckLibrary("@文件"))
+
+	policy = &PermissionPolicy{AllowedLibs: []string{"@JSON"}}
+	assert.Nil(t, policy.CheckLibrary("@JSON"))
+	assert.NotNil(t, policy.CheckLibrary("@文件"))
+}
+
+func TestPermissionPolicy_CheckFilePath(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "数据"), 0755))
+	// a symlink inside the root that points to outside
+	assert.Nil(t, os.Symlink(outside, filepath.Join(root, "链接")))
+
+	policy := &PermissionPolicy{FileRoots: []string{root}}
+
+	assert.Nil(t, policy.CheckFilePath(root))
+	assert.Nil(t, policy.CheckFilePath(filepath.Join(root, "数据")))
+	// file not exists yet
+	assert.Nil(t, policy.CheckFilePath(filepath.Join(root, "数据", "新文件.txt")))
+
+	assert.NotNil(t, policy.CheckFilePath(filepath.Join(root, "..", "其他.txt")))
+	assert.NotNil(t, policy.CheckFilePath(filepath.Join(outside, "A.txt")))
+	assert.NotNil(t, policy.CheckFilePath(filepath.Join(root, "链接", "A.txt")))
+	assert.NotNil(t, policy.CheckFilePath("/etc/passwd"))
+
+	// empty FileRoots - no file is accessible
+	assert.NotNil(t, (&PermissionPolicy{}).CheckFilePath(filepath.Join(root, "数据")))
+}
+
+func TestPermissionPolicy_CheckHTTPHost(t *testing.T) {
+	policy := &PermissionPolicy{HTTPHosts: []string{"api.example.com", "*.zinc.dev"}}
+
+	assert.Nil(t, policy.CheckHTTPHost("api.example.com"))
+	assert.Nil(t, policy.CheckHTTPHost("API.Example.com"))
+	assert.Nil(t, policy.CheckHTTPHost("docs.zinc.dev"))
+
+	assert.NotNil(t, policy.CheckHTTPHost("example.com"))
+	assert.NotNil(t, policy.CheckHTTPHost("zinc.dev"))
+	assert.NotNil(t, policy.CheckHTTPHost("evilzinc.dev"))
+	assert.NotNil(t, policy.CheckHTTPHost("127.0.0.1"))
+}
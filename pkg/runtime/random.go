@@ -0,0 +1,49 @@
+package runtime
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Random - the random number generator for 取随机数 and libraries (e.g. @数学). It's seeded
+// by the current time by default; set a fixed seed to make the results reproducible for tests.
+//
+// Random is safe for concurrent use.
+type Random struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewRandom - create a random number generator with the seed
+func NewRandom(seed int64) *Random {
+	return &Random{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Float64 - get a random number in [0, 1)
+func (r *Random) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Float64()
+}
+
+// Int63n - get a random integer in [0, n), n should be greater than 0
+func (r *Random) Int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Int63n(n)
+}
+
+// SetRandom - set the random number generator of the execution (nil = seeded by the time)
+func (vm *VM) SetRandom(random *Random) {
+	vm.random = random
+}
+
+// GetRandom - get the random number generator, a new one seeded by the current time is
+// created if not set
+func (vm *VM) GetRandom() *Random {
+	if vm.random == nil {
+		vm.random = NewRandom(time.Now().UnixNano())
+	}
+	return vm.random
+}
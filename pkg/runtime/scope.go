@@ -9,13 +9,20 @@ type Scope struct {
 	locals       []LocalSymbol
 	localCount   int
 	currentDepth int
-	values       []Element
+	// values - each symbol holds a cell of value, so that closures could share
+	// the same variable with the scope where they're defined
+	values []*valueCell
 	// symbolID -> moduleID - since external value from other modules
 	// is defined first and no chance to be poped from 'locals', we
 	// can add externalRefs to record
 	externalRefs map[int]int
 }
 
+// valueCell - a box of the symbol's value
+type valueCell struct {
+	value Element
+}
+
 type LocalSymbol struct {
 	name    string
 	depth   int
@@ -27,7 +34,7 @@ func NewScope() *Scope {
 		locals:       []LocalSymbol{},
 		localCount:   0,
 		currentDepth: 0,
-		values:       []Element{},
+		values:       []*valueCell{},
 		externalRefs: map[int]int{},
 	}
 }
@@ -48,7 +55,7 @@ func (sp *Scope) EndScope() {
 func (sp *Scope) GetValue(name string) Element {
 	symbolID := sp.getSymbolID(name)
 	if symbolID >= 0 && symbolID < len(sp.values) {
-		return sp.values[symbolID]
+		return sp.values[symbolID].value
 	}
 	return nil
 }
@@ -59,9 +66,9 @@ func (sp *Scope) GetValueWithModuleID(name string) (Element, int) {
 	if symbolID >= 0 && symbolID < len(sp.values) {
 		extModuleID, ok := sp.externalRefs[symbolID]
 		if ok {
-			return sp.values[symbolID], extModuleID
+			return sp.values[symbolID].value, extModuleID
 		} else {
-			return sp.values[symbolID], -1
+			return sp.values[symbolID].value, -1
 		}
 	}
 	return nil, -1
@@ -75,7 +82,7 @@ func (sp *Scope) SetValue(name string, value Element) error {
 				// error: cannot change const value
 				return zerr.AssignToConstant()
 			}
-			sp.values[i] = value
+			sp.values[i].value = value
 			return nil
 		}
 	}
@@ -101,6 +108,25 @@ func (sp *Scope) DeclareExternalValue(name string, value Element, moduleID int)
 	return nil
 }
 
+// GetSymbols - get symbols (with values) of index range [from, to) that are still in scope
+func (sp *Scope) GetSymbols(from int, to int) []FrameVariable {
+	if from < 0 {
+		from = 0
+	}
+	if to > sp.localCount {
+		to = sp.localCount
+	}
+	vars := []FrameVariable{}
+	for i := from; i < to; i++ {
+		vars = append(vars, FrameVariable{
+			Name:    sp.locals[i].name,
+			Value:   sp.values[i].value,
+			IsConst: sp.locals[i].isConst,
+		})
+	}
+	return vars
+}
+
 // getSymbolID - get the latest symbolID that matches the name
 // when not found, return -1
 func (sp *Scope) getSymbolID(name string) int {
@@ -114,6 +140,10 @@ func (sp *Scope) getSymbolID(name string) int {
 
 // declareValue - add new symbol to scope
 func (sp *Scope) declareValue(name string, value Element, isConst bool) error {
+	return sp.declareCell(name, &valueCell{value: value}, isConst)
+}
+
+func (sp *Scope) declareCell(name string, cell *valueCell, isConst bool) error {
 	for i := sp.localCount - 1; i >= 0; i-- {
 		if sp.locals[i].depth < sp.currentDepth {
 			break
@@ -138,7 +168,9 @@ func (sp *Scope) declareValue(name string, value Element, isConst bool) error {
 		depth:   sp.currentDepth,
 		isConst: isConst,
 	})
-	sp.values = append(sp.values[:sp.localCount], value)
+	sp.values = append(sp.values[:sp.localCount], cell)
+	// the slot may be used by a popped external symbol before
+	delete(sp.externalRefs, sp.localCount)
 	sp.localCount++
 
 	return nil
@@ -87,8 +87,8 @@ func assertSnapshot(t *testing.T, sp *Scope, scopeSnapshot string) {
 		t.Errorf("values length = %d, want %d", len(sp.values), len(values))
 	}
 	for idx, value := range values {
-		if value != sp.values[idx].(MockValue).value {
-			t.Errorf("idx=[%d] value = %s, want %s", idx, sp.values[idx].(MockValue).value, value)
+		if value != sp.values[idx].value.(MockValue).value {
+			t.Errorf("idx=[%d] value = %s, want %s", idx, sp.values[idx].value.(MockValue).value, value)
 		}
 	}
 }
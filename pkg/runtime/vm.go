@@ -1,6 +1,8 @@
 package runtime
 
 import (
+	"context"
+
 	zerr "github.com/DemoHn/Zn/pkg/error"
 	"github.com/DemoHn/Zn/pkg/syntax"
 )
@@ -28,6 +30,38 @@ type VM struct {
 
 	// moduleCodeFinder - HOWTO get the source code of a module
 	moduleCodeFinder ModuleCodeFinder
+	// moduleProgramLoader - [optional] HOWTO get the parsed program of a module
+	moduleProgramLoader ModuleProgramLoader
+
+	// ctx - the execution will be stopped once ctx is done (timeout or cancelled)
+	ctx context.Context
+
+	// limits - resource limits of the execution
+	limits ExecLimits
+	// steps - number of evaluated statements
+	steps int
+
+	// policy - permission policy of the execution (nil = no restriction)
+	policy *PermissionPolicy
+
+	// rateProvider - [optional] provides exchange rates for currency conversion
+	rateProvider ExchangeRateProvider
+
+	// decimalContext - [optional] how decimals are divided (nil = the defaults)
+	decimalContext *DecimalContext
+
+	// clock - [optional] provides the current time for libraries (nil = SystemClock)
+	clock Clock
+
+	// random - [optional] the random number generator (nil = seeded by the time)
+	random *Random
+
+	// bytecode - if enabled, exec blocks will be compiled to bytecode and executed by RunChunk()
+	// instead of walking the AST
+	bytecode bool
+
+	// debugHook - [optional] called before evaluating each statement
+	debugHook DebugHook
 }
 
 type ElementMap = map[string]Element
@@ -42,6 +76,30 @@ func InitVM(globals map[string]Element) *VM {
 		csModuleID:       -1, // 0 for main module
 		moduleCodeFinder: nil,
 		moduleGraph:      NewModuleGraph(),
+		ctx:              context.Background(),
+		limits:           ExecLimits{MaxCallDepth: DefaultMaxCallDepth},
+		steps:            0,
+	}
+}
+
+// SetContext - set the context to control the execution (e.g. timeout, cancellation)
+func (vm *VM) SetContext(ctx context.Context) {
+	vm.ctx = ctx
+}
+
+// GetContext - get the context of the execution
+func (vm *VM) GetContext() context.Context {
+	return vm.ctx
+}
+
+// CheckInterrupt - check if the execution should be stopped; usually it's called
+// at statement boundaries.
+func (vm *VM) CheckInterrupt() error {
+	select {
+	case <-vm.ctx.Done():
+		return interruptError(vm.ctx)
+	default:
+		return nil
 	}
 }
 
@@ -53,15 +111,44 @@ func (vm *VM) SetModuleCodeFinder(moduleCodeFinder ModuleCodeFinder) {
 	vm.moduleCodeFinder = moduleCodeFinder
 }
 
+func (vm *VM) GetModuleProgramLoader() ModuleProgramLoader {
+	return vm.moduleProgramLoader
+}
+
+func (vm *VM) SetModuleProgramLoader(loader ModuleProgramLoader) {
+	vm.moduleProgramLoader = loader
+}
+
 func (vm *VM) LoadExternalLibs(libs []*Library) {
 	for _, lib := range libs {
 		vm.externalLibs[lib.GetName()] = lib
 	}
 }
 
+// SetBytecode - enable/disable the bytecode engine
+func (vm *VM) SetBytecode(enabled bool) {
+	vm.bytecode = enabled
+}
+
+func (vm *VM) IsBytecodeEnabled() bool {
+	return vm.bytecode
+}
+
+// SetPermissionPolicy - set the permission policy to restrict libraries & resources
+func (vm *VM) SetPermissionPolicy(policy *PermissionPolicy) {
+	vm.policy = policy
+}
+
+func (vm *VM) GetPermissionPolicy() *PermissionPolicy {
+	return vm.policy
+}
+
 func (vm *VM) FindLibrary(name string) (*Library, error) {
 	if library, ok := vm.externalLibs[name]; ok {
-		return library, nil
+		if err := vm.policy.CheckLibrary(name); err != nil {
+			return nil, err
+		}
+		return library.Bind(vm), nil
 	}
 	return nil, zerr.LibraryNotFound(name)
 }
@@ -101,11 +188,17 @@ func (vm *VM) CheckDepedency(name string) error {
 
 // PushCallFrame - push a call frame onto the call stack
 // and update the current call stack cursor accordingly.
-func (vm *VM) PushCallFrame(callFrame *CallFrame) {
+// If the call stack exceeds max call depth, the frame won't be pushed.
+func (vm *VM) PushCallFrame(callFrame *CallFrame) error {
+	if vm.csCount >= vm.limits.MaxCallDepth {
+		return zerr.CallDepthExceeded(vm.limits.MaxCallDepth)
+	}
 	vm.callStack = append(vm.callStack, callFrame)
 	vm.csCount += 1
 	vm.csModuleID = callFrame.module.GetID()
 	vm.initValueStack(vm.csModuleID)
+	callFrame.scopeBase = vm.valueStack[vm.csModuleID].localCount
+	return nil
 }
 
 func (vm *VM) PopCallFrame() {
@@ -249,6 +342,24 @@ func (vm *VM) DeclareExternalElement(name *IDName, elem Element, module *Module)
 	return scope.DeclareExternalValue(name.GetLiteral(), elem, module.GetID())
 }
 
+// CaptureClosure - capture visible variables of current scope for an anonymous function
+func (vm *VM) CaptureClosure() *Closure {
+	scope := vm.getCurrentScope()
+	if scope == nil {
+		return &Closure{symbols: []capturedSymbol{}}
+	}
+	return scope.Capture()
+}
+
+// DeclareClosure - declare captured variables of the closure in current scope
+func (vm *VM) DeclareClosure(closure *Closure) error {
+	scope := vm.getCurrentScope()
+	if scope == nil {
+		return zerr.NameNotDefined("")
+	}
+	return scope.DeclareClosure(closure)
+}
+
 func (vm *VM) SetElement(name *IDName, elem Element) error {
 	scope := vm.getCurrentScope()
 	nameStr := name.GetLiteral()
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"testing"
+	"time"
 
 	"github.com/DemoHn/Zn/pkg/syntax"
 	"github.com/stretchr/testify/assert"
@@ -134,3 +135,60 @@ func TestPushCallFrame_PopCallFrame(t *testing.T) {
 func TestDeclareElement(t *testing.T) {
 
 }
+
+func TestGetFrameVariables(t *testing.T) {
+	vm := InitVM(globalValuesI)
+	module := vm.AllocateModule("main", mockProgram)
+
+	// script frame: 令C = 1
+	vm.PushCallFrame(NewScriptCallFrame(module))
+	vm.DeclareElement(NewIDName("C"), MockValue{"C"})
+	// function frame of the same module: 输入D
+	vm.PushCallFrame(NewFunctionCallFrame(module, nil))
+	vm.BeginScope()
+	vm.DeclareElement(NewIDName("D"), MockValue{"D"})
+
+	scriptVars := vm.GetFrameVariables(0)
+	assert.Equal(t, 1, len(scriptVars))
+	assert.Equal(t, "C", scriptVars[0].Name)
+
+	funcVars := vm.GetFrameVariables(1)
+	assert.Equal(t, 1, len(funcVars))
+	assert.Equal(t, "D", funcVars[0].Name)
+
+	// variables are popped after the scope ends
+	vm.EndScope()
+	assert.Equal(t, 0, len(vm.GetFrameVariables(1)))
+	assert.Nil(t, vm.GetFrameVariables(2))
+}
+
+func TestFindLibrary_Bind(t *testing.T) {
+	vm := InitVM(globalValuesI)
+	policy := &PermissionPolicy{FileRoots: []string{"/data"}}
+	clock := NewFrozenClock(time.Date(2024, 3, 15, 8, 30, 0, 0, time.UTC))
+	vm.SetPermissionPolicy(policy)
+	vm.SetClock(clock)
+
+	// the library depends on both the policy & the clock of the VM
+	var boundPolicy *PermissionPolicy
+	var boundClock Clock
+	var binder func(vm *VM) *Library
+	binder = func(v *VM) *Library {
+		boundPolicy, boundClock = v.GetPermissionPolicy(), v.GetClock()
+		return NewLibrary("@测试").SetBinder(binder)
+	}
+	lib := NewLibrary("@测试").SetBinder(binder)
+	plain := NewLibrary("@其他")
+	vm.LoadExternalLibs([]*Library{lib, plain})
+
+	bound, err := vm.FindLibrary("@测试")
+	assert.Nil(t, err)
+	assert.NotSame(t, lib, bound)
+	assert.Same(t, policy, boundPolicy)
+	assert.Equal(t, Clock(clock), boundClock)
+
+	// libraries without binder are returned as is
+	found, err := vm.FindLibrary("@其他")
+	assert.Nil(t, err)
+	assert.Same(t, plain, found)
+}
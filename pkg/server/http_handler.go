@@ -31,7 +31,7 @@ func (h *ZnHttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"当前请求": reqObj,
 	}
 	// execute code
-	rtnValue, err := h.interpreter.LoadFile(h.entryFile).Execute(varInput)
+	rtnValue, err := h.interpreter.LoadFile(h.entryFile).ExecuteContext(r.Context(), varInput)
 	sendHTTPResponse(rtnValue, err, w)
 }
 
@@ -46,7 +46,7 @@ func buildIncomingRequestBody(req *http.Request) (runtime.Element, error) {
 
 	contentType := req.Header.Get("Content-Type")
 	if contentType == "application/json" {
-		return common.JSONStringToElement(value.NewString(string(body)))
+		return common.JSONStringToElement(value.NewString(string(body)), false)
 	}
 	return value.NewString(string(body)), nil
 }
@@ -64,11 +64,24 @@ func buildIncomingRequest(r *http.Request) (runtime.Element, error) {
 
 	qsDict := value.NewEmptyHashMap()
 	for k, v := range r.URL.Query() {
-		if len(v) > 0 {
+		switch len(v) {
+		case 0:
+			continue
+		case 1:
 			qsDict.AppendKVPair(value.KVPair{
 				Key:   k,
 				Value: value.NewString(v[0]),
 			})
+		default:
+			// repeated keys (e.g. ?tag=a&tag=b) - all values are kept in an array
+			items := value.NewEmptyArray()
+			for _, item := range v {
+				items.AppendValue(value.NewString(item))
+			}
+			qsDict.AppendKVPair(value.KVPair{
+				Key:   k,
+				Value: items,
+			})
 		}
 	}
 
@@ -63,6 +63,10 @@ func WriteDataToNamedPipe(pipeWriter *os.File, b []byte) error {
 	return err
 }
 
+func RemoveNamedPipe(p *pipe) error {
+	return os.Remove(fmt.Sprintf(gPipeNameFmt, p.id))
+}
+
 func CloseFD(f *os.File) {
 	syscall.CloseOnExec(int(f.Fd()))
 }
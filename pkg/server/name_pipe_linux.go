@@ -0,0 +1,81 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const gPipeNameFmt = "/tmp/zinc-server-pipe-%s"
+
+type pipe struct {
+	id string
+}
+
+func NewPipe(id string) *pipe {
+	return &pipe{id: id}
+}
+
+func GetPipeID(p *pipe) string {
+	return p.id
+}
+
+func CreateNamedPipe() (*pipe, error) {
+	id := (rand.Int63() >> 32) | (time.Now().Unix() << 32)
+	idStr := strconv.FormatInt(id, 16)
+
+	if err := syscall.Mkfifo(fmt.Sprintf(gPipeNameFmt, idStr), 0666); err != nil {
+		return nil, err
+	}
+
+	return &pipe{id: idStr}, nil
+}
+
+// OpenNamedPipeReader - on Linux, the fifo is opened with O_RDWR instead of O_RDONLY:
+// 1. open() returns immediately instead of blocking until the first worker opens the writer side
+// 2. the master itself holds a writer, so Read() never gets EOF when all workers have exited
+// (e.g. all of them are killed due to timeout)
+func OpenNamedPipeReader(p *pipe) (*os.File, error) {
+	pipeFile := fmt.Sprintf(gPipeNameFmt, p.id)
+	pipeReader, err := os.OpenFile(pipeFile, os.O_RDWR, os.ModeNamedPipe)
+	if err != nil {
+		return nil, err
+	}
+	return pipeReader, nil
+}
+
+// ReadDataFromNamedPipe - read exactly len(b) bytes from the pipe.
+// Since every state msg (5 bytes) is less than PIPE_BUF, writes from different workers
+// won't be interleaved.
+func ReadDataFromNamedPipe(pipeReader *os.File, b []byte) error {
+	_, err := io.ReadFull(pipeReader, b)
+	return err
+}
+
+func OpenNamedPipeWriter(p *pipe) (*os.File, error) {
+	pipeFile := fmt.Sprintf(gPipeNameFmt, p.id)
+	pipeWriter, err := os.OpenFile(pipeFile, os.O_WRONLY, 0777)
+	if err != nil {
+		return nil, err
+	}
+	return pipeWriter, nil
+}
+
+func WriteDataToNamedPipe(pipeWriter *os.File, b []byte) error {
+	_, err := pipeWriter.Write(b)
+	return err
+}
+
+// RemoveNamedPipe - unlink the fifo file. Processes that have already opened the pipe
+// could still read/write data on it.
+func RemoveNamedPipe(p *pipe) error {
+	return os.Remove(fmt.Sprintf(gPipeNameFmt, p.id))
+}
+
+func CloseFD(f *os.File) {
+	syscall.CloseOnExec(int(f.Fd()))
+}
@@ -39,6 +39,10 @@ func WriteDataToNamedPipe(pipeWriter *os.File, b []byte) error {
 	panic("Not Supported in Windows!")
 }
 
+func RemoveNamedPipe(p *pipe) error {
+	panic("Not Supported in Windows!")
+}
+
 func CloseFD(f *os.File) {
 	syscall.CloseOnExec(syscall.Handle(f.Fd()))
 }
@@ -24,7 +24,8 @@ func (ph *ZnPlaygroundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	if err != nil {
 		writeResponseForPlayground(w, nil, err)
 	} else {
-		rtnValue, err := ph.interpreter.LoadScript(source).Execute(varInput)
+		// stop execution once the request is cancelled or timeout
+		rtnValue, err := ph.interpreter.LoadScript(source).ExecuteContext(r.Context(), varInput)
 		writeResponseForPlayground(w, rtnValue, err)
 	}
 }
@@ -49,6 +49,15 @@ type childProcManager struct {
 	addChan    chan workerState
 	updateChan chan workerState
 	delChan    chan int
+	// request a snapshot of current child states
+	listChan chan chan []workerState
+	// closed to stop maintaining child states & kill all child processes (see stop())
+	stopChan chan struct{}
+	stopOnce sync.Once
+	// pipeReader - the reader side of the named pipe, it's closed by stop() to
+	// unblock readNamedPipe()
+	pipeReader *os.File
+	readerLock sync.Mutex
 }
 
 type workerState struct {
@@ -71,6 +80,8 @@ func NewZnPMServer(config ZnPMServerConfig) *ZnPMServer {
 			addChan:    make(chan workerState),
 			updateChan: make(chan workerState),
 			delChan:    make(chan int),
+			listChan:   make(chan chan []workerState),
+			stopChan:   make(chan struct{}),
 			refCount:   0,
 		},
 		config: config,
@@ -133,21 +144,10 @@ func (zns *ZnPMServer) StartMaster(connUrl string, cfg ZnPMServerConfig) error {
 		log.Fatalf("CreatePipe: failed - %s", err)
 		return err
 	}
+	defer RemoveNamedPipe(p)
 
 	// kill child processes when master exits
-	defer func() {
-		var s sync.RWMutex
-		s.Lock()
-		for _, procState := range zns.childs {
-			proc := procState.cmd
-			if err := proc.Process.Kill(); err != nil {
-				if !errors.Is(err, os.ErrProcessDone) {
-					log.Fatalf("spawnProcs: failed to kill child: %v", err)
-				}
-			}
-		}
-		s.Unlock()
-	}()
+	defer zns.stop()
 
 	//// read named pipe data to recv msg from child process
 	go zns.readNamedPipe(p)
@@ -177,6 +177,12 @@ func (zns *ZnPMServer) StartMaster(connUrl string, cfg ZnPMServerConfig) error {
 
 // // fork child processes
 func (zns *ZnPMServer) spawnProcess(cfg ZnPMServerConfig, l *net.TCPListener, p *pipe) error {
+	// do not spawn new processes after the master is stopped
+	select {
+	case <-zns.stopChan:
+		return nil
+	default:
+	}
 	// prepare net.Conn file to transfer to child processes
 	lf, err := l.File()
 	if err != nil {
@@ -207,16 +213,26 @@ func (zns *ZnPMServer) spawnProcess(cfg ZnPMServerConfig, l *net.TCPListener, p
 	pid := cmd.Process.Pid
 
 	// register new child process to workerState
-	zns.addChan <- workerState{
+	select {
+	case zns.addChan <- workerState{
 		pid:   pid,
 		state: WORKER_STATE_IDLE,
 		cmd:   cmd,
+	}:
+	case <-zns.stopChan:
+		// the master is stopped - the child process is no longer maintained
+		cmd.Process.Kill()
+		go cmd.Wait()
+		return nil
 	}
 	// send msg to channel when cmd ends running
 	go func() {
 		cmd.Wait()
 		// after cmd is done, send pid to del channel
-		zns.delChan <- pid
+		select {
+		case zns.delChan <- pid:
+		case <-zns.stopChan:
+		}
 	}()
 
 	return nil
@@ -225,7 +241,10 @@ func (zns *ZnPMServer) spawnProcess(cfg ZnPMServerConfig, l *net.TCPListener, p
 func (zns *ZnPMServer) readNamedPipe(pipe *pipe) {
 	pipeReader, err := OpenNamedPipeReader(pipe)
 	if err != nil {
-		log.Fatal("[PARENT] Open named pipe file error:", err)
+		log.Printf("[PARENT] Open named pipe file error: %s", err)
+		return
+	}
+	if !zns.setPipeReader(pipeReader) {
 		return
 	}
 
@@ -235,22 +254,46 @@ func (zns *ZnPMServer) readNamedPipe(pipe *pipe) {
 		var pid int
 		// read packet
 		if err := ReadDataFromNamedPipe(pipeReader, buf); err != nil {
-			log.Fatalf("[PARENT] read buffer failed: %s", err)
-			continue
+			// the reader is closed by stop()
+			select {
+			case <-zns.stopChan:
+			default:
+				log.Printf("[PARENT] read buffer failed: %s", err)
+			}
+			return
 		}
 
 		// digest state & pid
 		pid = int(binary.BigEndian.Uint32(buf))
 		state = buf[4]
 
-		zns.updateChan <- workerState{
+		select {
+		case zns.updateChan <- workerState{
 			pid:   pid,
 			state: state,
 			cmd:   nil,
+		}:
+		case <-zns.stopChan:
+			return
 		}
 	}
 }
 
+// setPipeReader - keep the reader of named pipe so that stop() could close it.
+// If the master is already stopped, the reader is closed directly and it returns false.
+func (zns *ZnPMServer) setPipeReader(pipeReader *os.File) bool {
+	zns.readerLock.Lock()
+	defer zns.readerLock.Unlock()
+	select {
+	case <-zns.stopChan:
+		pipeReader.Close()
+		return false
+	default:
+		zns.pipeReader = pipeReader
+		return true
+	}
+}
+
 // summon all writing actions into one goroutine to ensure thread-safe on writing.
 func (zns *ZnPMServer) maintainChildState(cfg ZnPMServerConfig, ln *net.TCPListener, p *pipe) {
 	for {
@@ -297,6 +340,23 @@ func (zns *ZnPMServer) maintainChildState(cfg ZnPMServerConfig, ln *net.TCPListe
 					}
 				}()
 			}
+		case reply := <-zns.listChan:
+			states := make([]workerState, 0, len(zns.childs))
+			for _, w := range zns.childs {
+				states = append(states, w)
+			}
+			reply <- states
+		case <-zns.stopChan:
+			// kill child processes (and do not respawn them any more)
+			for _, procState := range zns.childs {
+				proc := procState.cmd
+				if err := proc.Process.Kill(); err != nil {
+					if !errors.Is(err, os.ErrProcessDone) {
+						log.Fatalf("spawnProcs: failed to kill child: %v", err)
+					}
+				}
+			}
+			return
 		case pid := <-zns.delChan:
 			delete(zns.childs, pid)
 			zns.refCount -= 1
@@ -320,6 +380,33 @@ func (zns *ZnPMServer) maintainChildState(cfg ZnPMServerConfig, ln *net.TCPListe
 	}
 }
 
+// listChildStates - get a snapshot of all child processes' states.
+// NOTE: it's only available when maintainChildState() is running.
+func (zns *ZnPMServer) listChildStates() []workerState {
+	reply := make(chan []workerState)
+	select {
+	case zns.listChan <- reply:
+		return <-reply
+	case <-zns.stopChan:
+		return nil
+	}
+}
+
+// stop - stop maintaining child states, kill all child processes and close the reader
+// of named pipe; it's safe to call stop() multiple times, even if maintainChildState()
+// has already returned.
+func (zns *ZnPMServer) stop() {
+	zns.stopOnce.Do(func() {
+		zns.readerLock.Lock()
+		defer zns.readerLock.Unlock()
+
+		close(zns.stopChan)
+		if zns.pipeReader != nil {
+			zns.pipeReader.Close()
+		}
+	})
+}
+
 ////////////////////////////
 /////// WORKER logic ///////
 ////////////////////////////
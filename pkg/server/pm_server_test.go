@@ -0,0 +1,219 @@
+//go:build !windows
+
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestMain - when the test binary is re-executed by spawnProcess() as a child worker
+// (with --child-worker flag & prefork env), run the worker loop instead of the tests.
+func TestMain(m *testing.M) {
+	if os.Getenv(EnvPreforkChildKey) == EnvPreforkChildVal {
+		zns := NewZnPMServer(ZnPMServerConfig{})
+		zns.SetHandler(http.HandlerFunc(sleepHandler))
+		if err := zns.Start(""); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// sleepHandler - sleep for ?ms=<N> milliseconds, then respond "OK"
+func sleepHandler(w http.ResponseWriter, r *http.Request) {
+	if ms, err := strconv.Atoi(r.URL.Query().Get("ms")); err == nil {
+		time.Sleep(time.Duration(ms) * time.Millisecond)
+	}
+	respondOK(w, "OK")
+}
+
+// startTestMaster - the same as StartMaster() but without signal handling, so that
+// the test could inspect the state of child processes.
+func startTestMaster(t *testing.T, cfg ZnPMServerConfig) (*ZnPMServer, string) {
+	zns := NewZnPMServer(cfg)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	ln := l.(*net.TCPListener)
+
+	p, err := CreateNamedPipe()
+	if err != nil {
+		t.Fatalf("CreateNamedPipe failed: %v", err)
+	}
+
+	go zns.readNamedPipe(p)
+	go zns.maintainChildState(cfg, ln, p)
+
+	for i := 0; i < cfg.InitProcs; i++ {
+		if err := zns.spawnProcess(cfg, ln, p); err != nil {
+			t.Fatalf("spawnProcess failed: %v", err)
+		}
+	}
+
+	t.Cleanup(func() {
+		zns.stop()
+		ln.Close()
+		RemoveNamedPipe(p)
+	})
+
+	return zns, ln.Addr().String()
+}
+
+// waitForStates - poll child states until cond() returns true or timeout
+func waitForStates(t *testing.T, zns *ZnPMServer, desc string, cond func([]workerState) bool) []workerState {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		states := zns.listChildStates()
+		if cond(states) {
+			return states
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("wait for %s: timeout, current states: %v", desc, zns.listChildStates())
+	return nil
+}
+
+func countState(states []workerState, state uint8) int {
+	num := 0
+	for _, w := range states {
+		if w.state == state {
+			num++
+		}
+	}
+	return num
+}
+
+func sendRequest(addr string, sleepMs int) (*http.Response, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("http://%s/?ms=%d", addr, sleepMs), nil)
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return http.ReadResponse(bufio.NewReader(conn), req)
+}
+
+func TestZnPMServer_ChildStateIdleBusy(t *testing.T) {
+	zns, addr := startTestMaster(t, ZnPMServerConfig{
+		InitProcs: 2,
+		MaxProcs:  2,
+		Timeout:   10,
+	})
+
+	waitForStates(t, zns, "2 idle workers", func(s []workerState) bool {
+		return len(s) == 2 && countState(s, WORKER_STATE_IDLE) == 2
+	})
+
+	done := make(chan error)
+	go func() {
+		resp, err := sendRequest(addr, 800)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				err = fmt.Errorf("unexpected status: %d", resp.StatusCode)
+			}
+		}
+		done <- err
+	}()
+
+	waitForStates(t, zns, "1 busy worker", func(s []workerState) bool {
+		return countState(s, WORKER_STATE_BUSY) == 1 && countState(s, WORKER_STATE_IDLE) == 1
+	})
+
+	if err := <-done; err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	waitForStates(t, zns, "all workers back to idle", func(s []workerState) bool {
+		return len(s) == 2 && countState(s, WORKER_STATE_IDLE) == 2
+	})
+}
+
+func TestZnPMServer_ChildStateStoppedOnTimeout(t *testing.T) {
+	zns, addr := startTestMaster(t, ZnPMServerConfig{
+		InitProcs: 1,
+		MaxProcs:  1,
+		Timeout:   1,
+	})
+
+	initStates := waitForStates(t, zns, "1 idle worker", func(s []workerState) bool {
+		return len(s) == 1 && countState(s, WORKER_STATE_IDLE) == 1
+	})
+	oldPid := initStates[0].pid
+
+	// the request exceeds timeout, so the worker will be stopped & exit
+	go func() {
+		if resp, err := sendRequest(addr, 5000); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	// the worker reports STOPPED state before it exits
+	waitForStates(t, zns, "worker stopped", func(s []workerState) bool {
+		return len(s) == 1 && s[0].pid == oldPid && s[0].state == WORKER_STATE_STOPPED
+	})
+
+	// the stopped worker is removed, and a new worker is spawned to keep `InitProcs` workers
+	waitForStates(t, zns, "worker respawned", func(s []workerState) bool {
+		return len(s) == 1 && s[0].pid != oldPid && s[0].state == WORKER_STATE_IDLE
+	})
+
+	// the new worker could handle requests normally
+	resp, err := sendRequest(addr, 0)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expect status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestZnPMServer_StopClosesPipeReader(t *testing.T) {
+	zns := NewZnPMServer(ZnPMServerConfig{})
+	p, err := CreateNamedPipe()
+	if err != nil {
+		t.Fatalf("CreateNamedPipe failed: %v", err)
+	}
+	defer RemoveNamedPipe(p)
+
+	done := make(chan struct{})
+	go func() {
+		zns.readNamedPipe(p)
+		close(done)
+	}()
+
+	// wait until the reader is opened (or give up and test stop() before opening)
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		zns.readerLock.Lock()
+		opened := zns.pipeReader != nil
+		zns.readerLock.Unlock()
+		if opened {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	zns.stop()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("readNamedPipe() is still blocked after stop()")
+	}
+}
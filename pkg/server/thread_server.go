@@ -5,18 +5,27 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"time"
 )
 
 // NOTE: although we name it 'thread' server, we actually use goroutine
 // as low level thread model instead of traditional "threads"
 type ZnThreadServer struct {
 	reqHandler http.Handler
+	// timeout - max execution time (in seconds) of each request, 0 means no limit.
+	// when timeout, the request context is cancelled so that the execution is stopped.
+	timeout int
 }
 
 func (zns *ZnThreadServer) SetHandler(handler http.Handler) {
 	zns.reqHandler = handler
 }
 
+func (zns *ZnThreadServer) SetTimeout(timeout int) *ZnThreadServer {
+	zns.timeout = timeout
+	return zns
+}
+
 func (zns *ZnThreadServer) Start(connUrl string) error {
 	if zns.reqHandler == nil {
 		return fmt.Errorf("处理逻辑未设置，需使用 setHandler() 配置")
@@ -33,7 +42,11 @@ func (zns *ZnThreadServer) Start(connUrl string) error {
 		return err
 	}
 
-	return http.Serve(ln, zns.reqHandler)
+	handler := zns.reqHandler
+	if zns.timeout > 0 {
+		handler = http.TimeoutHandler(handler, time.Duration(zns.timeout)*time.Second, "程序执行超时")
+	}
+	return http.Serve(ln, handler)
 }
 
 func NewZnThreadServer() *ZnThreadServer {
@@ -155,6 +155,11 @@ type ContinueStmt struct {
 	StmtBase
 }
 
+// RethrowStmt - 抛出！ (throw the exception caught by current 拦截 block again)
+type RethrowStmt struct {
+	StmtBase
+}
+
 // declare import libType enum
 const (
 	// LibTypeStd - standard lib
@@ -179,10 +184,15 @@ type StmtBlock struct {
 //
 //    拦截异常：  /* CatchBlock */
 //        令...   /* StmtBlock inside CatchBlock */
+//
+//    善后：      /* FinallyBlock */
+//        令...
 type ExecBlock struct {
 	InputBlock []*ID
 	StmtBlock  *StmtBlock
 	CatchBlock []*CatchBlockPair
+	// FinallyBlock - always executed at the end of the exec block (nil if not defined)
+	FinallyBlock *StmtBlock
 }
 
 const (
@@ -217,6 +227,8 @@ type FunctionReturnStmt struct {
 type ClassDeclareStmt struct {
 	StmtBase
 	ClassName *ID
+	// 继承XX (nil if the class has no parent class)
+	ParentClass *ID
 	// 其XX为XX
 	PropertyList []*PropertyDeclareStmt
 	// 如何XXX？
@@ -292,6 +304,14 @@ type ObjNewExpr struct {
 	Params    []Expression
 }
 
+// FunctionExpr - anonymous function (closure) that captures the scope where it's defined
+// Example:
+//    如何（X、Y）：X + Y
+type FunctionExpr struct {
+	ExprBase
+	ExecBlock *ExecBlock
+}
+
 // FuncCallExpr - function call
 type FuncCallExpr struct {
 	ExprBase
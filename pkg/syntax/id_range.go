@@ -314,6 +314,7 @@ var idRange = []runePair{
 	{0x2071, 0x2071},
 	{0x207f, 0x207f},
 	{0x2090, 0x209c},
+	{0x20a0, 0x20c0}, // currency symbols: ₠-⃀ (including €, ₩, ₹)
 	{0x20d0, 0x20dc},
 	{0x20e1, 0x20e1},
 	{0x20e5, 0x20f0},
@@ -441,6 +442,8 @@ var idRange = []runePair{
 	{0xffca, 0xffcf},
 	{0xffd2, 0xffd7},
 	{0xffda, 0xffdc},
+	{0xffe0, 0xffe1}, // ￠, ￡
+	{0xffe5, 0xffe6}, // ￥, ￦
 	// NOTE: 这里不考虑添加 emoji 支持，主要是考虑到目前 emoji 没有一个统一的编码来显示；如果加进去的话会导致不同设备显示混乱
 }
 
@@ -40,6 +40,11 @@ func TestIdInRange(t *testing.T) {
 			ids:    []rune{'f', 'Z', 'u', 'Ñ', 'â', 'æ', 'ö', 'µ', 'Š', 'Ʒ'},
 			expect: true,
 		},
+		{
+			name:   "currency symbols",
+			ids:    []rune{'¥', '£', '€', '₹', '￥', '￡'},
+			expect: true,
+		},
 		{
 			name:   "units",
 			ids:    []rune{'°', '²', '³'},
@@ -33,10 +33,17 @@ func StringifyAST(node Node) string {
 				StringifyAST(c.ExceptionClass), StringifyAST(c.StmtBlock)))
 		}
 
-		return fmt.Sprintf("$X(I=(%s) S=(%s) C=(%s))",
+		// finally block
+		finallyStr := ""
+		if v.FinallyBlock != nil {
+			finallyStr = fmt.Sprintf(" F=(%s)", StringifyAST(v.FinallyBlock))
+		}
+
+		return fmt.Sprintf("$X(I=(%s) S=(%s) C=(%s)%s)",
 			strings.Join(idList, " "),
 			StringifyAST(v.StmtBlock),
 			strings.Join(catchStrList, " "),
+			finallyStr,
 		)
 	// expressions
 	case *ArrayExpr:
@@ -129,6 +136,8 @@ func StringifyAST(node Node) string {
 			params = append(params, StringifyAST(p))
 		}
 		return fmt.Sprintf("$NEW(class=(%s) params=(%s))", StringifyAST(v.ClassName), strings.Join(params, " "))
+	case *FunctionExpr:
+		return fmt.Sprintf("$FE(block=(%s))", StringifyAST(v.ExecBlock))
 	case *EmptyStmt:
 		return "$"
 	case *VarDeclareStmt:
@@ -244,10 +253,15 @@ func StringifyAST(node Node) string {
 		for _, g := range v.GetterList {
 			getterStr = append(getterStr, StringifyAST(g))
 		}
+		parentStr := ""
+		if v.ParentClass != nil {
+			parentStr = fmt.Sprintf(" parent=(%s)", StringifyAST(v.ParentClass))
+		}
 
 		return fmt.Sprintf(
-			"$CLS(name=(%s) properties=(%s) methods=(%s) getters=(%s))",
+			"$CLS(name=(%s)%s properties=(%s) methods=(%s) getters=(%s))",
 			StringifyAST(v.ClassName),
+			parentStr,
 			strings.Join(propertyStr, " "),
 			strings.Join(methodStr, " "),
 			strings.Join(getterStr, " "),
@@ -256,6 +270,8 @@ func StringifyAST(node Node) string {
 		return "$BREAK"
 	case *ContinueStmt:
 		return "$CONTINUE"
+	case *RethrowStmt:
+		return "$RETHROW"
 	case *PropertyDeclareStmt:
 		return fmt.Sprintf(
 			"$PD(id=(%s) expr=(%s))",
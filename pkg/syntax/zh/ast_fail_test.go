@@ -191,6 +191,23 @@ code=20 cursor=21
 	输入A、&&B	
 --------
 code=20 cursor=14
+========
+4. anonymous function without block or colon
+--------
+令F = 如何（A）
+--------
+code=20 cursor=10
+========
+5. catch block after finally block
+--------
+如何搞个大新闻？
+	输出1
+	善后：
+		输出2
+	拦截异常：
+		输出3
+--------
+code=20 cursor=26
 `
 
 const importStmtCasesFAIL = `
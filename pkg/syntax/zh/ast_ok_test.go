@@ -21,6 +21,7 @@ var testSuccessSuites = []string{
 	iterateCasesOK,
 	classDeclareCasesOK,
 	functionDeclareCasesOK,
+	functionExprCasesOK,
 	importStmtCasesOK,
 }
 
@@ -1077,6 +1078,35 @@ $PG($X(I=() S=($BK(
 	)
 )) C=()
 ))
+========
+5. class definition with parent class
+--------
+定义柯基继承狗：
+	其腿长设为10
+
+	如何狂吠？
+		输出“嗷呜”
+--------
+$PG($X(I=() S=($BK(
+	$CLS(
+		name=($ID(柯基))
+		parent=($ID(狗))
+		properties=(
+			$PD(id=($ID(腿长)) expr=($ID(10)))
+		)
+		methods=(
+			$FN(
+				type=FN
+				name=($ID(狂吠))
+				block=($X(I=() S=($BK(
+					$RT($STR(嗷呜))
+				)) C=()))
+			)
+		)
+		getters=()
+	)
+)) C=()
+))
 `
 
 const functionDeclareCasesOK = `
@@ -1175,6 +1205,57 @@ $PG($X(I=() S=($BK(
 ))
 `
 
+const functionExprCasesOK = `
+========
+1. block form
+--------
+令F = 如何？
+	输入A、B
+	输出A + B
+--------
+$PG($X(I=() S=($BK(
+	$VD(
+		$VP(vars[]=($ID(F)) expr[]=($FE(block=($X(
+			I=($ID(A) $ID(B))
+			S=($BK($RT($AR(type=(ADD) left=($ID(A)) right=($ID(B))))))
+			C=()
+		)))))
+	)))
+	C=()
+))
+========
+2. inline form with params
+--------
+以数组（映射：如何（X、Y）：X * Y）
+--------
+$PG($X(I=() S=($BK(
+	$MMF(
+		root=($ID(数组))
+		chain=($FN(name=($ID(映射)) params=($FE(block=($X(
+			I=($ID(X) $ID(Y))
+			S=($BK($RT($AR(type=(MUL) left=($ID(X)) right=($ID(Y))))))
+			C=()
+		))))))
+	)))
+	C=()
+))
+========
+3. inline form without params
+--------
+令F = 如何：（显示：A）
+--------
+$PG($X(I=() S=($BK(
+	$VD(
+		$VP(vars[]=($ID(F)) expr[]=($FE(block=($X(
+			I=()
+			S=($BK($RT($FN(name=($ID(显示)) params=($ID(A))))))
+			C=()
+		)))))
+	)))
+	C=()
+))
+`
+
 // ////// BY FUNC ////////
 // test ParseProgram() only
 const testProgramOKCases = `
@@ -1240,6 +1321,37 @@ $X(
 	S=($BK($RT($ID(233))))
 	C=()
 )
+==========
+4. with catch block & finally block
+---------
+令X = 1
+
+拦截异常1：
+	抛出！
+善后：
+	X = 2
+---------
+$X(
+	I=()
+	S=($BK($VD($VP(vars[]=($ID(X)) expr[]=($ID(1))))))
+	C=( cls[]=($ID(异常1)) stmt[]=($BK($RETHROW)) )
+	F=($BK($VA(target=($ID(X)) assign=($ID(2)))))
+)
+==========
+5. with finally block only
+---------
+输入A
+
+输出A
+善后：
+	（显示：A）
+---------
+$X(
+	I=($ID(A))
+	S=($BK($RT($ID(A))))
+	C=()
+	F=($BK($FN(name=($ID(显示)) params=($ID(A)))))
+)
 `
 
 var testByFuncCaseList = []struct {
@@ -42,8 +42,12 @@ const (
 	GlyphLI rune = 0x5386
 	// GlyphQU - 取 -
 	GlyphQU rune = 0x53D6
+	// GlyphHOU - 后 - 善后
+	GlyphHOU rune = 0x540E
 	// GlyphFOU - 否 - 否则
 	GlyphFOU rune = 0x5426
+	// GlyphSHAN - 善 - 善后
+	GlyphSHAN rune = 0x5584
 	// GlyphDA - 大 - 大于，不大于
 	GlyphDA rune = 0x5927
 	// GlyphRU - 如 - 如果，如何，再如
@@ -72,6 +76,8 @@ const (
 	GlyphHUO rune = 0x6216
 	// GlyphJIEy - 截 - 拦截
 	GlyphJIEy rune = 0x622A
+	// GlyphCHENGy - 承 - 继承
+	GlyphCHENGy rune = 0x627F
 	// GlyphPAO - 抛 - 抛出
 	GlyphPAO rune = 0x629B
 	// GlyphLAN - 拦 - 拦截
@@ -96,7 +102,7 @@ const (
 	GlyphDENG rune = 0x7B49
 	// GlyphJIE - 结 - 结束循环
 	GlyphJIE rune = 0x7ED3
-	// GlyphJI - 继 - 继续循环
+	// GlyphJI - 继 - 继续循环，继承
 	GlyphJI rune = 0x7EE7
 	// GlyphXU - 续 - 继续循环
 	GlyphXU rune = 0x7EED
@@ -144,6 +150,8 @@ const (
 	TypeThrowErrorW  uint8 = 79 // 抛出
 	TypeContinueW    uint8 = 80 // 继续循环
 	TypeBreakW       uint8 = 81 // 结束循环
+	TypeExtendsW     uint8 = 82 // 继承
+	TypeFinallyW     uint8 = 83 // 善后
 )
 
 // parseKeyword -
@@ -314,6 +322,9 @@ func parseKeyword(l *syntax.Lexer, moveForward bool) (bool, syntax.Token, error)
 		if l.Peek() == GlyphXU && l.Peek2() == GlyphXUN && l.Peek3() == GlyphHUAN {
 			wordLen = 4
 			tk.Type = TypeContinueW
+		} else if l.Peek() == GlyphCHENGy {
+			wordLen = 2
+			tk.Type = TypeExtendsW
 		} else {
 			return false, syntax.Token{}, nil
 		}
@@ -341,6 +352,13 @@ func parseKeyword(l *syntax.Lexer, moveForward bool) (bool, syntax.Token, error)
 		} else {
 			return false, syntax.Token{}, nil
 		}
+	case GlyphSHAN:
+		if l.Peek() == GlyphHOU {
+			wordLen = 2
+			tk.Type = TypeFinallyW
+		} else {
+			return false, syntax.Token{}, nil
+		}
 	}
 
 	// tk not empty
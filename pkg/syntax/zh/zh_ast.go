@@ -108,7 +108,12 @@ func ParseStatement(p *ParserZH) syntax.Statement {
 		case TypeObjDefineW:
 			s = ParseClassDeclareStmt(p)
 		case TypeThrowErrorW:
-			s = ParseThrowExceptionStmt(p)
+			// 抛出！ - rethrow the caught exception
+			if match, _ := p.tryConsume(TypeExceptionT); match {
+				s = &syntax.RethrowStmt{}
+			} else {
+				s = ParseThrowExceptionStmt(p)
+			}
 		case TypeBreakW:
 			s = ParseBreakStmt(p)
 		case TypeContinueW:
@@ -465,6 +470,7 @@ func ParseMemberExpr(p *ParserZH) syntax.Expression {
 // BsE   -> { E }
 //       -> （ FuncID ： E、E、...）
 //       -> 以 E （ FuncID ： E、E、...）
+//       -> 如何 FuncExprT'
 //       -> ID
 //       -> String
 //       -> ArrayList
@@ -478,6 +484,7 @@ func ParseBasicExpr(p *ParserZH) syntax.Expression {
 		TypeStmtQuoteL,
 		TypeFuncQuoteL,
 		TypeVarOneW,
+		TypeFuncW,
 	}
 
 	match, tk := p.tryConsume(validTypes...)
@@ -502,6 +509,8 @@ func ParseBasicExpr(p *ParserZH) syntax.Expression {
 			}
 		case TypeVarOneW:
 			e = ParseMemberFuncCallExpr(p)
+		case TypeFuncW:
+			e = ParseFunctionExpr(p)
 		}
 		p.setStmtCurrentLine(e, tk)
 		return e
@@ -509,6 +518,64 @@ func ParseBasicExpr(p *ParserZH) syntax.Expression {
 	panic(p.getInvalidSyntaxPeek())
 }
 
+// ParseFunctionExpr - yield FunctionExpr node (anonymous function); the leading 如何 has
+// been consumed before.
+//
+// The block form declares inputs & statements like 如何XX？ - since the block ends at
+// the end of line, it must be the last expression of the statement:
+//
+// 令加法 = 如何？
+//     输入X、Y
+//     输出X + Y
+//
+// The inline form yields the value of ONE expression, thus it could be written as a
+// param of function calls:
+//
+// 以数组（映射：如何（X）：X * 2）
+//
+// CFG:
+// FuncExprT' -> ？ ExecBlock
+//            -> （ IdfList ） ： Expr
+//            -> ： Expr
+func ParseFunctionExpr(p *ParserZH) *syntax.FunctionExpr {
+	// #1. block form
+	if match, _ := p.tryConsume(TypeFuncDeclare); match {
+		ok, blockIndent := p.expectBlockIndent()
+		if !ok {
+			panic(p.getUnexpectedIndentPeek())
+		}
+		return &syntax.FunctionExpr{
+			ExecBlock: ParseExecBlock(p, blockIndent),
+		}
+	}
+
+	// #2. inline form
+	execBlock := &syntax.ExecBlock{
+		InputBlock: []*syntax.ID{},
+		StmtBlock:  &syntax.StmtBlock{},
+		CatchBlock: []*syntax.CatchBlockPair{},
+	}
+	if match, _ := p.tryConsume(TypeFuncQuoteL); match {
+		parsePauseCommaList(p, func() {
+			execBlock.InputBlock = append(execBlock.InputBlock, parseID(p))
+		})
+		p.consume(TypeFuncQuoteR)
+	}
+	match, tk := p.tryConsume(TypeFuncCall)
+	if !match {
+		panic(p.getInvalidSyntaxPeek())
+	}
+	returnStmt := &syntax.FunctionReturnStmt{
+		ReturnExpr: ParseExpression(p),
+	}
+	p.setStmtCurrentLine(returnStmt, tk)
+	execBlock.StmtBlock.Children = append(execBlock.StmtBlock.Children, returnStmt)
+
+	return &syntax.FunctionExpr{
+		ExecBlock: execBlock,
+	}
+}
+
 // ParseArrayExpr - yield ArrayExpr node (support both hashMap and arrayList)
 // CFG:
 // ArrayExpr -> 【 ItemList 】
@@ -1026,7 +1093,7 @@ func parseFunctionBlock(p *ParserZH) (*syntax.ID, *syntax.ExecBlock) {
 	return xID, xExecBlock
 }
 
-// ParseExecBlock - execBlock = inputStmt + stmtBlock + catchBlock
+// ParseExecBlock - execBlock = inputStmt + stmtBlock + catchBlock + finallyBlock
 func ParseExecBlock(p *ParserZH, mainIndent int) *syntax.ExecBlock {
 	execBlock := &syntax.ExecBlock{
 		InputBlock: []*syntax.ID{},
@@ -1034,13 +1101,14 @@ func ParseExecBlock(p *ParserZH, mainIndent int) *syntax.ExecBlock {
 		CatchBlock: []*syntax.CatchBlockPair{},
 	}
 	const (
-		stateInputBlock = 1
-		stateStmtBlock  = 2
-		stateCatchBlock = 3
+		stateInputBlock   = 1
+		stateStmtBlock    = 2
+		stateCatchBlock   = 3
+		stateFinallyBlock = 4
 	)
 
 	var validEndStates = []int{
-		stateStmtBlock, stateCatchBlock,
+		stateStmtBlock, stateCatchBlock, stateFinallyBlock,
 	}
 
 	var hState = stateInputBlock
@@ -1060,6 +1128,9 @@ func ParseExecBlock(p *ParserZH, mainIndent int) *syntax.ExecBlock {
 			if match, _ := p.tryConsume(TypeCatchErrorW); match {
 				execBlock.CatchBlock = append(execBlock.CatchBlock, ParseCatchErrorStmt(p))
 				hState = stateCatchBlock
+			} else if match, _ := p.tryConsume(TypeFinallyW); match {
+				execBlock.FinallyBlock = ParseFinallyStmt(p)
+				hState = stateFinallyBlock
 			} else {
 				// 2. parse statement block
 				stmt := ParseStatement(p)
@@ -1069,7 +1140,13 @@ func ParseExecBlock(p *ParserZH, mainIndent int) *syntax.ExecBlock {
 			p.unsetStmtCompleteFlag()
 			if match, _ := p.tryConsume(TypeCatchErrorW); match {
 				execBlock.CatchBlock = append(execBlock.CatchBlock, ParseCatchErrorStmt(p))
+			} else if match, _ := p.tryConsume(TypeFinallyW); match {
+				execBlock.FinallyBlock = ParseFinallyStmt(p)
+				hState = stateFinallyBlock
 			}
+		case stateFinallyBlock:
+			// 善后 block MUST be the last one
+			panic(p.getInvalidSyntaxPeek())
 		}
 	})
 
@@ -1268,6 +1345,24 @@ func ParseCatchErrorStmt(p *ParserZH) *syntax.CatchBlockPair {
 	}
 }
 
+// ParseFinallyStmt - parse "善后" block (like Python's `finally`)
+//
+// CFG:
+//
+// FinallyStmt   ->  善后 ：  ^[stmtBlock]
+func ParseFinallyStmt(p *ParserZH) *syntax.StmtBlock {
+	// #1. parse colon
+	p.consume(TypeFuncCall)
+
+	// #2. parse block manually
+	ok, newIndent := p.expectBlockIndent()
+	if !ok {
+		panic(p.getUnexpectedIndentPeek())
+	}
+
+	return ParseBlockStmt(p, newIndent)
+}
+
 // ParseImportStmt - parse import syntax.Statement
 // CFG:
 // ImportStmt  ->  导入 String ImportTail
@@ -1308,7 +1403,7 @@ func ParseImportStmt(p *ParserZH) *syntax.ImportStmt {
 // ParseClassDeclareStmt - define class structure
 // A typical class may look like this:
 //
-// 定义 <NAME>：
+// 定义 <NAME>：    (or 定义 <NAME> 继承 <PARENT>：)
 //    其 <Prop1> 为 <Value1>     <-- PropertyDeclare (for listing all properties with initial value)
 //    其 <Prop2> 为 <Value2>
 //
@@ -1323,6 +1418,8 @@ func ParseImportStmt(p *ParserZH) *syntax.ImportStmt {
 // CFG:
 // ClassStmt  ->  定义 ClassID ：
 //                    ClassDeclareBlock
+//            ->  定义 ClassID 继承 ClassID ：
+//                    ClassDeclareBlock
 //
 // ClassDeclareBlock  -> ClassDeclareBlockItem1  ClassDeclareBlockItem2 ...
 //
@@ -1335,6 +1432,10 @@ func ParseClassDeclareStmt(p *ParserZH) *syntax.ClassDeclareStmt {
 	var cdStmt = new(syntax.ClassDeclareStmt)
 	// #1. consume ID
 	cdStmt.ClassName = parseID(p)
+	// #1.1 consume parent class (optional)
+	if match, _ := p.tryConsume(TypeExtendsW); match {
+		cdStmt.ParentClass = parseID(p)
+	}
 
 	// #2. parse colon
 	p.consume(TypeFuncCall)
@@ -52,58 +52,98 @@ func (ar *Array) AppendValue(value r.Element) {
 	ar.value = append(ar.value, value)
 }
 
+var arrayGetterMap = map[string]arrayGetterFunc{
+	"文本": arrayGetText,
+	"首项": arrayGetFirstItem,
+	"末项": arrayGetLastItem,
+	"数目": arrayGetLength,
+	"长度": arrayGetLength,
+	"逆序": arrayGetReverse,
+}
+
 // GetProperty -
 func (ar *Array) GetProperty(name string) (r.Element, error) {
-	arrayGetterMap := map[string]arrayGetterFunc{
-		"文本": arrayGetText,
-		"首项": arrayGetFirstItem,
-		"末项": arrayGetLastItem,
-		"数目": arrayGetLength,
-		"长度": arrayGetLength,
-		"逆序": arrayGetReverse,
-	}
-
 	if fn, ok := arrayGetterMap[name]; ok {
 		return fn(ar)
 	}
 	return nil, zerr.PropertyNotFound(name)
 }
 
+var arraySetterMap = map[string]arraySetterFunc{
+	"首项": arraySetFirstItem,
+	"末项": arraySetLastItem,
+}
+
 // SetProperty -
 func (ar *Array) SetProperty(name string, value r.Element) error {
-	arraySetterMap := map[string]arraySetterFunc{
-		"首项": arraySetFirstItem,
-		"末项": arraySetLastItem,
-	}
-
 	if fn, ok := arraySetterMap[name]; ok {
 		return fn(ar, value)
 	}
 	return zerr.PropertyNotFound(name)
 }
 
+var arrayMethodMap = map[string]arrayMethodFunc{
+	"新增": arrayExecInsert,
+	"添加": arrayExecInsert,
+	"前增": arrayExecPrepend,
+	"后增": arrayExecAppend,
+	"左移": arrayExecShift,
+	"右移": arrayExecPop,
+	"拼接": arrayExecJoin,
+	"合并": arrayExecMerge,
+	"包含": arrayExecContains,
+	"寻找": arrayExecFind,
+	"交换": arrayExecSwap,
+	"映射": arrayExecMap,
+	"过滤": arrayExecFilter,
+}
+
 // ExecMethod -
 func (ar *Array) ExecMethod(name string, values []r.Element) (r.Element, error) {
-	arrayMethodMap := map[string]arrayMethodFunc{
-		"新增": arrayExecInsert,
-		"添加": arrayExecInsert,
-		"前增": arrayExecPrepend,
-		"后增": arrayExecAppend,
-		"左移": arrayExecShift,
-		"右移": arrayExecPop,
-		"拼接": arrayExecJoin,
-		"合并": arrayExecMerge,
-		"包含": arrayExecContains,
-		"寻找": arrayExecFind,
-		"交换": arrayExecSwap,
-	}
-
 	if fn, ok := arrayMethodMap[name]; ok {
 		return fn(ar, values)
 	}
 	return nil, zerr.MethodNotFound(name)
 }
 
+// arrayResultSizeMap - for methods that may create a huge string (or array), get the type & size
+// of the result before creating it, so that the exec limits are checked before the allocation.
+// Invalid params are left for the method itself to report.
+var arrayResultSizeMap = map[string]func(*Array, []r.Element) (string, int){
+	"拼接": func(ar *Array, values []r.Element) (string, int) {
+		if ValidateExactParams(values, "string") != nil || len(ar.value) == 0 {
+			return "string", 0
+		}
+		size := (len(ar.value) - 1) * len(values[0].(*String).value)
+		for _, v := range ar.value {
+			if str, ok := v.(*String); ok {
+				size += len(str.value)
+			}
+		}
+		return "string", size
+	},
+	"合并": func(ar *Array, values []r.Element) (string, int) {
+		size := len(ar.value)
+		for _, v := range values {
+			if arr, ok := v.(*Array); ok {
+				size += len(arr.value)
+			}
+		}
+		return "array", size
+	},
+}
+
+// ExecMethodVM - check the exec limits of VM before executing the method
+func (ar *Array) ExecMethodVM(vm *r.VM, name string, values []r.Element) (r.Element, error) {
+	if fn, ok := arrayResultSizeMap[name]; ok {
+		typeName, size := fn(ar, values)
+		if err := vm.GetExecLimits().CheckSize(typeName, size); err != nil {
+			return nil, err
+		}
+	}
+	return ar.ExecMethod(name, values)
+}
+
 //// getters, setters & methods
 
 // getters
@@ -293,6 +333,48 @@ func arrayExecSwap(ar *Array, values []r.Element) (r.Element, error) {
 	return ar, nil
 }
 
+// arrayExecMap - call the function with each item, and collect results as a new array
+func arrayExecMap(ar *Array, values []r.Element) (r.Element, error) {
+	if err := ValidateExactParams(values, "function"); err != nil {
+		return nil, err
+	}
+	fn := values[0].(*Function)
+
+	result := []r.Element{}
+	for _, item := range ar.value {
+		v, err := fn.Exec(nil, []r.Element{item})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return NewArray(result), nil
+}
+
+// arrayExecFilter - collect items that the function returns 真 as a new array
+func arrayExecFilter(ar *Array, values []r.Element) (r.Element, error) {
+	if err := ValidateExactParams(values, "function"); err != nil {
+		return nil, err
+	}
+	fn := values[0].(*Function)
+
+	result := []r.Element{}
+	for _, item := range ar.value {
+		v, err := fn.Exec(nil, []r.Element{item})
+		if err != nil {
+			return nil, err
+		}
+		flag, ok := v.(*Bool)
+		if !ok {
+			return nil, zerr.InvalidParamType("bool")
+		}
+		if flag.value {
+			result = append(result, item)
+		}
+	}
+	return NewArray(result), nil
+}
+
 // //// method handlers
 func insertArrayValue(target []r.Element, idx int, insertItem r.Element) []r.Element {
 	var result []r.Element
@@ -30,11 +30,12 @@ func (b *Bool) GetValue() bool {
 	return b.value
 }
 
+var boolGetterMap = map[string]boolGetterFunc{
+	"文本": boolGetText,
+}
+
 // GetProperty -
 func (b *Bool) GetProperty(name string) (r.Element, error) {
-	boolGetterMap := map[string]boolGetterFunc{
-		"文本": boolGetText,
-	}
 	if fn, ok := boolGetterMap[name]; ok {
 		return fn(b)
 	}
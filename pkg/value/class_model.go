@@ -28,23 +28,23 @@ type ClassModel struct {
 
 	// methodList - stores all available methods definition of class
 	methodList map[string]*Function
+
+	// parent - the parent class (定义X继承Y), nil if no parent class.
+	// Properties, computed properties and methods are inherited from the parent class,
+	// and could be overridden by the ones with same name.
+	parent *ClassModel
 }
 
 // NewClassModel - create new empty r.ClassRef
 func NewClassModel(name string) *ClassModel {
-	model := &ClassModel{
+	return &ClassModel{
 		name:         name,
 		constructor:  nil,
 		propList:     map[string]r.Element{},
 		compPropList: map[string]*Function{},
 		methodList:   map[string]*Function{},
+		parent:       nil,
 	}
-
-	// set default constructor - NO ACTION AT ALL
-	model.constructor = func(instance r.Element, params []r.Element) (r.Element, error) {
-		return instance, nil
-	}
-	return model
 }
 
 func (cm *ClassModel) String() string {
@@ -55,7 +55,19 @@ func (cm *ClassModel) String() string {
 func (cm *ClassModel) Construct(params []r.Element) (r.Element, error) {
 	// initialize a new object - an instance of class with no props set
 	instance := NewObject(cm, r.ElementMap{})
-	return cm.constructor(instance, params)
+	return cm.ConstructInstance(instance, params)
+}
+
+// ConstructInstance - execute the constructor on an existing instance. If the constructor is
+// not set, the one of parent class will be used (and finally, the default constructor that
+// does nothing at all).
+func (cm *ClassModel) ConstructInstance(instance r.Element, params []r.Element) (r.Element, error) {
+	for model := cm; model != nil; model = model.parent {
+		if model.constructor != nil {
+			return model.constructor(instance, params)
+		}
+	}
+	return instance, nil
 }
 
 // //// GETTERS //////
@@ -64,18 +76,51 @@ func (cm *ClassModel) GetName() string {
 }
 
 // GetPropList - list all defined properties to help duplicate initial properties to new Object
+// (including properties inherited from parent classes)
 func (cm *ClassModel) GetPropList() map[string]r.Element {
-	return cm.propList
+	if cm.parent == nil {
+		return cm.propList
+	}
+	propList := map[string]r.Element{}
+	for name, elem := range cm.parent.GetPropList() {
+		propList[name] = elem
+	}
+	for name, elem := range cm.propList {
+		propList[name] = elem
+	}
+	return propList
 }
 
 func (cm *ClassModel) FindCompProp(name string) (*Function, bool) {
-	cprop, ok := cm.compPropList[name]
-	return cprop, ok
+	for model := cm; model != nil; model = model.parent {
+		if cprop, ok := model.compPropList[name]; ok {
+			return cprop, true
+		}
+	}
+	return nil, false
 }
 
 func (cm *ClassModel) FindMethod(name string) (*Function, bool) {
-	method, ok := cm.methodList[name]
-	return method, ok
+	for model := cm; model != nil; model = model.parent {
+		if method, ok := model.methodList[name]; ok {
+			return method, true
+		}
+	}
+	return nil, false
+}
+
+func (cm *ClassModel) GetParent() *ClassModel {
+	return cm.parent
+}
+
+// IsSubclassOf - if the class is classModel itself, or inherits from classModel directly or indirectly
+func (cm *ClassModel) IsSubclassOf(classModel *ClassModel) bool {
+	for model := cm; model != nil; model = model.parent {
+		if model == classModel {
+			return true
+		}
+	}
+	return false
 }
 
 // //// SETTERS //////
@@ -85,6 +130,12 @@ func (cm *ClassModel) SetConstructor(fn r.FuncExecutor) *ClassModel {
 	return cm
 }
 
+func (cm *ClassModel) SetParent(parent *ClassModel) *ClassModel {
+	cm.parent = parent
+
+	return cm
+}
+
 // DefineProperty - define property of model and set the defaultValue
 func (cm *ClassModel) DefineProperty(name string, defaultValue r.Element) *ClassModel {
 	cm.propList[name] = defaultValue
@@ -0,0 +1,310 @@
+package value
+
+import (
+	"math/big"
+	"strings"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+type currencyGetterFunc func(*Currency) (r.Element, error)
+type currencyMethodFunc func(*Currency, []r.Element) (r.Element, error)
+
+// Currency - an amount of money tagged with its currency code (ISO 4217, e.g. CNY, USD), see 草案14.
+// The amount is stored as an exact decimal with at least CurrencyDefaultScale digits after
+// the decimal point, e.g. $20.3 -> USD$20.30, $20.0000 -> USD$20.0000
+//
+// Currency is immutable.
+type Currency struct {
+	code   string
+	amount *Decimal
+}
+
+// CurrencyDefaultScale - default number of digits after the decimal point (e.g. 分 of CNY)
+const CurrencyDefaultScale = 2
+
+// currencySymbols - symbols of common currencies, used for formatting (e.g. {$})
+var currencySymbols = map[string]string{
+	"CNY": "¥",
+	"USD": "$",
+	"HKD": "HK$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "JP¥",
+}
+
+// currencyPrefixes - prefixes of currency literals besides the general format `CNY$20.3`
+var currencyPrefixes = map[string]string{
+	"$":   "USD",
+	"US$": "USD",
+	"HK$": "HKD",
+	"¥":   "CNY",
+	"￥":   "CNY",
+	"€":   "EUR",
+	"£":   "GBP",
+	"￡":   "GBP",
+}
+
+// NewCurrency - create a new currency value. The amount is padded to CurrencyDefaultScale
+// digits if it has fewer digits after the decimal point.
+func NewCurrency(code string, amount *Decimal) *Currency {
+	if amount.GetScale() < CurrencyDefaultScale {
+		amount = amount.Round(CurrencyDefaultScale, RoundHalfUp)
+	}
+	return &Currency{code, amount}
+}
+
+// ParseCurrencyPrefix - get the currency code from the prefix of a currency literal,
+// e.g. "$" -> "USD", "HK$" -> "HKD", "CNY$" -> "CNY"
+func ParseCurrencyPrefix(prefix string) (string, bool) {
+	if code, ok := currencyPrefixes[prefix]; ok {
+		return code, true
+	}
+	if code := strings.TrimSuffix(prefix, "$"); code != prefix && IsValidCurrencyCode(code) {
+		return code, true
+	}
+	return "", false
+}
+
+// IsValidCurrencyCode - a currency code consists of 3 uppercase letters, e.g. CNY
+func IsValidCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, ch := range code {
+		if ch < 'A' || ch > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// String - show in the general format, e.g. USD$20.30, -CNY$8.50
+func (c *Currency) String() string {
+	amount := c.amount.String()
+	if strings.HasPrefix(amount, "-") {
+		return "-" + c.code + "$" + amount[1:]
+	}
+	return c.code + "$" + amount
+}
+
+// Construct - （新建货币：币种、金额）or （新建货币：币种、金额、位数）
+// where 金额 is a number, a decimal or a string.
+func (c *Currency) Construct(params []r.Element) (r.Element, error) {
+	if len(params) < 2 {
+		return nil, zerr.LeastParamsError(2)
+	}
+	if len(params) > 3 {
+		return nil, zerr.MostParamsError(3)
+	}
+
+	code, ok := params[0].(*String)
+	if !ok {
+		return nil, zerr.InvalidParamType("string")
+	}
+	if !IsValidCurrencyCode(code.value) {
+		return nil, ThrowException("「" + code.value + "」不是有效的币种")
+	}
+
+	var amount *Decimal
+	switch v := params[1].(type) {
+	case *String:
+		dv, err := NewDecimalFromString(v.value)
+		if err != nil {
+			return nil, ThrowException("「" + v.value + "」不是有效的金额")
+		}
+		amount = dv
+	case *Number, *Decimal:
+		dv, err := ToDecimal(v)
+		if err != nil {
+			return nil, err
+		}
+		amount = dv
+	default:
+		return nil, zerr.InvalidParamType("number", "decimal", "string")
+	}
+
+	result := NewCurrency(code.value, amount)
+	if len(params) > 2 {
+		return currencyExecRound(result, params[2:])
+	}
+	return result, nil
+}
+
+// GetCode - get the currency code, e.g. USD
+func (c *Currency) GetCode() string {
+	return c.code
+}
+
+// GetAmount -
+func (c *Currency) GetAmount() *Decimal {
+	return c.amount
+}
+
+// GetSymbol - get the symbol for display, e.g. $ for USD; for currencies without a
+// well-known symbol, the code is used instead (e.g. "THB ")
+func (c *Currency) GetSymbol() string {
+	if symbol, ok := currencySymbols[c.code]; ok {
+		return symbol
+	}
+	return c.code + " "
+}
+
+var currencyGetterMap = map[string]currencyGetterFunc{
+	"文本": currencyGetText,
+	"币种": currencyGetCode,
+	"金额": currencyGetAmount,
+	"精度": currencyGetScale,
+}
+
+// GetProperty -
+func (c *Currency) GetProperty(name string) (r.Element, error) {
+	if fn, ok := currencyGetterMap[name]; ok {
+		return fn(c)
+	}
+	return nil, zerr.PropertyNotFound(name)
+}
+
+// SetProperty -
+func (c *Currency) SetProperty(name string, value r.Element) error {
+	return zerr.PropertyNotFound(name)
+}
+
+var currencyMethodMap = map[string]currencyMethodFunc{
+	"保留": currencyExecRound,
+	"兑换": currencyExecExchange,
+}
+
+// ExecMethod -
+func (c *Currency) ExecMethod(name string, values []r.Element) (r.Element, error) {
+	if fn, ok := currencyMethodMap[name]; ok {
+		return fn(c, values)
+	}
+	return nil, zerr.MethodNotFound(name)
+}
+
+// ExecMethodVM - 兑换 converts the currency by the exchange rate provider of VM
+func (c *Currency) ExecMethodVM(vm *r.VM, name string, values []r.Element) (r.Element, error) {
+	if name == "兑换" {
+		return exchangeCurrency(c, values, vm.GetExchangeRateProvider())
+	}
+	return c.ExecMethod(name, values)
+}
+
+//// getters, setters and methods
+
+// getters
+func currencyGetText(c *Currency) (r.Element, error) {
+	return NewString(c.String()), nil
+}
+
+func currencyGetCode(c *Currency) (r.Element, error) {
+	return NewString(c.code), nil
+}
+
+func currencyGetAmount(c *Currency) (r.Element, error) {
+	return c.amount, nil
+}
+
+func currencyGetScale(c *Currency) (r.Element, error) {
+	return NewNumber(float64(c.amount.GetScale())), nil
+}
+
+// methods
+
+// 以X（保留：位数、舍入方式）
+func currencyExecRound(c *Currency, values []r.Element) (r.Element, error) {
+	result, err := decimalExecRound(c.amount, values)
+	if err != nil {
+		return nil, err
+	}
+	return &Currency{c.code, result.(*Decimal)}, nil
+}
+
+// 以X（兑换：币种） - without an exchange rate provider, only the same currency could be converted.
+// The interpreter calls ExecMethodVM() with the provider of the VM instead.
+func currencyExecExchange(c *Currency, values []r.Element) (r.Element, error) {
+	return exchangeCurrency(c, values, nil)
+}
+
+// exchangeCurrency - 以X（兑换：币种）, convert the currency by the rate from the provider
+func exchangeCurrency(c *Currency, values []r.Element, provider r.ExchangeRateProvider) (r.Element, error) {
+	if err := ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	code := values[0].(*String).value
+	if !IsValidCurrencyCode(code) {
+		return nil, ThrowException("「" + code + "」不是有效的币种")
+	}
+	if code == c.code {
+		return c, nil
+	}
+	if provider == nil {
+		return nil, zerr.ArithExchangeRateNotFound(c.code, code)
+	}
+
+	rate, err := provider.GetRate(c.code, code)
+	if err != nil {
+		// errors from custom providers (e.g. network errors) are thrown as exceptions
+		if _, ok := err.(*zerr.RuntimeError); !ok {
+			return nil, ThrowException(err.Error())
+		}
+		return nil, err
+	}
+	return ConvertCurrency(c, code, rate)
+}
+
+//// arithmetic
+
+// AddCurrencies - a + b, both amounts should be of the same currency
+func AddCurrencies(a *Currency, b *Currency) (*Currency, error) {
+	if a.code != b.code {
+		return nil, zerr.ArithIncompatibleUnits(a.code, b.code)
+	}
+	return &Currency{a.code, a.amount.Add(b.amount)}, nil
+}
+
+// SubCurrencies - a - b, both amounts should be of the same currency
+func SubCurrencies(a *Currency, b *Currency) (*Currency, error) {
+	if a.code != b.code {
+		return nil, zerr.ArithIncompatibleUnits(a.code, b.code)
+	}
+	return &Currency{a.code, a.amount.Sub(b.amount)}, nil
+}
+
+// MulCurrency - c × factor, the result is rounded (四舍五入) to the scale of c,
+// e.g. $10.00 × 0.333 = $3.33
+func MulCurrency(c *Currency, factor *Decimal) *Currency {
+	scale := c.amount.GetScale()
+	return &Currency{c.code, c.amount.Mul(factor).Round(scale, RoundHalfUp)}
+}
+
+// DivCurrency - c ÷ divisor, the result is rounded (四舍五入) to the scale of c
+func DivCurrency(c *Currency, divisor *Decimal) (*Currency, error) {
+	result, err := c.amount.DivRound(divisor, c.amount.GetScale(), RoundHalfUp)
+	if err != nil {
+		return nil, err
+	}
+	return &Currency{c.code, result}, nil
+}
+
+// CompareCurrencies - returns -1 if a < b, 0 if a == b and 1 if a > b;
+// both amounts should be of the same currency
+func CompareCurrencies(a *Currency, b *Currency) (int, error) {
+	if a.code != b.code {
+		return 0, zerr.ArithIncompatibleUnits(a.code, b.code)
+	}
+	return a.amount.Cmp(b.amount), nil
+}
+
+// ConvertCurrency - convert c to another currency by the exchange rate (1 c.code = rate × code).
+// The result keeps the scale of c, e.g. USD$10.00 -> CNY$71.23 (rate = 7.1234)
+func ConvertCurrency(c *Currency, code string, rate *big.Rat) (*Currency, error) {
+	num := c.amount.Mul(NewDecimal(new(big.Int).Set(rate.Num()), 0))
+	result, err := num.DivRound(NewDecimal(new(big.Int).Set(rate.Denom()), 0), c.amount.GetScale(), RoundHalfUp)
+	if err != nil {
+		return nil, err
+	}
+	return &Currency{code, result}, nil
+}
@@ -0,0 +1,105 @@
+package value
+
+import (
+	"math/big"
+	"testing"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+)
+
+func TestCurrency_Arithmetic(t *testing.T) {
+	cur := func(code string, amount string) *Currency {
+		d, _ := NewDecimalFromString(amount)
+		return NewCurrency(code, d)
+	}
+	dec := func(s string) *Decimal {
+		d, _ := NewDecimalFromString(s)
+		return d
+	}
+
+	cases := []struct {
+		name     string
+		op       func() (*Currency, error)
+		expected string
+		errCode  int
+	}{
+		{
+			name:     "default scale",
+			op:       func() (*Currency, error) { return cur("USD", "20.3"), nil },
+			expected: "USD$20.30",
+		},
+		{
+			name:     "keep higher scale",
+			op:       func() (*Currency, error) { return AddCurrencies(cur("USD", "20.0000"), cur("USD", "0.1")) },
+			expected: "USD$20.1000",
+		},
+		{
+			name:     "sub to negative",
+			op:       func() (*Currency, error) { return SubCurrencies(cur("CNY", "1"), cur("CNY", "9.5")) },
+			expected: "-CNY$8.50",
+		},
+		{
+			name:     "mul rounded to scale",
+			op:       func() (*Currency, error) { return MulCurrency(cur("USD", "10"), dec("0.333")), nil },
+			expected: "USD$3.33",
+		},
+		{
+			name:     "div rounded to scale",
+			op:       func() (*Currency, error) { return DivCurrency(cur("HKD", "100"), dec("3")) },
+			expected: "HKD$33.33",
+		},
+		{
+			name:     "convert by rate",
+			op:       func() (*Currency, error) { return ConvertCurrency(cur("USD", "10"), "CNY", big.NewRat(71234, 10000)) },
+			expected: "CNY$71.23",
+		},
+		{
+			name:    "add different currencies",
+			op:      func() (*Currency, error) { return AddCurrencies(cur("USD", "1"), cur("CNY", "1")) },
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:    "div by zero",
+			op:      func() (*Currency, error) { return DivCurrency(cur("USD", "1"), dec("0")) },
+			errCode: zerr.ErrArithDivZero,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := c.op()
+			if c.errCode != 0 {
+				e, ok := err.(*zerr.RuntimeError)
+				if !ok || e.Code != c.errCode {
+					t.Errorf("expect error code %d, got %v", c.errCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expect '%s', got error: '%s'", c.expected, err)
+			} else if result.String() != c.expected {
+				t.Errorf("expect '%s', got '%s'", c.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestCurrency_ParseCurrencyPrefix(t *testing.T) {
+	cases := map[string]string{
+		"$":    "USD",
+		"US$":  "USD",
+		"HK$":  "HKD",
+		"¥":    "CNY",
+		"€":    "EUR",
+		"CNY$": "CNY",
+		"cny$": "",
+		"AB$":  "",
+		"X":    "",
+	}
+	for prefix, expected := range cases {
+		code, ok := ParseCurrencyPrefix(prefix)
+		if ok != (expected != "") || code != expected {
+			t.Errorf("ParseCurrencyPrefix('%s'): expect '%s', got '%s'", prefix, expected, code)
+		}
+	}
+}
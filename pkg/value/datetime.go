@@ -0,0 +1,587 @@
+package value
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	// embed the timezone database, so that timezones (e.g. Asia/Shanghai) are always available
+	_ "time/tzdata"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+type dateTimeGetterFunc func(*DateTime) (r.Element, error)
+type dateTimeMethodFunc func(*DateTime, []r.Element) (r.Element, error)
+
+// DateTime - a date (e.g. 2024-03-15) or a datetime (e.g. 2024-03-15 08:30:00) with its timezone.
+// A date is stored as the midnight of that day.
+//
+// Durations are NOT a separate type: they're numbers with units of 时间 (e.g. 3天, 2h, 90min), see 草案14.
+//
+// DateTime is immutable.
+type DateTime struct {
+	value    time.Time
+	dateOnly bool
+}
+
+// default layouts of dates & datetimes, see formatDateTime() for all tokens
+const (
+	DateLayout     = "YYYY-MM-DD"
+	DateTimeLayout = "YYYY-MM-DD hh:mm:ss"
+)
+
+// durationUnits - units to display durations, from the largest to the smallest
+var durationUnits = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"天", 24 * time.Hour},
+	{"小时", time.Hour},
+	{"分钟", time.Minute},
+	{"秒", time.Second},
+	{"毫秒", time.Millisecond},
+}
+
+var weekdayNames = []string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+
+// NewDate - create a date (at the midnight of the day)
+func NewDate(year int, month int, day int, loc *time.Location) *DateTime {
+	return &DateTime{time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc), true}
+}
+
+// NewDateTime - create a datetime; if dateOnly = true, the time part is removed
+func NewDateTime(t time.Time, dateOnly bool) *DateTime {
+	if dateOnly {
+		return NewDate(t.Year(), int(t.Month()), t.Day(), t.Location())
+	}
+	return &DateTime{t, false}
+}
+
+// IsValidDate - check if the date exists (e.g. 2023-02-29 doesn't exist)
+func IsValidDate(year int, month int, day int) bool {
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return t.Year() == year && int(t.Month()) == month && t.Day() == day
+}
+
+// String - e.g. 2024-03-15 or 2024-03-15 08:30:00
+func (d *DateTime) String() string {
+	if d.dateOnly {
+		return formatDateTime(d.value, DateLayout)
+	}
+	return formatDateTime(d.value, DateTimeLayout)
+}
+
+// Format - format the datetime by the layout, see formatDateTime() for all tokens
+func (d *DateTime) Format(layout string) string {
+	return formatDateTime(d.value, layout)
+}
+
+// GetValue -
+func (d *DateTime) GetValue() time.Time {
+	return d.value
+}
+
+// IsDateOnly - if it's a date without the time part
+func (d *DateTime) IsDateOnly() bool {
+	return d.dateOnly
+}
+
+// Construct - （新建日期：文本、格式）, （新建日期：年、月、日）for dates;
+// （新建日期时间：文本、格式、时区）, （新建日期时间：年、月、日、时、分、秒）for datetimes.
+// 格式 and 时区 are optional.
+func (d *DateTime) Construct(params []r.Element) (r.Element, error) {
+	if len(params) == 0 {
+		return nil, zerr.LeastParamsError(1)
+	}
+	if _, ok := params[0].(*Number); ok {
+		return constructDateTimeFromNumbers(params, d.dateOnly)
+	}
+
+	if len(params) > 3 {
+		return nil, zerr.MostParamsError(3)
+	}
+	text, ok := params[0].(*String)
+	if !ok {
+		return nil, zerr.InvalidParamType("string", "number")
+	}
+	layout := ""
+	if len(params) > 1 {
+		l, ok := params[1].(*String)
+		if !ok {
+			return nil, zerr.InvalidParamType("string")
+		}
+		layout = l.value
+	}
+	loc := time.Local
+	if len(params) > 2 {
+		tz, ok := params[2].(*String)
+		if !ok {
+			return nil, zerr.InvalidParamType("string")
+		}
+		l, err := LoadLocation(tz.value)
+		if err != nil {
+			return nil, err
+		}
+		loc = l
+	}
+
+	return ParseDateTime(text.value, layout, loc, d.dateOnly)
+}
+
+func constructDateTimeFromNumbers(params []r.Element, dateOnly bool) (r.Element, error) {
+	maxParams := 6
+	if dateOnly {
+		maxParams = 3
+	}
+	if len(params) < 3 {
+		return nil, zerr.LeastParamsError(3)
+	}
+	if len(params) > maxParams {
+		return nil, zerr.MostParamsError(maxParams)
+	}
+	if err := ValidateAllParams(params, "number"); err != nil {
+		return nil, err
+	}
+
+	fields := [6]int{}
+	for i, p := range params {
+		fields[i] = int(p.(*Number).value)
+	}
+	if !IsValidDate(fields[0], fields[1], fields[2]) || fields[3] > 23 || fields[4] > 59 || fields[5] > 59 {
+		return nil, ThrowException("日期或时间超出有效范围")
+	}
+	t := time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], fields[4], fields[5], 0, time.Local)
+	return NewDateTime(t, dateOnly), nil
+}
+
+// ParseDateTime - parse the text by the layout (e.g. “YYYY年M月D日”) in the timezone.
+// If the layout is empty, RFC 3339 (e.g. 2024-03-15T08:30:00+08:00) and default layouts
+// (e.g. 2024-03-15 08:30:00, 2024-03-15 08:30, 2024-03-15) are tried in order.
+func ParseDateTime(text string, layout string, loc *time.Location, dateOnly bool) (*DateTime, error) {
+	if layout != "" {
+		t, err := parseDateTimeLayout(text, layout, loc)
+		if err != nil {
+			return nil, ThrowException(fmt.Sprintf("「%s」不符合日期格式「%s」", text, layout))
+		}
+		return NewDateTime(t, dateOnly), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		return NewDateTime(t, dateOnly), nil
+	}
+	for _, l := range []string{DateTimeLayout, "YYYY-MM-DD hh:mm", DateLayout} {
+		if t, err := parseDateTimeLayout(text, l, loc); err == nil {
+			return NewDateTime(t, dateOnly), nil
+		}
+	}
+	return nil, ThrowException(fmt.Sprintf("「%s」不是有效的日期", text))
+}
+
+// LoadLocation - get the timezone by name: “本地” (or empty) for the local timezone,
+// IANA names like “Asia/Shanghai”, “UTC”, or offsets like “+08:00”
+func LoadLocation(name string) (*time.Location, error) {
+	switch name {
+	case "", "本地", "Local":
+		return time.Local, nil
+	}
+	if offset, ok := parseZoneOffset(name); ok {
+		return time.FixedZone(name, offset), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ThrowException("「" + name + "」不是有效的时区")
+	}
+	return loc, nil
+}
+
+// parseZoneOffset - parse offsets like +08:00, -0530; returns offset in seconds
+func parseZoneOffset(s string) (int, bool) {
+	if len(s) < 3 || (s[0] != '+' && s[0] != '-') {
+		return 0, false
+	}
+	digits := strings.Replace(s[1:], ":", "", 1)
+	if len(digits) != 2 && len(digits) != 4 {
+		return 0, false
+	}
+	for _, ch := range digits {
+		if ch < '0' || ch > '9' {
+			return 0, false
+		}
+	}
+	hours, _ := strconv.Atoi(digits[:2])
+	minutes := 0
+	if len(digits) == 4 {
+		minutes, _ = strconv.Atoi(digits[2:])
+	}
+	offset := hours*3600 + minutes*60
+	if s[0] == '-' {
+		offset = -offset
+	}
+	return offset, true
+}
+
+var dateTimeGetterMap = map[string]dateTimeGetterFunc{
+	"文本":  dateTimeGetText,
+	"年":   dateTimeGetYear,
+	"月":   dateTimeGetMonth,
+	"日":   dateTimeGetDay,
+	"时":   dateTimeGetHour,
+	"分":   dateTimeGetMinute,
+	"秒":   dateTimeGetSecond,
+	"星期":  dateTimeGetWeekday,
+	"时区":  dateTimeGetZone,
+	"日期":  dateTimeGetDate,
+	"时间戳": dateTimeGetTimestamp,
+}
+
+// GetProperty -
+func (d *DateTime) GetProperty(name string) (r.Element, error) {
+	if fn, ok := dateTimeGetterMap[name]; ok {
+		return fn(d)
+	}
+	return nil, zerr.PropertyNotFound(name)
+}
+
+// SetProperty -
+func (d *DateTime) SetProperty(name string, value r.Element) error {
+	return zerr.PropertyNotFound(name)
+}
+
+var dateTimeMethodMap = map[string]dateTimeMethodFunc{
+	"格式化": dateTimeExecFormat,
+	"转时区": dateTimeExecInZone,
+	"加月":  dateTimeExecAddMonths,
+	"加年":  dateTimeExecAddYears,
+}
+
+// ExecMethod -
+func (d *DateTime) ExecMethod(name string, values []r.Element) (r.Element, error) {
+	if fn, ok := dateTimeMethodMap[name]; ok {
+		return fn(d, values)
+	}
+	return nil, zerr.MethodNotFound(name)
+}
+
+//// getters, setters and methods
+
+// getters
+func dateTimeGetText(d *DateTime) (r.Element, error) {
+	return NewString(d.String()), nil
+}
+
+func dateTimeGetYear(d *DateTime) (r.Element, error) {
+	return NewNumber(float64(d.value.Year())), nil
+}
+
+func dateTimeGetMonth(d *DateTime) (r.Element, error) {
+	return NewNumber(float64(d.value.Month())), nil
+}
+
+func dateTimeGetDay(d *DateTime) (r.Element, error) {
+	return NewNumber(float64(d.value.Day())), nil
+}
+
+func dateTimeGetHour(d *DateTime) (r.Element, error) {
+	return NewNumber(float64(d.value.Hour())), nil
+}
+
+func dateTimeGetMinute(d *DateTime) (r.Element, error) {
+	return NewNumber(float64(d.value.Minute())), nil
+}
+
+func dateTimeGetSecond(d *DateTime) (r.Element, error) {
+	return NewNumber(float64(d.value.Second())), nil
+}
+
+// 星期 - 1 (星期一) ~ 7 (星期日)
+func dateTimeGetWeekday(d *DateTime) (r.Element, error) {
+	weekday := int(d.value.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	return NewNumber(float64(weekday)), nil
+}
+
+func dateTimeGetZone(d *DateTime) (r.Element, error) {
+	return NewString(d.value.Location().String()), nil
+}
+
+func dateTimeGetDate(d *DateTime) (r.Element, error) {
+	return NewDateTime(d.value, true), nil
+}
+
+// 时间戳 - seconds since 1970-01-01 00:00:00 UTC
+func dateTimeGetTimestamp(d *DateTime) (r.Element, error) {
+	return NewNumber(float64(d.value.UnixMilli()) / 1000), nil
+}
+
+// methods
+
+// 以X（格式化：格式、时区）
+func dateTimeExecFormat(d *DateTime, values []r.Element) (r.Element, error) {
+	if len(values) == 0 {
+		return nil, zerr.LeastParamsError(1)
+	}
+	if len(values) > 2 {
+		return nil, zerr.MostParamsError(2)
+	}
+	if err := ValidateAllParams(values, "string"); err != nil {
+		return nil, err
+	}
+
+	t := d.value
+	if len(values) > 1 {
+		loc, err := LoadLocation(values[1].(*String).value)
+		if err != nil {
+			return nil, err
+		}
+		t = t.In(loc)
+	}
+	return NewString(formatDateTime(t, values[0].(*String).value)), nil
+}
+
+// 以X（转时区：时区） - the same instant in another timezone
+func dateTimeExecInZone(d *DateTime, values []r.Element) (r.Element, error) {
+	if err := ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	loc, err := LoadLocation(values[0].(*String).value)
+	if err != nil {
+		return nil, err
+	}
+	return &DateTime{d.value.In(loc), false}, nil
+}
+
+func dateTimeExecAddMonths(d *DateTime, values []r.Element) (r.Element, error) {
+	if err := ValidateExactParams(values, "number"); err != nil {
+		return nil, err
+	}
+	return d.AddMonths(int(values[0].(*Number).value)), nil
+}
+
+func dateTimeExecAddYears(d *DateTime, values []r.Element) (r.Element, error) {
+	if err := ValidateExactParams(values, "number"); err != nil {
+		return nil, err
+	}
+	return d.AddMonths(int(values[0].(*Number).value) * 12), nil
+}
+
+// AddMonths - add N months. If the day doesn't exist in the target month, the last day of
+// that month is used instead, e.g. 2024-01-31 + 1 month = 2024-02-29
+func (d *DateTime) AddMonths(months int) *DateTime {
+	t := d.value
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	target := first.AddDate(0, months, 0)
+	lastDay := target.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return &DateTime{target.AddDate(0, 0, day-1), d.dateOnly}
+}
+
+//// arithmetic
+
+// NumberToDuration - convert a number with unit of 时间 (e.g. 3天, 2h) to duration
+func NumberToDuration(n *Number) (time.Duration, error) {
+	if n.unit == nil || n.unit.Div != "" || lookupUnit(n.unit.Mul).dimension != "时间" {
+		return 0, zerr.ArithIncompatibleUnits(n.unit.String(), "天")
+	}
+	seconds := n.value * lookupUnit(n.unit.Mul).factor
+	return time.Duration(math.Round(seconds * float64(time.Second))), nil
+}
+
+// NewDurationNumber - convert the duration to a number with the largest unit that
+// represents the duration exactly, e.g. 36h -> 36小时, 48h -> 2天, 0 -> 0秒
+func NewDurationNumber(dur time.Duration) *Number {
+	if dur == 0 {
+		return NewNumberWithUnit(0, &Unit{Mul: "秒"})
+	}
+	for _, u := range durationUnits {
+		if dur%u.duration == 0 {
+			return NewNumberWithUnit(float64(dur/u.duration), &Unit{Mul: u.name})
+		}
+	}
+	return NewNumberWithUnit(dur.Seconds(), &Unit{Mul: "秒"})
+}
+
+// AddDuration - d + duration (or d - duration if sign < 0). For dates, adding whole
+// days keeps the result as a date; otherwise the result is a datetime.
+func AddDuration(d *DateTime, duration *Number, sign int) (*DateTime, error) {
+	dur, err := NumberToDuration(duration)
+	if err != nil {
+		return nil, err
+	}
+	if sign < 0 {
+		dur = -dur
+	}
+
+	day := 24 * time.Hour
+	if dur%day == 0 {
+		// add days by calendar, so that the time is kept across DST changes
+		return &DateTime{d.value.AddDate(0, 0, int(dur/day)), d.dateOnly}, nil
+	}
+	return &DateTime{d.value.Add(dur), false}, nil
+}
+
+// SubDateTimes - a - b, the result is a duration (number with unit of 时间).
+// For two dates, the result is counted in calendar days.
+func SubDateTimes(a *DateTime, b *DateTime) *Number {
+	if a.dateOnly && b.dateOnly {
+		ua := time.Date(a.value.Year(), a.value.Month(), a.value.Day(), 0, 0, 0, 0, time.UTC)
+		ub := time.Date(b.value.Year(), b.value.Month(), b.value.Day(), 0, 0, 0, 0, time.UTC)
+		return NewNumberWithUnit(float64(ua.Sub(ub)/(24*time.Hour)), &Unit{Mul: "天"})
+	}
+	return NewDurationNumber(a.value.Sub(b.value))
+}
+
+// CompareDateTimes - returns -1 if a is before b, 0 if they're the same instant, 1 if a is after b
+func CompareDateTimes(a *DateTime, b *DateTime) int {
+	switch {
+	case a.value.Before(b.value):
+		return -1
+	case a.value.After(b.value):
+		return 1
+	default:
+		return 0
+	}
+}
+
+//// layouts
+
+// layout tokens, longer tokens first
+var dateTimeLayoutTokens = []string{"YYYY", "SSS", "MM", "DD", "hh", "mm", "ss", "ZZ", "M", "D", "h", "W"}
+
+// formatDateTime - format the time by the layout. Tokens:
+//
+//	YYYY - year (4 digits)    MM, M - month (01, 1)    DD, D - day (05, 5)
+//	hh, h - hour (08, 8)      mm - minute (09)        ss - second (03)
+//	SSS - millisecond (045)   ZZ - offset (+08:00)    W - weekday (星期五)
+//
+// other chars are kept as is.
+func formatDateTime(t time.Time, layout string) string {
+	var sb strings.Builder
+	for layout != "" {
+		token := matchLayoutToken(layout)
+		switch token {
+		case "YYYY":
+			sb.WriteString(fmt.Sprintf("%04d", t.Year()))
+		case "MM":
+			sb.WriteString(fmt.Sprintf("%02d", int(t.Month())))
+		case "M":
+			sb.WriteString(strconv.Itoa(int(t.Month())))
+		case "DD":
+			sb.WriteString(fmt.Sprintf("%02d", t.Day()))
+		case "D":
+			sb.WriteString(strconv.Itoa(t.Day()))
+		case "hh":
+			sb.WriteString(fmt.Sprintf("%02d", t.Hour()))
+		case "h":
+			sb.WriteString(strconv.Itoa(t.Hour()))
+		case "mm":
+			sb.WriteString(fmt.Sprintf("%02d", t.Minute()))
+		case "ss":
+			sb.WriteString(fmt.Sprintf("%02d", t.Second()))
+		case "SSS":
+			sb.WriteString(fmt.Sprintf("%03d", t.Nanosecond()/int(time.Millisecond)))
+		case "ZZ":
+			sb.WriteString(t.Format("-07:00"))
+		case "W":
+			sb.WriteString(weekdayNames[t.Weekday()])
+		default:
+			sb.WriteString(token)
+		}
+		layout = layout[len(token):]
+	}
+	return sb.String()
+}
+
+// parseDateTimeLayout - parse the text by the layout (see formatDateTime() for tokens; W is not supported)
+func parseDateTimeLayout(text string, layout string, loc *time.Location) (time.Time, error) {
+	fields := map[string]int{"M": 1, "D": 1}
+	for layout != "" {
+		token := matchLayoutToken(layout)
+		layout = layout[len(token):]
+
+		var minDigits, maxDigits int
+		var field string
+		switch token {
+		case "YYYY":
+			minDigits, maxDigits, field = 4, 4, "Y"
+		case "MM", "M":
+			minDigits, maxDigits, field = len(token), 2, "M"
+		case "DD", "D":
+			minDigits, maxDigits, field = len(token), 2, "D"
+		case "hh", "h":
+			minDigits, maxDigits, field = len(token), 2, "h"
+		case "mm":
+			minDigits, maxDigits, field = 2, 2, "m"
+		case "ss":
+			minDigits, maxDigits, field = 2, 2, "s"
+		case "SSS":
+			minDigits, maxDigits, field = 3, 3, "S"
+		case "ZZ":
+			if strings.HasPrefix(text, "Z") {
+				loc, text = time.UTC, text[1:]
+				continue
+			}
+			// +08:00 or +0800
+			zoneLen := 5
+			if len(text) > 3 && text[3] == ':' {
+				zoneLen = 6
+			}
+			if len(text) < zoneLen {
+				return time.Time{}, zerr.InvalidParamType("string")
+			}
+			offset, ok := parseZoneOffset(text[:zoneLen])
+			if !ok {
+				return time.Time{}, zerr.InvalidParamType("string")
+			}
+			loc, text = time.FixedZone("", offset), text[zoneLen:]
+			continue
+		case "W":
+			return time.Time{}, zerr.InvalidParamType("string")
+		default:
+			if !strings.HasPrefix(text, token) {
+				return time.Time{}, zerr.InvalidParamType("string")
+			}
+			text = text[len(token):]
+			continue
+		}
+
+		n := 0
+		for n < maxDigits && n < len(text) && text[n] >= '0' && text[n] <= '9' {
+			n++
+		}
+		if n < minDigits {
+			return time.Time{}, zerr.InvalidParamType("string")
+		}
+		fields[field], _ = strconv.Atoi(text[:n])
+		text = text[n:]
+	}
+	if text != "" {
+		return time.Time{}, zerr.InvalidParamType("string")
+	}
+	if !IsValidDate(fields["Y"], fields["M"], fields["D"]) || fields["h"] > 23 || fields["m"] > 59 || fields["s"] > 59 {
+		return time.Time{}, zerr.InvalidParamType("string")
+	}
+	return time.Date(fields["Y"], time.Month(fields["M"]), fields["D"], fields["h"], fields["m"], fields["s"],
+		fields["S"]*int(time.Millisecond), loc), nil
+}
+
+// matchLayoutToken - get the token at the beginning of the layout; if no token is
+// matched, the first char is returned
+func matchLayoutToken(layout string) string {
+	for _, token := range dateTimeLayoutTokens {
+		if strings.HasPrefix(layout, token) {
+			return token
+		}
+	}
+	_, size := utf8.DecodeRuneInString(layout)
+	return layout[:size]
+}
@@ -0,0 +1,138 @@
+package value
+
+import (
+	"testing"
+	"time"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+)
+
+func TestDateTime_Arithmetic(t *testing.T) {
+	loc, _ := LoadLocation("Asia/Shanghai")
+	date := func(y, m, d int) *DateTime { return NewDate(y, m, d, loc) }
+	datetime := func(y, m, d, hh, mm int) *DateTime {
+		return NewDateTime(time.Date(y, time.Month(m), d, hh, mm, 0, 0, loc), false)
+	}
+	num := func(v float64, unit string) *Number {
+		u, _ := ParseUnit(unit)
+		return NewNumberWithUnit(v, u)
+	}
+
+	cases := []struct {
+		name     string
+		op       func() (any, error)
+		expected string
+		errCode  int
+	}{
+		{
+			name:     "date + days",
+			op:       func() (any, error) { return AddDuration(date(2024, 2, 27), num(3, "天"), 1) },
+			expected: "2024-03-01",
+		},
+		{
+			name:     "date + hours -> datetime",
+			op:       func() (any, error) { return AddDuration(date(2024, 3, 15), num(36, "h"), 1) },
+			expected: "2024-03-16 12:00:00",
+		},
+		{
+			name:     "datetime - minutes",
+			op:       func() (any, error) { return AddDuration(datetime(2024, 3, 15, 8, 30), num(90, "min"), -1) },
+			expected: "2024-03-15 07:00:00",
+		},
+		{
+			name:     "date + weeks",
+			op:       func() (any, error) { return AddDuration(date(2024, 3, 15), num(2, "周"), 1) },
+			expected: "2024-03-29",
+		},
+		{
+			name:    "date + plain number",
+			op:      func() (any, error) { return AddDuration(date(2024, 3, 15), NewNumber(3), 1) },
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:    "date + length",
+			op:      func() (any, error) { return AddDuration(date(2024, 3, 15), num(3, "km"), 1) },
+			errCode: zerr.ErrArithIncompatibleUnits,
+		},
+		{
+			name:     "date - date",
+			op:       func() (any, error) { return SubDateTimes(date(2024, 3, 1), date(2024, 2, 1)), nil },
+			expected: "29天",
+		},
+		{
+			name:     "datetime - date",
+			op:       func() (any, error) { return SubDateTimes(datetime(2024, 3, 15, 8, 30), date(2024, 3, 15)), nil },
+			expected: "510分钟",
+		},
+		{
+			name: "datetime - datetime (negative)",
+			op: func() (any, error) {
+				return SubDateTimes(datetime(2024, 3, 15, 8, 0), datetime(2024, 3, 15, 10, 0)), nil
+			},
+			expected: "-2小时",
+		},
+		{
+			name:     "add months to the end of month",
+			op:       func() (any, error) { return date(2024, 1, 31).AddMonths(1), nil },
+			expected: "2024-02-29",
+		},
+		{
+			name:     "add years from leap day",
+			op:       func() (any, error) { return date(2024, 2, 29).AddMonths(12), nil },
+			expected: "2025-02-28",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.op()
+			if tt.errCode != 0 {
+				if e, ok := err.(*zerr.RuntimeError); !ok || e.Code != tt.errCode {
+					t.Errorf("expect error code %d, got %v", tt.errCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s := result.(interface{ String() string }).String(); s != tt.expected {
+				t.Errorf("expect %s, got %s", tt.expected, s)
+			}
+		})
+	}
+}
+
+func TestDateTime_Layout(t *testing.T) {
+	loc, _ := LoadLocation("+08:00")
+	cases := []struct {
+		text     string
+		layout   string
+		expected string
+	}{
+		{"2024-03-15", "", "2024-03-15T00:00:00.000+08:00"},
+		{"2024-03-15 08:30", "", "2024-03-15T08:30:00.000+08:00"},
+		{"2024-03-15T08:30:00Z", "", "2024-03-15T08:30:00.000+00:00"},
+		{"2024年3月5日", "YYYY年M月D日", "2024-03-05T00:00:00.000+08:00"},
+		{"15/03/2024 20:05:07.045", "DD/MM/YYYY hh:mm:ss.SSS", "2024-03-15T20:05:07.045+08:00"},
+		{"2024-03-15 08:30 -0530", "YYYY-MM-DD hh:mm ZZ", "2024-03-15T08:30:00.000-05:30"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.text, func(t *testing.T) {
+			dt, err := ParseDateTime(tt.text, tt.layout, loc, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s := dt.Format("YYYY-MM-DDThh:mm:ss.SSSZZ"); s != tt.expected {
+				t.Errorf("expect %s, got %s", tt.expected, s)
+			}
+		})
+	}
+
+	// invalid texts
+	for _, text := range []string{"2023-02-29", "2024-03-15 25:00", "2024/03/15", "2024-03-15 08:30 extra"} {
+		if _, err := ParseDateTime(text, "", loc, false); err == nil {
+			t.Errorf("parse %s: expect error, got nil", text)
+		}
+	}
+}
@@ -0,0 +1,506 @@
+package value
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+type decimalGetterFunc func(*Decimal) (r.Element, error)
+type decimalMethodFunc func(*Decimal, []r.Element) (r.Element, error)
+
+// Decimal - arbitrary-precision decimal number, where value = unscaled × 10^(-scale).
+// Unlike Number (float64), the arithmetic is exact in base-10, thus 0.1 + 0.2 = 0.3
+// holds - which is essential for money calculations.
+//
+// Decimal is immutable: all operations return a new Decimal.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// rounding modes of Decimal
+const (
+	RoundHalfUp   uint8 = 1 // 四舍五入
+	RoundHalfEven uint8 = 2 // 银行家舍入 (四舍六入五成双)
+	RoundDown     uint8 = 3 // 截断 (toward zero)
+	RoundUp       uint8 = 4 // 进一 (away from zero)
+	RoundCeiling  uint8 = 5 // 向上取整
+	RoundFloor    uint8 = 6 // 向下取整
+)
+
+// DecimalDivisionScale - the minimum scale of the quotient when a division is not exact
+// (e.g. 1 / 3 = 0.3333333333333333) by default. Use 保留 to round the result to the expected
+// scale, or set a DecimalContext to the VM to change it.
+const DecimalDivisionScale = 16
+
+// DecimalMaxScale - the maximum scale to round (or divide) decimals to, which is also the maximum
+// exponent of parsed decimals (e.g. 1e10000) - so that a short input could not yield a huge number
+// that takes forever to compute.
+const DecimalMaxScale = 10000
+
+var roundingModeNames = map[string]uint8{
+	"四舍五入":  RoundHalfUp,
+	"银行家舍入": RoundHalfEven,
+	"截断":    RoundDown,
+	"进一":    RoundUp,
+	"向上取整":  RoundCeiling,
+	"向下取整":  RoundFloor,
+}
+
+var bigTen = big.NewInt(10)
+
+// NewDecimal - create a decimal of unscaled × 10^(-scale); |scale| should not exceed DecimalMaxScale
+func NewDecimal(unscaled *big.Int, scale int) *Decimal {
+	if scale < 0 {
+		unscaled = new(big.Int).Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+	return &Decimal{unscaled: unscaled, scale: scale}
+}
+
+// NewDecimalFromString - parse a decimal from string exactly. The formats are the same as
+// NewNumberFromString, e.g. "1,234.50", "-0.001", "1.5*10^3", "2e-2"
+func NewDecimalFromString(value string) (*Decimal, error) {
+	v := strings.ReplaceAll(value, ",", "")
+	v = strings.Replace(v, "*^", "", 1)
+	v = strings.Replace(v, "*10^", "e", 1)
+	v = strings.Replace(v, "E", "e", 1)
+
+	exp := 0
+	if idx := strings.Index(v, "e"); idx >= 0 {
+		e, err := strconv.Atoi(v[idx+1:])
+		if err != nil {
+			return nil, err
+		}
+		if e > DecimalMaxScale || e < -DecimalMaxScale {
+			return nil, strconv.ErrRange
+		}
+		exp = e
+		v = v[:idx]
+	}
+
+	intPart, fracPart := v, ""
+	if idx := strings.Index(v, "."); idx >= 0 {
+		intPart, fracPart = v[:idx], v[idx+1:]
+	}
+	digits := intPart + fracPart
+	// sign is allowed at the beginning ONLY
+	sign := ""
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return nil, strconv.ErrSyntax
+	}
+
+	scale := len(fracPart) - exp
+	if scale > DecimalMaxScale || scale < -DecimalMaxScale {
+		return nil, strconv.ErrRange
+	}
+	unscaled, ok := new(big.Int).SetString(sign+digits, 10)
+	if !ok {
+		return nil, strconv.ErrSyntax
+	}
+	return NewDecimal(unscaled, scale), nil
+}
+
+// NewDecimalFromNumber - convert a Number to Decimal with the shortest representation
+// of the float64 value, e.g. 0.1 -> 0.1 (instead of 0.1000000000000000055511151231257827)
+func NewDecimalFromNumber(n *Number) (*Decimal, error) {
+	if math.IsNaN(n.value) || math.IsInf(n.value, 0) {
+		return nil, zerr.InvalidParamType("decimal")
+	}
+	// decimals don't carry units
+	if n.unit != nil {
+		return nil, zerr.ArithIncompatibleUnits(n.unit.String(), "")
+	}
+	return NewDecimalFromString(strconv.FormatFloat(n.value, 'f', -1, 64))
+}
+
+// ToDecimal - convert a Number or Decimal to Decimal
+func ToDecimal(elem r.Element) (*Decimal, error) {
+	switch v := elem.(type) {
+	case *Decimal:
+		return v, nil
+	case *Number:
+		return NewDecimalFromNumber(v)
+	}
+	return nil, zerr.InvalidParamType("number", "decimal")
+}
+
+// GetRoundingMode - get rounding mode by its name (e.g. “四舍五入”)
+func GetRoundingMode(name string) (uint8, error) {
+	if mode, ok := roundingModeNames[name]; ok {
+		return mode, nil
+	}
+	return 0, zerr.InvalidParamType("rounding mode")
+}
+
+// GetRoundingScale - get the scale (number of digits after the decimal point) to round to,
+// which should be an integer within [0, DecimalMaxScale]
+func GetRoundingScale(n *Number) (int, error) {
+	if n.value != math.Trunc(n.value) || n.value < 0 || n.value > DecimalMaxScale {
+		return 0, zerr.InvalidParamType("integer")
+	}
+	return int(n.value), nil
+}
+
+// DivDecimals - a ÷ b by the DecimalContext of VM (if set), or by the default scale & rounding mode
+func DivDecimals(vm *r.VM, a *Decimal, b *Decimal) (*Decimal, error) {
+	if ctx := vm.GetDecimalContext(); ctx != nil {
+		if ctx.DivisionScale < 0 || ctx.DivisionScale > DecimalMaxScale {
+			return nil, zerr.InvalidParamType("integer")
+		}
+		if ctx.RoundingMode < RoundHalfUp || ctx.RoundingMode > RoundFloor {
+			return nil, zerr.InvalidParamType("rounding mode")
+		}
+		return a.DivScale(b, ctx.DivisionScale, ctx.RoundingMode)
+	}
+	return a.Div(b)
+}
+
+// String - show in plain notation with trailing zeros kept, e.g. 1.50
+func (d *Decimal) String() string {
+	digits := new(big.Int).Abs(d.unscaled).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if d.unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Construct - （新建小数：X）or （新建小数：X、位数、舍入方式）
+// where X is a number or a string; the result is rounded to 位数 (四舍五入 by default) if given.
+func (d *Decimal) Construct(params []r.Element) (r.Element, error) {
+	if len(params) == 0 {
+		return nil, zerr.LeastParamsError(1)
+	}
+	if len(params) > 3 {
+		return nil, zerr.MostParamsError(3)
+	}
+
+	var result *Decimal
+	switch v := params[0].(type) {
+	case *String:
+		dv, err := NewDecimalFromString(v.value)
+		if err != nil {
+			return nil, ThrowException("「" + v.value + "」不是有效的小数")
+		}
+		result = dv
+	case *Number, *Decimal:
+		dv, err := ToDecimal(v)
+		if err != nil {
+			return nil, err
+		}
+		result = dv
+	default:
+		return nil, zerr.InvalidParamType("number", "decimal", "string")
+	}
+
+	if len(params) > 1 {
+		return decimalExecRound(result, params[1:])
+	}
+	return result, nil
+}
+
+// GetScale - get the number of digits after the decimal point
+func (d *Decimal) GetScale() int {
+	return d.scale
+}
+
+// GetUnscaled -
+func (d *Decimal) GetUnscaled() *big.Int {
+	return new(big.Int).Set(d.unscaled)
+}
+
+// Float64 - convert to float64 (may lose precision)
+func (d *Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// IsZero -
+func (d *Decimal) IsZero() bool {
+	return d.unscaled.Sign() == 0
+}
+
+// Cmp - compare two decimals: returns -1 if d < other, 0 if d == other, 1 if d > other
+func (d *Decimal) Cmp(other *Decimal) int {
+	a, b := alignDecimals(d, other)
+	return a.Cmp(b)
+}
+
+// Add - d + other
+func (d *Decimal) Add(other *Decimal) *Decimal {
+	a, b := alignDecimals(d, other)
+	return NewDecimal(new(big.Int).Add(a, b), maxInt(d.scale, other.scale))
+}
+
+// Sub - d - other
+func (d *Decimal) Sub(other *Decimal) *Decimal {
+	a, b := alignDecimals(d, other)
+	return NewDecimal(new(big.Int).Sub(a, b), maxInt(d.scale, other.scale))
+}
+
+// Mul - d × other
+func (d *Decimal) Mul(other *Decimal) *Decimal {
+	return NewDecimal(new(big.Int).Mul(d.unscaled, other.unscaled), d.scale+other.scale)
+}
+
+// Div - d ÷ other. If the quotient is not exact, it's rounded (四舍五入) to
+// max(DecimalDivisionScale, d.scale, other.scale) digits.
+func (d *Decimal) Div(other *Decimal) (*Decimal, error) {
+	return d.DivScale(other, DecimalDivisionScale, RoundHalfUp)
+}
+
+// DivScale - d ÷ other. If the quotient is not exact, it's rounded by the rounding mode to
+// max(minScale, d.scale, other.scale) digits.
+func (d *Decimal) DivScale(other *Decimal, minScale int, mode uint8) (*Decimal, error) {
+	scale := maxInt(minScale, maxInt(d.scale, other.scale))
+	quotient, err := d.DivRound(other, scale, mode)
+	if err != nil {
+		return nil, err
+	}
+	// remove redundant trailing zeros, e.g. 1 / 4 = 0.25 instead of 0.2500000000000000
+	return quotient.trimZeros(maxInt(d.scale, other.scale)), nil
+}
+
+// DivRound - d ÷ other, then round the quotient to `scale` digits by the rounding mode
+func (d *Decimal) DivRound(other *Decimal, scale int, mode uint8) (*Decimal, error) {
+	if other.IsZero() {
+		return nil, zerr.ArithDivZero()
+	}
+	// d / other = (a × 10^(scale + other.scale - d.scale)) / b × 10^(-scale)
+	num := new(big.Int).Set(d.unscaled)
+	den := new(big.Int).Set(other.unscaled)
+	if shift := scale + other.scale - d.scale; shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	return NewDecimal(roundQuotient(num, den, mode), scale), nil
+}
+
+// Round - round the decimal to `scale` digits by the rounding mode
+func (d *Decimal) Round(scale int, mode uint8) *Decimal {
+	if scale >= d.scale {
+		return NewDecimal(new(big.Int).Mul(d.unscaled, pow10(scale-d.scale)), scale)
+	}
+	return NewDecimal(roundQuotient(d.unscaled, pow10(d.scale-scale), mode), scale)
+}
+
+// Neg - -d
+func (d *Decimal) Neg() *Decimal {
+	return NewDecimal(new(big.Int).Neg(d.unscaled), d.scale)
+}
+
+// trimZeros - remove trailing zeros after the decimal point, but keep at least minScale digits
+func (d *Decimal) trimZeros(minScale int) *Decimal {
+	unscaled := new(big.Int).Set(d.unscaled)
+	scale := d.scale
+	rem := new(big.Int)
+	for scale > minScale {
+		q, m := new(big.Int).QuoRem(unscaled, bigTen, rem)
+		if m.Sign() != 0 {
+			break
+		}
+		unscaled = q
+		scale--
+	}
+	return NewDecimal(unscaled, scale)
+}
+
+var decimalGetterMap = map[string]decimalGetterFunc{
+	"文本": decimalGetText,
+	"数值": decimalGetNumber,
+	"精度": decimalGetScale,
+}
+
+// GetProperty -
+func (d *Decimal) GetProperty(name string) (r.Element, error) {
+	if fn, ok := decimalGetterMap[name]; ok {
+		return fn(d)
+	}
+	return nil, zerr.PropertyNotFound(name)
+}
+
+// SetProperty -
+func (d *Decimal) SetProperty(name string, value r.Element) error {
+	return zerr.PropertyNotFound(name)
+}
+
+var decimalMethodMap = map[string]decimalMethodFunc{
+	"加":  decimalExecAdd,
+	"减":  decimalExecSub,
+	"乘":  decimalExecMul,
+	"除":  decimalExecDiv,
+	"保留": decimalExecRound,
+}
+
+// ExecMethod -
+func (d *Decimal) ExecMethod(name string, values []r.Element) (r.Element, error) {
+	if fn, ok := decimalMethodMap[name]; ok {
+		return fn(d, values)
+	}
+	return nil, zerr.MethodNotFound(name)
+}
+
+// ExecMethodVM - 除 follows the DecimalContext of VM, the same as the `/` operator
+func (d *Decimal) ExecMethodVM(vm *r.VM, name string, values []r.Element) (r.Element, error) {
+	if name == "除" {
+		return decimalReduce(d, values, func(a, b *Decimal) (*Decimal, error) {
+			return DivDecimals(vm, a, b)
+		})
+	}
+	return d.ExecMethod(name, values)
+}
+
+//// getters, setters and methods
+
+// getters
+func decimalGetText(d *Decimal) (r.Element, error) {
+	return NewString(d.String()), nil
+}
+
+func decimalGetNumber(d *Decimal) (r.Element, error) {
+	return NewNumber(d.Float64()), nil
+}
+
+func decimalGetScale(d *Decimal) (r.Element, error) {
+	return NewNumber(float64(d.scale)), nil
+}
+
+// methods
+func decimalExecAdd(d *Decimal, values []r.Element) (r.Element, error) {
+	return decimalReduce(d, values, func(a, b *Decimal) (*Decimal, error) {
+		return a.Add(b), nil
+	})
+}
+
+func decimalExecSub(d *Decimal, values []r.Element) (r.Element, error) {
+	return decimalReduce(d, values, func(a, b *Decimal) (*Decimal, error) {
+		return a.Sub(b), nil
+	})
+}
+
+func decimalExecMul(d *Decimal, values []r.Element) (r.Element, error) {
+	return decimalReduce(d, values, func(a, b *Decimal) (*Decimal, error) {
+		return a.Mul(b), nil
+	})
+}
+
+func decimalExecDiv(d *Decimal, values []r.Element) (r.Element, error) {
+	return decimalReduce(d, values, func(a, b *Decimal) (*Decimal, error) {
+		return a.Div(b)
+	})
+}
+
+// 以金额（保留：2）or 以金额（保留：2、“银行家舍入”）
+func decimalExecRound(d *Decimal, values []r.Element) (r.Element, error) {
+	if len(values) == 0 {
+		return nil, zerr.LeastParamsError(1)
+	}
+	if len(values) > 2 {
+		return nil, zerr.MostParamsError(2)
+	}
+	if err := ValidateLeastParams(values, "number", "string?"); err != nil {
+		return nil, err
+	}
+	scale, err := GetRoundingScale(values[0].(*Number))
+	if err != nil {
+		return nil, err
+	}
+
+	mode := RoundHalfUp
+	if len(values) > 1 {
+		m, err := GetRoundingMode(values[1].(*String).value)
+		if err != nil {
+			return nil, err
+		}
+		mode = m
+	}
+	return d.Round(scale, mode), nil
+}
+
+func decimalReduce(d *Decimal, values []r.Element, op func(a, b *Decimal) (*Decimal, error)) (r.Element, error) {
+	result := d
+	for _, v := range values {
+		dv, err := ToDecimal(v)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = op(result, dv); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+//// helpers
+
+// alignDecimals - get unscaled values of both decimals under the same scale
+func alignDecimals(a *Decimal, b *Decimal) (*big.Int, *big.Int) {
+	switch {
+	case a.scale > b.scale:
+		return a.unscaled, new(big.Int).Mul(b.unscaled, pow10(a.scale-b.scale))
+	case a.scale < b.scale:
+		return new(big.Int).Mul(a.unscaled, pow10(b.scale-a.scale)), b.unscaled
+	}
+	return a.unscaled, b.unscaled
+}
+
+// roundQuotient - calculate num / den, and round the result to an integer by the rounding mode
+func roundQuotient(num *big.Int, den *big.Int, mode uint8) *big.Int {
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return q
+	}
+	// sign of the exact quotient
+	sign := num.Sign() * den.Sign()
+	// compare 2×|rem| with |den| to find out if the remainder is more than half
+	half := new(big.Int).Abs(rem)
+	half.Lsh(half, 1)
+	halfCmp := half.Cmp(new(big.Int).Abs(den))
+
+	awayFromZero := false
+	switch mode {
+	case RoundHalfUp:
+		awayFromZero = halfCmp >= 0
+	case RoundHalfEven:
+		awayFromZero = halfCmp > 0 || (halfCmp == 0 && q.Bit(0) == 1)
+	case RoundDown:
+		awayFromZero = false
+	case RoundUp:
+		awayFromZero = true
+	case RoundCeiling:
+		awayFromZero = sign > 0
+	case RoundFloor:
+		awayFromZero = sign < 0
+	}
+
+	if awayFromZero {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,159 @@
+package value
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecimal_NewDecimalFromString(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		hasError bool
+	}{
+		{input: "123", expected: "123"},
+		{input: "0.10", expected: "0.10"},
+		{input: "-0.001", expected: "-0.001"},
+		{input: "+12.5", expected: "12.5"},
+		{input: "1,234,567.89", expected: "1234567.89"},
+		{input: "12345678901234567890.12", expected: "12345678901234567890.12"},
+		{input: "1.5*10^3", expected: "1500"},
+		{input: "1.25e-2", expected: "0.0125"},
+		{input: ".5", expected: "0.5"},
+		{input: "", hasError: true},
+		{input: "1.2.3", hasError: true},
+		{input: "12kg", hasError: true},
+		{input: "1-2", hasError: true},
+		{input: "1.23e", hasError: true},
+		// exponents & scales are limited by DecimalMaxScale
+		{input: "1e10000", expected: "1" + strings.Repeat("0", 10000)},
+		{input: "1e30000000", hasError: true},
+		{input: "1e-999999999", hasError: true},
+		{input: "0." + strings.Repeat("1", 10001), hasError: true},
+	}
+
+	for _, c := range cases {
+		result, err := NewDecimalFromString(c.input)
+		if c.hasError {
+			if err == nil {
+				t.Errorf("NewDecimalFromString('%s'): expect error, got '%s'", c.input, result.String())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewDecimalFromString('%s'): expect '%s', got error: '%s'", c.input, c.expected, err)
+		} else if result.String() != c.expected {
+			t.Errorf("NewDecimalFromString('%s'): expect '%s', got '%s'", c.input, c.expected, result.String())
+		}
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	dec := func(s string) *Decimal {
+		d, err := NewDecimalFromString(s)
+		if err != nil {
+			t.Fatalf("invalid decimal %s: %s", s, err)
+		}
+		return d
+	}
+	div := func(a, b *Decimal) *Decimal {
+		d, err := a.Div(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return d
+	}
+	divScale := func(a, b *Decimal, scale int, mode uint8) *Decimal {
+		d, err := a.DivScale(b, scale, mode)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return d
+	}
+
+	cases := []struct {
+		name     string
+		result   *Decimal
+		expected string
+	}{
+		{"add", dec("0.1").Add(dec("0.2")), "0.3"},
+		{"add with different scales", dec("1.5").Add(dec("2.25")), "3.75"},
+		{"sub", dec("0.3").Sub(dec("0.1")), "0.2"},
+		{"sub to negative", dec("1.00").Sub(dec("2.5")), "-1.50"},
+		{"mul", dec("19.99").Mul(dec("3")), "59.97"},
+		{"div exact", div(dec("1"), dec("4")), "0.25"},
+		{"div keeps scale", div(dec("10.00"), dec("4")), "2.50"},
+		{"div inexact", div(dec("2"), dec("3")), "0.6666666666666667"},
+		{"div negative", div(dec("-1"), dec("3")), "-0.3333333333333333"},
+		{"div with scale", divScale(dec("2"), dec("3"), 4, RoundDown), "0.6666"},
+		{"div with scale rounds half to even", divScale(dec("1"), dec("8"), 2, RoundHalfEven), "0.12"},
+		{"neg", dec("1.5").Neg(), "-1.5"},
+	}
+
+	for _, c := range cases {
+		if c.result.String() != c.expected {
+			t.Errorf("%s: expect '%s', got '%s'", c.name, c.expected, c.result.String())
+		}
+	}
+
+	if _, err := dec("1").Div(dec("0.00")); err == nil {
+		t.Errorf("divided by zero: expect error, got nil")
+	}
+	if dec("0.10").Cmp(dec("0.1")) != 0 || dec("-2").Cmp(dec("1.5")) != -1 || dec("2.01").Cmp(dec("2")) != 1 {
+		t.Errorf("Cmp() returns unexpected result")
+	}
+}
+
+func TestDecimal_Round(t *testing.T) {
+	cases := []struct {
+		input    string
+		scale    int
+		mode     uint8
+		expected string
+	}{
+		{"1.005", 2, RoundHalfUp, "1.01"},
+		{"-1.005", 2, RoundHalfUp, "-1.01"},
+		{"1.004", 2, RoundHalfUp, "1.00"},
+		{"2.5", 0, RoundHalfEven, "2"},
+		{"3.5", 0, RoundHalfEven, "4"},
+		{"-2.5", 0, RoundHalfEven, "-2"},
+		{"2.51", 0, RoundHalfEven, "3"},
+		{"1.99", 1, RoundDown, "1.9"},
+		{"-1.99", 1, RoundDown, "-1.9"},
+		{"1.01", 1, RoundUp, "1.1"},
+		{"-1.01", 1, RoundUp, "-1.1"},
+		{"1.01", 0, RoundCeiling, "2"},
+		{"-1.01", 0, RoundCeiling, "-1"},
+		{"1.01", 0, RoundFloor, "1"},
+		{"-1.01", 0, RoundFloor, "-2"},
+		{"1.5", 3, RoundHalfUp, "1.500"},
+	}
+
+	for _, c := range cases {
+		d, _ := NewDecimalFromString(c.input)
+		if result := d.Round(c.scale, c.mode).String(); result != c.expected {
+			t.Errorf("Round('%s', %d, %d): expect '%s', got '%s'", c.input, c.scale, c.mode, c.expected, result)
+		}
+	}
+}
+
+func TestDecimal_NewDecimalFromNumber(t *testing.T) {
+	cases := []struct {
+		input    float64
+		expected string
+	}{
+		{0.1, "0.1"},
+		{-12.5, "-12.5"},
+		{100, "100"},
+		{1e-7, "0.0000001"},
+	}
+
+	for _, c := range cases {
+		d, err := NewDecimalFromNumber(NewNumber(c.input))
+		if err != nil {
+			t.Errorf("NewDecimalFromNumber(%v): unexpected error: %s", c.input, err)
+		} else if d.String() != c.expected {
+			t.Errorf("NewDecimalFromNumber(%v): expect '%s', got '%s'", c.input, c.expected, d.String())
+		}
+	}
+}
@@ -9,10 +9,22 @@ import (
 
 type Exception struct {
 	Message string
+	// callStack - call stack of VM when the exception is thrown (see exec.captureCallStack)
+	callStack *Array
 }
 
 func NewException(message string) *Exception {
-	return &Exception{Message: message}
+	return &Exception{Message: message, callStack: NewEmptyArray()}
+}
+
+// GetCallStack -
+func (e *Exception) GetCallStack() *Array {
+	return e.callStack
+}
+
+// SetCallStack -
+func (e *Exception) SetCallStack(callStack *Array) {
+	e.callStack = callStack
 }
 
 func (e *Exception) String() string {
@@ -25,8 +37,11 @@ func (e *Exception) Error() string {
 
 // GetProperty -
 func (e *Exception) GetProperty(name string) (r.Element, error) {
-	if name == "内容" {
+	switch name {
+	case "内容":
 		return NewString(e.Message), nil
+	case "调用栈":
+		return e.callStack, nil
 	}
 	return nil, zerr.PropertyNotFound(name)
 }
@@ -38,17 +38,16 @@ func (fn *Function) Exec(thisValue r.Element, params []r.Element) (r.Element, er
 	result, err := fnLogicHandler(thisValue, params)
 	// convert error to exception
 	if err != nil {
-		switch err.(type) {
-		case *zerr.SyntaxError:
-		case *zerr.SemanticError:
-		case *zerr.IOError:
-		case *zerr.Signal:
+		switch e := err.(type) {
+		case *zerr.SyntaxError, *zerr.SemanticError, *zerr.IOError, *zerr.Signal:
 			// return the original error AS IS
 			return nil, err
 		case *Exception:
 			return nil, err
 		case *zerr.RuntimeError:
-			return nil, NewException(err.Error())
+			// return AS IS - runtime errors are converted to built-in exceptions (e.g. 索引异常)
+			// with the error code when caught; while interrupt errors (e.g. timeout) could not be caught
+			return nil, e
 		default:
 			// for other types of error (native errors), wrap the error as an Exception
 			return nil, NewException(err.Error())
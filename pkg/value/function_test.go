@@ -0,0 +1,40 @@
+package value
+
+import (
+	"errors"
+	"testing"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+func TestFunction_Exec_Errors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{name: "syntax error", err: zerr.InvalidSyntax(0)},
+		{name: "io error", err: zerr.FileNotFound("a.zn")},
+		{name: "runtime error", err: zerr.PropertyNotFound("长度")},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := NewFunction(func(r.Element, []r.Element) (r.Element, error) {
+				return nil, tt.err
+			})
+			if _, err := fn.Exec(nil, nil); err != tt.err {
+				t.Errorf("expect error returned as is, got %v", err)
+			}
+		})
+	}
+
+	// native errors are wrapped as exceptions
+	fn := NewFunction(func(r.Element, []r.Element) (r.Element, error) {
+		return nil, errors.New("失败")
+	})
+	if _, err := fn.Exec(nil, nil); err == nil {
+		t.Errorf("expect an exception, got nil")
+	} else if _, ok := err.(*Exception); !ok {
+		t.Errorf("expect an exception, got %T", err)
+	}
+}
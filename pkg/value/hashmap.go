@@ -81,14 +81,15 @@ func (hm *HashMap) AppendKVPair(pair KVPair) {
 	hm.keyOrder = append(hm.keyOrder, key)
 }
 
+var hmGetterMap = map[string]hmGetterFunc{
+	"数目":   hmGetLength,
+	"长度":   hmGetLength,
+	"所有索引": hmGetAllIndexes,
+	"所有值":  hmGetAllValues,
+}
+
 // GetProperty -
 func (hm *HashMap) GetProperty(name string) (r.Element, error) {
-	hmGetterMap := map[string]hmGetterFunc{
-		"数目":   hmGetLength,
-		"长度":   hmGetLength,
-		"所有索引": hmGetAllIndexes,
-		"所有值":  hmGetAllValues,
-	}
 	if fn, ok := hmGetterMap[name]; ok {
 		return fn(hm)
 	}
@@ -100,13 +101,14 @@ func (hm *HashMap) SetProperty(name string, value r.Element) error {
 	return zerr.PropertyNotFound(name)
 }
 
+var hmMethodMap = map[string]hmMethodFunc{
+	"读取": hmExecGet,
+	"写入": hmExecSet,
+	"移除": hmExecDelete,
+}
+
 // ExecMethod -
 func (hm *HashMap) ExecMethod(name string, values []r.Element) (r.Element, error) {
-	hmMethodMap := map[string]hmMethodFunc{
-		"读取": hmExecGet,
-		"写入": hmExecSet,
-		"移除": hmExecDelete,
-	}
 	if fn, ok := hmMethodMap[name]; ok {
 		return fn(hm, values)
 	}
@@ -61,6 +61,11 @@ func NewHashMapIV(root r.Element, member string) *IV {
 	}
 }
 
+// GetRoot - get the root object of IV
+func (iv *IV) GetRoot() r.Element {
+	return iv.root
+}
+
 // ReduceLHS - Reduce IV to value when IV on left-hand side
 // usually for setters
 func (iv *IV) ReduceLHS(input r.Element) error {
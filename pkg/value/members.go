@@ -0,0 +1,33 @@
+package value
+
+import "sort"
+
+// TypeMembers - names of properties & methods of a built-in type
+type TypeMembers struct {
+	Properties []string
+	Methods    []string
+}
+
+// GetBuiltinTypeMembers - get (sorted) member names of built-in types, keyed by the type name
+// (数值、小数、货币、日期、文本、逻辑、数组、列表). It's useful for tools like code completion.
+func GetBuiltinTypeMembers() map[string]TypeMembers {
+	return map[string]TypeMembers{
+		"数值": {Properties: sortedKeys(numGetterMap), Methods: sortedKeys(numMethodMap)},
+		"小数": {Properties: sortedKeys(decimalGetterMap), Methods: sortedKeys(decimalMethodMap)},
+		"货币": {Properties: sortedKeys(currencyGetterMap), Methods: sortedKeys(currencyMethodMap)},
+		"日期": {Properties: sortedKeys(dateTimeGetterMap), Methods: sortedKeys(dateTimeMethodMap)},
+		"文本": {Properties: sortedKeys(strGetterMap), Methods: sortedKeys(strMethodMap)},
+		"逻辑": {Properties: sortedKeys(boolGetterMap), Methods: []string{}},
+		"数组": {Properties: sortedKeys(arrayGetterMap), Methods: sortedKeys(arrayMethodMap)},
+		"列表": {Properties: sortedKeys(hmGetterMap), Methods: sortedKeys(hmMethodMap)},
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
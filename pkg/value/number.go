@@ -15,11 +15,18 @@ type numMethodFunc func(*Number, []r.Element) (r.Element, error)
 
 type Number struct {
 	value float64
+	// unit - the unit of number (e.g. kg, km/h); nil for plain numbers
+	unit *Unit
 }
 
 // NewNumber - create new number object (plain float64)
 func NewNumber(value float64) *Number {
-	return &Number{value}
+	return &Number{value, nil}
+}
+
+// NewNumberWithUnit - create new number object with unit (e.g. 80kg)
+func NewNumberWithUnit(value float64, unit *Unit) *Number {
+	return &Number{value, unit}
 }
 
 func NewNumberFromString(value string) (*Number, error) {
@@ -36,7 +43,7 @@ func NewNumberFromString(value string) (*Number, error) {
 
 // String -
 func (n *Number) String() string {
-	return fmt.Sprintf("%v", n.value)
+	return fmt.Sprintf("%v", n.value) + n.unit.String()
 }
 
 // Construct - make Number construtable
@@ -55,14 +62,24 @@ func (n *Number) GetValue() float64 {
 	return n.value
 }
 
+// GetUnit - get unit of the number (nil for plain numbers)
+func (n *Number) GetUnit() *Unit {
+	return n.unit
+}
+
+var numGetterMap = map[string]numGetterFunc{
+	"文本":  numGetText,
+	"平方":  numGetSquare,
+	"立方":  numGetCube,
+	"平方根": numGetSquareRoot,
+	"数字":  numGetDigits,
+	"单位":  numGetUnit,
+	"绝对值": numGetAbs,
+	"是整数": numGetIsInteger,
+}
+
 // GetProperty -
 func (n *Number) GetProperty(name string) (r.Element, error) {
-	numGetterMap := map[string]numGetterFunc{
-		"文本":  numGetText,
-		"平方":  numGetSquare,
-		"立方":  numGetCube,
-		"平方根": numGetSquareRoot,
-	}
 	if fn, ok := numGetterMap[name]; ok {
 		return fn(n)
 	}
@@ -74,18 +91,24 @@ func (n *Number) SetProperty(name string, value r.Element) error {
 	return zerr.PropertyNotFound(name)
 }
 
+var numMethodMap = map[string]numMethodFunc{
+	"加":    numExecAdd,
+	"减":    numExecSub,
+	"乘":    numExecMul,
+	"除":    numExecDiv,
+	"自增":   numExecSelfAdd,
+	"自减":   numExecSelfSub,
+	"向下取整": numExecFloor,
+	"向上取整": numExecCeil,
+	"转换":   numExecConvert,
+	"保留":   numExecRound,
+	"幂":    numExecPow,
+	"对数":   numExecLog,
+	"限制":   numExecClamp,
+}
+
 // ExecMethod -
 func (n *Number) ExecMethod(name string, values []r.Element) (r.Element, error) {
-	numMethodMap := map[string]numMethodFunc{
-		"加":    numExecAdd,
-		"减":    numExecSub,
-		"乘":    numExecMul,
-		"除":    numExecDiv,
-		"自增":   numExecSelfAdd,
-		"自减":   numExecSelfSub,
-		"向下取整": numExecFloor,
-		"向上取整": numExecCeil,
-	}
 	if fn, ok := numMethodMap[name]; ok {
 		return fn(n, values)
 	}
@@ -100,16 +123,25 @@ func numGetText(n *Number) (r.Element, error) {
 }
 
 func numGetSquare(n *Number) (r.Element, error) {
+	if n.unit != nil {
+		return nil, zerr.ArithIncompatibleUnits(n.unit.String(), n.unit.String())
+	}
 	res := n.value * n.value
 	return NewNumber(res), nil
 }
 
 func numGetCube(n *Number) (r.Element, error) {
+	if n.unit != nil {
+		return nil, zerr.ArithIncompatibleUnits(n.unit.String(), n.unit.String())
+	}
 	res := n.value * n.value * n.value
 	return NewNumber(res), nil
 }
 
 func numGetSquareRoot(n *Number) (r.Element, error) {
+	if n.unit != nil {
+		return nil, zerr.ArithIncompatibleUnits(n.unit.String(), "")
+	}
 	if n.value <= 0 {
 		return nil, zerr.ArithRootLessThanZero()
 	}
@@ -117,94 +149,197 @@ func numGetSquareRoot(n *Number) (r.Element, error) {
 	return NewNumber(res), nil
 }
 
+func numGetDigits(n *Number) (r.Element, error) {
+	return NewNumber(n.value), nil
+}
+
+func numGetUnit(n *Number) (r.Element, error) {
+	return NewString(n.unit.String()), nil
+}
+
+func numGetAbs(n *Number) (r.Element, error) {
+	return NewNumberWithUnit(math.Abs(n.value), n.unit), nil
+}
+
+func numGetIsInteger(n *Number) (r.Element, error) {
+	return NewBool(!math.IsInf(n.value, 0) && n.value == math.Trunc(n.value)), nil
+}
+
 // methods
 func numExecAdd(n *Number, values []r.Element) (r.Element, error) {
-	if err := ValidateAllParams(values, "number"); err != nil {
-		return nil, err
-	}
+	return numReduce(n, values, AddNumbers)
+}
 
-	sum := n.value
-	for _, v := range values {
-		vr, _ := v.(*Number)
-		sum += vr.value
-	}
+func numExecSub(n *Number, values []r.Element) (r.Element, error) {
+	return numReduce(n, values, SubNumbers)
+}
 
-	return NewNumber(sum), nil
+func numExecMul(n *Number, values []r.Element) (r.Element, error) {
+	return numReduce(n, values, MulNumbers)
 }
 
-func numExecSub(n *Number, values []r.Element) (r.Element, error) {
-	if err := ValidateAllParams(values, "number"); err != nil {
+func numExecDiv(n *Number, values []r.Element) (r.Element, error) {
+	return numReduce(n, values, DivNumbers)
+}
+
+func numExecSelfAdd(n *Number, values []r.Element) (r.Element, error) {
+	if err := ValidateExactParams(values, "number"); err != nil {
 		return nil, err
 	}
 
-	sum := n.value
-	for _, v := range values {
-		vr, _ := v.(*Number)
-		sum -= vr.value
+	res, err := AddNumbers(n, values[0].(*Number))
+	if err != nil {
+		return nil, err
 	}
+	n.value, n.unit = res.value, res.unit
 
-	return NewNumber(sum), nil
+	return n, nil
 }
 
-func numExecMul(n *Number, values []r.Element) (r.Element, error) {
-	if err := ValidateAllParams(values, "number"); err != nil {
+func numExecSelfSub(n *Number, values []r.Element) (r.Element, error) {
+	if err := ValidateExactParams(values, "number"); err != nil {
 		return nil, err
 	}
 
-	sum := n.value
-	for _, v := range values {
-		vr, _ := v.(*Number)
-		sum *= vr.value
+	res, err := SubNumbers(n, values[0].(*Number))
+	if err != nil {
+		return nil, err
 	}
+	n.value, n.unit = res.value, res.unit
 
-	return NewNumber(sum), nil
+	return n, nil
 }
 
-func numExecDiv(n *Number, values []r.Element) (r.Element, error) {
+// numReduce - apply the arith operation on the number and all values in order
+func numReduce(n *Number, values []r.Element, op func(*Number, *Number) (*Number, error)) (r.Element, error) {
 	if err := ValidateAllParams(values, "number"); err != nil {
 		return nil, err
 	}
 
-	sum := n.value
+	result := n
 	for _, v := range values {
 		vr, _ := v.(*Number)
-		if vr.value == 0 {
-			return nil, zerr.ArithDivZero()
+		res, err := op(result, vr)
+		if err != nil {
+			return nil, err
 		}
-		sum /= vr.value
+		result = res
 	}
 
-	return NewNumber(sum), nil
+	return NewNumberWithUnit(result.value, result.unit), nil
 }
 
-func numExecSelfAdd(n *Number, values []r.Element) (r.Element, error) {
-	if err := ValidateExactParams(values, "number"); err != nil {
+func numExecFloor(n *Number, values []r.Element) (r.Element, error) {
+	return NewNumberWithUnit(math.Floor(n.value), n.unit), nil
+}
+
+func numExecCeil(n *Number, values []r.Element) (r.Element, error) {
+	return NewNumberWithUnit(math.Ceil(n.value), n.unit), nil
+}
+
+func numExecConvert(n *Number, values []r.Element) (r.Element, error) {
+	if err := ValidateExactParams(values, "string"); err != nil {
 		return nil, err
 	}
 
-	target, _ := values[0].(*Number)
-
-	n.value = n.value + target.value
+	unit, err := ParseUnit(values[0].(*String).value)
+	if err != nil {
+		return nil, err
+	}
+	return ConvertNumber(n, unit)
+}
 
-	return n, nil
+// 以X（保留：位数、舍入方式） - the number is rounded as a decimal, so that 2.675 is rounded
+// to 2.68 (四舍五入) as it's written, instead of 2.67 from its binary form. The unit is kept.
+func numExecRound(n *Number, values []r.Element) (r.Element, error) {
+	d, err := NewDecimalFromNumber(NewNumber(n.value))
+	if err != nil {
+		return nil, err
+	}
+	result, err := decimalExecRound(d, values)
+	if err != nil {
+		return nil, err
+	}
+	return NewNumberWithUnit(result.(*Decimal).Float64(), n.unit), nil
 }
 
-func numExecSelfSub(n *Number, values []r.Element) (r.Element, error) {
+// 以X（幂：指数）
+func numExecPow(n *Number, values []r.Element) (r.Element, error) {
 	if err := ValidateExactParams(values, "number"); err != nil {
 		return nil, err
 	}
+	exp := values[0].(*Number)
+	if n.unit != nil || exp.unit != nil {
+		return nil, zerr.ArithIncompatibleUnits(n.unit.String(), exp.unit.String())
+	}
+	res := math.Pow(n.value, exp.value)
+	if math.IsNaN(res) || math.IsInf(res, 0) {
+		return nil, zerr.ArithOutOfDomain("幂")
+	}
+	return NewNumber(res), nil
+}
 
-	target, _ := values[0].(*Number)
-
-	n.value = n.value - target.value
+// 以X（对数） or 以X（对数：底数） - the natural logarithm if 底数 is omitted
+func numExecLog(n *Number, values []r.Element) (r.Element, error) {
+	if len(values) > 1 {
+		return nil, zerr.MostParamsError(1)
+	}
+	if err := ValidateAllParams(values, "number"); err != nil {
+		return nil, err
+	}
+	if n.unit != nil {
+		return nil, zerr.ArithIncompatibleUnits(n.unit.String(), "")
+	}
+	if n.value <= 0 {
+		return nil, zerr.ArithOutOfDomain("对数")
+	}
 
-	return n, nil
+	res := math.Log(n.value)
+	if len(values) > 0 {
+		base := values[0].(*Number)
+		if base.unit != nil {
+			return nil, zerr.ArithIncompatibleUnits(base.unit.String(), "")
+		}
+		if base.value <= 0 || base.value == 1 {
+			return nil, zerr.ArithOutOfDomain("对数")
+		}
+		// use Log10 & Log2 for common bases to avoid float errors (e.g. log10(1000) = 3)
+		switch base.value {
+		case 10:
+			res = math.Log10(n.value)
+		case 2:
+			res = math.Log2(n.value)
+		default:
+			res = res / math.Log(base.value)
+		}
+	}
+	return NewNumber(res), nil
 }
 
-func numExecFloor(n *Number, values []r.Element) (r.Element, error) {
-	return NewNumber(math.Floor(n.value)), nil
-}
+// 以X（限制：最小值、最大值） - limit the number within [最小值, 最大值]; the result keeps
+// the unit of X, e.g. 以1.2kg（限制：0kg、500g） -> 0.5kg
+func numExecClamp(n *Number, values []r.Element) (r.Element, error) {
+	if err := ValidateExactParams(values, "number", "number"); err != nil {
+		return nil, err
+	}
+	min, max := values[0].(*Number), values[1].(*Number)
+	if cmp, err := CompareNumbers(min, max); err != nil {
+		return nil, err
+	} else if cmp > 0 {
+		return nil, ThrowException("最小值不能大于最大值")
+	}
 
-func numExecCeil(n *Number, values []r.Element) (r.Element, error) {
-	return NewNumber(math.Ceil(n.value)), nil
+	for _, bound := range []struct {
+		value *Number
+		sign  int
+	}{{min, -1}, {max, 1}} {
+		cmp, err := CompareNumbers(n, bound.value)
+		if err != nil {
+			return nil, err
+		}
+		if cmp == bound.sign {
+			return ConvertNumber(bound.value, n.unit)
+		}
+	}
+	return n, nil
 }
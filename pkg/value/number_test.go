@@ -0,0 +1,107 @@
+package value
+
+import (
+	"math"
+	"testing"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+)
+
+func TestNumber_ExecMethod(t *testing.T) {
+	num := func(v float64, unitStr string) *Number {
+		unit, _ := ParseUnit(unitStr)
+		return NewNumberWithUnit(v, unit)
+	}
+	cases := []struct {
+		name     string
+		number   *Number
+		method   string
+		params   []r.Element
+		expected string
+		errCode  int
+		// throws - an exception is thrown instead of a runtime error
+		throws bool
+	}{
+		{name: "round half up", number: num(2.675, ""), method: "保留", params: []r.Element{NewNumber(2)}, expected: "2.68"},
+		{name: "round half even", number: num(2.665, ""), method: "保留", params: []r.Element{NewNumber(2), NewString("银行家舍入")}, expected: "2.66"},
+		{name: "round negative", number: num(-1.5, ""), method: "保留", params: []r.Element{NewNumber(0)}, expected: "-2"},
+		{name: "round keeps unit", number: num(1.2345, "kg"), method: "保留", params: []r.Element{NewNumber(1)}, expected: "1.2kg"},
+		{name: "round huge scale", number: num(1.5, ""), method: "保留", params: []r.Element{NewNumber(1e9)}, errCode: zerr.ErrInvalidParamType},
+		{name: "round non-integer scale", number: num(1.5, ""), method: "保留", params: []r.Element{NewNumber(1.5)}, errCode: zerr.ErrInvalidParamType},
+		{name: "round NaN scale", number: num(1.5, ""), method: "保留", params: []r.Element{NewNumber(math.NaN())}, errCode: zerr.ErrInvalidParamType},
+		{name: "round invalid mode", number: num(1.5, ""), method: "保留", params: []r.Element{NewNumber(0), NewString("随便")}, errCode: zerr.ErrInvalidParamType},
+		{name: "pow", number: num(2, ""), method: "幂", params: []r.Element{NewNumber(10)}, expected: "1024"},
+		{name: "pow fraction", number: num(9, ""), method: "幂", params: []r.Element{NewNumber(0.5)}, expected: "3"},
+		{name: "pow out of domain", number: num(-8, ""), method: "幂", params: []r.Element{NewNumber(0.5)}, errCode: zerr.ErrArithOutOfDomain},
+		{name: "pow with unit", number: num(2, "m"), method: "幂", params: []r.Element{NewNumber(2)}, errCode: zerr.ErrArithIncompatibleUnits},
+		{name: "log with base", number: num(1000, ""), method: "对数", params: []r.Element{NewNumber(10)}, expected: "3"},
+		{name: "log base 2", number: num(8, ""), method: "对数", params: []r.Element{NewNumber(2)}, expected: "3"},
+		{name: "natural log", number: num(1, ""), method: "对数", params: []r.Element{}, expected: "0"},
+		{name: "log of zero", number: num(0, ""), method: "对数", params: []r.Element{}, errCode: zerr.ErrArithOutOfDomain},
+		{name: "log base 1", number: num(5, ""), method: "对数", params: []r.Element{NewNumber(1)}, errCode: zerr.ErrArithOutOfDomain},
+		{name: "clamp within", number: num(5, ""), method: "限制", params: []r.Element{NewNumber(0), NewNumber(10)}, expected: "5"},
+		{name: "clamp below", number: num(-3, ""), method: "限制", params: []r.Element{NewNumber(0), NewNumber(10)}, expected: "0"},
+		{name: "clamp above", number: num(12, ""), method: "限制", params: []r.Element{NewNumber(0), NewNumber(10)}, expected: "10"},
+		{name: "clamp converts unit", number: num(1.2, "kg"), method: "限制", params: []r.Element{num(0, "kg"), num(500, "g")}, expected: "0.5kg"},
+		{name: "clamp invalid range", number: num(1, ""), method: "限制", params: []r.Element{NewNumber(10), NewNumber(0)}, throws: true},
+		{name: "clamp incompatible units", number: num(1, "kg"), method: "限制", params: []r.Element{num(0, "m"), num(5, "m")}, errCode: zerr.ErrArithIncompatibleUnits},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := c.number.ExecMethod(c.method, c.params)
+			if c.throws {
+				if _, ok := err.(*zerr.Signal); !ok {
+					t.Errorf("expect exception, got %v", err)
+				}
+				return
+			}
+			if c.errCode != 0 {
+				if code := getErrorCode(err); code != c.errCode {
+					t.Errorf("expect error code %d, got %v", c.errCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expect no error, got %v", err)
+				return
+			}
+			if result.String() != c.expected {
+				t.Errorf("expect '%s', got '%s'", c.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestNumber_GetProperty(t *testing.T) {
+	cases := []struct {
+		number   *Number
+		property string
+		expected string
+	}{
+		{NewNumber(-2.5), "绝对值", "2.5"},
+		{NewNumberWithUnit(-3, &Unit{Mul: "kg"}), "绝对值", "3kg"},
+		{NewNumber(3), "是整数", "真"},
+		{NewNumber(-4.0), "是整数", "真"},
+		{NewNumber(3.01), "是整数", "假"},
+	}
+
+	for _, c := range cases {
+		result, err := c.number.GetProperty(c.property)
+		if err != nil {
+			t.Errorf("%s之%s: expect no error, got %v", c.number, c.property, err)
+			continue
+		}
+		if result.String() != c.expected {
+			t.Errorf("%s之%s: expect '%s', got '%s'", c.number, c.property, c.expected, result.String())
+		}
+	}
+}
+
+func getErrorCode(err error) int {
+	if e, ok := err.(*zerr.RuntimeError); ok {
+		return e.Code
+	}
+	return 0
+}
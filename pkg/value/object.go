@@ -41,8 +41,13 @@ func (zo *Object) GetObjectName() string {
 	return zo.model.GetName()
 }
 
+func (zo *Object) GetModel() *ClassModel {
+	return zo.model
+}
+
+// IsInstanceOf - if the object is created from classModel or its subclasses
 func (zo *Object) IsInstanceOf(classModel *ClassModel) bool {
-	return zo.model == classModel
+	return zo.model.IsSubclassOf(classModel)
 }
 
 // GetProperty -
@@ -75,3 +80,53 @@ func (zo *Object) ExecMethod(name string, values []r.Element) (r.Element, error)
 	}
 	return nil, zerr.MethodNotFound(name)
 }
+
+// ParentObject - the 「父类」 value inside methods & constructors of a class that has
+// a parent class. Properties are the same as the object itself, while methods are looked
+// up from the parent class, thus an overridden method could still call the original one
+// by 父类之（方法）. Specially, 父类之（构造：...） executes the constructor of the parent class.
+type ParentObject struct {
+	object *Object
+	model  *ClassModel
+}
+
+// ParentConstructorMethod - the method name to call the constructor of the parent class
+const ParentConstructorMethod = "构造"
+
+// NewParentObject - parent is the parent class of the class where the method is defined
+// (which is not always the parent class of the object's model)
+func NewParentObject(object *Object, parent *ClassModel) *ParentObject {
+	return &ParentObject{
+		object: object,
+		model:  parent,
+	}
+}
+
+func (po *ParentObject) GetObject() *Object {
+	return po.object
+}
+
+func (po *ParentObject) String() string {
+	return fmt.Sprintf("‹父类·%s›", po.model.name)
+}
+
+// GetProperty -
+func (po *ParentObject) GetProperty(name string) (r.Element, error) {
+	return po.object.GetProperty(name)
+}
+
+// SetProperty -
+func (po *ParentObject) SetProperty(name string, value r.Element) error {
+	return po.object.SetProperty(name, value)
+}
+
+// ExecMethod -
+func (po *ParentObject) ExecMethod(name string, values []r.Element) (r.Element, error) {
+	if name == ParentConstructorMethod {
+		return po.model.ConstructInstance(po.object, values)
+	}
+	if method, ok := po.model.FindMethod(name); ok {
+		return method.Exec(po.object, values)
+	}
+	return nil, zerr.MethodNotFound(name)
+}
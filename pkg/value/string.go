@@ -31,14 +31,15 @@ func (s *String) GetValue() string {
 	return s.value
 }
 
+var strGetterMap = map[string]strGetterFunc{
+	"长度":  strGetLength,
+	"字数":  strGetLength,
+	"文本":  strGetText,
+	"字符组": strGetCharArray,
+}
+
 // GetProperty -
 func (s *String) GetProperty(name string) (r.Element, error) {
-	strGetterMap := map[string]strGetterFunc{
-		"长度":  strGetLength,
-		"字数":  strGetLength,
-		"文本":  strGetText,
-		"字符组": strGetCharArray,
-	}
 	if fn, ok := strGetterMap[name]; ok {
 		return fn(s)
 	}
@@ -50,28 +51,85 @@ func (s *String) SetProperty(name string, value r.Element) error {
 	return zerr.PropertyNotFound(name)
 }
 
+var strMethodMap = map[string]strMethodFunc{
+	"替换":     strExecReplace,
+	"分隔":     strExecSplit,
+	"匹配":     strExecMatch,
+	"匹配开头":   strExecMatchStart,
+	"匹配结尾":   strExecMatchEnd,
+	"取样":     strExecSlice,
+	"去除空格":   strExecStripWhitespaces,
+	"转小写-英文": strExecToLowerCase,
+	"转大写-英文": strExecToUpperCase,
+	"拼接":     strExecJoin,
+	"格式化":    strExecFormat,
+	"转换数值":   strExecAtoi,
+}
+
 // ExecMethod -
 func (s *String) ExecMethod(name string, values []r.Element) (r.Element, error) {
-	strMethodMap := map[string]strMethodFunc{
-		"替换":     strExecReplace,
-		"分隔":     strExecSplit,
-		"匹配":     strExecMatch,
-		"匹配开头":   strExecMatchStart,
-		"匹配结尾":   strExecMatchEnd,
-		"取样":     strExecSlice,
-		"去除空格":   strExecStripWhitespaces,
-		"转小写-英文": strExecToLowerCase,
-		"转大写-英文": strExecToUpperCase,
-		"拼接":     strExecJoin,
-		"格式化":    strExecFormat,
-		"转换数值":   strExecAtoi,
-	}
 	if fn, ok := strMethodMap[name]; ok {
 		return fn(s, values)
 	}
 	return nil, zerr.MethodNotFound(name)
 }
 
+// strResultSizeMap - for methods that may create a huge string (or array), get the type & size
+// of the result before creating it, so that the exec limits are checked before the allocation.
+// Invalid params are left for the method itself to report.
+var strResultSizeMap = map[string]func(*String, []r.Element) (string, int){
+	"替换": func(s *String, values []r.Element) (string, int) {
+		if ValidateExactParams(values, "string", "string") != nil {
+			return "string", 0
+		}
+		oldItem, newItem := values[0].(*String).value, values[1].(*String).value
+		if oldItem == "" {
+			// an empty oldItem matches at the beginning & after each char
+			return "string", len(s.value) + (utf8.RuneCountInString(s.value)+1)*len(newItem)
+		}
+		return "string", len(s.value) + strings.Count(s.value, oldItem)*(len(newItem)-len(oldItem))
+	},
+	"分隔": func(s *String, values []r.Element) (string, int) {
+		if ValidateExactParams(values, "string") != nil {
+			return "array", 0
+		}
+		sep := values[0].(*String).value
+		if sep == "" {
+			return "array", utf8.RuneCountInString(s.value)
+		}
+		return "array", strings.Count(s.value, sep) + 1
+	},
+	"拼接": func(s *String, values []r.Element) (string, int) {
+		size := len(s.value)
+		for _, v := range values {
+			if str, ok := v.(*String); ok {
+				size += len(str.value)
+			}
+		}
+		return "string", size
+	},
+	"格式化": func(s *String, values []r.Element) (string, int) {
+		size := len(s.value)
+		for idx, v := range values {
+			if str, ok := v.(*String); ok {
+				size += strings.Count(s.value, fmt.Sprintf("{#%d}", idx+1)) * len(str.value)
+			}
+		}
+		return "string", size
+	},
+}
+
+// ExecMethodVM - check the exec limits of VM before executing the method
+func (s *String) ExecMethodVM(vm *r.VM, name string, values []r.Element) (r.Element, error) {
+	if fn, ok := strResultSizeMap[name]; ok {
+		typeName, size := fn(s, values)
+		if err := vm.GetExecLimits().CheckSize(typeName, size); err != nil {
+			return nil, err
+		}
+	}
+	return s.ExecMethod(name, values)
+}
+
 // ///// getters, setters and methods
 // getters
 func strGetLength(s *String) (r.Element, error) {
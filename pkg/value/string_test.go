@@ -2,6 +2,8 @@ package value
 
 import (
 	"testing"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
 )
 
 func TestString_StrExecAtoi(t *testing.T) {
@@ -45,3 +47,31 @@ func TestString_StrExecAtoi(t *testing.T) {
 		}
 	}
 }
+
+func TestString_ExecMethodVM_Limits(t *testing.T) {
+	vm := r.InitVM(nil)
+	vm.SetExecLimits(r.ExecLimits{MaxStringSize: 20, MaxArraySize: 5})
+
+	cases := []struct {
+		name     string
+		method   string
+		params   []r.Element
+		hasError bool
+	}{
+		{"replace within the limit", "替换", []r.Element{NewString("a"), NewString("b")}, false},
+		{"replace exceeds the limit", "替换", []r.Element{NewString("a"), NewString("bbb")}, true},
+		{"replace empty string", "替换", []r.Element{NewString(""), NewString("-")}, true},
+		{"split exceeds the limit", "分隔", []r.Element{NewString("")}, true},
+		{"join exceeds the limit", "拼接", []r.Element{NewString("0123456789"), NewString("0123456789")}, true},
+		{"format within the limit", "格式化", []r.Element{NewString("X")}, false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewString("aaaaaaaaaa").ExecMethodVM(vm, tt.method, tt.params)
+			if tt.hasError != (err != nil) {
+				t.Errorf("expect hasError = %v, got %v", tt.hasError, err)
+			}
+		})
+	}
+}
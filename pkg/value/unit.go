@@ -0,0 +1,261 @@
+package value
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+)
+
+// Unit - the unit of a number (see 草案14), consists of a 乘法单位 (Mul) and
+// an optional 除法单位 (Div). e.g. `80kg` -> {kg, ""}, `3.6km/h` -> {km, h}
+//
+// Unit is immutable, thus it could be shared among numbers safely.
+type Unit struct {
+	Mul string
+	Div string
+}
+
+// unitDef - a registered unit is defined by its dimension and the factor
+// relative to the base unit of that dimension; e.g. km = 1000 × m (长度)
+type unitDef struct {
+	dimension string
+	factor    float64
+}
+
+// unitRegistry - all registered units. Units that are not registered
+// (e.g. 件、箱) are NOT errors: each of them is treated as a dimension of its
+// own, thus only the same unit is compatible with it.
+var unitRegistry = map[string]unitDef{}
+
+// RegisterUnit - register a unit with its dimension and the factor relative
+// to the base unit of the dimension. e.g. RegisterUnit("吨", "质量", 1000)
+// means 1吨 = 1000kg where kg is the base unit of 质量 (factor = 1).
+//
+// NOTE: it's NOT thread-safe and should be called before any execution.
+func RegisterUnit(name string, dimension string, factor float64) {
+	unitRegistry[name] = unitDef{dimension, factor}
+}
+
+func init() {
+	builtinUnits := []struct {
+		dimension string
+		factor    float64
+		names     []string
+	}{
+		// 长度 (base: m)
+		{"长度", 1, []string{"m", "米"}},
+		{"长度", 1000, []string{"km", "千米", "公里"}},
+		{"长度", 0.01, []string{"cm", "厘米"}},
+		{"长度", 0.001, []string{"mm", "毫米"}},
+		// 质量 (base: kg)
+		{"质量", 1, []string{"kg", "千克", "公斤"}},
+		{"质量", 0.001, []string{"g", "克"}},
+		{"质量", 0.000001, []string{"mg", "毫克"}},
+		{"质量", 1000, []string{"t", "吨"}},
+		{"质量", 0.5, []string{"斤"}},
+		// 时间 (base: s)
+		{"时间", 1, []string{"s", "秒"}},
+		{"时间", 0.001, []string{"ms", "毫秒"}},
+		{"时间", 60, []string{"min", "分钟"}},
+		{"时间", 3600, []string{"h", "小时"}},
+		{"时间", 86400, []string{"d", "天"}},
+		{"时间", 604800, []string{"周"}},
+		// 体积 (base: CBM)
+		{"体积", 1, []string{"CBM", "立方米"}},
+		{"体积", 0.001, []string{"L", "升"}},
+		{"体积", 0.000001, []string{"mL", "毫升", "立方厘米"}},
+	}
+	for _, def := range builtinUnits {
+		for _, name := range def.names {
+			RegisterUnit(name, def.dimension, def.factor)
+		}
+	}
+}
+
+// ParseUnit - parse unit string (e.g. "kg", "km/h"). An empty string means
+// no unit (returns nil).
+func ParseUnit(unitStr string) (*Unit, error) {
+	if unitStr == "" {
+		return nil, nil
+	}
+	parts := strings.Split(unitStr, "/")
+	if len(parts) > 2 {
+		return nil, zerr.InvalidIDFormat(unitStr)
+	}
+	for _, part := range parts {
+		if !isValidUnitName(part) {
+			return nil, zerr.InvalidIDFormat(unitStr)
+		}
+	}
+	unit := &Unit{Mul: parts[0]}
+	if len(parts) == 2 {
+		unit.Div = parts[1]
+	}
+	return unit, nil
+}
+
+// isValidUnitName - a unit name is either a registered unit (e.g. kg, m, 立方米), or
+// consists of letters only (e.g. 件, 箱, pcs). Single Latin letters that are not registered
+// (e.g. `2x`) are not allowed, since they're more likely to be typos of expressions.
+func isValidUnitName(name string) bool {
+	if _, ok := unitRegistry[name]; ok {
+		return true
+	}
+	if name == "" {
+		return false
+	}
+	hasHan := false
+	for _, ch := range name {
+		if !unicode.IsLetter(ch) {
+			return false
+		}
+		if unicode.Is(unicode.Han, ch) {
+			hasHan = true
+		}
+	}
+	return hasHan || utf8.RuneCountInString(name) > 1
+}
+
+// String -
+func (u *Unit) String() string {
+	if u == nil {
+		return ""
+	}
+	if u.Div == "" {
+		return u.Mul
+	}
+	return u.Mul + "/" + u.Div
+}
+
+// GetDimension - get the dimension of the unit, e.g. "长度", "长度/时间"
+func (u *Unit) GetDimension() string {
+	if u == nil {
+		return ""
+	}
+	dim := lookupUnit(u.Mul).dimension
+	if u.Div != "" {
+		dim = dim + "/" + lookupUnit(u.Div).dimension
+	}
+	return dim
+}
+
+func lookupUnit(name string) unitDef {
+	if def, ok := unitRegistry[name]; ok {
+		return def
+	}
+	return unitDef{dimension: name, factor: 1}
+}
+
+func unitEquals(a *Unit, b *Unit) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Mul == b.Mul && a.Div == b.Div
+}
+
+// convertUnitValue - convert the value from one unit to another unit within
+// the same dimension. The factors of 乘法单位 and 除法单位 are applied
+// separately to reduce float errors: e.g. 3.6km/h -> (3.6 × 1000 × 1) / (3600 × 1) m/s
+func convertUnitValue(value float64, from *Unit, to *Unit) (float64, error) {
+	if unitEquals(from, to) {
+		return value, nil
+	}
+	if from == nil || to == nil || from.GetDimension() != to.GetDimension() {
+		return 0, zerr.ArithIncompatibleUnits(from.String(), to.String())
+	}
+
+	num := value * lookupUnit(from.Mul).factor
+	den := lookupUnit(to.Mul).factor
+	if from.Div != "" {
+		num *= lookupUnit(to.Div).factor
+		den *= lookupUnit(from.Div).factor
+	}
+	return num / den, nil
+}
+
+// ConvertNumber - convert a number with unit to the target unit
+// e.g. 2.3kg -> 2300g
+func ConvertNumber(n *Number, to *Unit) (*Number, error) {
+	v, err := convertUnitValue(n.value, n.unit, to)
+	if err != nil {
+		return nil, err
+	}
+	return NewNumberWithUnit(v, to), nil
+}
+
+// AddNumbers - calculate a + b. Both numbers should be either plain numbers
+// or numbers with units of the same dimension; the result takes the unit of
+// the last number, e.g. 2.3kg + 500g = 2800g
+func AddNumbers(a *Number, b *Number) (*Number, error) {
+	va, err := convertUnitValue(a.value, a.unit, b.unit)
+	if err != nil {
+		return nil, err
+	}
+	return NewNumberWithUnit(va+b.value, b.unit), nil
+}
+
+// SubNumbers - calculate a - b, same rule of units as AddNumbers
+func SubNumbers(a *Number, b *Number) (*Number, error) {
+	va, err := convertUnitValue(a.value, a.unit, b.unit)
+	if err != nil {
+		return nil, err
+	}
+	return NewNumberWithUnit(va-b.value, b.unit), nil
+}
+
+// MulNumbers - calculate a * b. A number with unit could only be multiplied
+// by a plain number (as coefficient), except rate × time, i.e. the 除法单位
+// of one number is of the same dimension of the other: 2km/h * 15min = 0.5km
+func MulNumbers(a *Number, b *Number) (*Number, error) {
+	if a.unit == nil || b.unit == nil {
+		unit := a.unit
+		if unit == nil {
+			unit = b.unit
+		}
+		return NewNumberWithUnit(a.value*b.value, unit), nil
+	}
+
+	rate, amount := a, b
+	if rate.unit.Div == "" {
+		rate, amount = b, a
+	}
+	if rate.unit.Div != "" && amount.unit.Div == "" {
+		divUnit := &Unit{Mul: rate.unit.Div}
+		if amount.unit.GetDimension() == divUnit.GetDimension() {
+			v, _ := convertUnitValue(amount.value, amount.unit, divUnit)
+			return NewNumberWithUnit(rate.value*v, &Unit{Mul: rate.unit.Mul}), nil
+		}
+	}
+	return nil, zerr.ArithIncompatibleUnits(a.unit.String(), b.unit.String())
+}
+
+// DivNumbers - calculate a / b. The divisor should always be a plain number,
+// and the result keeps the unit of the dividend, e.g. 2.5km/s / 2 = 1.25km/s
+func DivNumbers(a *Number, b *Number) (*Number, error) {
+	if b.unit != nil {
+		return nil, zerr.ArithIncompatibleUnits(a.unit.String(), b.unit.String())
+	}
+	if b.value == 0 {
+		return nil, zerr.ArithDivZero()
+	}
+	return NewNumberWithUnit(a.value/b.value, a.unit), nil
+}
+
+// CompareNumbers - compare two numbers (with units). Returns -1 if a < b,
+// 0 if a == b and 1 if a > b.
+func CompareNumbers(a *Number, b *Number) (int, error) {
+	va, err := convertUnitValue(a.value, a.unit, b.unit)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case va < b.value:
+		return -1, nil
+	case va > b.value:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
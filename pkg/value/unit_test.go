@@ -0,0 +1,107 @@
+package value
+
+import (
+	"testing"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+)
+
+func TestUnit_ParseUnit(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		hasError bool
+	}{
+		{input: "kg", expected: "kg"},
+		{input: "km/h", expected: "km/h"},
+		{input: "立方米", expected: "立方米"},
+		{input: "", expected: ""},
+		{input: "km/h/s", hasError: true},
+		{input: "/h", hasError: true},
+		{input: "kg/", hasError: true},
+		{input: "-3", hasError: true},
+		{input: "%3", hasError: true},
+		{input: "x", hasError: true},
+		{input: "m²", hasError: true},
+		{input: "件", expected: "件"},
+		{input: "pcs", expected: "pcs"},
+	}
+
+	for _, c := range cases {
+		unit, err := ParseUnit(c.input)
+		if c.hasError {
+			if err == nil {
+				t.Errorf("ParseUnit('%s'): expect error, got '%s'", c.input, unit.String())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUnit('%s'): expect '%s', got error: '%s'", c.input, c.expected, err)
+		} else if unit.String() != c.expected {
+			t.Errorf("ParseUnit('%s'): expect '%s', got '%s'", c.input, c.expected, unit.String())
+		}
+	}
+}
+
+func TestUnit_Arithmetic(t *testing.T) {
+	num := func(v float64, unitStr string) *Number {
+		unit, _ := ParseUnit(unitStr)
+		return NewNumberWithUnit(v, unit)
+	}
+	cases := []struct {
+		name     string
+		op       func(*Number, *Number) (*Number, error)
+		left     *Number
+		right    *Number
+		expected string
+		errCode  int
+	}{
+		{name: "add same unit", op: AddNumbers, left: num(2.0, "CBM"), right: num(3.3, "CBM"), expected: "5.3CBM"},
+		{name: "add takes last unit", op: AddNumbers, left: num(2.3, "kg"), right: num(500, "g"), expected: "2800g"},
+		{name: "add compound units", op: AddNumbers, left: num(3.6, "km/h"), right: num(4.0, "m/s"), expected: "5m/s"},
+		{name: "add aliases", op: AddNumbers, left: num(1, "立方米"), right: num(1, "CBM"), expected: "2CBM"},
+		{name: "sub business units", op: SubNumbers, left: num(10, "箱"), right: num(3, "箱"), expected: "7箱"},
+		{name: "add plain numbers", op: AddNumbers, left: num(20, ""), right: num(30, ""), expected: "50"},
+		{name: "mul coefficient", op: MulNumbers, left: num(2, ""), right: num(3, "m"), expected: "6m"},
+		{name: "mul rate by time", op: MulNumbers, left: num(2, "km/h"), right: num(15, "min"), expected: "0.5km"},
+		{name: "div by coefficient", op: DivNumbers, left: num(2.5, "km/s"), right: num(2, ""), expected: "1.25km/s"},
+		{name: "add different dimensions", op: AddNumbers, left: num(2, "kg"), right: num(3, "m"), errCode: zerr.ErrArithIncompatibleUnits},
+		{name: "add plain & unit", op: AddNumbers, left: num(2, ""), right: num(3, "CBM"), errCode: zerr.ErrArithIncompatibleUnits},
+		{name: "add different business units", op: AddNumbers, left: num(2, "件"), right: num(3, "箱"), errCode: zerr.ErrArithIncompatibleUnits},
+		{name: "mul units", op: MulNumbers, left: num(1, "m"), right: num(1, "m"), errCode: zerr.ErrArithIncompatibleUnits},
+		{name: "div units", op: DivNumbers, left: num(1, "m"), right: num(1, "s"), errCode: zerr.ErrArithIncompatibleUnits},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, err := c.op(c.left, c.right)
+			if c.errCode != 0 {
+				e, ok := err.(*zerr.RuntimeError)
+				if !ok || e.Code != c.errCode {
+					t.Errorf("expect error code %d, got %v", c.errCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expect '%s', got error: '%s'", c.expected, err)
+			} else if result.String() != c.expected {
+				t.Errorf("expect '%s', got '%s'", c.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestUnit_ConvertNumber(t *testing.T) {
+	unit, _ := ParseUnit("斤")
+	result, err := ConvertNumber(NewNumberWithUnit(2.5, &Unit{Mul: "kg"}), unit)
+	if err != nil {
+		t.Fatalf("expect no error, got: %s", err)
+	}
+	if result.String() != "5斤" {
+		t.Errorf("expect '5斤', got '%s'", result.String())
+	}
+
+	if _, err := ConvertNumber(NewNumberWithUnit(1, &Unit{Mul: "kg"}), &Unit{Mul: "m"}); err == nil {
+		t.Errorf("expect error when converting kg to m")
+	}
+}
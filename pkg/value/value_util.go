@@ -70,21 +70,97 @@ func CompareValues(left r.Element, right r.Element, verb uint8) (bool, error) {
 			return true, nil
 		}
 		return false, nil
+	case *Currency:
+		// compare right value - currency of the same code only
+		vr, ok := right.(*Currency)
+		if !ok {
+			if verb == CmpEq {
+				return false, nil
+			}
+			return false, zerr.InvalidCompareRType("currency")
+		}
+		cmp, err := CompareCurrencies(vl, vr)
+		if err != nil {
+			if verb == CmpEq {
+				return false, nil
+			}
+			return false, err
+		}
+		switch verb {
+		case CmpEq:
+			return cmp == 0, nil
+		case CmpLt:
+			return cmp < 0, nil
+		case CmpGt:
+			return cmp > 0, nil
+		}
+		return false, zerr.UnexpectedCase("比较类型", strconv.Itoa(int(verb)))
+	case *DateTime:
+		// compare right value - datetime only
+		vr, ok := right.(*DateTime)
+		if !ok {
+			if verb == CmpEq {
+				return false, nil
+			}
+			return false, zerr.InvalidCompareRType("datetime")
+		}
+		cmp := CompareDateTimes(vl, vr)
+		switch verb {
+		case CmpEq:
+			return cmp == 0, nil
+		case CmpLt:
+			return cmp < 0, nil
+		case CmpGt:
+			return cmp > 0, nil
+		}
+		return false, zerr.UnexpectedCase("比较类型", strconv.Itoa(int(verb)))
+	case *Decimal:
+		// compare right value - number or decimal
+		vr, err := ToDecimal(right)
+		if err != nil {
+			if verb == CmpEq {
+				return false, nil
+			}
+			return false, zerr.InvalidCompareRType("number", "decimal")
+		}
+		switch verb {
+		case CmpEq:
+			return vl.Cmp(vr) == 0, nil
+		case CmpLt:
+			return vl.Cmp(vr) < 0, nil
+		case CmpGt:
+			return vl.Cmp(vr) > 0, nil
+		}
+		return false, zerr.UnexpectedCase("比较类型", strconv.Itoa(int(verb)))
 	case *Number:
+		// compare with a decimal - compare them as decimals
+		if vr, ok := right.(*Decimal); ok {
+			vld, err := NewDecimalFromNumber(vl)
+			if err != nil {
+				return false, err
+			}
+			return CompareValues(vld, vr, verb)
+		}
 		// compare right value - decimal only
 		if vr, ok := right.(*Number); ok {
-			cmpResult := false
+			cmp, err := CompareNumbers(vl, vr)
+			if err != nil {
+				// numbers with incompatible units are never equal
+				if verb == CmpEq {
+					return false, nil
+				}
+				return false, err
+			}
 			switch verb {
 			case CmpEq:
-				cmpResult = vl.value == vr.value
+				return cmp == 0, nil
 			case CmpLt:
-				cmpResult = vl.value < vr.value
+				return cmp < 0, nil
 			case CmpGt:
-				cmpResult = vl.value > vr.value
+				return cmp > 0, nil
 			default:
 				return false, zerr.UnexpectedCase("比较类型", strconv.Itoa(int(verb)))
 			}
-			return cmpResult, nil
 		}
 		// if vert == CmbEq and rightValue is not decimal type
 		// then return `false` directly
@@ -175,7 +251,7 @@ func DuplicateValue(in r.Element) r.Element {
 	case *String:
 		return NewString(v.value)
 	case *Number:
-		return NewNumber(v.value)
+		return NewNumberWithUnit(v.value, v.unit)
 	case *Null:
 		return in // no need to copy since all "NULL" values are same
 	case *Array:
@@ -329,6 +405,18 @@ func ValidateAllParams(values []r.Element, typeStr string) error {
 	return nil
 }
 
+// ValidateParamsCount only checks the number of params, which should be within [min, max].
+// It's used for functions with optional params, whose types are validated separately.
+func ValidateParamsCount(values []r.Element, min int, max int) error {
+	if len(values) < min {
+		return zerr.LeastParamsError(min)
+	}
+	if len(values) > max {
+		return zerr.MostParamsError(max)
+	}
+	return nil
+}
+
 func validateOneParam(v r.Element, typeStr string) error {
 	valid := true
 
@@ -337,6 +425,18 @@ func validateOneParam(v r.Element, typeStr string) error {
 		if _, ok := v.(*Number); !ok {
 			valid = false
 		}
+	case "decimal":
+		if _, ok := v.(*Decimal); !ok {
+			valid = false
+		}
+	case "currency":
+		if _, ok := v.(*Currency); !ok {
+			valid = false
+		}
+	case "datetime":
+		if _, ok := v.(*DateTime); !ok {
+			valid = false
+		}
 	case "string":
 		if _, ok := v.(*String); !ok {
 			valid = false
@@ -389,6 +489,12 @@ func getElementTypeString(elem r.Element) string {
 	switch elem.(type) {
 	case *Number:
 		return "number"
+	case *Decimal:
+		return "decimal"
+	case *Currency:
+		return "currency"
+	case *DateTime:
+		return "datetime"
 	case *String:
 		return "string"
 	case *Array:
@@ -0,0 +1,444 @@
+package csv
+
+import (
+	"bytes"
+	gocsv "encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	zio "github.com/DemoHn/Zn/pkg/io"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+const CSV_LIB_NAME = "@CSV"
+
+var csvLIB *r.Library
+
+// csvLib - functions of @CSV, all file paths are checked by the policy
+type csvLib struct {
+	policy *r.PermissionPolicy
+}
+
+// csvOptions - options of reading & writing CSV, set by the last (optional) param as a hashmap,
+// e.g. 【“分隔符” = “；”，“编码” = “UTF-8-BOM”，“表头” = 假】
+type csvOptions struct {
+	// delimiter - “分隔符”, “,” by default
+	delimiter rune
+	// encoding - “编码”, UTF-8 by default (see zio.GetEncoding)
+	encoding zio.Encoding
+	// header - “表头”, if the first row is the header. If true (by default), each row is a
+	// hashmap keyed by the header; otherwise each row is an array of texts.
+	header bool
+}
+
+// (解析CSV：文本、选项)
+func (cl *csvLib) FN_parse(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 1, 2); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateExactParams(values[:1], "string"); err != nil {
+		return nil, err
+	}
+	opts, err := parseOptions(values[1:])
+	if err != nil {
+		return nil, err
+	}
+	return readAllRows(strings.NewReader(values[0].String()), opts)
+}
+
+// (读取CSV：文件名、选项)
+func (cl *csvLib) FN_readFile(receiver r.Element, values []r.Element) (r.Element, error) {
+	file, opts, err := cl.openFile(values)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readAllRows(opts.encoding.NewReader(file), opts)
+}
+
+// (逐行读取CSV：文件名、选项) - read rows lazily by 遍历, so that large files don't have to be
+// loaded into memory at once, e.g. 以序号，行遍历（逐行读取CSV：“订单.csv”）：……
+func (cl *csvLib) FN_streamFile(receiver r.Element, values []r.Element) (r.Element, error) {
+	file, opts, err := cl.openFile(values)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	return &rowStream{path: file.Name(), opts: opts}, nil
+}
+
+// (生成CSV：数组、选项)
+func (cl *csvLib) FN_generate(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 1, 2); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateExactParams(values[:1], "array"); err != nil {
+		return nil, err
+	}
+	opts, err := parseOptions(values[1:])
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeRows(&buf, values[0].(*value.Array), opts); err != nil {
+		return nil, err
+	}
+	return value.NewString(buf.String()), nil
+}
+
+// (写入CSV：文件名、数组、选项)
+func (cl *csvLib) FN_writeFile(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 2, 3); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateExactParams(values[:2], "string", "array"); err != nil {
+		return nil, err
+	}
+	opts, err := parseOptions(values[2:])
+	if err != nil {
+		return nil, err
+	}
+	fileName := values[0].String()
+	if err := cl.policy.CheckFilePath(fileName); err != nil {
+		return nil, value.ThrowException(err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := writeRows(opts.encoding.NewWriter(&buf), values[1].(*value.Array), opts); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(fileName, buf.Bytes(), 0644); err != nil {
+		return nil, value.ThrowException("写入文件失败：" + err.Error())
+	}
+	return nil, nil
+}
+
+func (cl *csvLib) openFile(values []r.Element) (*os.File, *csvOptions, error) {
+	if err := value.ValidateParamsCount(values, 1, 2); err != nil {
+		return nil, nil, err
+	}
+	if err := value.ValidateExactParams(values[:1], "string"); err != nil {
+		return nil, nil, err
+	}
+	opts, err := parseOptions(values[1:])
+	if err != nil {
+		return nil, nil, err
+	}
+	fileName := values[0].String()
+	if err := cl.policy.CheckFilePath(fileName); err != nil {
+		return nil, nil, value.ThrowException(err.Error())
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, value.ThrowException("打开文件失败：" + err.Error())
+	}
+	return file, opts, nil
+}
+
+//// reading
+
+// rowReader - read rows one by one; the header is read before the first row
+type rowReader struct {
+	reader *gocsv.Reader
+	opts   *csvOptions
+	header []string
+	count  int
+}
+
+func newRowReader(rd io.Reader, opts *csvOptions) *rowReader {
+	reader := gocsv.NewReader(rd)
+	reader.Comma = opts.delimiter
+	return &rowReader{reader: reader, opts: opts}
+}
+
+// next - read the next row, returns nil when there's no more rows
+func (rr *rowReader) next() (r.Element, error) {
+	if rr.opts.header && rr.header == nil {
+		header, err := rr.reader.Read()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, parseError(err)
+		}
+		if err := checkHeader(header); err != nil {
+			return nil, err
+		}
+		rr.header = header
+	}
+
+	record, err := rr.reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, parseError(err)
+	}
+	rr.count++
+
+	if !rr.opts.header {
+		items := []r.Element{}
+		for _, field := range record {
+			items = append(items, value.NewString(field))
+		}
+		return value.NewArray(items), nil
+	}
+	row := value.NewEmptyHashMap()
+	for i, key := range rr.header {
+		row.AppendKVPair(value.KVPair{Key: key, Value: value.NewString(record[i])})
+	}
+	return row, nil
+}
+
+func readAllRows(rd io.Reader, opts *csvOptions) (r.Element, error) {
+	rr := newRowReader(rd, opts)
+	rows := value.NewArray([]r.Element{})
+	for {
+		row, err := rr.next()
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			return rows, nil
+		}
+		rows.AppendValue(row)
+	}
+}
+
+// rowStream - rows of a CSV file that could be iterated by 遍历. The file is opened on
+// each iteration, and closed once the iteration ends.
+type rowStream struct {
+	path string
+	opts *csvOptions
+}
+
+func (rs *rowStream) String() string {
+	return "‹CSV行·" + rs.path + "›"
+}
+
+func (rs *rowStream) GetProperty(name string) (r.Element, error) {
+	if name == "文件名" {
+		return value.NewString(rs.path), nil
+	}
+	return nil, zerr.PropertyNotFound(name)
+}
+
+func (rs *rowStream) SetProperty(name string, v r.Element) error {
+	return zerr.PropertyNotFound(name)
+}
+
+func (rs *rowStream) ExecMethod(name string, values []r.Element) (r.Element, error) {
+	return nil, zerr.MethodNotFound(name)
+}
+
+// Iterate - the key is the row number (starts from 1, excluding the header)
+func (rs *rowStream) Iterate() (r.Iterator, error) {
+	file, err := os.Open(rs.path)
+	if err != nil {
+		return nil, value.ThrowException("打开文件失败：" + err.Error())
+	}
+	return &rowIterator{
+		file:   file,
+		reader: newRowReader(rs.opts.encoding.NewReader(file), rs.opts),
+	}, nil
+}
+
+// rowIterator - implements r.StreamIterator
+type rowIterator struct {
+	file   *os.File
+	reader *rowReader
+	err    error
+}
+
+func (it *rowIterator) Next() (r.Element, r.Element, bool) {
+	row, err := it.reader.next()
+	if err != nil {
+		it.err = err
+		return nil, nil, false
+	}
+	if row == nil {
+		return nil, nil, false
+	}
+	return value.NewNumber(float64(it.reader.count)), row, true
+}
+
+func (it *rowIterator) Err() error {
+	return it.err
+}
+
+func (it *rowIterator) Close() error {
+	return it.file.Close()
+}
+
+//// writing
+
+// writeRows - write an array of hashmaps (with the header) or an array of arrays (without
+// the header). For hashmaps, the header consists of keys of all rows in the order they appear.
+func writeRows(w io.Writer, rows *value.Array, opts *csvOptions) error {
+	writer := gocsv.NewWriter(w)
+	writer.Comma = opts.delimiter
+
+	records, err := buildRecords(rows, opts)
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return value.ThrowException("生成CSV失败：" + err.Error())
+	}
+	return nil
+}
+
+func buildRecords(rows *value.Array, opts *csvOptions) ([][]string, error) {
+	var records [][]string
+	var header []string
+	headerIndex := map[string]bool{}
+
+	for _, row := range rows.GetValue() {
+		switch rv := row.(type) {
+		case *value.Array:
+			record := []string{}
+			for _, cell := range rv.GetValue() {
+				record = append(record, cellText(cell))
+			}
+			records = append(records, record)
+		case *value.HashMap:
+			for _, key := range rv.GetKeyOrder() {
+				if !headerIndex[key] {
+					headerIndex[key] = true
+					header = append(header, key)
+				}
+			}
+		default:
+			return nil, zerr.InvalidParamType("array", "hashmap")
+		}
+	}
+	if header == nil {
+		return records, nil
+	}
+	if records != nil {
+		return nil, value.ThrowException("数组中的行须均为列表或均为元组")
+	}
+
+	if opts.header {
+		records = append(records, header)
+	}
+	for _, row := range rows.GetValue() {
+		hm := row.(*value.HashMap).GetValue()
+		record := make([]string, len(header))
+		for i, key := range header {
+			if cell, ok := hm[key]; ok {
+				record[i] = cellText(cell)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// cellText - 空 is written as an empty cell, and numbers are written in full (e.g. 1700000000
+// instead of 1.7e+09)
+func cellText(cell r.Element) string {
+	switch v := cell.(type) {
+	case *value.Null:
+		return ""
+	case *value.Number:
+		return strconv.FormatFloat(v.GetValue(), 'f', -1, 64) + v.GetUnit().String()
+	}
+	return cell.String()
+}
+
+//// helpers
+
+func parseOptions(values []r.Element) (*csvOptions, error) {
+	enc, _ := zio.GetEncoding("")
+	opts := &csvOptions{delimiter: ',', encoding: enc, header: true}
+	if len(values) == 0 {
+		return opts, nil
+	}
+	hm, ok := values[0].(*value.HashMap)
+	if !ok {
+		return nil, zerr.InvalidParamType("hashmap")
+	}
+
+	for _, key := range hm.GetKeyOrder() {
+		v := hm.GetValue()[key]
+		switch key {
+		case "分隔符":
+			s, ok := v.(*value.String)
+			if !ok {
+				return nil, zerr.InvalidParamType("string")
+			}
+			if utf8.RuneCountInString(s.String()) != 1 {
+				return nil, value.ThrowException("分隔符须为单个字符")
+			}
+			opts.delimiter, _ = utf8.DecodeRuneInString(s.String())
+		case "编码":
+			s, ok := v.(*value.String)
+			if !ok {
+				return nil, zerr.InvalidParamType("string")
+			}
+			enc, err := zio.GetEncoding(s.String())
+			if err != nil {
+				return nil, value.ThrowException(err.Error())
+			}
+			opts.encoding = enc
+		case "表头":
+			b, ok := v.(*value.Bool)
+			if !ok {
+				return nil, zerr.InvalidParamType("bool")
+			}
+			opts.header = b.GetValue()
+		default:
+			return nil, value.ThrowException("不支持的选项「" + key + "」")
+		}
+	}
+	return opts, nil
+}
+
+// checkHeader - keys of the header should be unique
+func checkHeader(header []string) error {
+	keys := map[string]bool{}
+	for _, key := range header {
+		if keys[key] {
+			return value.ThrowException("表头「" + key + "」重复")
+		}
+		keys[key] = true
+	}
+	return nil
+}
+
+func parseError(err error) error {
+	return value.ThrowException("解析CSV失败：" + err.Error())
+}
+
+func Export() *r.Library {
+	return csvLIB
+}
+
+// bindLibrary - build the library restricted by the permission policy of the VM
+func bindLibrary(vm *r.VM) *r.Library {
+	return NewLibrary(vm.GetPermissionPolicy())
+}
+
+// NewLibrary - build @CSV library restricted by the policy (nil = no restriction)
+func NewLibrary(policy *r.PermissionPolicy) *r.Library {
+	cl := &csvLib{policy: policy}
+	lib := r.NewLibrary(CSV_LIB_NAME)
+
+	lib.RegisterFunction("解析CSV", value.NewFunction(cl.FN_parse)).
+		RegisterFunction("读取CSV", value.NewFunction(cl.FN_readFile)).
+		RegisterFunction("逐行读取CSV", value.NewFunction(cl.FN_streamFile)).
+		RegisterFunction("生成CSV", value.NewFunction(cl.FN_generate)).
+		RegisterFunction("写入CSV", value.NewFunction(cl.FN_writeFile)).
+		SetBinder(bindLibrary)
+	return lib
+}
+
+func init() {
+	csvLIB = NewLibrary(nil)
+}
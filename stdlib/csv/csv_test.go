@@ -0,0 +1,208 @@
+package csv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+func hashMap(kvs ...interface{}) *value.HashMap {
+	hm := value.NewEmptyHashMap()
+	for i := 0; i < len(kvs); i += 2 {
+		hm.AppendKVPair(value.KVPair{Key: kvs[i].(string), Value: kvs[i+1].(r.Element)})
+	}
+	return hm
+}
+
+func array(items ...r.Element) *value.Array {
+	return value.NewArray(items)
+}
+
+func str(s string) r.Element {
+	return value.NewString(s)
+}
+
+func TestCSVFunctions(t *testing.T) {
+	const data = "订单号,客户,金额\n" +
+		"A-1024,\"上海某某贸易有限公司, 浦东分公司\",1200.50\n" +
+		"B-77,\"他说\"\"你好\"\"\",80\n"
+
+	cl := &csvLib{}
+	cases := []struct {
+		name     string
+		fn       func(r.Element, []r.Element) (r.Element, error)
+		params   []r.Element
+		expected string
+	}{
+		{
+			name:     "parse with header",
+			fn:       cl.FN_parse,
+			params:   []r.Element{str(data)},
+			expected: "[[订单号=A-1024，客户=上海某某贸易有限公司, 浦东分公司，金额=1200.50]，[订单号=B-77，客户=他说\"你好\"，金额=80]]",
+		},
+		{
+			name:     "parse with custom delimiter without header",
+			fn:       cl.FN_parse,
+			params:   []r.Element{str("a;\"b;c\"\n1;2\n"), hashMap("分隔符", str(";"), "表头", value.NewBool(false))},
+			expected: "[[a，b;c]，[1，2]]",
+		},
+		{
+			name:     "parse empty text",
+			fn:       cl.FN_parse,
+			params:   []r.Element{str("")},
+			expected: "[]",
+		},
+		{
+			name: "generate from hashmaps",
+			fn:   cl.FN_generate,
+			params: []r.Element{array(
+				hashMap("名称", str("甲, 乙"), "数量", value.NewNumber(1700000000)),
+				hashMap("名称", str("丙"), "备注", value.NewNull()),
+			)},
+			expected: "名称,数量,备注\n\"甲, 乙\",1700000000,\n丙,,\n",
+		},
+		{
+			name:     "generate from hashmaps without header",
+			fn:       cl.FN_generate,
+			params:   []r.Element{array(hashMap("名称", str("甲"))), hashMap("表头", value.NewBool(false))},
+			expected: "甲\n",
+		},
+		{
+			name:     "generate from arrays",
+			fn:       cl.FN_generate,
+			params:   []r.Element{array(array(str("a"), str("b")), array(value.NewNumber(1), value.NewNumber(2.5))), hashMap("分隔符", str("\t"))},
+			expected: "a\tb\n1\t2.5\n",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn(nil, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.String() != tt.expected {
+				t.Errorf("expect %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+
+	// round trip
+	rows, err := cl.FN_parse(nil, []r.Element{str(data)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text, err := cl.FN_generate(nil, []r.Element{rows}); err != nil || text.String() != data {
+		t.Errorf("expect %q, got %v (%v)", data, text, err)
+	}
+}
+
+func TestCSVFunctions_FAIL(t *testing.T) {
+	cl := &csvLib{}
+	cases := []struct {
+		name   string
+		fn     func(r.Element, []r.Element) (r.Element, error)
+		params []r.Element
+	}{
+		{name: "wrong number of fields", fn: cl.FN_parse, params: []r.Element{str("a,b\n1,2,3\n")}},
+		{name: "duplicated header", fn: cl.FN_parse, params: []r.Element{str("a,a\n1,2\n")}},
+		{name: "unsupported option", fn: cl.FN_parse, params: []r.Element{str("a\n1\n"), hashMap("分割符", str(";"))}},
+		{name: "delimiter of multiple chars", fn: cl.FN_parse, params: []r.Element{str("a\n1\n"), hashMap("分隔符", str(";;"))}},
+		{name: "unsupported encoding", fn: cl.FN_parse, params: []r.Element{str("a\n1\n"), hashMap("编码", str("Big5"))}},
+		{name: "options is not a hashmap", fn: cl.FN_parse, params: []r.Element{str("a\n1\n"), str(";")}},
+		{name: "mixed rows", fn: cl.FN_generate, params: []r.Element{array(array(str("a")), hashMap("名称", str("甲")))}},
+		{name: "row is not an array or a hashmap", fn: cl.FN_generate, params: []r.Element{array(str("a"))}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.fn(nil, tt.params); err == nil {
+				t.Errorf("expect error, got nil")
+			}
+		})
+	}
+}
+
+func TestCSVFiles(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "订单.csv")
+	// exported by Excel: starts with BOM and ends lines with CRLF
+	content := "\xEF\xBB\xBF订单号,金额\r\nA-1,10\r\nA-2,20\r\nA-3,30\r\n"
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cl := &csvLib{}
+
+	rows, err := cl.FN_readFile(nil, []r.Element{str(inputFile)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "[[订单号=A-1，金额=10]，[订单号=A-2，金额=20]，[订单号=A-3，金额=30]]"; rows.String() != expected {
+		t.Errorf("expect %s, got %s", expected, rows.String())
+	}
+
+	// stream rows
+	stream, err := cl.FN_streamFile(nil, []r.Element{str(inputFile)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	iter, err := stream.(r.IterableElement).Iterate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var keys, amounts []string
+	for {
+		key, row, ok := iter.Next()
+		if !ok {
+			break
+		}
+		amount := row.(*value.HashMap).GetValue()["金额"]
+		keys = append(keys, key.String())
+		amounts = append(amounts, amount.String())
+	}
+	if err := iter.(r.StreamIterator).Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(keys) != 3 || keys[2] != "3" || amounts[2] != "30" {
+		t.Errorf("expect 3 rows, got keys %v, amounts %v", keys, amounts)
+	}
+
+	// write file with BOM
+	outputFile := filepath.Join(dir, "输出.csv")
+	if _, err := cl.FN_writeFile(nil, []r.Element{str(outputFile), array(hashMap("名称", str("甲"))), hashMap("编码", str("UTF-8-BOM"))}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "\xEF\xBB\xBF名称\n甲\n" {
+		t.Errorf("expect the file starts with BOM, got %q", data)
+	}
+}
+
+func TestCSVFiles_PermissionPolicy(t *testing.T) {
+	dir := t.TempDir()
+	cl := &csvLib{policy: &r.PermissionPolicy{FileRoots: []string{filepath.Join(dir, "数据")}}}
+	for name, params := range map[string][]r.Element{
+		"read":   {str("/etc/passwd")},
+		"stream": {str("/etc/passwd")},
+		"write":  {str(filepath.Join(dir, "a.csv")), array()},
+	} {
+		var err error
+		switch name {
+		case "read":
+			_, err = cl.FN_readFile(nil, params)
+		case "stream":
+			_, err = cl.FN_streamFile(nil, params)
+		case "write":
+			_, err = cl.FN_writeFile(nil, params)
+		}
+		if err == nil {
+			t.Errorf("%s: expect permission error, got nil", name)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package encoding
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+const ENCODING_LIB_NAME = "@编码"
+
+var encodingLIB *r.Library
+
+// digestAlgorithms - supported algorithms of 摘要 & HMAC签名. Names are case-insensitive
+// and "-" is ignored, e.g. “sha-256” = “SHA256”
+var digestAlgorithms = map[string]func() hash.Hash{
+	"MD5":    md5.New,
+	"SHA1":   sha1.New,
+	"SHA224": sha256.New224,
+	"SHA256": sha256.New,
+	"SHA384": sha512.New384,
+	"SHA512": sha512.New,
+}
+
+// base64Encodings - variants of Base64 by the name of 方式
+var base64Encodings = map[string]*base64.Encoding{
+	"标准":     base64.StdEncoding,
+	"URL":    base64.URLEncoding,
+	"无填充":    base64.RawStdEncoding,
+	"URL无填充": base64.RawURLEncoding,
+}
+
+const (
+	outputHex    = "十六进制"
+	outputBase64 = "Base64"
+)
+
+// (Base64编码：文本、方式) - 方式 is optional, see base64Encodings
+func FN_base64Encode(receiver r.Element, values []r.Element) (r.Element, error) {
+	enc, err := getBase64Encoding(values)
+	if err != nil {
+		return nil, err
+	}
+	return value.NewString(enc.EncodeToString([]byte(values[0].String()))), nil
+}
+
+// (Base64解码：文本、方式)
+func FN_base64Decode(receiver r.Element, values []r.Element) (r.Element, error) {
+	enc, err := getBase64Encoding(values)
+	if err != nil {
+		return nil, err
+	}
+	data, err := enc.DecodeString(values[0].String())
+	if err != nil {
+		return nil, value.ThrowException("「" + values[0].String() + "」不是有效的Base64文本")
+	}
+	return decodedText(data)
+}
+
+// (Base32编码：文本)
+func FN_base32Encode(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	return value.NewString(base32.StdEncoding.EncodeToString([]byte(values[0].String()))), nil
+}
+
+// (Base32解码：文本)
+func FN_base32Decode(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	data, err := base32.StdEncoding.DecodeString(values[0].String())
+	if err != nil {
+		return nil, value.ThrowException("「" + values[0].String() + "」不是有效的Base32文本")
+	}
+	return decodedText(data)
+}
+
+// (十六进制编码：文本) - the result is in lowercase
+func FN_hexEncode(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	return value.NewString(hex.EncodeToString([]byte(values[0].String()))), nil
+}
+
+// (十六进制解码：文本)
+func FN_hexDecode(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(values[0].String())
+	if err != nil {
+		return nil, value.ThrowException("「" + values[0].String() + "」不是有效的十六进制文本")
+	}
+	return decodedText(data)
+}
+
+// (URL编码：文本) - escape the text to be placed in a URL query, e.g. “a b&c” -> “a+b%26c”
+func FN_urlEncode(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	return value.NewString(url.QueryEscape(values[0].String())), nil
+}
+
+// (URL解码：文本)
+func FN_urlDecode(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	text, err := url.QueryUnescape(values[0].String())
+	if err != nil {
+		return nil, value.ThrowException("「" + values[0].String() + "」不是有效的URL编码文本")
+	}
+	return value.NewString(text), nil
+}
+
+// (生成查询文本：列表、排序) - build the query text from the hashmap, e.g. 【“a” = 1，“b” = “x y”】
+// -> “a=1&b=x+y”. Keys are in the order of the hashmap, or sorted if 排序 is 真 (usually required
+// for request signing). Items of an array value are added as the same key repeatedly.
+func FN_buildQuery(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 1, 2); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateExactParams(values[:1], "hashmap"); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateAllParams(values[1:], "bool"); err != nil {
+		return nil, err
+	}
+	hm := values[0].(*value.HashMap)
+	keys := append([]string{}, hm.GetKeyOrder()...)
+	if len(values) > 1 && values[1].(*value.Bool).GetValue() {
+		sort.Strings(keys)
+	}
+
+	var items []string
+	for _, key := range keys {
+		v := hm.GetValue()[key]
+		elems := []r.Element{v}
+		if arr, ok := v.(*value.Array); ok {
+			elems = arr.GetValue()
+		}
+		for _, elem := range elems {
+			items = append(items, url.QueryEscape(key)+"="+url.QueryEscape(queryValueText(elem)))
+		}
+	}
+	return value.NewString(strings.Join(items, "&")), nil
+}
+
+// (摘要：文本、算法、输出格式) - 输出格式 is optional (“十六进制” by default, or “Base64”)
+func FN_digest(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 2, 3); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateAllParams(values, "string"); err != nil {
+		return nil, err
+	}
+	newHash, err := getDigestAlgorithm(values[1].String())
+	if err != nil {
+		return nil, err
+	}
+	h := newHash()
+	h.Write([]byte(values[0].String()))
+	return formatOutput(h.Sum(nil), values[2:])
+}
+
+// newDigestFunction - shortcut of 摘要 with a fixed algorithm, e.g. (SHA256：文本、输出格式)
+func newDigestFunction(algorithm string) *value.Function {
+	return value.NewFunction(func(receiver r.Element, values []r.Element) (r.Element, error) {
+		if err := value.ValidateParamsCount(values, 1, 2); err != nil {
+			return nil, err
+		}
+		params := append([]r.Element{values[0], value.NewString(algorithm)}, values[1:]...)
+		return FN_digest(receiver, params)
+	})
+}
+
+// (HMAC签名：密钥、文本、算法、输出格式) - 算法 (“SHA256” by default) and 输出格式 are optional
+func FN_hmac(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 2, 4); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateAllParams(values, "string"); err != nil {
+		return nil, err
+	}
+	algorithm := "SHA256"
+	if len(values) > 2 {
+		algorithm = values[2].String()
+	}
+	newHash, err := getDigestAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(newHash, []byte(values[0].String()))
+	mac.Write([]byte(values[1].String()))
+
+	var format []r.Element
+	if len(values) > 3 {
+		format = values[3:]
+	}
+	return formatOutput(mac.Sum(nil), format)
+}
+
+// (生成UUID) - a random UUID (version 4), e.g. “0b5b5a0e-4c3f-4d8e-9a8e-3f1c2b7d6e5a”
+func FN_uuid(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 0, 0); err != nil {
+		return nil, err
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, value.ThrowException("生成UUID失败：" + err.Error())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant RFC 4122
+	uuid := fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+	return value.NewString(uuid), nil
+}
+
+//// helpers
+
+// queryValueText - numbers are written in full (e.g. 1700000000 instead of 1.7e+09), since
+// they're usually timestamps or amounts for signing
+func queryValueText(elem r.Element) string {
+	if n, ok := elem.(*value.Number); ok {
+		return strconv.FormatFloat(n.GetValue(), 'f', -1, 64) + n.GetUnit().String()
+	}
+	return elem.String()
+}
+
+func getBase64Encoding(values []r.Element) (*base64.Encoding, error) {
+	if err := value.ValidateParamsCount(values, 1, 2); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateAllParams(values, "string"); err != nil {
+		return nil, err
+	}
+	if len(values) == 1 {
+		return base64.StdEncoding, nil
+	}
+	if enc, ok := base64Encodings[values[1].String()]; ok {
+		return enc, nil
+	}
+	return nil, value.ThrowException("不支持的Base64方式「" + values[1].String() + "」")
+}
+
+func getDigestAlgorithm(name string) (func() hash.Hash, error) {
+	key := strings.ReplaceAll(strings.ToUpper(name), "-", "")
+	if newHash, ok := digestAlgorithms[key]; ok {
+		return newHash, nil
+	}
+	return nil, value.ThrowException("不支持的摘要算法「" + name + "」")
+}
+
+// formatOutput - format the digest as hex (by default) or Base64 text
+func formatOutput(data []byte, format []r.Element) (r.Element, error) {
+	if len(format) == 0 {
+		return value.NewString(hex.EncodeToString(data)), nil
+	}
+	switch format[0].String() {
+	case outputHex:
+		return value.NewString(hex.EncodeToString(data)), nil
+	case outputBase64:
+		return value.NewString(base64.StdEncoding.EncodeToString(data)), nil
+	}
+	return nil, value.ThrowException("不支持的输出格式「" + format[0].String() + "」")
+}
+
+// decodedText - texts of Zn are UTF-8 strings, thus decoded binary data can't be a text
+func decodedText(data []byte) (r.Element, error) {
+	if !utf8.Valid(data) {
+		return nil, value.ThrowException("解码结果不是有效的UTF-8文本")
+	}
+	return value.NewString(string(data)), nil
+}
+
+func Export() *r.Library {
+	return encodingLIB
+}
+
+func init() {
+	encodingLIB = r.NewLibrary(ENCODING_LIB_NAME)
+	encodingLIB.RegisterFunction("Base64编码", value.NewFunction(FN_base64Encode)).
+		RegisterFunction("Base64解码", value.NewFunction(FN_base64Decode)).
+		RegisterFunction("Base32编码", value.NewFunction(FN_base32Encode)).
+		RegisterFunction("Base32解码", value.NewFunction(FN_base32Decode)).
+		RegisterFunction("十六进制编码", value.NewFunction(FN_hexEncode)).
+		RegisterFunction("十六进制解码", value.NewFunction(FN_hexDecode)).
+		RegisterFunction("URL编码", value.NewFunction(FN_urlEncode)).
+		RegisterFunction("URL解码", value.NewFunction(FN_urlDecode)).
+		RegisterFunction("生成查询文本", value.NewFunction(FN_buildQuery)).
+		RegisterFunction("摘要", value.NewFunction(FN_digest)).
+		RegisterFunction("MD5", newDigestFunction("MD5")).
+		RegisterFunction("SHA1", newDigestFunction("SHA1")).
+		RegisterFunction("SHA256", newDigestFunction("SHA256")).
+		RegisterFunction("SHA512", newDigestFunction("SHA512")).
+		RegisterFunction("HMAC签名", value.NewFunction(FN_hmac)).
+		RegisterFunction("生成UUID", value.NewFunction(FN_uuid))
+}
@@ -0,0 +1,105 @@
+package encoding
+
+import (
+	"regexp"
+	"testing"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+func strs(items ...string) []r.Element {
+	var values []r.Element
+	for _, item := range items {
+		values = append(values, value.NewString(item))
+	}
+	return values
+}
+
+func TestEncodingFunctions(t *testing.T) {
+	const text = "The quick brown fox jumps over the lazy dog"
+	query := value.NewEmptyHashMap()
+	query.AppendKVPair(value.KVPair{Key: "timestamp", Value: value.NewNumber(1700000000)})
+	query.AppendKVPair(value.KVPair{Key: "q", Value: value.NewString("a b")})
+	query.AppendKVPair(value.KVPair{Key: "tag", Value: value.NewArray(strs("x", "y"))})
+
+	cases := []struct {
+		name     string
+		fn       func(r.Element, []r.Element) (r.Element, error)
+		params   []r.Element
+		expected string
+	}{
+		{name: "base64 encode", fn: FN_base64Encode, params: strs("你好，Zn"), expected: "5L2g5aW977yMWm4="},
+		{name: "base64 decode", fn: FN_base64Decode, params: strs("5L2g5aW977yMWm4="), expected: "你好，Zn"},
+		{name: "base64 URL encode", fn: FN_base64Encode, params: strs("~~~", "URL"), expected: "fn5-"},
+		{name: "base64 encode without padding", fn: FN_base64Encode, params: strs("Zn", "无填充"), expected: "Wm4"},
+		{name: "base32 encode", fn: FN_base32Encode, params: strs("Zn"), expected: "LJXA===="},
+		{name: "base32 decode", fn: FN_base32Decode, params: strs("LJXA===="), expected: "Zn"},
+		{name: "hex encode", fn: FN_hexEncode, params: strs("你好，Zn"), expected: "e4bda0e5a5bdefbc8c5a6e"},
+		{name: "hex decode", fn: FN_hexDecode, params: strs("e4bda0e5a5bdefbc8c5a6e"), expected: "你好，Zn"},
+		{name: "url encode", fn: FN_urlEncode, params: strs("a b&c=你"), expected: "a+b%26c%3D%E4%BD%A0"},
+		{name: "url decode", fn: FN_urlDecode, params: strs("a+b%26c%3D%E4%BD%A0"), expected: "a b&c=你"},
+		{name: "build query", fn: FN_buildQuery, params: []r.Element{query}, expected: "timestamp=1700000000&q=a+b&tag=x&tag=y"},
+		{name: "build sorted query", fn: FN_buildQuery, params: []r.Element{query, value.NewBool(true)}, expected: "q=a+b&tag=x&tag=y&timestamp=1700000000"},
+		{name: "md5", fn: newDigestFunction("MD5").Exec, params: strs("abc"), expected: "900150983cd24fb0d6963f7d28e17f72"},
+		{name: "sha1", fn: newDigestFunction("SHA1").Exec, params: strs("abc"), expected: "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{name: "digest", fn: FN_digest, params: strs("abc", "sha-256"), expected: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{name: "digest in base64", fn: FN_digest, params: strs("abc", "SHA256", "Base64"), expected: "ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0="},
+		{name: "hmac", fn: FN_hmac, params: strs("key", text), expected: "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		{name: "hmac with algorithm", fn: FN_hmac, params: strs("key", text, "SHA1", "Base64"), expected: "3nybhbi3iqa8ino29wqQcBydtNk="},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn(nil, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.String() != tt.expected {
+				t.Errorf("expect %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestEncodingFunctions_FAIL(t *testing.T) {
+	cases := []struct {
+		name   string
+		fn     func(r.Element, []r.Element) (r.Element, error)
+		params []r.Element
+	}{
+		{name: "invalid base64", fn: FN_base64Decode, params: strs("!!!")},
+		{name: "unsupported base64 variant", fn: FN_base64Encode, params: strs("Zn", "URL安全")},
+		{name: "decoded data is not a text", fn: FN_hexDecode, params: strs("ff")},
+		{name: "unsupported algorithm", fn: FN_digest, params: strs("abc", "SHA3")},
+		{name: "unsupported output format", fn: FN_digest, params: strs("abc", "MD5", "二进制")},
+		{name: "too many params", fn: FN_hmac, params: strs("key", "abc", "SHA1", "Base64", "x")},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.fn(nil, tt.params); err == nil {
+				t.Errorf("expect error, got nil")
+			}
+		})
+	}
+}
+
+func TestEncodingUUID(t *testing.T) {
+	uuidRegex := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	var last string
+	for i := 0; i < 2; i++ {
+		result, err := FN_uuid(nil, []r.Element{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		uuid := result.String()
+		if !uuidRegex.MatchString(uuid) {
+			t.Errorf("expect UUID v4, got %s", uuid)
+		}
+		if uuid == last {
+			t.Errorf("expect different UUIDs, got %s twice", uuid)
+		}
+		last = uuid
+	}
+}
@@ -1,9 +1,11 @@
 package file
 
 import (
+	"bytes"
 	"io"
 	"os"
 
+	zio "github.com/DemoHn/Zn/pkg/io"
 	"github.com/DemoHn/Zn/pkg/value"
 
 	r "github.com/DemoHn/Zn/pkg/runtime"
@@ -13,46 +15,87 @@ const FILE_LIB_NAME = "@文件"
 
 var fileLIB *r.Library
 
-func FN_readTextFromFile(receiver r.Element, values []r.Element) (r.Element, error) {
-	// validate one param: string ONLY
-	if err := value.ValidateExactParams(values, "string"); err != nil {
+// fileLib - functions of @文件, all file paths are checked by the policy
+type fileLib struct {
+	policy *r.PermissionPolicy
+}
+
+// 读取文件：文件名、编码 -> 文件的内容；编码可省略，即按原样读取（UTF-8）
+func (fl *fileLib) FN_readTextFromFile(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 1, 2); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateExactParams(values[:1], "string"); err != nil {
+		return nil, err
+	}
+	enc, err := getEncoding(values[1:])
+	if err != nil {
 		return nil, err
 	}
 	v := values[0].(*value.String)
+	if err := fl.policy.CheckFilePath(v.String()); err != nil {
+		return nil, value.ThrowException(err.Error())
+	}
 	// open file
 	file, err := os.Open(v.String())
 	if err != nil {
 		return nil, value.ThrowException("打开文件失败：" + err.Error())
 	}
 	defer file.Close()
-	data, err := io.ReadAll(file)
+
+	var reader io.Reader = file
+	if enc != nil {
+		reader = enc.NewReader(file)
+	}
+	data, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, value.ThrowException("读取文件失败：" + err.Error())
 	}
 	return value.NewString(string(data)), nil
 }
 
-func FN_writeTextFromFile(receiver r.Element, values []r.Element) (r.Element, error) {
-	// validate one param: string ONLY
-	if err := value.ValidateExactParams(values, "string", "string"); err != nil {
+// 写入文件：文件名、内容、编码；编码可省略，即以 UTF-8 写入
+func (fl *fileLib) FN_writeTextFromFile(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 2, 3); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateExactParams(values[:2], "string", "string"); err != nil {
+		return nil, err
+	}
+	enc, err := getEncoding(values[2:])
+	if err != nil {
 		return nil, err
 	}
 	fileName := values[0].(*value.String)
 	content := values[1].(*value.String)
+	if err := fl.policy.CheckFilePath(fileName.String()); err != nil {
+		return nil, value.ThrowException(err.Error())
+	}
 
-	err := os.WriteFile(fileName.String(), []byte(content.String()), 0644)
-	if err != nil {
+	data := []byte(content.String())
+	if enc != nil {
+		// encode the content first, so that the file is not changed if it fails
+		var buf bytes.Buffer
+		if _, err := enc.NewWriter(&buf).Write(data); err != nil {
+			return nil, value.ThrowException("写入文件失败：" + err.Error())
+		}
+		data = buf.Bytes()
+	}
+	if err := os.WriteFile(fileName.String(), data, 0644); err != nil {
 		return nil, value.ThrowException("写入文件失败：" + err.Error())
 	}
 	return nil, nil
 }
 
-func FN_readDir(receiver r.Element, values []r.Element) (r.Element, error) {
+func (fl *fileLib) FN_readDir(receiver r.Element, values []r.Element) (r.Element, error) {
 	// validate one param: string ONLY
 	if err := value.ValidateExactParams(values, "string"); err != nil {
 		return nil, err
 	}
 	dirName := values[0].(*value.String)
+	if err := fl.policy.CheckFilePath(dirName.String()); err != nil {
+		return nil, value.ThrowException(err.Error())
+	}
 	dirs, err := os.ReadDir(dirName.String())
 	if err != nil {
 		return nil, value.ThrowException("读取目录失败：" + err.Error())
@@ -65,14 +108,42 @@ func FN_readDir(receiver r.Element, values []r.Element) (r.Element, error) {
 	return info, nil
 }
 
+// getEncoding - get the encoding from the optional param (nil if not set)
+func getEncoding(values []r.Element) (zio.Encoding, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	enc, err := zio.GetEncoding(values[0].String())
+	if err != nil {
+		return nil, value.ThrowException(err.Error())
+	}
+	return enc, nil
+}
+
 func Export() *r.Library {
 	return fileLIB
 }
 
-func init() {
-	fileLIB = r.NewLibrary(FILE_LIB_NAME)
+// bindLibrary - build the library restricted by the permission policy of the VM
+func bindLibrary(vm *r.VM) *r.Library {
+	return NewLibrary(vm.GetPermissionPolicy())
+}
 
-	fileLIB.RegisterFunction("读取文件", value.NewFunction(FN_readTextFromFile)).
-		RegisterFunction("写入文件", value.NewFunction(FN_writeTextFromFile)).
-		RegisterFunction("读取目录", value.NewFunction(FN_readDir))
+// NewLibrary - build @文件 library restricted by the policy (nil = no restriction)
+func NewLibrary(policy *r.PermissionPolicy) *r.Library {
+	fl := &fileLib{policy: policy}
+	lib := r.NewLibrary(FILE_LIB_NAME)
+
+	lib.RegisterFunction("读取文件", value.NewFunction(fl.FN_readTextFromFile)).
+		RegisterFunction("写入文件", value.NewFunction(fl.FN_writeTextFromFile)).
+		RegisterFunction("读取目录", value.NewFunction(fl.FN_readDir)).
+		SetBinder(bindLibrary)
+	return lib
+}
+
+func init() {
+	fileLIB = NewLibrary(nil)
 }
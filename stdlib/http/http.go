@@ -9,8 +9,15 @@ import (
 
 var httpLIB *r.Library
 
+const STDLIB_HTTP_NAME = "@HTTP"
+
+// httpLib - functions of @HTTP, all request hosts are checked by the policy
+type httpLib struct {
+	policy *r.PermissionPolicy
+}
+
 // (发送HTTP请求：请求@HTTP请求)
-func FN_sendHTTPRequest(receiver r.Element, values []r.Element) (r.Element, error) {
+func (hl *httpLib) FN_sendHTTPRequest(receiver r.Element, values []r.Element) (r.Element, error) {
 	const TOTAL_PARAMS = 1
 	if len(values) != TOTAL_PARAMS {
 		return nil, zerr.ExactParamsError(TOTAL_PARAMS)
@@ -22,7 +29,7 @@ func FN_sendHTTPRequest(receiver r.Element, values []r.Element) (r.Element, erro
 
 	const defaultTimeout = 30
 	// #1. go! sendRequest
-	resp, data, err := sendHttpRequest(req, true, defaultTimeout)
+	resp, data, err := sendHttpRequest(hl.policy, req, true, defaultTimeout)
 	if err != nil {
 		return nil, value.ThrowException(err.Error())
 	}
@@ -31,7 +38,7 @@ func FN_sendHTTPRequest(receiver r.Element, values []r.Element) (r.Element, erro
 }
 
 // (发送GET请求：URL)
-func FN_sendHTTPRequest_GET(receiver r.Element, values []r.Element) (r.Element, error) {
+func (hl *httpLib) FN_sendHTTPRequest_GET(receiver r.Element, values []r.Element) (r.Element, error) {
 	if err := value.ValidateExactParams(values, "string"); err != nil {
 		return nil, err
 	}
@@ -40,7 +47,7 @@ func FN_sendHTTPRequest_GET(receiver r.Element, values []r.Element) (r.Element,
 	const defaultTimeout = 30
 	const method = "GET"
 
-	url := values[1].(*value.String).GetValue()
+	url := values[0].(*value.String).GetValue()
 	emptyHeader := [][2]string{}
 	emptyQuery := [][2]string{}
 	emptyBody := ReqBody{}
@@ -56,7 +63,7 @@ func FN_sendHTTPRequest_GET(receiver r.Element, values []r.Element) (r.Element,
 		return nil, value.ThrowException(err.Error())
 	}
 
-	resp, data, err := sendHttpRequest(req, true, defaultTimeout)
+	resp, data, err := sendHttpRequest(hl.policy, req, true, defaultTimeout)
 	if err != nil {
 		return nil, value.ThrowException(err.Error())
 	}
@@ -65,7 +72,7 @@ func FN_sendHTTPRequest_GET(receiver r.Element, values []r.Element) (r.Element,
 }
 
 // (发送POST请求：URL@文本、内容@字典/文本)
-func FN_sendHTTPRequest_POST(receiver r.Element, values []r.Element) (r.Element, error) {
+func (hl *httpLib) FN_sendHTTPRequest_POST(receiver r.Element, values []r.Element) (r.Element, error) {
 	const TOTAL_PARAMS = 2
 	if len(values) != TOTAL_PARAMS {
 		return nil, zerr.ExactParamsError(2)
@@ -109,7 +116,7 @@ func FN_sendHTTPRequest_POST(receiver r.Element, values []r.Element) (r.Element,
 		return nil, value.ThrowException(err.Error())
 	}
 
-	resp, data, err := sendHttpRequest(req, true, defaultTimeout)
+	resp, data, err := sendHttpRequest(hl.policy, req, true, defaultTimeout)
 	if err != nil {
 		return nil, value.ThrowException(err.Error())
 	}
@@ -121,13 +128,25 @@ func Export() *r.Library {
 	return httpLIB
 }
 
-func init() {
-	var STDLIB_HTTP_NAME = "@HTTP"
-	httpLIB = r.NewLibrary(STDLIB_HTTP_NAME)
+// bindLibrary - build the library restricted by the permission policy of the VM
+func bindLibrary(vm *r.VM) *r.Library {
+	return NewLibrary(vm.GetPermissionPolicy())
+}
 
-	httpLIB.RegisterClass("HTTP请求", common.CLASS_HttpRequest).
+// NewLibrary - build @HTTP library restricted by the policy (nil = no restriction)
+func NewLibrary(policy *r.PermissionPolicy) *r.Library {
+	hl := &httpLib{policy: policy}
+	lib := r.NewLibrary(STDLIB_HTTP_NAME)
+
+	lib.RegisterClass("HTTP请求", common.CLASS_HttpRequest).
 		RegisterClass("HTTP响应", common.CLASS_HttpResponse).
-		RegisterFunction("发送HTTP请求", value.NewFunction(FN_sendHTTPRequest)).
-		RegisterFunction("发送GET请求", value.NewFunction(FN_sendHTTPRequest_GET)).
-		RegisterFunction("发送POST请求", value.NewFunction(FN_sendHTTPRequest_POST))
+		RegisterFunction("发送HTTP请求", value.NewFunction(hl.FN_sendHTTPRequest)).
+		RegisterFunction("发送GET请求", value.NewFunction(hl.FN_sendHTTPRequest_GET)).
+		RegisterFunction("发送POST请求", value.NewFunction(hl.FN_sendHTTPRequest_POST)).
+		SetBinder(bindLibrary)
+	return lib
+}
+
+func init() {
+	httpLIB = NewLibrary(nil)
 }
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	libHTTP "net/http"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/DemoHn/Zn/pkg/common"
+	zerr "github.com/DemoHn/Zn/pkg/error"
 	r "github.com/DemoHn/Zn/pkg/runtime"
 	"github.com/DemoHn/Zn/pkg/value"
 )
@@ -89,17 +91,9 @@ func digestBaseRequest(reqElement r.Element) (*libHTTP.Request, error) {
 	}
 
 	// convert reqBody
-	var reqBody ReqBody
-	if body.IsA() { // A:*String, B:*HashMap
-		reqBody.ContentType = "application/x-www-form-urlencoded"
-		reqBody.Value = body.GetA().GetValue()
-	} else {
-		bodyStr, err := common.HashMapToJSONString(body.GetB())
-		if err != nil {
-			return nil, err
-		}
-		reqBody.ContentType = "application/json"
-		reqBody.Value = bodyStr.GetValue()
+	reqBody, err := digestReqBody(body)
+	if err != nil {
+		return nil, err
 	}
 
 	// build request
@@ -113,8 +107,21 @@ func digestBaseRequest(reqElement r.Element) (*libHTTP.Request, error) {
 }
 
 // accept HashMap or String
-func digestReqBody(reqBodyElement r.Element) (ReqBody, error) {
+func digestReqBody(body value.Either[*value.String, *value.HashMap]) (ReqBody, error) {
+	var reqBody ReqBody
+	if body.IsA() { // A:*String, B:*HashMap
+		reqBody.ContentType = "application/x-www-form-urlencoded"
+		reqBody.Value = body.GetA().GetValue()
+		return reqBody, nil
+	}
 
+	bodyStr, err := common.HashMapToJSONString(body.GetB())
+	if err != nil {
+		return reqBody, err
+	}
+	reqBody.ContentType = "application/json"
+	reqBody.Value = bodyStr.GetValue()
+	return reqBody, nil
 }
 
 func buildBaseHttpRequest(
@@ -193,19 +200,28 @@ func buildBaseHttpRequest(
 	}, nil
 }
 
-func sendHttpRequest(req *libHTTP.Request, allowRedicrect bool, timeout int) (*libHTTP.Response, []byte, error) {
+func sendHttpRequest(policy *r.PermissionPolicy, req *libHTTP.Request, allowRedicrect bool, timeout int) (*libHTTP.Response, []byte, error) {
+	if err := policy.CheckHTTPHost(req.URL.Hostname()); err != nil {
+		return nil, []byte{}, err
+	}
 	client := &libHTTP.Client{
 		CheckRedirect: func(req *libHTTP.Request, via []*libHTTP.Request) error {
 			if !allowRedicrect {
 				return fmt.Errorf("此请求不允许自动重定向")
 			}
-			return nil
+			// the redirected host should also be allowed
+			return policy.CheckHTTPHost(req.URL.Hostname())
 		},
 		Timeout: time.Duration(timeout) * time.Second,
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
+		// unwrap permission error of redirected requests
+		var rerr *zerr.RuntimeError
+		if errors.As(err, &rerr) {
+			return nil, []byte{}, rerr
+		}
 		return nil, []byte{}, err
 	}
 	defer resp.Body.Close()
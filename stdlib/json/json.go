@@ -22,15 +22,16 @@ testing...
 	    xxxx
 */
 // parseJsonFunc - 解析JSON
+// (解析JSON：文本、解析日期) - if 解析日期 is 真, date-like texts are converted to dates
 func FN_parseJson(receiver r.Element, values []r.Element) (r.Element, error) {
-	// validate string ONLY
-	if err := value.ValidateExactParams(values, "string"); err != nil {
+	if err := value.ValidateLeastParams(values, "string", "bool?"); err != nil {
 		return nil, err
 	}
 	// get exact type of params
 	p1 := values[0].(*value.String)
+	parseDates := len(values) > 1 && values[1].(*value.Bool).GetValue()
 
-	return common.JSONStringToElement(p1)
+	return common.JSONStringToElement(p1, parseDates)
 }
 
 // generateJsonFunc - 生成JSON
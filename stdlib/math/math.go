@@ -0,0 +1,269 @@
+package math
+
+import (
+	gomath "math"
+	gotime "time"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+const MATH_LIB_NAME = "@数学"
+
+var mathLIB *r.Library
+
+// mathLib - random functions of @数学, the random numbers are got from the generator of the VM
+// (so that they could be seeded for tests)
+type mathLib struct {
+	random *r.Random
+}
+
+// (圆周率)
+func FN_pi(receiver r.Element, values []r.Element) (r.Element, error) {
+	return value.NewNumber(gomath.Pi), nil
+}
+
+// (自然常数)
+func FN_e(receiver r.Element, values []r.Element) (r.Element, error) {
+	return value.NewNumber(gomath.E), nil
+}
+
+// (绝对值：数)
+func FN_abs(receiver r.Element, values []r.Element) (r.Element, error) {
+	return getNumberProperty(values, "绝对值")
+}
+
+// (平方根：数)
+func FN_sqrt(receiver r.Element, values []r.Element) (r.Element, error) {
+	return getNumberProperty(values, "平方根")
+}
+
+// (是否整数：数)
+func FN_isInteger(receiver r.Element, values []r.Element) (r.Element, error) {
+	return getNumberProperty(values, "是整数")
+}
+
+// (幂：底数、指数)
+func FN_pow(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "number", "number"); err != nil {
+		return nil, err
+	}
+	return values[0].ExecMethod("幂", values[1:])
+}
+
+// (对数：真数) or (对数：真数、底数) - the natural logarithm if 底数 is omitted
+func FN_log(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 1, 2); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateAllParams(values, "number"); err != nil {
+		return nil, err
+	}
+	return values[0].ExecMethod("对数", values[1:])
+}
+
+// (保留：数、位数、舍入方式) - 舍入方式 is optional; 数 could be a number, a decimal or a currency
+func FN_round(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 2, 3); err != nil {
+		return nil, err
+	}
+	switch values[0].(type) {
+	case *value.Number, *value.Decimal, *value.Currency:
+		return values[0].ExecMethod("保留", values[1:])
+	}
+	return nil, zerr.InvalidParamType("number", "decimal", "currency")
+}
+
+// (限制：数、最小值、最大值)
+func FN_clamp(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "number", "number", "number"); err != nil {
+		return nil, err
+	}
+	return values[0].ExecMethod("限制", values[1:])
+}
+
+// (最大值：A、B、C) or (最大值：列表)
+func FN_max(receiver r.Element, values []r.Element) (r.Element, error) {
+	return findExtremum(values, value.CmpGt)
+}
+
+// (最小值：A、B、C) or (最小值：列表)
+func FN_min(receiver r.Element, values []r.Element) (r.Element, error) {
+	return findExtremum(values, value.CmpLt)
+}
+
+// (弧度：角度)
+func FN_radians(receiver r.Element, values []r.Element) (r.Element, error) {
+	return applyFloatFunc(values, "弧度", func(x float64) float64 {
+		return x * gomath.Pi / 180
+	})
+}
+
+// (角度：弧度)
+func FN_degrees(receiver r.Element, values []r.Element) (r.Element, error) {
+	return applyFloatFunc(values, "角度", func(x float64) float64 {
+		return x * 180 / gomath.Pi
+	})
+}
+
+// (正弦：弧度)
+func FN_sin(receiver r.Element, values []r.Element) (r.Element, error) {
+	return applyFloatFunc(values, "正弦", gomath.Sin)
+}
+
+// (余弦：弧度)
+func FN_cos(receiver r.Element, values []r.Element) (r.Element, error) {
+	return applyFloatFunc(values, "余弦", gomath.Cos)
+}
+
+// (正切：弧度)
+func FN_tan(receiver r.Element, values []r.Element) (r.Element, error) {
+	return applyFloatFunc(values, "正切", gomath.Tan)
+}
+
+// (反正弦：数) - the result is within [-π/2, π/2]
+func FN_asin(receiver r.Element, values []r.Element) (r.Element, error) {
+	return applyFloatFunc(values, "反正弦", gomath.Asin)
+}
+
+// (反余弦：数) - the result is within [0, π]
+func FN_acos(receiver r.Element, values []r.Element) (r.Element, error) {
+	return applyFloatFunc(values, "反余弦", gomath.Acos)
+}
+
+// (反正切：数) - the result is within [-π/2, π/2]
+func FN_atan(receiver r.Element, values []r.Element) (r.Element, error) {
+	return applyFloatFunc(values, "反正切", gomath.Atan)
+}
+
+// (随机数) - a random number within [0, 1)
+func (ml *mathLib) FN_random(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 0, 0); err != nil {
+		return nil, err
+	}
+	return value.NewNumber(ml.random.Float64()), nil
+}
+
+// (随机整数：最小值、最大值) - a random integer within [最小值, 最大值]
+func (ml *mathLib) FN_randomInt(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "number", "number"); err != nil {
+		return nil, err
+	}
+	min := gomath.Ceil(values[0].(*value.Number).GetValue())
+	max := gomath.Floor(values[1].(*value.Number).GetValue())
+	if min > max {
+		return nil, value.ThrowException("最小值不能大于最大值")
+	}
+	// integers within the range should be represented by float64 exactly
+	if gomath.IsInf(min, 0) || gomath.IsInf(max, 0) || max-min >= 1<<53 {
+		return nil, zerr.ArithOutOfDomain("随机整数")
+	}
+	n := ml.random.Int63n(int64(max-min) + 1)
+	return value.NewNumber(min + float64(n)), nil
+}
+
+// (随机选择：列表) - pick an item of the array randomly
+func (ml *mathLib) FN_randomChoice(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "array"); err != nil {
+		return nil, err
+	}
+	items := values[0].(*value.Array).GetValue()
+	if len(items) == 0 {
+		return nil, value.ThrowException("不能从空元组中选择")
+	}
+	return items[ml.random.Int63n(int64(len(items)))], nil
+}
+
+// getNumberProperty - get the property of the only number param, e.g. 绝对值
+func getNumberProperty(values []r.Element, name string) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "number"); err != nil {
+		return nil, err
+	}
+	return values[0].GetProperty(name)
+}
+
+// applyFloatFunc - apply fn on the only number param (without unit); results
+// that are not a number (NaN) are out of domain, e.g. (反正弦：2)
+func applyFloatFunc(values []r.Element, name string, fn func(float64) float64) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "number"); err != nil {
+		return nil, err
+	}
+	n := values[0].(*value.Number)
+	if n.GetUnit() != nil {
+		return nil, zerr.ArithIncompatibleUnits(n.GetUnit().String(), "")
+	}
+	res := fn(n.GetValue())
+	if gomath.IsNaN(res) {
+		return nil, zerr.ArithOutOfDomain(name)
+	}
+	return value.NewNumber(res), nil
+}
+
+// findExtremum - find the max (CmpGt) or min (CmpLt) item of params, or items of the only
+// array param. Items could be numbers (of compatible units), decimals, currencies or dates.
+func findExtremum(values []r.Element, verb uint8) (r.Element, error) {
+	if len(values) == 0 {
+		return nil, zerr.LeastParamsError(1)
+	}
+	items := values
+	if arr, ok := values[0].(*value.Array); ok && len(values) == 1 {
+		items = arr.GetValue()
+		if len(items) == 0 {
+			return nil, value.ThrowException("元组中没有可以比较的值")
+		}
+	}
+
+	result := items[0]
+	for _, item := range items[1:] {
+		ok, err := value.CompareValues(item, result, verb)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = item
+		}
+	}
+	return result, nil
+}
+
+func Export() *r.Library {
+	return mathLIB
+}
+
+// NewLibrary - build @数学 library that gets random numbers from the generator
+func NewLibrary(random *r.Random) *r.Library {
+	ml := &mathLib{random: random}
+	lib := r.NewLibrary(MATH_LIB_NAME)
+
+	lib.RegisterFunction("圆周率", value.NewFunction(FN_pi)).
+		RegisterFunction("自然常数", value.NewFunction(FN_e)).
+		RegisterFunction("绝对值", value.NewFunction(FN_abs)).
+		RegisterFunction("平方根", value.NewFunction(FN_sqrt)).
+		RegisterFunction("是否整数", value.NewFunction(FN_isInteger)).
+		RegisterFunction("幂", value.NewFunction(FN_pow)).
+		RegisterFunction("对数", value.NewFunction(FN_log)).
+		RegisterFunction("保留", value.NewFunction(FN_round)).
+		RegisterFunction("限制", value.NewFunction(FN_clamp)).
+		RegisterFunction("最大值", value.NewFunction(FN_max)).
+		RegisterFunction("最小值", value.NewFunction(FN_min)).
+		RegisterFunction("弧度", value.NewFunction(FN_radians)).
+		RegisterFunction("角度", value.NewFunction(FN_degrees)).
+		RegisterFunction("正弦", value.NewFunction(FN_sin)).
+		RegisterFunction("余弦", value.NewFunction(FN_cos)).
+		RegisterFunction("正切", value.NewFunction(FN_tan)).
+		RegisterFunction("反正弦", value.NewFunction(FN_asin)).
+		RegisterFunction("反余弦", value.NewFunction(FN_acos)).
+		RegisterFunction("反正切", value.NewFunction(FN_atan)).
+		RegisterFunction("随机数", value.NewFunction(ml.FN_random)).
+		RegisterFunction("随机整数", value.NewFunction(ml.FN_randomInt)).
+		RegisterFunction("随机选择", value.NewFunction(ml.FN_randomChoice)).
+		SetBinder(func(vm *r.VM) *r.Library {
+			return NewLibrary(vm.GetRandom())
+		})
+	return lib
+}
+
+func init() {
+	mathLIB = NewLibrary(r.NewRandom(gotime.Now().UnixNano()))
+}
@@ -0,0 +1,161 @@
+package math
+
+import (
+	"testing"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+func num(v float64) r.Element {
+	return value.NewNumber(v)
+}
+
+func decimal(t *testing.T, v string) r.Element {
+	d, err := value.NewDecimalFromString(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func numWithUnit(t *testing.T, v float64, unit string) r.Element {
+	u, err := value.ParseUnit(unit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value.NewNumberWithUnit(v, u)
+}
+
+func getErrorCode(err error) int {
+	if rerr, ok := err.(*zerr.RuntimeError); ok {
+		return rerr.Code
+	}
+	return 0
+}
+
+func TestMathFunctions(t *testing.T) {
+	cases := []struct {
+		name     string
+		fn       func(r.Element, []r.Element) (r.Element, error)
+		params   []r.Element
+		expected string
+	}{
+		{name: "abs", fn: FN_abs, params: []r.Element{num(-3)}, expected: "3"},
+		{name: "sqrt", fn: FN_sqrt, params: []r.Element{num(16)}, expected: "4"},
+		{name: "pow", fn: FN_pow, params: []r.Element{num(2), num(10)}, expected: "1024"},
+		{name: "log with base", fn: FN_log, params: []r.Element{num(1000), num(10)}, expected: "3"},
+		{name: "natural log", fn: FN_log, params: []r.Element{num(2.718281828459045)}, expected: "1"},
+		{name: "is integer", fn: FN_isInteger, params: []r.Element{num(3.5)}, expected: "假"},
+		{name: "round decimal", fn: FN_round, params: []r.Element{decimal(t, "2.675"), num(2)}, expected: "2.68"},
+		{name: "round half to even", fn: FN_round, params: []r.Element{decimal(t, "2.665"), num(2), value.NewString("银行家舍入")}, expected: "2.66"},
+		{name: "clamp to max", fn: FN_clamp, params: []r.Element{num(120), num(0), num(100)}, expected: "100"},
+		{name: "clamp to min", fn: FN_clamp, params: []r.Element{num(-5), num(0), num(100)}, expected: "0"},
+		{name: "max of params", fn: FN_max, params: []r.Element{num(3), num(7), num(5)}, expected: "7"},
+		{name: "min of array", fn: FN_min, params: []r.Element{value.NewArray([]r.Element{num(12.5), num(8), num(20.25)})}, expected: "8"},
+		{name: "sin", fn: FN_sin, params: []r.Element{num(1.5707963267948966)}, expected: "1"},
+		{name: "cos", fn: FN_cos, params: []r.Element{num(0)}, expected: "1"},
+		{name: "radians", fn: FN_radians, params: []r.Element{num(180)}, expected: "3.141592653589793"},
+		{name: "degrees", fn: FN_degrees, params: []r.Element{num(0.7853981633974483)}, expected: "45"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.fn(nil, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.String() != tt.expected {
+				t.Errorf("expect %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestMathFunctions_FAIL(t *testing.T) {
+	ml := &mathLib{random: r.NewRandom(1)}
+	cases := []struct {
+		name    string
+		fn      func(r.Element, []r.Element) (r.Element, error)
+		params  []r.Element
+		errCode int
+	}{
+		{name: "out of domain", fn: FN_asin, params: []r.Element{num(2)}, errCode: zerr.ErrArithOutOfDomain},
+		{name: "number with unit", fn: FN_sin, params: []r.Element{numWithUnit(t, 1, "kg")}, errCode: zerr.ErrArithIncompatibleUnits},
+		{name: "huge scale to round", fn: FN_round, params: []r.Element{decimal(t, "2.675"), num(1000000000)}, errCode: zerr.ErrInvalidParamType},
+		{name: "round a text", fn: FN_round, params: []r.Element{value.NewString("2.675"), num(2)}, errCode: zerr.ErrInvalidParamType},
+		{name: "no values to compare", fn: FN_min, params: []r.Element{}, errCode: zerr.ErrLeastParamsError},
+		{name: "too many params", fn: FN_log, params: []r.Element{num(1), num(2), num(3)}, errCode: zerr.ErrMostParamsError},
+		{name: "range of random integers too large", fn: ml.FN_randomInt, params: []r.Element{num(0), num(1e300)}, errCode: zerr.ErrArithOutOfDomain},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.fn(nil, tt.params)
+			if code := getErrorCode(err); code != tt.errCode {
+				t.Errorf("expect error code %d, got %v", tt.errCode, err)
+			}
+		})
+	}
+
+	// exceptions without code
+	for name, fn := range map[string]func() (r.Element, error){
+		"min of empty array": func() (r.Element, error) {
+			return FN_min(nil, []r.Element{value.NewEmptyArray()})
+		},
+		"min is greater than max": func() (r.Element, error) {
+			return ml.FN_randomInt(nil, []r.Element{num(6), num(1)})
+		},
+		"choose from empty array": func() (r.Element, error) {
+			return ml.FN_randomChoice(nil, []r.Element{value.NewEmptyArray()})
+		},
+	} {
+		if _, err := fn(); err == nil {
+			t.Errorf("%s: expect error, got nil", name)
+		}
+	}
+}
+
+func TestMathRandom(t *testing.T) {
+	run := func(seed int64) []float64 {
+		ml := &mathLib{random: r.NewRandom(seed)}
+		var results []float64
+		for i := 0; i < 5; i++ {
+			n, err := ml.FN_random(nil, []r.Element{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			results = append(results, n.(*value.Number).GetValue())
+		}
+		return results
+	}
+
+	// the same seed gives the same numbers
+	expected := run(42)
+	for idx, n := range run(42) {
+		if n != expected[idx] || n < 0 || n >= 1 {
+			t.Errorf("expect %v, got %v", expected[idx], n)
+		}
+	}
+
+	// random integers & choices are within the range
+	ml := &mathLib{random: r.NewRandom(7)}
+	items := value.NewArray([]r.Element{value.NewString("甲"), value.NewString("乙")})
+	for i := 0; i < 20; i++ {
+		n, err := ml.FN_randomInt(nil, []r.Element{num(0.5), num(3.5)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s := n.String(); s != "1" && s != "2" && s != "3" {
+			t.Errorf("expect 1 ~ 3, got %s", s)
+		}
+		item, err := ml.FN_randomChoice(nil, []r.Element{items})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s := item.String(); s != "甲" && s != "乙" {
+			t.Errorf("expect 甲 or 乙, got %s", s)
+		}
+	}
+}
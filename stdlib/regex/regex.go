@@ -0,0 +1,361 @@
+package regex
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	zerr "github.com/DemoHn/Zn/pkg/error"
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+const STDLIB_REGEX_NAME = "@正则"
+
+// GOVALUE_TAG_REGEXP - tag of the GoValue that holds the compiled regexp
+const GOVALUE_TAG_REGEXP = "regexp"
+
+var regexLIB *r.Library
+
+// CLASS_Regex - a compiled regular expression (RE2 syntax), created by （编译正则：模式）
+// or （新建正则：模式）. The compiled pattern is kept in the property 编译结果 as a GoValue.
+var CLASS_Regex *value.ClassModel
+
+// compiledRegex - the compiled regexp. Since Go (RE2) only accepts group names of word chars,
+// named groups (e.g. `(?P<编号>...)`) are renamed to `zn_gN` before compiling, and names holds
+// the original names of all groups (by index, "" for unnamed groups).
+type compiledRegex struct {
+	re    *regexp.Regexp
+	names []string
+}
+
+// regexExecutor - the logic of a function (or method) with the regexp & remaining params
+type regexExecutor func(re *compiledRegex, values []r.Element) (r.Element, error)
+
+func regexConstructor(self r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	re, err := compileRegex(values[0].(*value.String).String())
+	if err != nil {
+		return nil, err
+	}
+	self.SetProperty("模式", values[0])
+	self.SetProperty("编译结果", value.NewGoValue(GOVALUE_TAG_REGEXP, re))
+	return self, nil
+}
+
+// (编译正则：模式)
+func FN_compile(receiver r.Element, values []r.Element) (r.Element, error) {
+	return CLASS_Regex.Construct(values)
+}
+
+// newRegexMethod - method of 正则 objects, e.g. 以R（测试：文本）
+func newRegexMethod(executor regexExecutor) *value.Function {
+	return value.NewFunction(func(receiver r.Element, values []r.Element) (r.Element, error) {
+		re, err := getRegex(receiver)
+		if err != nil {
+			return nil, err
+		}
+		return executor(re, values)
+	})
+}
+
+// newRegexFunction - function of @正则 where the first param is the regex (a 正则 object or
+// the pattern text), e.g. （正则测试：“\d+”、文本）
+func newRegexFunction(executor regexExecutor) *value.Function {
+	return value.NewFunction(func(receiver r.Element, values []r.Element) (r.Element, error) {
+		if len(values) == 0 {
+			return nil, zerr.LeastParamsError(1)
+		}
+		re, err := getRegex(values[0])
+		if err != nil {
+			return nil, err
+		}
+		return executor(re, values[1:])
+	})
+}
+
+// getRegex - get the compiled regexp from a 正则 object, or compile the pattern text
+func getRegex(elem r.Element) (*compiledRegex, error) {
+	switch v := elem.(type) {
+	case *value.String:
+		return compileRegex(v.String())
+	case *value.Object:
+		if v.IsInstanceOf(CLASS_Regex) {
+			prop, err := v.GetProperty("编译结果")
+			if err != nil {
+				return nil, err
+			}
+			if gv, ok := prop.(*value.GoValue); ok && gv.GetTag() == GOVALUE_TAG_REGEXP {
+				if re, ok := gv.GetValue().(*compiledRegex); ok && re != nil {
+					return re, nil
+				}
+			}
+			return nil, value.ThrowException("正则表达式尚未编译")
+		}
+	}
+	return nil, zerr.InvalidParamType("string", "object")
+}
+
+func compileRegex(pattern string) (*compiledRegex, error) {
+	rewritten, groupNames := renameGroups(pattern)
+	re, err := regexp.Compile(rewritten)
+	if err != nil {
+		return nil, value.ThrowException("正则表达式「" + pattern + "」格式有误：" + err.Error())
+	}
+	names := re.SubexpNames()
+	for idx, name := range names {
+		if strings.HasPrefix(name, groupNamePrefix) {
+			n, _ := strconv.Atoi(strings.TrimPrefix(name, groupNamePrefix))
+			names[idx] = groupNames[n]
+		}
+	}
+	return &compiledRegex{re, names}, nil
+}
+
+const groupNamePrefix = "zn_g"
+
+// renameGroups - rename named groups `(?P<名称>` or `(?<名称>` to `(?P<zn_gN>`, where N is the
+// index of groupNames. Escaped chars and chars inside [...] are skipped.
+func renameGroups(pattern string) (string, []string) {
+	var sb strings.Builder
+	groupNames := []string{}
+	inClass := false
+	chars := []rune(pattern)
+	for i := 0; i < len(chars); i++ {
+		ch := chars[i]
+		switch {
+		case ch == '\\' && i+1 < len(chars):
+			sb.WriteRune(ch)
+			sb.WriteRune(chars[i+1])
+			i++
+			continue
+		case inClass:
+			if ch == ']' {
+				inClass = false
+			}
+		case ch == '[':
+			inClass = true
+			// `]` right after `[` or `[^` is a literal char
+			if i+1 < len(chars) && chars[i+1] == '^' {
+				sb.WriteRune(ch)
+				ch, i = '^', i+1
+			}
+			if i+1 < len(chars) && chars[i+1] == ']' {
+				sb.WriteRune(ch)
+				ch, i = ']', i+1
+			}
+		case ch == '(':
+			if name, length, ok := matchNamedGroup(string(chars[i:])); ok {
+				sb.WriteString("(?P<" + groupNamePrefix + strconv.Itoa(len(groupNames)) + ">")
+				groupNames = append(groupNames, name)
+				i += length - 1
+				continue
+			}
+		}
+		sb.WriteRune(ch)
+	}
+	return sb.String(), groupNames
+}
+
+// matchNamedGroup - match `(?P<名称>` or `(?<名称>` at the beginning of the text,
+// returns the name and the length (in runes) of the matched text
+func matchNamedGroup(text string) (string, int, bool) {
+	for _, prefix := range []string{"(?P<", "(?<"} {
+		if !strings.HasPrefix(text, prefix) {
+			continue
+		}
+		end := strings.IndexRune(text, '>')
+		if end < 0 {
+			return "", 0, false
+		}
+		return text[len(prefix):end], len([]rune(text[:end+1])), true
+	}
+	return "", 0, false
+}
+
+// expandTemplate - rewrite group names in the replacement template (e.g. `${编号}`, `$编号`)
+// to the renamed ones, see renameGroups()
+func (cr *compiledRegex) expandTemplate(template string) string {
+	indexOf := func(name string) (int, bool) {
+		for idx, n := range cr.names {
+			if idx > 0 && n == name && n != "" {
+				return idx, true
+			}
+		}
+		return 0, false
+	}
+
+	var sb strings.Builder
+	chars := []rune(template)
+	for i := 0; i < len(chars); i++ {
+		if chars[i] != '$' || i+1 >= len(chars) {
+			sb.WriteRune(chars[i])
+			continue
+		}
+		// $$ -> $
+		if chars[i+1] == '$' {
+			sb.WriteString("$$")
+			i++
+			continue
+		}
+		// ${name} or $name
+		var name string
+		var j int
+		if chars[i+1] == '{' {
+			end := strings.IndexRune(string(chars[i+2:]), '}')
+			if end < 0 {
+				sb.WriteRune(chars[i])
+				continue
+			}
+			name = string(chars[i+2:])[:end]
+			j = i + 2 + len([]rune(name)) + 1
+		} else {
+			j = i + 1
+			for j < len(chars) && (unicode.IsLetter(chars[j]) || unicode.IsDigit(chars[j]) || chars[j] == '_') {
+				j++
+			}
+			name = string(chars[i+1 : j])
+		}
+		if idx, ok := indexOf(name); ok {
+			sb.WriteString("${" + strconv.Itoa(idx) + "}")
+		} else {
+			sb.WriteString(string(chars[i:j]))
+		}
+		i = j - 1
+	}
+	return sb.String()
+}
+
+// 测试：文本 -> 逻辑
+func execTest(re *compiledRegex, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	return value.NewBool(re.re.MatchString(values[0].(*value.String).String())), nil
+}
+
+// 查找：文本 -> 第一处匹配的文本，无匹配时为空
+func execFind(re *compiledRegex, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	text := values[0].(*value.String).String()
+	loc := re.re.FindStringIndex(text)
+	if loc == nil {
+		return value.NewNull(), nil
+	}
+	return value.NewString(text[loc[0]:loc[1]]), nil
+}
+
+// 查找全部：文本 -> 所有匹配文本组成的数组
+func execFindAll(re *compiledRegex, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	arr := value.NewEmptyArray()
+	for _, match := range re.re.FindAllString(values[0].(*value.String).String(), -1) {
+		arr.AppendValue(value.NewString(match))
+	}
+	return arr, nil
+}
+
+// 提取：文本 -> 第一处匹配的分组（列表），无匹配时为空
+func execExtract(re *compiledRegex, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	text := values[0].(*value.String).String()
+	loc := re.re.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return value.NewNull(), nil
+	}
+	return buildGroupHashMap(re, text, loc), nil
+}
+
+// 提取全部：文本 -> 所有匹配的分组（列表）组成的数组
+func execExtractAll(re *compiledRegex, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string"); err != nil {
+		return nil, err
+	}
+	text := values[0].(*value.String).String()
+	arr := value.NewEmptyArray()
+	for _, loc := range re.re.FindAllStringSubmatchIndex(text, -1) {
+		arr.AppendValue(buildGroupHashMap(re, text, loc))
+	}
+	return arr, nil
+}
+
+// 替换：文本、替换文本 -> 替换所有匹配后的文本。替换文本中可以用 ${1}、${名称} 引用分组
+func execReplace(re *compiledRegex, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "string", "string"); err != nil {
+		return nil, err
+	}
+	text := values[0].(*value.String).String()
+	repl := values[1].(*value.String).String()
+	return value.NewString(re.re.ReplaceAllString(text, re.expandTemplate(repl))), nil
+}
+
+// 分割：文本、次数 -> 以匹配处分割得到的数组；次数可省略，即分割全部
+func execSplit(re *compiledRegex, values []r.Element) (r.Element, error) {
+	if err := value.ValidateLeastParams(values, "string", "number?"); err != nil {
+		return nil, err
+	}
+	n := -1
+	if len(values) > 1 {
+		n = int(values[1].(*value.Number).GetValue())
+	}
+	arr := value.NewEmptyArray()
+	for _, part := range re.re.Split(values[0].(*value.String).String(), n) {
+		arr.AppendValue(value.NewString(part))
+	}
+	return arr, nil
+}
+
+// buildGroupHashMap - the whole match is keyed by “0”; named groups are keyed by their names,
+// and other groups by their indexes (e.g. “1”). Unmatched groups are 空.
+func buildGroupHashMap(re *compiledRegex, text string, loc []int) *value.HashMap {
+	hm := value.NewEmptyHashMap()
+	for idx, name := range re.names {
+		if name == "" {
+			name = strconv.Itoa(idx)
+		}
+		var elem r.Element = value.NewNull()
+		if start, end := loc[2*idx], loc[2*idx+1]; start >= 0 {
+			elem = value.NewString(text[start:end])
+		}
+		hm.AppendKVPair(value.KVPair{Key: name, Value: elem})
+	}
+	return hm
+}
+
+func Export() *r.Library {
+	return regexLIB
+}
+
+func init() {
+	CLASS_Regex = value.NewClassModel("正则").
+		DefineProperty("模式", value.NewString("")).
+		DefineProperty("编译结果", value.NewGoValue(GOVALUE_TAG_REGEXP, nil)).
+		SetConstructor(regexConstructor).
+		DefineMethod("测试", newRegexMethod(execTest)).
+		DefineMethod("查找", newRegexMethod(execFind)).
+		DefineMethod("查找全部", newRegexMethod(execFindAll)).
+		DefineMethod("提取", newRegexMethod(execExtract)).
+		DefineMethod("提取全部", newRegexMethod(execExtractAll)).
+		DefineMethod("替换", newRegexMethod(execReplace)).
+		DefineMethod("分割", newRegexMethod(execSplit))
+
+	regexLIB = r.NewLibrary(STDLIB_REGEX_NAME)
+	regexLIB.RegisterClass("正则", CLASS_Regex).
+		RegisterFunction("编译正则", value.NewFunction(FN_compile)).
+		RegisterFunction("正则测试", newRegexFunction(execTest)).
+		RegisterFunction("正则查找", newRegexFunction(execFind)).
+		RegisterFunction("正则查找全部", newRegexFunction(execFindAll)).
+		RegisterFunction("正则提取", newRegexFunction(execExtract)).
+		RegisterFunction("正则提取全部", newRegexFunction(execExtractAll)).
+		RegisterFunction("正则替换", newRegexFunction(execReplace)).
+		RegisterFunction("正则分割", newRegexFunction(execSplit))
+}
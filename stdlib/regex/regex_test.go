@@ -0,0 +1,153 @@
+package regex
+
+import (
+	"testing"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+const testText = "订单A-1024于3月发货，电话13800138000；订单B-77未发货"
+
+func TestRegexExecutors(t *testing.T) {
+	cases := []struct {
+		name     string
+		pattern  string
+		executor regexExecutor
+		params   []r.Element
+		expected string
+	}{
+		{
+			name:     "test",
+			pattern:  `^订单`,
+			executor: execTest,
+			params:   []r.Element{value.NewString(testText)},
+			expected: "真",
+		},
+		{
+			name:     "find",
+			pattern:  `1[3-9]\d{9}`,
+			executor: execFind,
+			params:   []r.Element{value.NewString(testText)},
+			expected: "13800138000",
+		},
+		{
+			name:     "find nothing",
+			pattern:  `x+`,
+			executor: execFind,
+			params:   []r.Element{value.NewString(testText)},
+			expected: "空",
+		},
+		{
+			name:     "find all",
+			pattern:  `订单[A-Z]-\d+`,
+			executor: execFindAll,
+			params:   []r.Element{value.NewString(testText)},
+			expected: "[订单A-1024，订单B-77]",
+		},
+		{
+			name:     "extract named groups",
+			pattern:  `订单(?P<类别>[A-Z])-(?<编号>\d+)`,
+			executor: execExtract,
+			params:   []r.Element{value.NewString(testText)},
+			expected: "[0=订单A-1024，类别=A，编号=1024]",
+		},
+		{
+			name:     "extract unmatched groups",
+			pattern:  `(a)|(b)`,
+			executor: execExtract,
+			params:   []r.Element{value.NewString("b")},
+			expected: "[0=b，1=空，2=b]",
+		},
+		{
+			name:     "extract all",
+			pattern:  `订单(?P<类别>[A-Z])-(?P<编号>\d+)`,
+			executor: execExtractAll,
+			params:   []r.Element{value.NewString(testText)},
+			expected: "[[0=订单A-1024，类别=A，编号=1024]，[0=订单B-77，类别=B，编号=77]]",
+		},
+		{
+			name:     "replace with named groups",
+			pattern:  `订单(?P<类别>[A-Z])-(?P<编号>\d+)`,
+			executor: execReplace,
+			params:   []r.Element{value.NewString(testText), value.NewString("#${编号}")},
+			expected: "#1024于3月发货，电话13800138000；#77未发货",
+		},
+		{
+			name:     "replace with group indexes",
+			pattern:  `(\d+)-(\d+)`,
+			executor: execReplace,
+			params:   []r.Element{value.NewString("1-2"), value.NewString("${2}-${1}")},
+			expected: "2-1",
+		},
+		{
+			name:     "replace with dollar signs",
+			pattern:  `a`,
+			executor: execReplace,
+			params:   []r.Element{value.NewString("abc"), value.NewString("$$")},
+			expected: "$bc",
+		},
+		{
+			name:     "split",
+			pattern:  `[，；]`,
+			executor: execSplit,
+			params:   []r.Element{value.NewString(testText)},
+			expected: "[订单A-1024于3月发货，电话13800138000，订单B-77未发货]",
+		},
+		{
+			name:     "split with count",
+			pattern:  `x+`,
+			executor: execSplit,
+			params:   []r.Element{value.NewString("axxbxc"), value.NewNumber(2)},
+			expected: "[a，bxc]",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := compileRegex(tt.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result, err := tt.executor(re, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.String() != tt.expected {
+				t.Errorf("expect %s, got %s", tt.expected, result.String())
+			}
+		})
+	}
+}
+
+func TestRenameGroups(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		expected string
+		names    []string
+	}{
+		{`(?P<类别>[A-Z])-(?<编号>\d+)`, `(?P<zn_g0>[A-Z])-(?P<zn_g1>\d+)`, []string{"类别", "编号"}},
+		{`\(?P<x>)`, `\(?P<x>)`, []string{}},
+		{`[(?P<x>]`, `[(?P<x>]`, []string{}},
+		{`[](?P<x>a)]`, `[](?P<x>a)]`, []string{}},
+		{`(a)(?:b)`, `(a)(?:b)`, []string{}},
+	}
+	for _, tt := range cases {
+		rewritten, names := renameGroups(tt.pattern)
+		if rewritten != tt.expected || len(names) != len(tt.names) {
+			t.Errorf("%s: expect %s %v, got %s %v", tt.pattern, tt.expected, tt.names, rewritten, names)
+			continue
+		}
+		for idx := range names {
+			if names[idx] != tt.names[idx] {
+				t.Errorf("%s: expect names %v, got %v", tt.pattern, tt.names, names)
+			}
+		}
+	}
+}
+
+func TestCompileRegex_Invalid(t *testing.T) {
+	if _, err := compileRegex("("); err == nil {
+		t.Errorf("expect error for invalid pattern, got nil")
+	}
+}
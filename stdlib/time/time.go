@@ -0,0 +1,158 @@
+package time
+
+import (
+	"context"
+	gotime "time"
+
+	r "github.com/DemoHn/Zn/pkg/runtime"
+	"github.com/DemoHn/Zn/pkg/value"
+)
+
+const TIME_LIB_NAME = "@时间"
+
+var timeLIB *r.Library
+
+// timeLib - functions of @时间, the current time is got from the clock of the VM
+// (so that it could be frozen for tests)
+type timeLib struct {
+	clock r.Clock
+	ctx   context.Context
+}
+
+// (当前时间) or (当前时间：时区)
+func (tl *timeLib) FN_now(receiver r.Element, values []r.Element) (r.Element, error) {
+	now, err := tl.getNow(values)
+	if err != nil {
+		return nil, err
+	}
+	return value.NewDateTime(now, false), nil
+}
+
+// (今天) or (今天：时区)
+func (tl *timeLib) FN_today(receiver r.Element, values []r.Element) (r.Element, error) {
+	now, err := tl.getNow(values)
+	if err != nil {
+		return nil, err
+	}
+	return value.NewDateTime(now, true), nil
+}
+
+// (解析时间：文本、格式、时区) - 格式 and 时区 are optional
+func (tl *timeLib) FN_parse(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 1, 3); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateAllParams(values, "string"); err != nil {
+		return nil, err
+	}
+	text := values[0].(*value.String).String()
+	layout := ""
+	if len(values) > 1 {
+		layout = values[1].(*value.String).String()
+	}
+	loc, err := getLocation(values, 2)
+	if err != nil {
+		return nil, err
+	}
+	return value.ParseDateTime(text, layout, loc, false)
+}
+
+// (格式化时间：日期、格式、时区) - 时区 is optional
+func (tl *timeLib) FN_format(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateParamsCount(values, 2, 3); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateExactParams(values[:2], "datetime", "string"); err != nil {
+		return nil, err
+	}
+	if err := value.ValidateAllParams(values[2:], "string"); err != nil {
+		return nil, err
+	}
+	dt := values[0].(*value.DateTime)
+	loc, err := getLocation(values, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) > 2 {
+		dt = value.NewDateTime(dt.GetValue().In(loc), false)
+	}
+	return value.NewString(dt.Format(values[1].(*value.String).String())), nil
+}
+
+// (等待：时长) - 时长 is a number with unit of 时间 (e.g. 500ms, 2秒); plain numbers are in seconds
+func (tl *timeLib) FN_sleep(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "number"); err != nil {
+		return nil, err
+	}
+	dur, err := getDuration(values[0].(*value.Number))
+	if err != nil {
+		return nil, err
+	}
+	if err := tl.clock.Sleep(tl.ctx, dur); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// (经过时间：起点) - the duration from 起点 to now, e.g. 1.5秒
+func (tl *timeLib) FN_elapsed(receiver r.Element, values []r.Element) (r.Element, error) {
+	if err := value.ValidateExactParams(values, "datetime"); err != nil {
+		return nil, err
+	}
+	start := values[0].(*value.DateTime)
+	return value.NewDurationNumber(tl.clock.Now().Sub(start.GetValue())), nil
+}
+
+func (tl *timeLib) getNow(values []r.Element) (gotime.Time, error) {
+	if err := value.ValidateParamsCount(values, 0, 1); err != nil {
+		return gotime.Time{}, err
+	}
+	if err := value.ValidateAllParams(values, "string"); err != nil {
+		return gotime.Time{}, err
+	}
+	loc, err := getLocation(values, 0)
+	if err != nil {
+		return gotime.Time{}, err
+	}
+	return tl.clock.Now().In(loc), nil
+}
+
+// getLocation - get the timezone from values[idx] if exists, otherwise the local timezone
+func getLocation(values []r.Element, idx int) (*gotime.Location, error) {
+	if len(values) <= idx {
+		return gotime.Local, nil
+	}
+	return value.LoadLocation(values[idx].(*value.String).String())
+}
+
+func getDuration(n *value.Number) (gotime.Duration, error) {
+	if n.GetUnit() == nil {
+		return gotime.Duration(n.GetValue() * float64(gotime.Second)), nil
+	}
+	return value.NumberToDuration(n)
+}
+
+func Export() *r.Library {
+	return timeLIB
+}
+
+// NewLibrary - build @时间 library that uses the clock; the sleeping stops once ctx is done
+func NewLibrary(clock r.Clock, ctx context.Context) *r.Library {
+	tl := &timeLib{clock: clock, ctx: ctx}
+	lib := r.NewLibrary(TIME_LIB_NAME)
+
+	lib.RegisterFunction("当前时间", value.NewFunction(tl.FN_now)).
+		RegisterFunction("今天", value.NewFunction(tl.FN_today)).
+		RegisterFunction("解析时间", value.NewFunction(tl.FN_parse)).
+		RegisterFunction("格式化时间", value.NewFunction(tl.FN_format)).
+		RegisterFunction("等待", value.NewFunction(tl.FN_sleep)).
+		RegisterFunction("经过时间", value.NewFunction(tl.FN_elapsed)).
+		SetBinder(func(vm *r.VM) *r.Library {
+			return NewLibrary(vm.GetClock(), vm.GetContext())
+		})
+	return lib
+}
+
+func init() {
+	timeLIB = NewLibrary(r.SystemClock, context.Background())
+}
@@ -7,9 +7,14 @@ import (
 	"github.com/DemoHn/Zn/pkg/value"
 
 	// stdlibs
+	libCsv "github.com/DemoHn/Zn/stdlib/csv"
+	libEncoding "github.com/DemoHn/Zn/stdlib/encoding"
 	libFile "github.com/DemoHn/Zn/stdlib/file"
 	libHttp "github.com/DemoHn/Zn/stdlib/http"
 	libJson "github.com/DemoHn/Zn/stdlib/json"
+	libMath "github.com/DemoHn/Zn/stdlib/math"
+	libRegex "github.com/DemoHn/Zn/stdlib/regex"
+	libTime "github.com/DemoHn/Zn/stdlib/time"
 )
 
 type Element = runtime.Element
@@ -39,12 +44,22 @@ var NewHttpHandler = server.NewZnHttpHandler
 var NewPMServer = server.NewZnPMServer
 var NewThreadServer = server.NewZnThreadServer
 
+// caches
+type ProgramCache = exec.ProgramCache
+
+var NewProgramCache = exec.NewProgramCache
+
 const ZINC_VERSION = "rev08"
 
 var StandardLibs = []*runtime.Library{
 	libHttp.Export(),
 	libJson.Export(),
 	libFile.Export(),
+	libTime.Export(),
+	libRegex.Export(),
+	libMath.Export(),
+	libEncoding.Export(),
+	libCsv.Export(),
 }
 
 // ZnInterpreter - MAIN CODE EXECUTION INSTANCE -